@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// component is a prerequisite installed by forge-infra. Its manifests are
+// vendored below the releases directory:
+//
+//	<releases>/<name>/*.yaml       the operator itself, applied first
+//	<releases>/<name>/post/*.yaml  objects that need the running operator,
+//	                               e.g. a ClusterIssuer, applied once it is ready
+type component struct {
+	// Name is the component's directory below the releases directory.
+	Name string
+	// Namespace is created before the manifests are applied and used for
+	// namespaced objects that do not set one.
+	Namespace string
+	// ReadyKinds lists the kinds of custom resources in the post manifests
+	// that must report a Ready=True condition.
+	ReadyKinds []string
+	// CRDs is a directory below the CRDs directory whose manifests are
+	// applied together with the component's own. It serves components
+	// without an upstream release that ships their CRDs.
+	CRDs string
+}
+
+// components is the ordered list of prerequisites. cert-manager comes first
+// because the other operators' webhooks may depend on it.
+var components = []component{
+	{Name: "cert-manager", Namespace: "cert-manager", ReadyKinds: []string{"ClusterIssuer"}},
+	{Name: "external-secrets", Namespace: "external-secrets", ReadyKinds: []string{"ClusterSecretStore"}},
+	{Name: "mariadb-operator", Namespace: "mariadb-operator"},
+	{Name: "memcached", Namespace: "memcached", CRDs: "memcached"},
+	{Name: "rabbitmq-operator", Namespace: "rabbitmq-system"},
+}
+
+// selectComponents returns the components named in the comma-separated list
+// in installation order, or all components if the list is empty.
+func selectComponents(list string) ([]component, error) {
+	if strings.TrimSpace(list) == "" {
+		return components, nil
+	}
+
+	wanted := map[string]bool{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !knownComponent(name) {
+			return nil, fmt.Errorf("unknown component %q (known: %s)", name, strings.Join(componentNames(), ", "))
+		}
+		wanted[name] = true
+	}
+
+	var selected []component
+	for _, comp := range components {
+		if wanted[comp.Name] {
+			selected = append(selected, comp)
+		}
+	}
+	return selected, nil
+}
+
+func knownComponent(name string) bool {
+	for _, comp := range components {
+		if comp.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func componentNames() []string {
+	names := make([]string, 0, len(components))
+	for _, comp := range components {
+		names = append(names, comp.Name)
+	}
+	return names
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSelectComponents(t *testing.T) {
+	tests := []struct {
+		name      string
+		list      string
+		want      []string
+		wantError bool
+	}{
+		{
+			name: "empty list selects all",
+			list: "",
+			want: componentNames(),
+		},
+		{
+			name: "installation order is kept",
+			list: "rabbitmq-operator, cert-manager",
+			want: []string{"cert-manager", "rabbitmq-operator"},
+		},
+		{
+			name:      "unknown component",
+			list:      "cert-manager,vault",
+			wantError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			selected, err := selectComponents(tc.list)
+			if tc.wantError {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			names := make([]string, 0, len(selected))
+			for _, comp := range selected {
+				names = append(names, comp.Name)
+			}
+			g.Expect(names).To(Equal(tc.want))
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fieldManager owns every field forge-infra applies. Re-running the command
+// server-side applies the same manifests again, which makes it idempotent.
+const fieldManager = "forge-infra"
+
+// installer applies the vendored manifests of components and waits for them
+// to become ready.
+type installer struct {
+	client      client.Client
+	releasesDir string
+	// crdsDir holds the CRDs of components without an upstream release,
+	// one subdirectory per component (see component.CRDs).
+	crdsDir string
+	// timeout bounds the installation of a single component.
+	timeout time.Duration
+	// interval is the delay between readiness checks and apply retries.
+	interval time.Duration
+	log      logr.Logger
+}
+
+// install brings up comp: the operator manifests and the component's CRDs
+// are applied and waited for, then the post manifests that need the running
+// operator.
+func (i *installer) install(ctx context.Context, comp component) error {
+	dir := filepath.Join(i.releasesDir, comp.Name)
+	objs, err := loadManifests(dir)
+	if err != nil {
+		return err
+	}
+	if len(objs) == 0 {
+		return fmt.Errorf("no manifests vendored in %s; run make vendor-infra", dir)
+	}
+	if comp.CRDs != "" {
+		crds, err := loadManifests(filepath.Join(i.crdsDir, comp.CRDs))
+		if err != nil {
+			return err
+		}
+		if len(crds) == 0 {
+			return fmt.Errorf("no CRDs found in %s", filepath.Join(i.crdsDir, comp.CRDs))
+		}
+		objs = append(crds, objs...)
+	}
+	post, err := loadManifests(filepath.Join(dir, "post"))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, i.timeout)
+	defer cancel()
+
+	log := i.log.WithValues("component", comp.Name)
+	log.Info("applying manifests", "objects", len(objs))
+	if err := i.applyAndWait(ctx, comp, append([]*unstructured.Unstructured{namespaceObject(comp.Namespace)}, objs...)); err != nil {
+		return err
+	}
+	if len(post) > 0 {
+		log.Info("applying post-install manifests", "objects", len(post))
+		if err := i.applyAndWait(ctx, comp, post); err != nil {
+			return err
+		}
+	}
+	log.Info("ready")
+	return nil
+}
+
+// applyAndWait applies CRDs first and waits until they are established, so
+// that custom resources in the same set can be mapped, then applies and
+// waits for everything else.
+func (i *installer) applyAndWait(ctx context.Context, comp component, objs []*unstructured.Unstructured) error {
+	sortForApply(objs)
+
+	var crds, rest []*unstructured.Unstructured
+	for _, obj := range objs {
+		if obj.GetKind() == "CustomResourceDefinition" {
+			crds = append(crds, obj)
+		} else {
+			rest = append(rest, obj)
+		}
+	}
+
+	for _, batch := range [][]*unstructured.Unstructured{crds, rest} {
+		if len(batch) == 0 {
+			continue
+		}
+		if err := i.apply(ctx, comp, batch); err != nil {
+			return err
+		}
+		if err := i.waitReady(ctx, comp, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply server-side applies objs. Failures are retried until the context
+// expires because admission webhooks of a freshly installed operator may not
+// be serving yet.
+func (i *installer) apply(ctx context.Context, comp component, objs []*unstructured.Unstructured) error {
+	var lastErr error
+	pending := objs
+	err := wait.PollUntilContextCancel(ctx, i.interval, true, func(ctx context.Context) (bool, error) {
+		var failed []*unstructured.Unstructured
+		for _, obj := range pending {
+			if err := i.applyObject(ctx, comp, obj); err != nil {
+				lastErr = err
+				failed = append(failed, obj)
+			}
+		}
+		pending = failed
+		if len(pending) > 0 {
+			i.log.V(1).Info("retrying apply", "component", comp.Name, "pending", len(pending), "error", lastErr.Error())
+		}
+		return len(pending) == 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("applying %s: %w", comp.Name, errors.Join(err, lastErr))
+	}
+	return nil
+}
+
+func (i *installer) applyObject(ctx context.Context, comp component, obj *unstructured.Unstructured) error {
+	obj = obj.DeepCopy()
+	if obj.GetNamespace() == "" {
+		namespaced, err := i.client.IsObjectNamespaced(obj)
+		if err != nil {
+			return fmt.Errorf("resolving scope of %s: %w", describe(obj), err)
+		}
+		if namespaced {
+			obj.SetNamespace(comp.Namespace)
+		}
+	}
+
+	if err := i.client.Apply(ctx, client.ApplyConfigurationFromUnstructured(obj), client.FieldOwner(fieldManager), client.ForceOwnership); err != nil {
+		return fmt.Errorf("applying %s: %w", describe(obj), err)
+	}
+	return nil
+}
+
+// waitReady polls objs until isReady reports all of them ready.
+func (i *installer) waitReady(ctx context.Context, comp component, objs []*unstructured.Unstructured) error {
+	var waitingFor string
+	err := wait.PollUntilContextCancel(ctx, i.interval, true, func(ctx context.Context) (bool, error) {
+		for _, obj := range objs {
+			current := &unstructured.Unstructured{}
+			current.SetGroupVersionKind(obj.GroupVersionKind())
+			key := client.ObjectKeyFromObject(obj)
+			if key.Namespace == "" {
+				if namespaced, err := i.client.IsObjectNamespaced(obj); err == nil && namespaced {
+					key.Namespace = comp.Namespace
+				}
+			}
+			if err := i.client.Get(ctx, key, current); err != nil {
+				waitingFor = fmt.Sprintf("%s: %v", describe(obj), err)
+				return false, nil
+			}
+			if ready, reason := isReady(current, comp.ReadyKinds); !ready {
+				if waiting := fmt.Sprintf("%s: %s", describe(obj), reason); waiting != waitingFor {
+					waitingFor = waiting
+					i.log.Info("waiting", "component", comp.Name, "for", waitingFor)
+				}
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("waiting for %s (%s): %w", comp.Name, waitingFor, err)
+	}
+	return nil
+}
+
+func namespaceObject(name string) *unstructured.Unstructured {
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(name)
+	return ns
+}
+
+func describe(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetKind() + " " + obj.GetName()
+	}
+	return obj.GetKind() + " " + obj.GetNamespace() + "/" + obj.GetName()
+}
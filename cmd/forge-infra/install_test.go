@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func newTestInstaller(c client.Client, releasesDir string) *installer {
+	return &installer{
+		client:      c,
+		releasesDir: releasesDir,
+		crdsDir:     filepath.Join(releasesDir, "crds"),
+		timeout:     time.Second,
+		interval:    10 * time.Millisecond,
+		log:         log.Log,
+	}
+}
+
+func TestInstall(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+
+	releases := t.TempDir()
+	dir := filepath.Join(releases, "widgets")
+	g.Expect(os.MkdirAll(filepath.Join(dir, "post"), 0o700)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(dir, "operator.yaml"),
+		[]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: operator-config\ndata:\n  level: info\n"), 0o600)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(dir, "post", "store.yaml"),
+		[]byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: store\n  namespace: shared\nstringData:\n  key: value\n"), 0o600)).To(Succeed())
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(testrestmapper.TestOnlyStaticRESTMapper(scheme)).Build()
+	inst := newTestInstaller(c, releases)
+	comp := component{Name: "widgets", Namespace: "widgets-system"}
+
+	// The second run must converge on the same state.
+	for range 2 {
+		g.Expect(inst.install(ctx, comp)).To(Succeed())
+	}
+
+	g.Expect(c.Get(ctx, client.ObjectKey{Name: "widgets-system"}, &corev1.Namespace{})).To(Succeed())
+
+	cm := &corev1.ConfigMap{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Name: "operator-config", Namespace: "widgets-system"}, cm)).To(Succeed())
+	g.Expect(cm.Data).To(HaveKeyWithValue("level", "info"))
+
+	g.Expect(c.Get(ctx, client.ObjectKey{Name: "store", Namespace: "shared"}, &corev1.Secret{})).To(Succeed())
+}
+
+func TestInstall_CRDs(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+
+	releases := t.TempDir()
+	g.Expect(os.MkdirAll(filepath.Join(releases, "widgets"), 0o700)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(releases, "widgets", "widgets.yaml"),
+		[]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: widgets\n"), 0o600)).To(Succeed())
+	// A ConfigMap stands in for the CRD, which the fake client cannot serve.
+	g.Expect(os.MkdirAll(filepath.Join(releases, "crds", "widgets"), 0o700)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(releases, "crds", "widgets", "widget.yaml"),
+		[]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: widget-crd\n"), 0o600)).To(Succeed())
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(testrestmapper.TestOnlyStaticRESTMapper(scheme)).Build()
+	inst := newTestInstaller(c, releases)
+	g.Expect(inst.install(ctx, component{Name: "widgets", Namespace: "widgets", CRDs: "widgets"})).To(Succeed())
+	g.Expect(c.Get(ctx, client.ObjectKey{Name: "widget-crd", Namespace: "widgets"}, &corev1.ConfigMap{})).To(Succeed())
+
+	err := inst.install(ctx, component{Name: "widgets", Namespace: "widgets", CRDs: "gadgets"})
+	g.Expect(err).To(MatchError(ContainSubstring("no CRDs found")))
+}
+
+func TestInstall_NoVendoredManifests(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	err := newTestInstaller(c, t.TempDir()).install(context.Background(), component{Name: "widgets", Namespace: "widgets"})
+	g.Expect(err).To(MatchError(ContainSubstring("make vendor-infra")))
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// loadManifests decodes all objects from the *.yaml and *.yml files directly
+// in dir, in file name order. A missing directory yields no objects.
+func loadManifests(dir string) ([]*unstructured.Unstructured, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest directory %s: %w", dir, err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(files)
+
+	var objs []*unstructured.Unstructured
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+		decoded, err := decodeManifests(data)
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", file, err)
+		}
+		objs = append(objs, decoded...)
+	}
+	return objs, nil
+}
+
+// decodeManifests splits a multi-document YAML stream into objects, skipping
+// empty documents and flattening List kinds.
+func decodeManifests(data []byte) ([]*unstructured.Unstructured, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+
+	var objs []*unstructured.Unstructured
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objs, nil
+			}
+			return nil, err
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+			return nil, fmt.Errorf("object %q has no apiVersion or kind", obj.GetName())
+		}
+
+		if obj.IsList() {
+			if err := obj.EachListItem(func(item runtime.Object) error {
+				objs = append(objs, item.(*unstructured.Unstructured))
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		objs = append(objs, obj)
+	}
+}
+
+// kindOrder ranks kinds that other objects depend on so they are applied
+// first; everything else keeps its manifest order.
+var kindOrder = map[string]int{
+	"CustomResourceDefinition": 0,
+	"Namespace":                1,
+	"ServiceAccount":           2,
+	"ClusterRole":              2,
+	"Role":                     2,
+	"ConfigMap":                2,
+	"Secret":                   2,
+}
+
+const defaultKindRank = 3
+
+// sortForApply orders objects so that CRDs, namespaces and the objects that
+// workloads reference are applied before the workloads themselves.
+func sortForApply(objs []*unstructured.Unstructured) {
+	rank := func(obj *unstructured.Unstructured) int {
+		if r, ok := kindOrder[obj.GetKind()]; ok {
+			return r
+		}
+		return defaultKindRank
+	}
+	sort.SliceStable(objs, func(i, j int) bool {
+		return rank(objs[i]) < rank(objs[j])
+	})
+}
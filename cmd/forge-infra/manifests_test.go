@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDecodeManifests(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantKinds []string
+		wantError bool
+	}{
+		{
+			name:      "multiple documents with empty ones",
+			data:      "---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: a\n---\n# comment only\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n",
+			wantKinds: []string{"Namespace", "ConfigMap"},
+		},
+		{
+			name:      "list is flattened",
+			data:      "apiVersion: v1\nkind: List\nitems:\n- apiVersion: v1\n  kind: Secret\n  metadata:\n    name: a\n- apiVersion: v1\n  kind: Service\n  metadata:\n    name: b\n",
+			wantKinds: []string{"Secret", "Service"},
+		},
+		{
+			name:      "missing kind",
+			data:      "apiVersion: v1\nmetadata:\n  name: a\n",
+			wantError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			objs, err := decodeManifests([]byte(tc.data))
+			if tc.wantError {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(kindsOf(objs)).To(Equal(tc.wantKinds))
+		})
+	}
+}
+
+func TestLoadManifests(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	dir := t.TempDir()
+	write := func(name, data string) {
+		g.Expect(os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600)).To(Succeed())
+	}
+	write("b.yaml", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n")
+	write("a.yml", "apiVersion: v1\nkind: Secret\nmetadata:\n  name: a\n")
+	write("README.md", "not a manifest")
+	g.Expect(os.Mkdir(filepath.Join(dir, "post"), 0o700)).To(Succeed())
+
+	objs, err := loadManifests(dir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(kindsOf(objs)).To(Equal([]string{"Secret", "ConfigMap"}))
+
+	objs, err = loadManifests(filepath.Join(dir, "missing"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(BeEmpty())
+}
+
+func TestSortForApply(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	objs, err := decodeManifests([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata: {name: operator}
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata: {name: operator}
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata: {name: widgets.example.com}
+---
+apiVersion: v1
+kind: Service
+metadata: {name: webhook}
+---
+apiVersion: v1
+kind: Namespace
+metadata: {name: operator}
+`))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	sortForApply(objs)
+	g.Expect(kindsOf(objs)).To(Equal([]string{"CustomResourceDefinition", "Namespace", "ServiceAccount", "Deployment", "Service"}))
+}
+
+func kindsOf(objs []*unstructured.Unstructured) []string {
+	kinds := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		kinds = append(kinds, obj.GetKind())
+	}
+	return kinds
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// isReady reports whether obj has reached its ready state and, if not, why.
+// CRDs must be Established and workloads fully rolled out; custom resources
+// of one of readyKinds must have a Ready=True condition. All other objects
+// are ready as soon as they exist.
+func isReady(obj *unstructured.Unstructured, readyKinds []string) (bool, string) {
+	switch obj.GetKind() {
+	case "CustomResourceDefinition":
+		return hasCondition(obj, "Established")
+	case "Deployment":
+		return rolledOut(obj, "availableReplicas")
+	case "StatefulSet":
+		return rolledOut(obj, "readyReplicas")
+	case "DaemonSet":
+		desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "desiredNumberScheduled")
+		ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "numberReady")
+		if !observedLatest(obj) || ready < desired {
+			return false, fmt.Sprintf("%d/%d pods ready", ready, desired)
+		}
+		return true, ""
+	}
+
+	if slices.Contains(readyKinds, obj.GetKind()) {
+		return hasCondition(obj, "Ready")
+	}
+	return true, ""
+}
+
+// rolledOut checks that a Deployment or StatefulSet has observed its latest
+// generation and that all replicas are updated and counted in field.
+func rolledOut(obj *unstructured.Unstructured, field string) (bool, string) {
+	replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		replicas = 1
+	}
+	updated, _, _ := unstructured.NestedInt64(obj.Object, "status", "updatedReplicas")
+	ready, _, _ := unstructured.NestedInt64(obj.Object, "status", field)
+
+	if !observedLatest(obj) || updated < replicas || ready < replicas {
+		return false, fmt.Sprintf("%d/%d replicas ready", ready, replicas)
+	}
+	return true, ""
+}
+
+func observedLatest(obj *unstructured.Unstructured) bool {
+	observed, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	return observed >= obj.GetGeneration()
+}
+
+// hasCondition reports whether obj has condType with status True.
+func hasCondition(obj *unstructured.Unstructured, condType string) (bool, string) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, raw := range conditions {
+		cond, ok := raw.(map[string]interface{})
+		if !ok || cond["type"] != condType {
+			continue
+		}
+		if cond["status"] == "True" {
+			return true, ""
+		}
+		if msg, _ := cond["message"].(string); msg != "" {
+			return false, fmt.Sprintf("%s: %s", condType, msg)
+		}
+		return false, fmt.Sprintf("%s is %v", condType, cond["status"])
+	}
+	return false, fmt.Sprintf("no %s condition", condType)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func object(kind string, generation int64, fields map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: fields}
+	if obj.Object == nil {
+		obj.Object = map[string]interface{}{}
+	}
+	obj.SetKind(kind)
+	obj.SetName("test")
+	obj.SetGeneration(generation)
+	return obj
+}
+
+func condition(condType, status string) map[string]interface{} {
+	return map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": condType, "status": status},
+			},
+		},
+	}
+}
+
+func TestIsReady(t *testing.T) {
+	tests := []struct {
+		name       string
+		obj        *unstructured.Unstructured
+		readyKinds []string
+		want       bool
+	}{
+		{
+			name: "established CRD",
+			obj:  object("CustomResourceDefinition", 1, condition("Established", "True")),
+			want: true,
+		},
+		{
+			name: "CRD without conditions",
+			obj:  object("CustomResourceDefinition", 1, nil),
+			want: false,
+		},
+		{
+			name: "available deployment",
+			obj: object("Deployment", 2, map[string]interface{}{
+				"spec":   map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{"observedGeneration": int64(2), "updatedReplicas": int64(2), "availableReplicas": int64(2)},
+			}),
+			want: true,
+		},
+		{
+			name: "deployment with stale observed generation",
+			obj: object("Deployment", 3, map[string]interface{}{
+				"spec":   map[string]interface{}{"replicas": int64(1)},
+				"status": map[string]interface{}{"observedGeneration": int64(2), "updatedReplicas": int64(1), "availableReplicas": int64(1)},
+			}),
+			want: false,
+		},
+		{
+			name: "deployment defaults to one replica",
+			obj: object("Deployment", 1, map[string]interface{}{
+				"status": map[string]interface{}{"observedGeneration": int64(1)},
+			}),
+			want: false,
+		},
+		{
+			name: "statefulset rolled out",
+			obj: object("StatefulSet", 1, map[string]interface{}{
+				"spec":   map[string]interface{}{"replicas": int64(3)},
+				"status": map[string]interface{}{"observedGeneration": int64(1), "updatedReplicas": int64(3), "readyReplicas": int64(3)},
+			}),
+			want: true,
+		},
+		{
+			name: "daemonset partially ready",
+			obj: object("DaemonSet", 1, map[string]interface{}{
+				"status": map[string]interface{}{"observedGeneration": int64(1), "desiredNumberScheduled": int64(3), "numberReady": int64(2)},
+			}),
+			want: false,
+		},
+		{
+			name:       "ready kind with Ready=False",
+			obj:        object("ClusterIssuer", 1, condition("Ready", "False")),
+			readyKinds: []string{"ClusterIssuer"},
+			want:       false,
+		},
+		{
+			name:       "ready kind with Ready=True",
+			obj:        object("ClusterIssuer", 1, condition("Ready", "True")),
+			readyKinds: []string{"ClusterIssuer"},
+			want:       true,
+		},
+		{
+			name: "other kinds are ready once they exist",
+			obj:  object("ClusterIssuer", 1, condition("Ready", "False")),
+			want: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			ready, reason := isReady(tc.obj, tc.readyKinds)
+			g.Expect(ready).To(Equal(tc.want))
+			if !ready {
+				g.Expect(reason).NotTo(BeEmpty())
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// healthState summarises whether a resource is ready.
+type healthState string
+
+const (
+	healthReady    healthState = "Ready"
+	healthNotReady healthState = "NotReady"
+	// healthUnknown is used for resources without a notion of readiness,
+	// such as ConfigMaps, and for objects that publish no Ready condition.
+	healthUnknown healthState = "-"
+)
+
+// health is the readiness of a single resource and, if it is not ready, why.
+type health struct {
+	State  healthState
+	Reason string
+}
+
+func ready() health { return health{State: healthReady} }
+
+func notReady(format string, args ...interface{}) health {
+	return health{State: healthNotReady, Reason: fmt.Sprintf(format, args...)}
+}
+
+// healthOf derives the readiness of obj from its kind-specific status.
+// Objects of other kinds are judged by their Ready condition, which covers
+// the forge CRs and the dependency CRs they create.
+func healthOf(obj *unstructured.Unstructured) health {
+	switch obj.GetKind() {
+	case "Deployment":
+		return replicasHealth(obj, "availableReplicas")
+	case "StatefulSet", "ReplicaSet":
+		return replicasHealth(obj, "readyReplicas")
+	case "DaemonSet":
+		desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "desiredNumberScheduled")
+		numberReady, _, _ := unstructured.NestedInt64(obj.Object, "status", "numberReady")
+		if numberReady < desired {
+			return notReady("%d/%d pods ready", numberReady, desired)
+		}
+		return ready()
+	case "Job":
+		return jobHealth(obj)
+	case "Pod":
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		if phase == "Succeeded" {
+			return ready()
+		}
+		if h, found := conditionHealth(obj, "Ready"); found {
+			return h
+		}
+		return notReady("phase %s", phase)
+	}
+
+	if h, found := conditionHealth(obj, "Ready"); found {
+		return h
+	}
+	return health{State: healthUnknown}
+}
+
+func replicasHealth(obj *unstructured.Unstructured, field string) health {
+	replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		replicas = 1
+	}
+	observed, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	current, _, _ := unstructured.NestedInt64(obj.Object, "status", field)
+	if observed < obj.GetGeneration() {
+		return notReady("rollout pending")
+	}
+	if current < replicas {
+		return notReady("%d/%d replicas ready", current, replicas)
+	}
+	return ready()
+}
+
+func jobHealth(obj *unstructured.Unstructured) health {
+	if h, found := conditionHealth(obj, "Failed"); found && h.State == healthReady {
+		return notReady("failed: %s", h.Reason)
+	}
+	if h, found := conditionHealth(obj, "Complete"); found && h.State == healthReady {
+		return ready()
+	}
+	return notReady("running")
+}
+
+// conditionHealth maps the condition condType to a health; found is false if
+// obj has no such condition. For a True condition Reason carries the
+// condition's reason so that callers can report it.
+func conditionHealth(obj *unstructured.Unstructured, condType string) (health, bool) {
+	for _, cond := range conditionsOf(obj) {
+		if cond.Type != condType {
+			continue
+		}
+		if cond.Status == metav1.ConditionTrue {
+			return health{State: healthReady, Reason: cond.Reason}, true
+		}
+		return notReady("%s", conditionSummary(cond)), true
+	}
+	return health{}, false
+}
+
+// conditionsOf decodes .status.conditions of obj, skipping malformed entries.
+func conditionsOf(obj *unstructured.Unstructured) []metav1.Condition {
+	raw, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	conditions := make([]metav1.Condition, 0, len(raw))
+	for _, item := range raw {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var cond metav1.Condition
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, &cond); err == nil {
+			conditions = append(conditions, cond)
+		}
+	}
+	return conditions
+}
+
+// conditionSummary renders "Reason: message", omitting empty parts.
+func conditionSummary(cond metav1.Condition) string {
+	switch {
+	case cond.Reason != "" && cond.Message != "":
+		return cond.Reason + ": " + cond.Message
+	case cond.Reason != "":
+		return cond.Reason
+	case cond.Message != "":
+		return cond.Message
+	default:
+		return string(cond.Status)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newObject(apiVersion, kind string, fields map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: fields}
+	if obj.Object == nil {
+		obj.Object = map[string]interface{}{}
+	}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetName("test")
+	return obj
+}
+
+func withConditions(conditions ...map[string]interface{}) map[string]interface{} {
+	raw := make([]interface{}, 0, len(conditions))
+	for _, cond := range conditions {
+		raw = append(raw, cond)
+	}
+	return map[string]interface{}{"status": map[string]interface{}{"conditions": raw}}
+}
+
+func cond(condType, status, reason, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":               condType,
+		"status":             status,
+		"reason":             reason,
+		"message":            message,
+		"lastTransitionTime": "2026-01-01T00:00:00Z",
+	}
+}
+
+func TestHealthOf(t *testing.T) {
+	tests := []struct {
+		name       string
+		obj        *unstructured.Unstructured
+		wantState  healthState
+		wantReason string
+	}{
+		{
+			name: "available deployment",
+			obj: newObject("apps/v1", "Deployment", map[string]interface{}{
+				"spec":   map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{"availableReplicas": int64(2)},
+			}),
+			wantState: healthReady,
+		},
+		{
+			name: "deployment missing replicas",
+			obj: newObject("apps/v1", "Deployment", map[string]interface{}{
+				"spec":   map[string]interface{}{"replicas": int64(3)},
+				"status": map[string]interface{}{"availableReplicas": int64(1)},
+			}),
+			wantState:  healthNotReady,
+			wantReason: "1/3 replicas ready",
+		},
+		{
+			name:      "completed job",
+			obj:       newObject("batch/v1", "Job", withConditions(cond("Complete", "True", "", ""))),
+			wantState: healthReady,
+		},
+		{
+			name:       "failed job",
+			obj:        newObject("batch/v1", "Job", withConditions(cond("Failed", "True", "BackoffLimitExceeded", ""))),
+			wantState:  healthNotReady,
+			wantReason: "failed: BackoffLimitExceeded",
+		},
+		{
+			name:       "pending pod",
+			obj:        newObject("v1", "Pod", map[string]interface{}{"status": map[string]interface{}{"phase": "Pending"}}),
+			wantState:  healthNotReady,
+			wantReason: "phase Pending",
+		},
+		{
+			name:       "custom resource with Ready=False",
+			obj:        newObject("k8s.mariadb.com/v1alpha1", "Database", withConditions(cond("Ready", "False", "MariaDBNotReady", "waiting for MariaDB"))),
+			wantState:  healthNotReady,
+			wantReason: "MariaDBNotReady: waiting for MariaDB",
+		},
+		{
+			name:      "resource without readiness",
+			obj:       newObject("v1", "ConfigMap", nil),
+			wantState: healthUnknown,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			h := healthOf(tc.obj)
+			g.Expect(h.State).To(Equal(tc.wantState))
+			if tc.wantReason != "" {
+				g.Expect(h.Reason).To(Equal(tc.wantReason))
+			}
+		})
+	}
+}
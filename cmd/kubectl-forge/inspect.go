@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	c5c3v1alpha1 "github.com/c5c3/forge/operators/c5c3/api/v1alpha1"
+	keystonev1alpha1 "github.com/c5c3/forge/operators/keystone/api/v1alpha1"
+)
+
+// Release fields read from the inspected CR. The target release is what the
+// user asked for, the current release what the operator last rolled out.
+var (
+	targetReleasePath  = []string{"spec", "openStackRelease"}
+	currentReleasePath = []string{"status", "openStackRelease"}
+)
+
+// conditionDependencies are the condition dependency graphs of the forge
+// API groups.
+var conditionDependencies = map[string]map[string][]string{
+	keystonev1alpha1.GroupVersion.Group: keystonev1alpha1.ConditionDependencies,
+	c5c3v1alpha1.GroupVersion.Group:     c5c3v1alpha1.ConditionDependencies,
+}
+
+// certificateGroup is the API group of cert-manager Certificates, whose
+// issued Secrets are inspected for their expiry.
+const certificateGroup = "cert-manager.io"
+
+// report is everything kubectl-forge prints about a single CR.
+type report struct {
+	// Root is the inspected CR with the tree of resources it owns.
+	Root *node
+	// Conditions are the CR's status conditions.
+	Conditions []metav1.Condition
+	// CurrentRelease and TargetRelease are empty if the CR does not report
+	// them.
+	CurrentRelease string
+	TargetRelease  string
+	// Dependencies maps each condition type to the condition types it waits
+	// for. It is nil for kinds whose dependency graph is unknown.
+	Dependencies map[string][]string
+	// FernetRotatedAt is the most recent rotation time recorded on a Secret
+	// in the tree, or nil if there was none.
+	FernetRotatedAt *time.Time
+	// Certificates lists the expiry of every certificate issued for the CR.
+	Certificates []certificate
+}
+
+// node is a resource in the ownership tree.
+type node struct {
+	Object   *unstructured.Unstructured
+	Health   health
+	Children []*node
+}
+
+// certificate is the expiry of a TLS certificate stored in a Secret.
+type certificate struct {
+	Secret   string
+	NotAfter time.Time
+}
+
+// inspector collects reports from the cluster.
+type inspector struct {
+	client client.Client
+	// kinds are searched for resources owned by the inspected CR.
+	kinds []schema.GroupVersionKind
+}
+
+// inspect builds the report for the object of kind gvk identified by key.
+// The tree is built from the metadata of the candidates; full objects are
+// only read for the resources in the tree whose readiness is reported and
+// for the TLS Secrets in it.
+func (i *inspector) inspect(ctx context.Context, gvk schema.GroupVersionKind, key client.ObjectKey) (*report, error) {
+	root := &unstructured.Unstructured{}
+	root.SetGroupVersionKind(gvk)
+	if err := i.client.Get(ctx, key, root); err != nil {
+		return nil, fmt.Errorf("getting %s %s: %w", gvk.Kind, key, err)
+	}
+
+	owned, err := i.listOwnedCandidates(ctx, key.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &report{
+		Root:         buildTree(root, owned),
+		Conditions:   conditionsOf(root),
+		Dependencies: conditionDependencies[gvk.Group],
+	}
+	r.TargetRelease, _, _ = unstructured.NestedString(root.Object, targetReleasePath...)
+	r.CurrentRelease, _, _ = unstructured.NestedString(root.Object, currentReleasePath...)
+
+	if err := i.readStatus(ctx, r.Root); err != nil {
+		return nil, err
+	}
+	if r.Certificates, err = i.certificates(ctx, r.Root); err != nil {
+		return nil, err
+	}
+	r.FernetRotatedAt = lastFernetRotation(r.Root)
+	return r, nil
+}
+
+// listOwnedCandidates lists the metadata of all objects of the inspector's
+// kinds in namespace that have at least one owner. Kinds the cluster does
+// not serve or the user may not list are skipped.
+func (i *inspector) listOwnedCandidates(ctx context.Context, namespace string) ([]*metav1.PartialObjectMetadata, error) {
+	var objs []*metav1.PartialObjectMetadata
+	for _, gvk := range i.kinds {
+		list := &metav1.PartialObjectMetadataList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := i.client.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			if skippable(err) {
+				continue
+			}
+			return nil, fmt.Errorf("listing %s: %w", gvk.Kind, err)
+		}
+		for idx := range list.Items {
+			obj := &list.Items[idx]
+			if len(obj.GetOwnerReferences()) > 0 {
+				// Items of a metadata list carry no kind of their own.
+				obj.SetGroupVersionKind(gvk)
+				objs = append(objs, obj)
+			}
+		}
+	}
+	return objs, nil
+}
+
+// skippable reports whether err means that a kind cannot be read at all,
+// because the cluster does not serve it or the user may not read it.
+func skippable(err error) bool {
+	return meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) ||
+		apierrors.IsNotFound(err) || apierrors.IsForbidden(err) || apierrors.IsMethodNotSupported(err)
+}
+
+// buildTree arranges objs below root by their owner references. The nodes
+// below root only carry the metadata of their object; see readStatus.
+func buildTree(root *unstructured.Unstructured, objs []*metav1.PartialObjectMetadata) *node {
+	byOwner := map[types.UID][]*metav1.PartialObjectMetadata{}
+	for _, obj := range objs {
+		for _, ref := range obj.GetOwnerReferences() {
+			byOwner[ref.UID] = append(byOwner[ref.UID], obj)
+		}
+	}
+
+	visited := map[types.UID]bool{}
+	var build func(obj *unstructured.Unstructured) *node
+	build = func(obj *unstructured.Unstructured) *node {
+		visited[obj.GetUID()] = true
+		n := &node{Object: obj, Health: healthOf(obj)}
+
+		children := byOwner[obj.GetUID()]
+		sort.Slice(children, func(a, b int) bool {
+			if children[a].Kind != children[b].Kind {
+				return children[a].Kind < children[b].Kind
+			}
+			return children[a].Name < children[b].Name
+		})
+		for _, child := range children {
+			if !visited[child.UID] {
+				n.Children = append(n.Children, build(metadataObject(child)))
+			}
+		}
+		return n
+	}
+	return build(root)
+}
+
+// metadataObject returns an unstructured object with the type and metadata
+// of obj.
+func metadataObject(obj *metav1.PartialObjectMetadata) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	u.SetGroupVersionKind(obj.GroupVersionKind())
+	if m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&obj.ObjectMeta); err == nil {
+		u.Object["metadata"] = m
+	}
+	return u
+}
+
+// metadataOnlyKinds are the core kinds without a notion of readiness. Their
+// nodes keep only metadata, so that Secret data in particular is not read.
+var metadataOnlyKinds = map[string]bool{
+	"ConfigMap":      true,
+	"Secret":         true,
+	"Service":        true,
+	"ServiceAccount": true,
+}
+
+// readStatus replaces the metadata of every node below root whose kind
+// reports readiness with the full object and derives its health. Objects
+// deleted since they were listed keep their metadata.
+func (i *inspector) readStatus(ctx context.Context, root *node) error {
+	var err error
+	walk(root, func(n *node) bool {
+		obj := n.Object
+		if n == root || (obj.GroupVersionKind().Group == "" && metadataOnlyKinds[obj.GetKind()]) {
+			return true
+		}
+		full := &unstructured.Unstructured{}
+		full.SetGroupVersionKind(obj.GroupVersionKind())
+		getErr := i.client.Get(ctx, client.ObjectKeyFromObject(obj), full)
+		if apierrors.IsNotFound(getErr) || (getErr != nil && skippable(getErr)) {
+			return true
+		}
+		if getErr != nil {
+			err = fmt.Errorf("getting %s %s: %w", obj.GetKind(), obj.GetName(), getErr)
+			return false
+		}
+		n.Object = full
+		n.Health = healthOf(full)
+		return true
+	})
+	return err
+}
+
+// certificates reads the expiry of the TLS Secrets in the tree and of the
+// Secrets issued for Certificates in the tree. Secrets shared by both are
+// reported once.
+func (i *inspector) certificates(ctx context.Context, root *node) ([]certificate, error) {
+	namespace := root.Object.GetNamespace()
+	seen := map[string]bool{}
+	var certs []certificate
+	add := func(name string) error {
+		if seen[name] {
+			return nil
+		}
+		seen[name] = true
+		secret, err := i.tlsSecret(ctx, client.ObjectKey{Namespace: namespace, Name: name})
+		if err != nil || secret == nil {
+			return err
+		}
+		if notAfter, ok := certificateExpiry(secret.Data[corev1.TLSCertKey]); ok {
+			certs = append(certs, certificate{Secret: secret.Name, NotAfter: notAfter})
+		}
+		return nil
+	}
+
+	var err error
+	walk(root, func(n *node) bool {
+		obj := n.Object
+		switch {
+		case obj.GroupVersionKind().Group == certificateGroup && obj.GetKind() == "Certificate":
+			if secretName, _, _ := unstructured.NestedString(obj.Object, "spec", "secretName"); secretName != "" {
+				err = add(secretName)
+			}
+		case obj.GroupVersionKind().Group == "" && obj.GetKind() == "Secret":
+			err = add(obj.GetName())
+		}
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(certs, func(a, b int) bool { return certs[a].NotAfter.Before(certs[b].NotAfter) })
+	return certs, nil
+}
+
+// Field selectors of Secrets.
+const (
+	secretNameField = "metadata.name"
+	secretTypeField = "type"
+)
+
+// tlsSecret reads the Secret identified by key if it is a TLS Secret and
+// returns nil otherwise. The type is matched by the API server, so that the
+// data of other Secrets, such as fernet keys, is never read.
+func (i *inspector) tlsSecret(ctx context.Context, key client.ObjectKey) (*corev1.Secret, error) {
+	list := &corev1.SecretList{}
+	if err := i.client.List(ctx, list, client.InNamespace(key.Namespace), client.MatchingFields{
+		secretNameField: key.Name,
+		secretTypeField: string(corev1.SecretTypeTLS),
+	}); err != nil {
+		return nil, fmt.Errorf("reading Secret %s: %w", key.Name, err)
+	}
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+	return &list.Items[0], nil
+}
+
+// certificateExpiry returns the NotAfter of the first certificate in a PEM
+// bundle, which is the leaf certificate.
+func certificateExpiry(data []byte) (time.Time, bool) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return time.Time{}, false
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return cert.NotAfter, true
+}
+
+// lastFernetRotation returns the latest FernetKeysRotatedAtAnnotation of
+// the Secrets in the tree.
+func lastFernetRotation(root *node) *time.Time {
+	var latest *time.Time
+	walk(root, func(n *node) bool {
+		obj := n.Object
+		if obj.GroupVersionKind().Group != "" || obj.GetKind() != "Secret" {
+			return true
+		}
+		at, err := time.Parse(time.RFC3339, obj.GetAnnotations()[keystonev1alpha1.FernetKeysRotatedAtAnnotation])
+		if err == nil && (latest == nil || at.After(*latest)) {
+			latest = &at
+		}
+		return true
+	})
+	return latest
+}
+
+// walk visits n and its descendants depth-first until fn returns false.
+func walk(n *node, fn func(*node) bool) bool {
+	if !fn(n) {
+		return false
+	}
+	for _, child := range n.Children {
+		if !walk(child, fn) {
+			return false
+		}
+	}
+	return true
+}
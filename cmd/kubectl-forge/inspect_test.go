@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	keystonev1alpha1 "github.com/c5c3/forge/operators/keystone/api/v1alpha1"
+)
+
+var (
+	keystoneGVK    = schema.GroupVersionKind{Group: "keystone.openstack.c5c3.io", Version: "v1alpha1", Kind: "Keystone"}
+	certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+)
+
+func selfSignedPEM(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "keystone"},
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func ownedBy(owner client.Object, kind string) []metav1.OwnerReference {
+	controller := true
+	return []metav1.OwnerReference{{
+		APIVersion: owner.GetObjectKind().GroupVersionKind().GroupVersion().String(),
+		Kind:       kind,
+		Name:       owner.GetName(),
+		UID:        owner.GetUID(),
+		Controller: &controller,
+	}}
+}
+
+func TestInspect(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	s := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(s)
+	for _, gvk := range []schema.GroupVersionKind{keystoneGVK, certificateGVK} {
+		s.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		s.AddKnownTypeWithName(gvk.GroupVersion().WithKind(gvk.Kind+"List"), &unstructured.UnstructuredList{})
+	}
+
+	keystone := newObject(keystoneGVK.GroupVersion().String(), keystoneGVK.Kind, withConditions(
+		cond("Ready", "False", "DependencyNotReady", "Memcached is not ready"),
+		cond("KeystoneAPIReady", "False", "APIUnreachable", "context deadline exceeded"),
+		cond("DeploymentReady", "False", "DependencyNotReady", "waiting for the cache"),
+		cond("DatabaseReady", "True", "Ready", ""),
+		cond("CacheReady", "False", "DependencyNotReady", "Memcached keystone-memcached is not ready"),
+		cond("Paused", "False", "NotPaused", ""),
+	))
+	keystone.SetName("keystone")
+	keystone.SetNamespace("openstack")
+	keystone.SetUID("keystone-uid")
+	g.Expect(unstructured.SetNestedField(keystone.Object, "2025.2", "spec", "openStackRelease")).To(Succeed())
+	g.Expect(unstructured.SetNestedField(keystone.Object, "2025.1", "status", "openStackRelease")).To(Succeed())
+
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "keystone-api", Namespace: "openstack", UID: "deployment-uid",
+			OwnerReferences: ownedBy(keystone, "Keystone"),
+		},
+		Spec:   appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{AvailableReplicas: 1},
+	}
+	rotatedAt := now.Add(-3 * 24 * time.Hour)
+	fernet := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name: "keystone-fernet", Namespace: "openstack", UID: "fernet-uid",
+		OwnerReferences: ownedBy(keystone, "Keystone"),
+		Annotations:     map[string]string{keystonev1alpha1.FernetKeysRotatedAtAnnotation: rotatedAt.Format(time.RFC3339)},
+	}}
+	// A Secret outside the tree is ignored.
+	unrelated := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name: "other-fernet", Namespace: "openstack", UID: "other-uid",
+		Annotations: map[string]string{keystonev1alpha1.FernetKeysRotatedAtAnnotation: now.Format(time.RFC3339)},
+	}}
+
+	cert := newObject(certificateGVK.GroupVersion().String(), certificateGVK.Kind, withConditions(cond("Ready", "True", "Ready", "")))
+	cert.SetName("keystone-api")
+	cert.SetNamespace("openstack")
+	cert.SetUID("cert-uid")
+	cert.SetOwnerReferences(ownedBy(keystone, "Keystone"))
+	g.Expect(unstructured.SetNestedField(cert.Object, "keystone-api-tls", "spec", "secretName")).To(Succeed())
+	// The issued Secret is owned by nobody, as cert-manager creates it.
+	tlsSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "keystone-api-tls", Namespace: "openstack"},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: selfSignedPEM(t, now.Add(5*24*time.Hour))},
+	}
+
+	// A TLS Secret owned by the Keystone is reported as well.
+	ownedTLS := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "keystone-internal-tls", Namespace: "openstack", UID: "internal-tls-uid",
+			OwnerReferences: ownedBy(keystone, "Keystone"),
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{corev1.TLSCertKey: selfSignedPEM(t, now.Add(60*24*time.Hour))},
+	}
+
+	// Secrets are only read in full if they hold a certificate.
+	var secretsRead []string
+	c := fake.NewClientBuilder().WithScheme(s).
+		WithObjects(keystone, deployment, fernet, unrelated, cert, tlsSecret, ownedTLS).
+		WithIndex(&corev1.Secret{}, secretNameField, func(obj client.Object) []string {
+			return []string{obj.GetName()}
+		}).
+		WithIndex(&corev1.Secret{}, secretTypeField, func(obj client.Object) []string {
+			return []string{string(obj.(*corev1.Secret).Type)}
+		}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if _, ok := obj.(*corev1.Secret); ok {
+					secretsRead = append(secretsRead, key.Name)
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				switch list := list.(type) {
+				case *metav1.PartialObjectMetadataList:
+					return c.List(ctx, list, opts...)
+				case *corev1.SecretList:
+					err := c.List(ctx, list, opts...)
+					for _, item := range list.Items {
+						secretsRead = append(secretsRead, item.Name)
+					}
+					return err
+				}
+				return fmt.Errorf("unexpected list of full objects: %T", list)
+			},
+		}).
+		Build()
+
+	insp := &inspector{client: c, kinds: []schema.GroupVersionKind{
+		{Group: "apps", Version: "v1", Kind: "Deployment"},
+		{Version: "v1", Kind: "Secret"},
+		certificateGVK,
+		{Group: "k8s.mariadb.com", Version: "v1alpha1", Kind: "Database"},
+	}}
+	r, err := insp.inspect(ctx, keystoneGVK, client.ObjectKey{Namespace: "openstack", Name: "keystone"})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(r.TargetRelease).To(Equal("2025.2"))
+	g.Expect(r.CurrentRelease).To(Equal("2025.1"))
+	g.Expect(r.FernetRotatedAt).NotTo(BeNil())
+	g.Expect(r.FernetRotatedAt.Equal(rotatedAt)).To(BeTrue())
+	g.Expect(r.Certificates).To(HaveLen(2))
+	g.Expect(r.Certificates[0].Secret).To(Equal("keystone-api-tls"))
+	g.Expect(r.Certificates[1].Secret).To(Equal("keystone-internal-tls"))
+	g.Expect(secretsRead).To(ConsistOf("keystone-api-tls", "keystone-internal-tls"))
+
+	var out bytes.Buffer
+	g.Expect(render(&out, r, now)).To(Succeed())
+	lines := strings.Split(out.String(), "\n")
+	collapsed := make([]string, 0, len(lines))
+	for _, line := range lines {
+		collapsed = append(collapsed, strings.Join(strings.Fields(line), " "))
+	}
+
+	g.Expect(collapsed).To(ContainElements(
+		"Keystone openstack/keystone NotReady (DependencyNotReady: Memcached is not ready)",
+		"Release: 2025.1, upgrading to 2025.2",
+		"Fernet keys: rotated 3d ago (2026-10-15T12:00:00Z)",
+		"keystone-api-tls expires in 5d (2026-10-23T12:00:00Z) - renewal overdue",
+		"✗ Ready DependencyNotReady: Memcached is not ready",
+		"└── ✗ KeystoneAPIReady APIUnreachable: context deadline exceeded",
+		"└── ✗ DeploymentReady DependencyNotReady: waiting for the cache",
+		"├── ✓ DatabaseReady",
+		"├── ✗ CacheReady DependencyNotReady: Memcached keystone-memcached is not ready (root cause)",
+		"└── ? FernetKeysReady not reported",
+		"✗ Paused NotPaused",
+		"Keystone/keystone NotReady (DependencyNotReady: Memcached is not ready)",
+		"├── Certificate/keystone-api Ready",
+		"├── Deployment/keystone-api NotReady (1/2 replicas ready)",
+		"├── Secret/keystone-fernet -",
+		"└── Secret/keystone-internal-tls -",
+	))
+}
+
+func TestDescribeRelease(t *testing.T) {
+	tests := []struct {
+		current, target, want string
+	}{
+		{"", "", "not reported"},
+		{"", "2025.1", "target 2025.1, not rolled out yet"},
+		{"2025.1", "2025.1", "2025.1"},
+		{"2025.1", "", "2025.1"},
+		{"2024.2", "2025.1", "2024.2, upgrading to 2025.1"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.want, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(describeRelease(tc.current, tc.target)).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestRenderDependenciesWithoutGraph(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var out bytes.Buffer
+	renderDependencies(&out, []metav1.Condition{
+		{Type: "Ready", Status: metav1.ConditionFalse, Reason: "DependencyNotReady"},
+		{Type: "DatabaseReady", Status: metav1.ConditionTrue},
+		{Type: "CacheReady", Status: metav1.ConditionFalse, Reason: "DependencyNotReady"},
+	}, nil)
+	g.Expect(out.String()).To(Equal("  ✓ DatabaseReady\t\n  ✗ CacheReady\tDependencyNotReady\n"))
+
+	out.Reset()
+	renderDependencies(&out, nil, keystonev1alpha1.ConditionDependencies)
+	g.Expect(out.String()).To(Equal("  none reported\n"))
+}
+
+func TestServedKinds(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	mariadb := schema.GroupVersion{Group: "k8s.mariadb.com", Version: "v1alpha1"}
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion, mariadb})
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Secret"), meta.RESTScopeNamespace)
+	mapper.Add(mariadb.WithKind("Database"), meta.RESTScopeNamespace)
+
+	kinds, err := servedKinds(mapper, ownedKinds)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(kinds).To(Equal([]schema.GroupVersionKind{
+		{Version: "v1", Kind: "Secret"},
+		{Group: "k8s.mariadb.com", Version: "v1alpha1", Kind: "Database"},
+	}))
+}
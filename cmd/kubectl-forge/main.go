@@ -0,0 +1,180 @@
+// Command kubectl-forge is a kubectl plugin that gives on-call engineers a
+// quick overview of a forge-managed CR such as a ControlPlane or Keystone:
+//
+//	kubectl forge status keystone my-keystone -n openstack
+//
+// It prints the CR's readiness, the dependency conditions it publishes with
+// the reasons that block them as a dependency tree with the root causes
+// marked, the current and target OpenStack release (status.openStackRelease
+// and spec.openStackRelease), the age of the fernet keys (from the rotation
+// time annotated on the fernet key Secret), the expiry of the certificates
+// issued for it and the tree of the resources the operators created for it
+// with their readiness.
+//
+// Install it by putting the binary on the PATH; kubectl discovers plugins by
+// their kubectl- prefix.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	keystonev1alpha1 "github.com/c5c3/forge/operators/keystone/api/v1alpha1"
+)
+
+const usage = `Inspect forge-managed control planes.
+
+Usage:
+  kubectl forge status TYPE[.GROUP] NAME [flags]
+
+Examples:
+  kubectl forge status controlplane production -n openstack
+  kubectl forge status keystone keystone -n openstack
+
+Flags:
+`
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+}
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, out io.Writer) error {
+	flags := pflag.NewFlagSet("kubectl-forge", pflag.ContinueOnError)
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	overrides := &clientcmd.ConfigOverrides{}
+	flags.StringVar(&loadingRules.ExplicitPath, "kubeconfig", "", "Path to the kubeconfig file.")
+	flags.StringVar(&overrides.CurrentContext, "context", "", "The kubeconfig context to use.")
+	flags.StringVarP(&overrides.Context.Namespace, "namespace", "n", "", "The namespace of the object.")
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	positional := flags.Args()
+	if len(positional) != 3 || positional[0] != "status" {
+		flags.Usage()
+		return fmt.Errorf("expected: status TYPE NAME")
+	}
+
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return fmt.Errorf("loading kubeconfig: %w", err)
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return fmt.Errorf("resolving namespace: %w", err)
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("creating discovery client: %w", err)
+	}
+	cached := memory.NewMemCacheClient(discoveryClient)
+	mapper := restmapper.NewShortcutExpander(restmapper.NewDeferredDiscoveryRESTMapper(cached), cached, nil)
+
+	gvk, err := resolveKind(mapper, positional[1])
+	if err != nil {
+		return err
+	}
+	kinds, err := servedKinds(mapper, ownedKinds)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.New(restConfig, client.Options{Scheme: scheme, Mapper: mapper})
+	if err != nil {
+		return fmt.Errorf("creating client: %w", err)
+	}
+
+	insp := &inspector{client: c, kinds: kinds}
+	r, err := insp.inspect(ctx, gvk, client.ObjectKey{Namespace: namespace, Name: positional[2]})
+	if err != nil {
+		return err
+	}
+	return render(out, r, time.Now())
+}
+
+// resolveKind maps a kubectl-style resource argument (keystone, keystones,
+// a short name or keystones.some.group) to its kind.
+func resolveKind(mapper meta.RESTMapper, arg string) (schema.GroupVersionKind, error) {
+	gr := schema.ParseGroupResource(strings.ToLower(arg))
+	gvk, err := mapper.KindFor(gr.WithVersion(""))
+	if err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("resolving resource type %q: %w", arg, err)
+	}
+	return gvk, nil
+}
+
+// ownedKinds are the kinds the forge operators create for a CR, which are
+// the candidates for owned resources. They mirror the operators' RBAC rules.
+var ownedKinds = []schema.GroupKind{
+	{Kind: "ConfigMap"},
+	{Kind: "Secret"},
+	{Kind: "Service"},
+	{Kind: "ServiceAccount"},
+	{Group: "apps", Kind: "Deployment"},
+	{Group: "batch", Kind: "Job"},
+	{Group: "batch", Kind: "CronJob"},
+	{Group: "k8s.mariadb.com", Kind: "MariaDB"},
+	{Group: "k8s.mariadb.com", Kind: "Database"},
+	{Group: "k8s.mariadb.com", Kind: "User"},
+	{Group: "k8s.mariadb.com", Kind: "Grant"},
+	{Group: "k8s.mariadb.com", Kind: "Backup"},
+	{Group: "opsv1.memcached.com", Kind: "Memcached"},
+	{Group: "rabbitmq.com", Kind: "RabbitmqCluster"},
+	{Group: "external-secrets.io", Kind: "ExternalSecret"},
+	{Group: "external-secrets.io", Kind: "PushSecret"},
+	{Group: "cert-manager.io", Kind: "Certificate"},
+	{Group: keystonev1alpha1.GroupVersion.Group, Kind: "Keystone"},
+}
+
+// servedKinds resolves the preferred version of each of kinds. Kinds the
+// cluster does not serve, such as those of an operator that is not
+// installed, are skipped.
+func servedKinds(mapper meta.RESTMapper, kinds []schema.GroupKind) ([]schema.GroupVersionKind, error) {
+	var served []schema.GroupVersionKind
+	for _, gk := range kinds {
+		mapping, err := mapper.RESTMapping(gk)
+		if meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", gk, err)
+		}
+		served = append(served, mapping.GroupVersionKind)
+	}
+	return served, nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// certificateExpiryWarning is how long before expiry a certificate is
+// flagged. cert-manager renews well before this, so a flagged certificate
+// usually means renewal is stuck.
+const certificateExpiryWarning = 14 * 24 * time.Hour
+
+// render writes r in a human-readable form. now is used for relative ages.
+func render(w io.Writer, r *report, now time.Time) error {
+	root := r.Root.Object
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "%s %s/%s\t%s\n", root.GetKind(), root.GetNamespace(), root.GetName(), describeHealth(r.Root.Health))
+	fmt.Fprintf(tw, "Release:\t%s\n", describeRelease(r.CurrentRelease, r.TargetRelease))
+	fmt.Fprintf(tw, "Fernet keys:\t%s\n", describeFernet(r.FernetRotatedAt, now))
+
+	fmt.Fprintln(tw, "\nCertificates:")
+	if len(r.Certificates) == 0 {
+		fmt.Fprintln(tw, "  none")
+	}
+	for _, cert := range r.Certificates {
+		fmt.Fprintf(tw, "  %s\t%s\n", cert.Secret, describeExpiry(cert.NotAfter, now))
+	}
+
+	fmt.Fprintln(tw, "\nDependencies:")
+	renderDependencies(tw, r.Conditions, r.Dependencies)
+
+	fmt.Fprintln(tw, "\nResources:")
+	renderNode(tw, r.Root, "", "")
+	return tw.Flush()
+}
+
+// renderDependencies writes the conditions as a tree from Ready along deps.
+// A condition in the tree that is not true although everything it waits for
+// is true is marked as the root cause of the conditions it blocks.
+// Conditions outside the tree follow as a list, which is all of them except
+// Ready if deps is nil.
+func renderDependencies(w io.Writer, conds []metav1.Condition, deps map[string][]string) {
+	byType := make(map[string]metav1.Condition, len(conds))
+	for _, cond := range conds {
+		byType[cond.Type] = cond
+	}
+	isTrue := func(condType string) bool {
+		cond, ok := byType[condType]
+		return ok && cond.Status == metav1.ConditionTrue
+	}
+
+	shown := map[string]bool{"Ready": true}
+	var renderCondition func(condType, prefix, childPrefix string, inTree bool)
+	renderCondition = func(condType, prefix, childPrefix string, inTree bool) {
+		shown[condType] = true
+		cond, ok := byType[condType]
+		switch {
+		case !ok:
+			fmt.Fprintf(w, "  %s? %s\tnot reported\n", prefix, condType)
+		case cond.Status == metav1.ConditionTrue:
+			fmt.Fprintf(w, "  %s✓ %s\t\n", prefix, condType)
+		case inTree && !slices.ContainsFunc(deps[condType], func(dep string) bool { return !isTrue(dep) }):
+			fmt.Fprintf(w, "  %s✗ %s\t%s (root cause)\n", prefix, condType, conditionSummary(cond))
+		default:
+			fmt.Fprintf(w, "  %s✗ %s\t%s\n", prefix, condType, conditionSummary(cond))
+		}
+		children := deps[condType]
+		for idx, child := range children {
+			if idx == len(children)-1 {
+				renderCondition(child, childPrefix+"└── ", childPrefix+"    ", inTree)
+			} else {
+				renderCondition(child, childPrefix+"├── ", childPrefix+"│   ", inTree)
+			}
+		}
+	}
+
+	rendered := false
+	if deps != nil && len(conds) > 0 {
+		renderCondition("Ready", "", "", true)
+		rendered = true
+	}
+	for _, cond := range conds {
+		if !shown[cond.Type] {
+			renderCondition(cond.Type, "", "", false)
+			rendered = true
+		}
+	}
+	if !rendered {
+		fmt.Fprintln(w, "  none reported")
+	}
+}
+
+// renderNode writes n and its children as a tree. prefix is written before
+// n's own line, childPrefix before the lines of its descendants.
+func renderNode(w io.Writer, n *node, prefix, childPrefix string) {
+	fmt.Fprintf(w, "  %s%s/%s\t%s\n", prefix, n.Object.GetKind(), n.Object.GetName(), describeHealth(n.Health))
+	for idx, child := range n.Children {
+		if idx == len(n.Children)-1 {
+			renderNode(w, child, childPrefix+"└── ", childPrefix+"    ")
+		} else {
+			renderNode(w, child, childPrefix+"├── ", childPrefix+"│   ")
+		}
+	}
+}
+
+func describeHealth(h health) string {
+	if h.State == healthNotReady && h.Reason != "" {
+		return fmt.Sprintf("%s (%s)", h.State, h.Reason)
+	}
+	return string(h.State)
+}
+
+func describeRelease(current, target string) string {
+	switch {
+	case current == "" && target == "":
+		return "not reported"
+	case current == "":
+		return fmt.Sprintf("target %s, not rolled out yet", target)
+	case target == "" || current == target:
+		return current
+	default:
+		return fmt.Sprintf("%s, upgrading to %s", current, target)
+	}
+}
+
+func describeFernet(rotatedAt *time.Time, now time.Time) string {
+	if rotatedAt == nil {
+		return "no rotation recorded"
+	}
+	return fmt.Sprintf("rotated %s ago (%s)", humanDuration(now.Sub(*rotatedAt)), rotatedAt.UTC().Format(time.RFC3339))
+}
+
+func describeExpiry(notAfter, now time.Time) string {
+	remaining := notAfter.Sub(now)
+	date := notAfter.UTC().Format(time.RFC3339)
+	switch {
+	case remaining <= 0:
+		return fmt.Sprintf("EXPIRED %s ago (%s)", humanDuration(-remaining), date)
+	case remaining < certificateExpiryWarning:
+		return fmt.Sprintf("expires in %s (%s) - renewal overdue", humanDuration(remaining), date)
+	default:
+		return fmt.Sprintf("expires in %s (%s)", humanDuration(remaining), date)
+	}
+}
+
+// humanDuration renders d like kubectl ages: 45s, 12m, 5h, 3d.
+func humanDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
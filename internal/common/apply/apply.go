@@ -0,0 +1,271 @@
+package apply
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/c5c3/forge/internal/common/events"
+)
+
+const (
+	// IgnoreDriftAnnotation opts a child out of reconciliation while it is
+	// set to "true".
+	IgnoreDriftAnnotation = "forge.c5c3.io/ignore-drift"
+	// AppliedHashAnnotation holds the hash of the rendering last applied to
+	// a child.
+	AppliedHashAnnotation = "forge.c5c3.io/applied-hash"
+	// OwnerLabel is set on every owned child to the UID of its owner so that
+	// Set.Prune can find the children of an owner.
+	OwnerLabel = "forge.c5c3.io/owner-uid"
+)
+
+// ConflictPolicy decides what happens when an applied field is owned by
+// another field manager.
+type ConflictPolicy int
+
+const (
+	// ForceConflicts takes over conflicting fields. This is what reverts
+	// manual changes and is the default.
+	ForceConflicts ConflictPolicy = iota
+	// FailOnConflict leaves conflicting fields alone and makes Apply return
+	// a Conflict error, for fields the operator shares with users or other
+	// controllers.
+	FailOnConflict
+)
+
+// Option customizes a single Apply.
+type Option func(*options)
+
+type options struct {
+	orphan    bool
+	conflicts ConflictPolicy
+}
+
+// Orphan applies the child without a controller reference to the owner, so
+// that it survives the owner's deletion. Backups are an example.
+func Orphan() Option {
+	return func(o *options) { o.orphan = true }
+}
+
+// WithConflictPolicy selects how field manager conflicts are handled.
+func WithConflictPolicy(p ConflictPolicy) Option {
+	return func(o *options) { o.conflicts = p }
+}
+
+// FieldManager returns the server-side apply field manager of the named
+// operator, e.g. "forge-keystone" for "keystone".
+func FieldManager(operator string) string {
+	return "forge-" + operator
+}
+
+// Result describes the outcome of Apply.
+type Result struct {
+	// Created reports whether the child did not exist before.
+	Created bool
+	// Drift lists the fields that were changed outside the operator, e.g.
+	// "spec.replicas" or "data[keystone.conf]".
+	Drift []string
+	// Ignored reports whether the child was left untouched because of
+	// IgnoreDriftAnnotation.
+	Ignored bool
+}
+
+// Applier applies rendered children with a fixed field manager.
+type Applier struct {
+	client       client.Client
+	scheme       *runtime.Scheme
+	recorder     *events.Recorder
+	fieldManager string
+}
+
+// NewApplier returns an Applier applying with fieldManager. Events are
+// reported through recorder.
+func NewApplier(c client.Client, scheme *runtime.Scheme, recorder *events.Recorder, fieldManager string) *Applier {
+	return &Applier{client: c, scheme: scheme, recorder: recorder, fieldManager: fieldManager}
+}
+
+// Apply applies obj, a typed or unstructured child of owner, and updates obj
+// with the result. Unless Orphan is given, owner becomes the child's
+// controller and the child is labelled with OwnerLabel. Drift of an existing
+// child is reverted and reported on owner unless the child is opted out with
+// IgnoreDriftAnnotation.
+//
+// Only the fields set in obj are applied; fields the operator applied before
+// and no longer renders are removed by the API server.
+func (a *Applier) Apply(ctx context.Context, owner, obj client.Object, opts ...Option) (Result, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	desired, err := a.toUnstructured(obj)
+	if err != nil {
+		return Result{}, err
+	}
+	if !o.orphan {
+		if err := controllerutil.SetControllerReference(owner, desired, a.scheme); err != nil {
+			return Result{}, fmt.Errorf("setting owner of %s %s: %w", desired.GetKind(), desired.GetName(), err)
+		}
+		labels := desired.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[OwnerLabel] = string(owner.GetUID())
+		desired.SetLabels(labels)
+	}
+	hash, err := renderingHash(desired)
+	if err != nil {
+		return Result{}, err
+	}
+	annotations := desired.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AppliedHashAnnotation] = hash
+	desired.SetAnnotations(annotations)
+
+	kind := desired.GetKind()
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(desired.GroupVersionKind())
+	err = a.client.Get(ctx, client.ObjectKeyFromObject(desired), live)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return Result{}, fmt.Errorf("getting %s %s: %w", kind, desired.GetName(), err)
+	}
+
+	result := Result{Created: apierrors.IsNotFound(err)}
+	if !result.Created {
+		if live.GetAnnotations()[AppliedHashAnnotation] == hash {
+			result.Drift = Diff(desired, live)
+		}
+		if live.GetAnnotations()[IgnoreDriftAnnotation] == "true" {
+			result.Ignored = true
+			if len(result.Drift) > 0 {
+				driftTotal.WithLabelValues(kind, "ignored").Inc()
+			}
+			return result, a.fromUnstructured(live, obj)
+		}
+	}
+
+	applyOpts := []client.ApplyOption{client.FieldOwner(a.fieldManager)}
+	if o.conflicts == ForceConflicts {
+		applyOpts = append(applyOpts, client.ForceOwnership)
+	}
+	if err := a.client.Apply(ctx, client.ApplyConfigurationFromUnstructured(desired), applyOpts...); err != nil {
+		return result, fmt.Errorf("applying %s %s: %w", kind, desired.GetName(), err)
+	}
+
+	if len(result.Drift) > 0 {
+		driftTotal.WithLabelValues(kind, "reverted").Inc()
+		a.recorder.EventWithRelated(owner, desired, events.ReasonDriftCorrected,
+			"Reverted manual changes to %s %s: %s", kind, desired.GetName(), strings.Join(result.Drift, ", "))
+	}
+	return result, a.fromUnstructured(desired, obj)
+}
+
+// toUnstructured converts obj to an unstructured object with its kind set
+// and without the fields the API server manages.
+func (a *Applier) toUnstructured(obj client.Object) (*unstructured.Unstructured, error) {
+	gvk, err := apiutil.GVKForObject(obj, a.scheme)
+	if err != nil {
+		return nil, fmt.Errorf("resolving kind of %s: %w", obj.GetName(), err)
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("converting %s %s: %w", gvk.Kind, obj.GetName(), err)
+	}
+	u := &unstructured.Unstructured{Object: withoutNulls(content)}
+	u.SetGroupVersionKind(gvk)
+	if gvk.GroupKind() == (schema.GroupKind{Kind: "Secret"}) {
+		stringDataToData(u.Object)
+	}
+	delete(u.Object, "status")
+	for _, field := range []string{"creationTimestamp", "resourceVersion", "uid", "generation", "managedFields"} {
+		unstructured.RemoveNestedField(u.Object, "metadata", field)
+	}
+	return u, nil
+}
+
+// withoutNulls returns a copy of m without null values, which the
+// converter renders for unset fields that lack omitempty and which server-side
+// apply would treat as a request to remove the field.
+func withoutNulls(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		switch v := value.(type) {
+		case nil:
+			continue
+		case map[string]interface{}:
+			out[key] = withoutNulls(v)
+		case []interface{}:
+			list := make([]interface{}, len(v))
+			for i, item := range v {
+				if child, ok := item.(map[string]interface{}); ok {
+					list[i] = withoutNulls(child)
+				} else {
+					list[i] = runtime.DeepCopyJSONValue(item)
+				}
+			}
+			out[key] = list
+		default:
+			out[key] = runtime.DeepCopyJSONValue(v)
+		}
+	}
+	return out
+}
+
+// stringDataToData moves the stringData of a Secret into its data, the way
+// the API server does on write. The live object never has stringData, so
+// applying it would be reported as drift on every reconcile.
+func stringDataToData(secret map[string]interface{}) {
+	stringData, ok := secret["stringData"].(map[string]interface{})
+	delete(secret, "stringData")
+	if !ok || len(stringData) == 0 {
+		return
+	}
+	data, ok := secret["data"].(map[string]interface{})
+	if !ok {
+		data = make(map[string]interface{}, len(stringData))
+		secret["data"] = data
+	}
+	for key, value := range stringData {
+		s, _ := value.(string)
+		data[key] = base64.StdEncoding.EncodeToString([]byte(s))
+	}
+}
+
+// fromUnstructured copies u into obj.
+func (a *Applier) fromUnstructured(u *unstructured.Unstructured, obj client.Object) error {
+	if target, ok := obj.(*unstructured.Unstructured); ok {
+		u.DeepCopyInto(target)
+		return nil
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj); err != nil {
+		return fmt.Errorf("converting %s %s: %w", u.GetKind(), u.GetName(), err)
+	}
+	return nil
+}
+
+// renderingHash hashes the rendered object, excluding the hash annotation.
+func renderingHash(u *unstructured.Unstructured) (string, error) {
+	c := u.DeepCopy()
+	unstructured.RemoveNestedField(c.Object, "metadata", "annotations", AppliedHashAnnotation)
+	data, err := json.Marshal(c.Object)
+	if err != nil {
+		return "", fmt.Errorf("hashing %s %s: %w", u.GetKind(), u.GetName(), err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
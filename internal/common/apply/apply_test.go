@@ -0,0 +1,228 @@
+package apply
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	dto "github.com/prometheus/client_model/go"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clientevents "k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/c5c3/forge/internal/common/events"
+)
+
+func newApplier(g *WithT) (*Applier, client.Client, *clientevents.FakeRecorder) {
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	fakeRecorder := clientevents.NewFakeRecorder(10)
+	return NewApplier(c, scheme, events.NewRecorder(fakeRecorder, time.Minute), FieldManager("keystone")), c, fakeRecorder
+}
+
+func newOwner() *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "keystone", Namespace: "default", UID: "owner-uid"}}
+}
+
+func renderConfigMap(conf string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "keystone-config", Namespace: "default", Labels: map[string]string{"app": "keystone"}},
+		Data:       map[string]string{"keystone.conf": conf},
+	}
+}
+
+// editConfigMap changes the live ConfigMap the way kubectl edit would.
+func editConfigMap(g *WithT, c client.Client, edit func(*corev1.ConfigMap)) {
+	cm := &corev1.ConfigMap{}
+	g.Expect(c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "keystone-config"}, cm)).To(Succeed())
+	edit(cm)
+	g.Expect(c.Update(context.Background(), cm, client.FieldOwner("kubectl-edit"))).To(Succeed())
+}
+
+func liveData(g *WithT, c client.Client) string {
+	cm := &corev1.ConfigMap{}
+	g.Expect(c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "keystone-config"}, cm)).To(Succeed())
+	return cm.Data["keystone.conf"]
+}
+
+func driftCount(g *WithT, kind, action string) float64 {
+	m := &dto.Metric{}
+	g.Expect(driftTotal.WithLabelValues(kind, action).Write(m)).To(Succeed())
+	return m.GetCounter().GetValue()
+}
+
+func TestApplyRevertsDrift(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	a, c, fakeRecorder := newApplier(g)
+	owner := newOwner()
+
+	cm := renderConfigMap("[DEFAULT]\n")
+	result, err := a.Apply(ctx, owner, cm)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(Result{Created: true}))
+	g.Expect(cm.ResourceVersion).NotTo(BeEmpty())
+	g.Expect(cm.Annotations).To(HaveKey(AppliedHashAnnotation))
+
+	result, err = a.Apply(ctx, owner, renderConfigMap("[DEFAULT]\n"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(Result{}))
+
+	editConfigMap(g, c, func(cm *corev1.ConfigMap) {
+		cm.Data["keystone.conf"] = "[DEFAULT]\ndebug = true\n"
+		cm.Labels["app"] = "debug"
+	})
+	before := driftCount(g, "ConfigMap", "reverted")
+	result, err = a.Apply(ctx, owner, renderConfigMap("[DEFAULT]\n"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.Drift).To(Equal([]string{"data[keystone.conf]", "metadata.labels.app"}))
+	g.Expect(liveData(g, c)).To(Equal("[DEFAULT]\n"))
+	g.Expect(driftCount(g, "ConfigMap", "reverted")).To(Equal(before + 1))
+	g.Expect(fakeRecorder.Events).To(Receive(And(
+		HavePrefix("Warning DriftCorrected"),
+		ContainSubstring("data[keystone.conf], metadata.labels.app"),
+	)))
+}
+
+func TestApplyRenderingChangeIsNotDrift(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	a, c, fakeRecorder := newApplier(g)
+
+	_, err := a.Apply(ctx, newOwner(), renderConfigMap("[DEFAULT]\n"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	result, err := a.Apply(ctx, newOwner(), renderConfigMap("[DEFAULT]\ndebug = false\n"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.Drift).To(BeEmpty())
+	g.Expect(liveData(g, c)).To(Equal("[DEFAULT]\ndebug = false\n"))
+	g.Expect(fakeRecorder.Events).To(BeEmpty())
+}
+
+func TestApplyIgnoreDrift(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	a, c, fakeRecorder := newApplier(g)
+
+	_, err := a.Apply(ctx, newOwner(), renderConfigMap("[DEFAULT]\n"))
+	g.Expect(err).NotTo(HaveOccurred())
+	editConfigMap(g, c, func(cm *corev1.ConfigMap) {
+		cm.Annotations[IgnoreDriftAnnotation] = "true"
+		cm.Data["keystone.conf"] = "[DEFAULT]\ndebug = true\n"
+	})
+
+	before := driftCount(g, "ConfigMap", "ignored")
+	cm := renderConfigMap("[DEFAULT]\n")
+	result, err := a.Apply(ctx, newOwner(), cm)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(Result{Drift: []string{"data[keystone.conf]"}, Ignored: true}))
+	g.Expect(cm.Data["keystone.conf"]).To(Equal("[DEFAULT]\ndebug = true\n"))
+	g.Expect(liveData(g, c)).To(Equal("[DEFAULT]\ndebug = true\n"))
+	g.Expect(driftCount(g, "ConfigMap", "ignored")).To(Equal(before + 1))
+	g.Expect(fakeRecorder.Events).To(BeEmpty())
+
+	// Removing the annotation reverts the change on the next apply.
+	editConfigMap(g, c, func(cm *corev1.ConfigMap) { delete(cm.Annotations, IgnoreDriftAnnotation) })
+	result, err = a.Apply(ctx, newOwner(), renderConfigMap("[DEFAULT]\n"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.Drift).To(Equal([]string{"data[keystone.conf]"}))
+	g.Expect(liveData(g, c)).To(Equal("[DEFAULT]\n"))
+}
+
+func TestApplySecretStringData(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	a, c, fakeRecorder := newApplier(g)
+	render := func() *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "keystone-db", Namespace: "default"},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{"username": []byte("keystone")},
+			StringData: map[string]string{"password": "secret"},
+		}
+	}
+
+	secret := render()
+	_, err := a.Apply(ctx, newOwner(), secret)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(secret.StringData).To(BeEmpty())
+
+	live := &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "keystone-db"}, live)).To(Succeed())
+	g.Expect(live.Data).To(Equal(map[string][]byte{"username": []byte("keystone"), "password": []byte("secret")}))
+
+	// The stringData is not reported as drift on the next apply.
+	result, err := a.Apply(ctx, newOwner(), render())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(Result{}))
+	g.Expect(fakeRecorder.Events).To(BeEmpty())
+}
+
+func TestApplyUnstructured(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	a, c, _ := newApplier(g)
+
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "keystone-config", "namespace": "default"},
+		"data":       map[string]interface{}{"keystone.conf": "[DEFAULT]\n"},
+	}}
+	result, err := a.Apply(ctx, newOwner(), u)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.Created).To(BeTrue())
+	g.Expect(u.GetResourceVersion()).NotTo(BeEmpty())
+	g.Expect(liveData(g, c)).To(Equal("[DEFAULT]\n"))
+}
+
+func TestApplyDeployment(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	a, c, _ := newApplier(g)
+
+	render := func() *appsv1.Deployment {
+		replicas := int32(3)
+		labels := map[string]string{"app": "keystone"}
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "keystone-api", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{Containers: []corev1.Container{
+						{Name: "keystone-api", Image: "keystone:2025.2"},
+					}},
+				},
+			},
+		}
+	}
+	_, err := a.Apply(ctx, newOwner(), render())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	live := &appsv1.Deployment{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "keystone-api"}, live)).To(Succeed())
+	one := int32(1)
+	live.Spec.Replicas = &one
+	g.Expect(c.Update(ctx, live, client.FieldOwner("kubectl-scale"))).To(Succeed())
+
+	deploy := render()
+	result, err := a.Apply(ctx, newOwner(), deploy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.Drift).To(Equal([]string{"spec.replicas"}))
+	g.Expect(deploy.Spec.Replicas).To(HaveValue(Equal(int32(3))))
+}
+
+func TestFieldManager(t *testing.T) {
+	g := NewGomegaWithT(t)
+	g.Expect(FieldManager("keystone")).To(Equal("forge-keystone"))
+}
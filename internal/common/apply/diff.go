@@ -0,0 +1,93 @@
+package apply
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Diff returns the fields of desired whose value differs in live, sorted.
+// Only labels, annotations and the fields outside metadata and status are
+// compared. Fields that live has in addition, such as defaults set by the
+// API server, are not drift.
+func Diff(desired, live *unstructured.Unstructured) []string {
+	var drift []string
+	for key, want := range desired.Object {
+		switch key {
+		case "apiVersion", "kind", "status":
+			continue
+		case "metadata":
+			for _, field := range []string{"labels", "annotations"} {
+				w, _, _ := unstructured.NestedFieldNoCopy(desired.Object, "metadata", field)
+				l, _, _ := unstructured.NestedFieldNoCopy(live.Object, "metadata", field)
+				drift = diff("metadata."+field, w, l, drift)
+			}
+			continue
+		}
+		drift = diff(key, want, live.Object[key], drift)
+	}
+	sort.Strings(drift)
+	return drift
+}
+
+func diff(path string, want, got interface{}, drift []string) []string {
+	switch w := want.(type) {
+	case nil:
+		return drift
+	case map[string]interface{}:
+		g, _ := got.(map[string]interface{})
+		for key, value := range w {
+			drift = diff(childPath(path, key), value, g[key], drift)
+		}
+		return drift
+	case []interface{}:
+		g, _ := got.([]interface{})
+		if len(w) != len(g) {
+			return append(drift, path)
+		}
+		for i := range w {
+			drift = diff(fmt.Sprintf("%s[%d]", path, i), w[i], g[i], drift)
+		}
+		return drift
+	}
+	if !scalarEqual(want, got) {
+		return append(drift, path)
+	}
+	return drift
+}
+
+// childPath appends key to path, bracketing keys that are not identifiers
+// such as annotation names and ConfigMap keys.
+func childPath(path, key string) string {
+	if strings.ContainsAny(key, "./-") {
+		return path + "[" + key + "]"
+	}
+	return path + "." + key
+}
+
+// scalarEqual compares JSON scalars, treating numbers of different Go types
+// as equal if their values are.
+func scalarEqual(a, b interface{}) bool {
+	if af, ok := toFloat(a); ok {
+		bf, ok := toFloat(b)
+		return ok && af == bf
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,92 @@
+package apply
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		desired map[string]interface{}
+		live    map[string]interface{}
+		want    []string
+	}{
+		{
+			name:    "equal",
+			desired: map[string]interface{}{"data": map[string]interface{}{"a": "1"}},
+			live:    map[string]interface{}{"data": map[string]interface{}{"a": "1"}},
+		},
+		{
+			name:    "changed value",
+			desired: map[string]interface{}{"data": map[string]interface{}{"keystone.conf": "a"}},
+			live:    map[string]interface{}{"data": map[string]interface{}{"keystone.conf": "b"}},
+			want:    []string{"data[keystone.conf]"},
+		},
+		{
+			name:    "removed field",
+			desired: map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(2)}},
+			live:    map[string]interface{}{"spec": map[string]interface{}{}},
+			want:    []string{"spec.replicas"},
+		},
+		{
+			name:    "defaults in live are not drift",
+			desired: map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(2)}},
+			live:    map[string]interface{}{"spec": map[string]interface{}{"replicas": float64(2), "revisionHistoryLimit": int64(10)}},
+		},
+		{
+			name: "list elements",
+			desired: map[string]interface{}{"spec": map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "api", "image": "keystone:2025.2"},
+			}}},
+			live: map[string]interface{}{"spec": map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "api", "image": "keystone:debug", "terminationMessagePath": "/dev/termination-log"},
+			}}},
+			want: []string{"spec.containers[0].image"},
+		},
+		{
+			name:    "list length",
+			desired: map[string]interface{}{"spec": map[string]interface{}{"ports": []interface{}{"a"}}},
+			live:    map[string]interface{}{"spec": map[string]interface{}{"ports": []interface{}{"a", "b"}}},
+			want:    []string{"spec.ports"},
+		},
+		{
+			name:    "empty rendered values",
+			desired: map[string]interface{}{"spec": map[string]interface{}{"resources": map[string]interface{}{}, "selector": nil}},
+			live:    map[string]interface{}{},
+		},
+		{
+			name: "metadata",
+			desired: map[string]interface{}{"metadata": map[string]interface{}{
+				"name":        "keystone",
+				"labels":      map[string]interface{}{"app": "keystone"},
+				"annotations": map[string]interface{}{"forge.c5c3.io/x": "1"},
+			}},
+			live: map[string]interface{}{"metadata": map[string]interface{}{
+				"name":   "keystone",
+				"uid":    "1234",
+				"labels": map[string]interface{}{"app": "other"},
+			}},
+			want: []string{"metadata.annotations[forge.c5c3.io/x]", "metadata.labels.app"},
+		},
+		{
+			name:    "status is ignored",
+			desired: map[string]interface{}{"status": map[string]interface{}{"ready": true}},
+			live:    map[string]interface{}{"status": map[string]interface{}{"ready": false}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			got := Diff(&unstructured.Unstructured{Object: tc.desired}, &unstructured.Unstructured{Object: tc.live})
+			if tc.want == nil {
+				g.Expect(got).To(BeEmpty())
+				return
+			}
+			g.Expect(got).To(Equal(tc.want))
+		})
+	}
+}
@@ -0,0 +1,36 @@
+// Package apply reconciles the children of the operators' CRs with
+// server-side apply and reverts manual changes to them.
+//
+// Every operator applies its children, typed or unstructured, through an
+// Applier with its own field manager named by FieldManager, so the API
+// server tracks which fields the operator owns. The Applier stamps each child with a hash of the rendered
+// object. When a child still carries the hash of the current rendering but
+// its fields no longer match, it was changed outside the operator: the
+// Applier lists the drifted fields in a DriftCorrected event on the owner,
+// counts the correction in forge_child_drift_total and applies the rendering
+// again, which reverts the change.
+//
+// Children are usually owned: Apply makes the CR their controller and labels
+// them with the owner's UID. A Set collects the children applied during one
+// reconcile, and Set.Prune deletes the owned children of the given kinds that
+// were not applied, e.g. the Service of a disabled endpoint:
+//
+//	children := applier.NewSet(keystone)
+//	if _, err := children.Apply(ctx, configMap); err != nil {
+//		return ctrl.Result{}, err
+//	}
+//	...
+//	pruned, err := children.Prune(ctx, serviceGVK, configMapGVK)
+//
+// Apply forces ownership of conflicting fields by default. Fields the
+// operator shares with users or other controllers are applied with
+// WithConflictPolicy(FailOnConflict) instead, which returns a Conflict error.
+//
+// Setting the annotation forge.c5c3.io/ignore-drift: "true" on a child opts
+// it out temporarily: the Applier leaves it untouched, including changes the
+// operator would make, until the annotation is removed.
+//
+// Drift is found by comparing the rendered fields with the live object, so
+// controllers should render values in the form the API server stores them,
+// e.g. "1" rather than "1000m" for quantities.
+package apply
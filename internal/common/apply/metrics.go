@@ -0,0 +1,21 @@
+package apply
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// driftTotal counts children found changed outside the operator as
+// forge_child_drift_total{kind,action}, where action is "reverted" or
+// "ignored" for children opted out with IgnoreDriftAnnotation.
+var driftTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "forge_child_drift_total",
+		Help: "Number of times a managed child resource was found changed outside the operator.",
+	},
+	[]string{"kind", "action"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(driftTotal)
+}
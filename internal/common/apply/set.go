@@ -0,0 +1,79 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Set applies the children of one owner during a reconcile and remembers
+// them, so that children that are no longer rendered can be pruned.
+type Set struct {
+	applier *Applier
+	owner   client.Object
+	applied map[schema.GroupVersionKind]map[client.ObjectKey]bool
+}
+
+// NewSet returns an empty Set of the children of owner.
+func (a *Applier) NewSet(owner client.Object) *Set {
+	return &Set{applier: a, owner: owner, applied: map[schema.GroupVersionKind]map[client.ObjectKey]bool{}}
+}
+
+// Apply applies obj like Applier.Apply and records it in the Set.
+func (s *Set) Apply(ctx context.Context, obj client.Object, opts ...Option) (Result, error) {
+	gvk, err := apiutil.GVKForObject(obj, s.applier.scheme)
+	if err != nil {
+		return Result{}, fmt.Errorf("resolving kind of %s: %w", obj.GetName(), err)
+	}
+	if s.applied[gvk] == nil {
+		s.applied[gvk] = map[client.ObjectKey]bool{}
+	}
+	s.applied[gvk][client.ObjectKeyFromObject(obj)] = true
+	return s.applier.Apply(ctx, s.owner, obj, opts...)
+}
+
+// Prune deletes the children of the owner of the given kinds that were not
+// applied through the Set. Only children the owner controls are considered,
+// and children opted out with IgnoreDriftAnnotation are kept. Prune must
+// only run after every child was applied successfully, or children whose
+// rendering failed are deleted. It returns the deleted children as
+// "Kind/name", sorted.
+func (s *Set) Prune(ctx context.Context, kinds ...schema.GroupVersionKind) ([]string, error) {
+	var pruned []string
+	for _, gvk := range kinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := s.applier.client.List(ctx, list, client.InNamespace(s.owner.GetNamespace()),
+			client.MatchingLabels{OwnerLabel: string(s.owner.GetUID())}); err != nil {
+			return pruned, fmt.Errorf("listing %s children of %s: %w", gvk.Kind, s.owner.GetName(), err)
+		}
+		for i := range list.Items {
+			child := &list.Items[i]
+			if s.applied[gvk][client.ObjectKeyFromObject(child)] || !s.controls(child) ||
+				child.GetAnnotations()[IgnoreDriftAnnotation] == "true" {
+				continue
+			}
+			if err := s.applier.client.Delete(ctx, child, client.PropagationPolicy("Background")); client.IgnoreNotFound(err) != nil {
+				return pruned, fmt.Errorf("pruning %s %s: %w", gvk.Kind, child.GetName(), err)
+			}
+			pruned = append(pruned, gvk.Kind+"/"+child.GetName())
+		}
+	}
+	sort.Strings(pruned)
+	return pruned, nil
+}
+
+// controls reports whether the Set's owner is the controller of child.
+func (s *Set) controls(child client.Object) bool {
+	for _, ref := range child.GetOwnerReferences() {
+		if ref.Controller != nil && *ref.Controller {
+			return ref.UID == s.owner.GetUID()
+		}
+	}
+	return false
+}
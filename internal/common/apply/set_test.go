@@ -0,0 +1,127 @@
+package apply
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func mustUnstructured(g *WithT, a *Applier, obj client.Object) *unstructured.Unstructured {
+	u, err := a.toUnstructured(obj)
+	g.Expect(err).NotTo(HaveOccurred())
+	return u
+}
+
+var (
+	configMapGVK = corev1.SchemeGroupVersion.WithKind("ConfigMap")
+	secretGVK    = corev1.SchemeGroupVersion.WithKind("Secret")
+)
+
+func configMap(name string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Data:       map[string]string{"key": "value"},
+	}
+}
+
+func secret(name string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		StringData: map[string]string{"key": "value"},
+	}
+}
+
+func exists(g *WithT, c client.Client, obj client.Object) bool {
+	err := c.Get(context.Background(), client.ObjectKeyFromObject(obj), obj)
+	if apierrors.IsNotFound(err) {
+		return false
+	}
+	g.Expect(err).NotTo(HaveOccurred())
+	return true
+}
+
+func TestApplyOwnership(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	a, _, _ := newApplier(g)
+	owner := newOwner()
+
+	owned := configMap("owned")
+	_, err := a.Apply(ctx, owner, owned)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(owned.Labels).To(HaveKeyWithValue(OwnerLabel, "owner-uid"))
+	g.Expect(owned.OwnerReferences).To(ConsistOf(And(
+		HaveField("UID", BeEquivalentTo("owner-uid")),
+		HaveField("Controller", HaveValue(BeTrue())),
+	)))
+
+	orphan := configMap("orphan")
+	_, err = a.Apply(ctx, owner, orphan, Orphan())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(orphan.Labels).NotTo(HaveKey(OwnerLabel))
+	g.Expect(orphan.OwnerReferences).To(BeEmpty())
+}
+
+func TestApplyConflictPolicy(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	a, c, _ := newApplier(g)
+
+	shared := configMap("shared")
+	shared.Data["key"] = "theirs"
+	g.Expect(c.Apply(ctx, client.ApplyConfigurationFromUnstructured(mustUnstructured(g, a, shared)),
+		client.FieldOwner("someone-else"))).To(Succeed())
+
+	_, err := a.Apply(ctx, newOwner(), configMap("shared"), WithConflictPolicy(FailOnConflict))
+	g.Expect(apierrors.IsConflict(err)).To(BeTrue(), "expected conflict, got %v", err)
+
+	shared = configMap("shared")
+	_, err = a.Apply(ctx, newOwner(), shared)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(shared.Data).To(HaveKeyWithValue("key", "value"))
+}
+
+func TestSetPrune(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	a, c, _ := newApplier(g)
+	owner := newOwner()
+
+	first := a.NewSet(owner)
+	for _, obj := range []client.Object{configMap("keep"), configMap("stale"), configMap("ignored"), secret("stale")} {
+		_, err := first.Apply(ctx, obj)
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	_, err := first.Apply(ctx, configMap("orphan"), Orphan())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	ignored := configMap("ignored")
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(ignored), ignored)).To(Succeed())
+	ignored.Annotations[IgnoreDriftAnnotation] = "true"
+	g.Expect(c.Update(ctx, ignored)).To(Succeed())
+
+	other := newOwner()
+	other.UID = "other-uid"
+	_, err = a.Apply(ctx, other, configMap("other"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	second := a.NewSet(owner)
+	_, err = second.Apply(ctx, configMap("keep"))
+	g.Expect(err).NotTo(HaveOccurred())
+	pruned, err := second.Prune(ctx, configMapGVK, secretGVK)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pruned).To(Equal([]string{"ConfigMap/stale", "Secret/stale"}))
+
+	g.Expect(exists(g, c, configMap("keep"))).To(BeTrue())
+	g.Expect(exists(g, c, configMap("stale"))).To(BeFalse())
+	g.Expect(exists(g, c, secret("stale"))).To(BeFalse())
+	g.Expect(exists(g, c, configMap("ignored"))).To(BeTrue())
+	g.Expect(exists(g, c, configMap("orphan"))).To(BeTrue())
+	g.Expect(exists(g, c, configMap("other"))).To(BeTrue())
+}
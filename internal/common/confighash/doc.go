@@ -0,0 +1,19 @@
+// Package confighash restarts pods when the ConfigMaps and Secrets they
+// mount change.
+//
+// A controller lists the inputs of a Deployment, e.g. the ConfigMap holding
+// keystone.conf, the fernet key Secret and the TLS Secret, computes their
+// hashes and stamps them on the pod template. Any change to an input changes
+// the template and makes the Deployment roll:
+//
+//	hashes, err := confighash.Compute(ctx, c, keystone.Namespace,
+//		confighash.ConfigMap("keystone-config"),
+//		confighash.Secret("keystone-fernet-keys"),
+//		confighash.Secret("keystone-tls").AsOptional())
+//	triggers := confighash.Changed(&live.Spec.Template, hashes)
+//	confighash.Stamp(&deploy.Spec.Template, hashes)
+//	keystone.Status.LastRollout = keystone.Status.LastRollout.Observe(hashes, triggers, now)
+//
+// Hashes cover the content of the inputs only, so they are stable across
+// reconciles and operator restarts, and they do not reveal Secret content.
+package confighash
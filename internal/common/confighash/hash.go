@@ -0,0 +1,167 @@
+package confighash
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// HashAnnotation holds the combined hash of all inputs on a pod
+	// template.
+	HashAnnotation = "forge.c5c3.io/config-hash"
+	// InputsAnnotation holds the hash of every input on a pod template as a
+	// JSON object keyed by "Kind/name".
+	InputsAnnotation = "forge.c5c3.io/config-inputs"
+)
+
+// absent is the hash of an optional input that does not exist.
+const absent = "absent"
+
+// Input is a ConfigMap or Secret a pod depends on.
+type Input struct {
+	Kind     string
+	Name     string
+	Optional bool
+}
+
+// ConfigMap returns the Input for the named ConfigMap.
+func ConfigMap(name string) Input {
+	return Input{Kind: "ConfigMap", Name: name}
+}
+
+// Secret returns the Input for the named Secret.
+func Secret(name string) Input {
+	return Input{Kind: "Secret", Name: name}
+}
+
+// AsOptional returns a copy of in that may be missing. A missing optional
+// input hashes to a fixed value, so creating or deleting it rolls the pods.
+func (in Input) AsOptional() Input {
+	in.Optional = true
+	return in
+}
+
+// String returns "Kind/name".
+func (in Input) String() string {
+	return in.Kind + "/" + in.Name
+}
+
+// Hashes maps every input, as "Kind/name", to the hash of its content.
+type Hashes map[string]string
+
+// Sum returns the combined hash of all inputs.
+func (h Hashes) Sum() string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	sum := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(sum, "%s=%s\n", key, h[key])
+	}
+	return hex.EncodeToString(sum.Sum(nil))[:16]
+}
+
+// Compute reads the inputs from namespace and hashes their content. A
+// missing input that is not optional is an error.
+func Compute(ctx context.Context, c client.Client, namespace string, inputs ...Input) (Hashes, error) {
+	hashes := make(Hashes, len(inputs))
+	for _, in := range inputs {
+		key := client.ObjectKey{Namespace: namespace, Name: in.Name}
+		var data map[string][]byte
+		var err error
+		switch in.Kind {
+		case "ConfigMap":
+			cm := &corev1.ConfigMap{}
+			if err = c.Get(ctx, key, cm); err == nil {
+				data = make(map[string][]byte, len(cm.Data)+len(cm.BinaryData))
+				for k, v := range cm.Data {
+					data[k] = []byte(v)
+				}
+				for k, v := range cm.BinaryData {
+					data[k] = v
+				}
+			}
+		case "Secret":
+			secret := &corev1.Secret{}
+			if err = c.Get(ctx, key, secret); err == nil {
+				data = secret.Data
+			}
+		default:
+			return nil, fmt.Errorf("input %s: unsupported kind %q", in, in.Kind)
+		}
+		switch {
+		case apierrors.IsNotFound(err) && in.Optional:
+			hashes[in.String()] = absent
+		case err != nil:
+			return nil, fmt.Errorf("getting input %s: %w", in, err)
+		default:
+			hashes[in.String()] = hashData(data)
+		}
+	}
+	return hashes, nil
+}
+
+// hashData hashes data independently of map order.
+func hashData(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	sum := sha256.New()
+	for _, key := range keys {
+		// Length prefixes keep "a"+"bc" and "ab"+"c" apart.
+		fmt.Fprintf(sum, "%d:%s%d:", len(key), key, len(data[key]))
+		sum.Write(data[key])
+	}
+	return hex.EncodeToString(sum.Sum(nil))[:16]
+}
+
+// Stamp records hashes on the pod template.
+func Stamp(template *corev1.PodTemplateSpec, hashes Hashes) {
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	// Marshalling a map[string]string cannot fail and sorts the keys.
+	inputs, _ := json.Marshal(hashes)
+	template.Annotations[HashAnnotation] = hashes.Sum()
+	template.Annotations[InputsAnnotation] = string(inputs)
+}
+
+// Changed returns the inputs whose hash differs from the one stamped on the
+// pod template, sorted. Inputs that were added or removed count as changed.
+// It returns nil if template was never stamped, e.g. because the Deployment
+// does not exist yet.
+func Changed(template *corev1.PodTemplateSpec, hashes Hashes) []string {
+	raw, ok := template.Annotations[InputsAnnotation]
+	if !ok {
+		return nil
+	}
+	stamped := Hashes{}
+	if err := json.Unmarshal([]byte(raw), &stamped); err != nil {
+		stamped = Hashes{}
+	}
+	var changed []string
+	for key, hash := range hashes {
+		if stamped[key] != hash {
+			changed = append(changed, key)
+		}
+	}
+	for key := range stamped {
+		if _, ok := hashes[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,112 @@
+package confighash
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newClient() client.Client {
+	return fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "keystone-config", Namespace: "default"},
+			Data:       map[string]string{"keystone.conf": "[DEFAULT]\n", "logging.conf": "[loggers]\n"},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "keystone-fernet-keys", Namespace: "default"},
+			Data:       map[string][]byte{"0": []byte("key0"), "1": []byte("key1")},
+		},
+	).Build()
+}
+
+var inputs = []Input{
+	ConfigMap("keystone-config"),
+	Secret("keystone-fernet-keys"),
+	Secret("keystone-tls").AsOptional(),
+}
+
+func TestCompute(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	c := newClient()
+
+	hashes, err := Compute(ctx, c, "default", inputs...)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(hashes).To(HaveLen(3))
+	g.Expect(hashes).To(HaveKeyWithValue("Secret/keystone-tls", absent))
+
+	again, err := Compute(ctx, c, "default", inputs...)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(again).To(Equal(hashes))
+	g.Expect(again.Sum()).To(Equal(hashes.Sum()))
+
+	secret := &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "keystone-fernet-keys"}, secret)).To(Succeed())
+	secret.Data["2"] = []byte("key2")
+	g.Expect(c.Update(ctx, secret)).To(Succeed())
+
+	rotated, err := Compute(ctx, c, "default", inputs...)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(rotated["ConfigMap/keystone-config"]).To(Equal(hashes["ConfigMap/keystone-config"]))
+	g.Expect(rotated["Secret/keystone-fernet-keys"]).NotTo(Equal(hashes["Secret/keystone-fernet-keys"]))
+	g.Expect(rotated.Sum()).NotTo(Equal(hashes.Sum()))
+}
+
+func TestComputeErrors(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+
+	_, err := Compute(ctx, newClient(), "default", Secret("keystone-tls"))
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	g.Expect(err).To(MatchError(ContainSubstring("Secret/keystone-tls")))
+
+	_, err = Compute(ctx, newClient(), "default", Input{Kind: "Service", Name: "keystone"})
+	g.Expect(err).To(MatchError(ContainSubstring("unsupported kind")))
+}
+
+func TestHashData(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b map[string][]byte
+		same bool
+	}{
+		{"equal", map[string][]byte{"a": []byte("1"), "b": []byte("2")}, map[string][]byte{"b": []byte("2"), "a": []byte("1")}, true},
+		{"empty", nil, map[string][]byte{}, true},
+		{"value changed", map[string][]byte{"a": []byte("1")}, map[string][]byte{"a": []byte("2")}, false},
+		{"key renamed", map[string][]byte{"a": []byte("1")}, map[string][]byte{"b": []byte("1")}, false},
+		{"boundary moved", map[string][]byte{"a": []byte("bc")}, map[string][]byte{"ab": []byte("c")}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(hashData(tc.a) == hashData(tc.b)).To(Equal(tc.same))
+		})
+	}
+}
+
+func TestStampAndChanged(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	template := &corev1.PodTemplateSpec{}
+	hashes := Hashes{"ConfigMap/keystone-config": "a", "Secret/keystone-fernet-keys": "b"}
+	g.Expect(Changed(template, hashes)).To(BeNil())
+
+	Stamp(template, hashes)
+	g.Expect(template.Annotations).To(HaveKeyWithValue(HashAnnotation, hashes.Sum()))
+	g.Expect(template.Annotations).To(HaveKeyWithValue(InputsAnnotation,
+		`{"ConfigMap/keystone-config":"a","Secret/keystone-fernet-keys":"b"}`))
+	g.Expect(Changed(template, hashes)).To(BeEmpty())
+
+	g.Expect(Changed(template, Hashes{
+		"ConfigMap/keystone-config":   "a",
+		"Secret/keystone-fernet-keys": "c",
+		"Secret/keystone-tls":         "d",
+	})).To(Equal([]string{"Secret/keystone-fernet-keys", "Secret/keystone-tls"}))
+	g.Expect(Changed(template, Hashes{"ConfigMap/keystone-config": "a"})).To(Equal([]string{"Secret/keystone-fernet-keys"}))
+}
@@ -0,0 +1,56 @@
+package confighash
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LastRollout records the last rollout caused by a change of the inputs. It
+// is meant to be embedded in the status of the service CR.
+type LastRollout struct {
+	// Hash is the combined hash of the inputs the pods run with.
+	// +optional
+	Hash string `json:"hash,omitempty"`
+	// Triggers lists the inputs whose change caused the last rollout, as
+	// "Kind/name".
+	// +optional
+	Triggers []string `json:"triggers,omitempty"`
+	// Time is when the last rollout was triggered.
+	// +optional
+	Time *metav1.Time `json:"time,omitempty"`
+}
+
+// DeepCopyInto copies r into out.
+func (r *LastRollout) DeepCopyInto(out *LastRollout) {
+	*out = *r
+	if r.Triggers != nil {
+		out.Triggers = make([]string, len(r.Triggers))
+		copy(out.Triggers, r.Triggers)
+	}
+	if r.Time != nil {
+		out.Time = r.Time.DeepCopy()
+	}
+}
+
+// DeepCopy returns a copy of r.
+func (r *LastRollout) DeepCopy() *LastRollout {
+	if r == nil {
+		return nil
+	}
+	out := new(LastRollout)
+	r.DeepCopyInto(out)
+	return out
+}
+
+// Observe returns r updated for the current hashes and the inputs Changed
+// reported. Triggers and Time only change when the hash does and some input
+// changed; the first stamp of a new Deployment only records the hash.
+func (r LastRollout) Observe(hashes Hashes, triggers []string, now metav1.Time) LastRollout {
+	sum := hashes.Sum()
+	if sum == r.Hash {
+		return r
+	}
+	if len(triggers) == 0 {
+		return LastRollout{Hash: sum, Triggers: r.Triggers, Time: r.Time}
+	}
+	return LastRollout{Hash: sum, Triggers: triggers, Time: &now}
+}
@@ -0,0 +1,33 @@
+package confighash
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLastRolloutObserve(t *testing.T) {
+	g := NewGomegaWithT(t)
+	t1 := metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	t2 := metav1.NewTime(t1.Add(time.Hour))
+	initial := Hashes{"ConfigMap/keystone-config": "a"}
+	changed := Hashes{"ConfigMap/keystone-config": "b"}
+
+	// The first stamp records the hash without a rollout.
+	r := LastRollout{}.Observe(initial, nil, t1)
+	g.Expect(r).To(Equal(LastRollout{Hash: initial.Sum()}))
+
+	// Nothing changes while the hash stays the same.
+	g.Expect(r.Observe(initial, nil, t2)).To(Equal(r))
+
+	r = r.Observe(changed, []string{"ConfigMap/keystone-config"}, t2)
+	g.Expect(r.Hash).To(Equal(changed.Sum()))
+	g.Expect(r.Triggers).To(Equal([]string{"ConfigMap/keystone-config"}))
+	g.Expect(r.Time).To(HaveValue(Equal(t2)))
+
+	copied := r.DeepCopy()
+	copied.Triggers[0] = "Secret/other"
+	g.Expect(r.Triggers).To(Equal([]string{"ConfigMap/keystone-config"}))
+}
@@ -0,0 +1,60 @@
+package cronschedule
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestNext(t *testing.T) {
+	// A Wednesday.
+	from := time.Date(2026, 1, 7, 10, 17, 30, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 1, 7, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 1, 7, 10, 30, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)},
+		{"30 2 1,15 * *", time.Date(2026, 1, 15, 2, 30, 0, 0, time.UTC)},
+		{"0 12 * 3 *", time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{"5-10/5 9-17 * * 1-5", time.Date(2026, 1, 7, 11, 5, 0, 0, time.UTC)},
+		{"10/20 * * * *", time.Date(2026, 1, 7, 10, 30, 0, 0, time.UTC)},
+		// Day of month or day of week: the 20th or the next Friday.
+		{"0 0 20 * 5", time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			s, err := Parse(tt.spec)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(s.Next(from)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		spec    string
+		message string
+	}{
+		{"* * * *", "expected 5 fields, got 4"},
+		{"60 * * * *", "minute: 60 is outside 0-59"},
+		{"* 1-x * * *", `hour: invalid value "x"`},
+		{"* * 0 * *", "day of month: 0 is outside 1-31"},
+		{"* * * 10-2 *", `month: range "10-2" is reversed`},
+		{"*/0 * * * *", `minute: invalid step "0"`},
+		{"@daily", "expected 5 fields, got 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			_, err := Parse(tt.spec)
+			g.Expect(err).To(MatchError(ContainSubstring(tt.message)))
+		})
+	}
+}
@@ -0,0 +1,16 @@
+// Package cronschedule evaluates the standard five-field cron schedules of
+// CR specs, such as the fernet key rotation schedule of a Keystone CR.
+//
+// A schedule lists the minute, hour, day of month, month and day of week it
+// fires at. Each field is "*", a value, a range "a-b" or a comma-separated
+// list of them, each optionally followed by a step "/n":
+//
+//	0 0 * * 0       every Sunday at midnight
+//	*/15 * * * *    every 15 minutes
+//	30 2 1,15 * 1-5 at 02:30 on the 1st, the 15th and on weekdays
+//
+// As in cron, a day matches if either the day of month or the day of week
+// matches, unless one of them is "*". Names such as "sun" or "@daily" are
+// not supported. Schedules are evaluated in the location of the time passed
+// to Next; the operators pass UTC times.
+package cronschedule
@@ -0,0 +1,287 @@
+package dbbackup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// GroupVersionKinds of the mariadb-operator resources used for backups.
+var (
+	BackupGVK  = schema.GroupVersionKind{Group: "k8s.mariadb.com", Version: "v1alpha1", Kind: "Backup"}
+	RestoreGVK = schema.GroupVersionKind{Group: "k8s.mariadb.com", Version: "v1alpha1", Kind: "Restore"}
+)
+
+const (
+	// LabelOwnerUID is set on every Backup to the UID of the CR whose
+	// database it contains. Prune and DeleteAll select Backups by it, so
+	// that a CR of another kind or a recreated CR with the same name does
+	// not match.
+	LabelOwnerUID = "forge.c5c3.io/backup-owner-uid"
+	// LabelOwnerKind is set on every Backup to the kind of the CR whose
+	// database it contains.
+	LabelOwnerKind = "forge.c5c3.io/backup-owner-kind"
+	// AnnotationBackupOf is set on every Backup to the name of the CR whose
+	// database it contains. Names may be longer than label values allow.
+	AnnotationBackupOf = "forge.c5c3.io/backup-of"
+	// LabelRevision is set on every Backup to the revision of the migration
+	// it precedes.
+	LabelRevision = "forge.c5c3.io/backup-revision"
+)
+
+// Phase is the state of a Backup or Restore.
+type Phase string
+
+// Phases of a Backup or Restore.
+const (
+	PhasePending  Phase = "Pending"
+	PhaseComplete Phase = "Complete"
+	PhaseFailed   Phase = "Failed"
+)
+
+// Status is the observed state of a Backup.
+type Status struct {
+	// Name of the Backup.
+	Name  string
+	Phase Phase
+	// Message is the message of the Backup's Complete condition, if any.
+	Message string
+}
+
+// BackupRequest describes the backup taken before a migration.
+type BackupRequest struct {
+	// Owner is the CR whose database is backed up. The Backup is created in
+	// its namespace.
+	Owner client.Object
+	// Revision identifies the migration, e.g. the target OpenStack release.
+	// There is one Backup per owner and revision.
+	Revision string
+	// MariaDB is the name of the MariaDB holding the database, in the
+	// owner's namespace.
+	MariaDB string
+	// Database is the name of the schema to back up.
+	Database string
+	// Policy configures the Backup's storage.
+	Policy Policy
+}
+
+// BackupName returns the name of the Backup of the CR of the given kind and
+// name taken before the migration to revision. The kind keeps CRs of
+// different kinds with the same name apart. Names that are too long are
+// shortened with a hash of the kind, name and revision, keeping the revision
+// readable.
+func BackupName(kind, owner, revision string) string {
+	const maxLen = 63
+	prefix := strings.Trim(sanitize(kind+"-"+owner), "-.")
+	suffix := "-pre-" + strings.Trim(sanitize(revision), "-.")
+	if len(prefix)+len(suffix) <= maxLen {
+		return prefix + suffix
+	}
+	sum := sha256.Sum256([]byte(kind + "/" + owner + "/" + revision))
+	hash := "-" + hex.EncodeToString(sum[:4])
+	keep := maxLen - len(hash) - len(suffix)
+	if keep < len(hash) {
+		return resourceName(prefix + suffix)
+	}
+	return strings.TrimRight(prefix[:keep], "-.") + hash + suffix
+}
+
+// EnsureBackup creates the Backup for req unless it exists and returns its
+// status. A failed Backup is not retried; delete it to take a new one. The
+// kind of the owner is looked up in scheme.
+func EnsureBackup(ctx context.Context, c client.Client, scheme *runtime.Scheme, req BackupRequest) (Status, error) {
+	gvk, err := apiutil.GVKForObject(req.Owner, scheme)
+	if err != nil {
+		return Status{}, fmt.Errorf("resolving kind of backup owner: %w", err)
+	}
+	if req.Owner.GetUID() == "" {
+		return Status{}, fmt.Errorf("backup owner %s has no UID", req.Owner.GetName())
+	}
+	name := BackupName(gvk.Kind, req.Owner.GetName(), req.Revision)
+	backup := &unstructured.Unstructured{}
+	backup.SetGroupVersionKind(BackupGVK)
+	err = c.Get(ctx, client.ObjectKey{Namespace: req.Owner.GetNamespace(), Name: name}, backup)
+	if apierrors.IsNotFound(err) {
+		backup = newBackup(name, gvk.Kind, req)
+		if err := c.Create(ctx, backup); err != nil && !apierrors.IsAlreadyExists(err) {
+			return Status{}, fmt.Errorf("creating Backup %s: %w", name, err)
+		}
+		return Status{Name: name, Phase: PhasePending}, nil
+	}
+	if err != nil {
+		return Status{}, fmt.Errorf("getting Backup %s: %w", name, err)
+	}
+	phase, message := phaseOf(backup)
+	return Status{Name: name, Phase: phase, Message: message}, nil
+}
+
+// newBackup renders the Backup for req, whose owner is of the given kind.
+func newBackup(name, kind string, req BackupRequest) *unstructured.Unstructured {
+	size := req.Policy.size()
+	pvc := map[string]interface{}{
+		"accessModes": []interface{}{"ReadWriteOnce"},
+		"resources": map[string]interface{}{
+			"requests": map[string]interface{}{"storage": size.String()},
+		},
+	}
+	if req.Policy.Storage.StorageClassName != nil {
+		pvc["storageClassName"] = *req.Policy.Storage.StorageClassName
+	}
+
+	backup := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"mariaDbRef": map[string]interface{}{"name": req.MariaDB},
+			"databases":  []interface{}{req.Database},
+			"storage":    map[string]interface{}{"persistentVolumeClaim": pvc},
+		},
+	}}
+	backup.SetGroupVersionKind(BackupGVK)
+	backup.SetName(name)
+	backup.SetNamespace(req.Owner.GetNamespace())
+	backup.SetLabels(map[string]string{
+		LabelOwnerUID:  string(req.Owner.GetUID()),
+		LabelOwnerKind: labelValue(kind),
+		LabelRevision:  labelValue(req.Revision),
+	})
+	backup.SetAnnotations(map[string]string{AnnotationBackupOf: req.Owner.GetName()})
+	return backup
+}
+
+// Prune deletes the Backups of owner except the newest retention completed
+// ones and any newer Backups. Backups that are still running are never
+// deleted. It returns the names of the deleted Backups, oldest first.
+func Prune(ctx context.Context, c client.Client, owner client.Object, policy Policy) ([]string, error) {
+	backups, err := listBackups(ctx, c, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		ti, tj := backups[i].GetCreationTimestamp(), backups[j].GetCreationTimestamp()
+		if !ti.Equal(&tj) {
+			return tj.Before(&ti)
+		}
+		return backups[i].GetName() > backups[j].GetName()
+	})
+
+	// Walk from newest to oldest; once enough completed Backups were kept,
+	// every older finished Backup is deleted.
+	var deleted []string
+	kept := int32(0)
+	for i := range backups {
+		backup := &backups[i]
+		phase, _ := phaseOf(backup)
+		if kept < policy.retention() {
+			if phase == PhaseComplete {
+				kept++
+			}
+			continue
+		}
+		if phase == PhasePending {
+			continue
+		}
+		if err := c.Delete(ctx, backup); client.IgnoreNotFound(err) != nil {
+			return deleted, fmt.Errorf("deleting Backup %s: %w", backup.GetName(), err)
+		}
+		deleted = append([]string{backup.GetName()}, deleted...)
+	}
+	return deleted, nil
+}
+
+// DeleteAll deletes all Backups of owner, for owners whose deletion policy
+// is Delete. It returns the names of the deleted Backups, sorted.
+func DeleteAll(ctx context.Context, c client.Client, owner client.Object) ([]string, error) {
+	backups, err := listBackups(ctx, c, owner)
+	if err != nil {
+		return nil, err
+	}
+	var deleted []string
+	for i := range backups {
+		backup := &backups[i]
+		if err := c.Delete(ctx, backup); client.IgnoreNotFound(err) != nil {
+			return deleted, fmt.Errorf("deleting Backup %s: %w", backup.GetName(), err)
+		}
+		deleted = append(deleted, backup.GetName())
+	}
+	sort.Strings(deleted)
+	return deleted, nil
+}
+
+// listBackups lists the Backups labelled with the UID of owner.
+func listBackups(ctx context.Context, c client.Client, owner client.Object) ([]unstructured.Unstructured, error) {
+	if owner.GetUID() == "" {
+		return nil, fmt.Errorf("backup owner %s has no UID", owner.GetName())
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(BackupGVK.GroupVersion().WithKind(BackupGVK.Kind + "List"))
+	if err := c.List(ctx, list, client.InNamespace(owner.GetNamespace()),
+		client.MatchingLabels{LabelOwnerUID: string(owner.GetUID())}); err != nil {
+		return nil, fmt.Errorf("listing Backups of %s: %w", owner.GetName(), err)
+	}
+	return list.Items, nil
+}
+
+// phaseOf reads the Complete condition the mariadb-operator sets on Backups
+// and Restores.
+func phaseOf(obj *unstructured.Unstructured) (Phase, string) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, raw := range conditions {
+		cond, ok := raw.(map[string]interface{})
+		if !ok || cond["type"] != "Complete" {
+			continue
+		}
+		message, _ := cond["message"].(string)
+		switch {
+		case cond["status"] == string(metav1.ConditionTrue):
+			return PhaseComplete, message
+		case cond["reason"] == "JobFailed":
+			return PhaseFailed, message
+		}
+		return PhasePending, message
+	}
+	return PhasePending, ""
+}
+
+// resourceName turns s into a valid object name, shortening it with a hash
+// suffix if it is too long.
+func resourceName(s string) string {
+	const maxLen = 63
+	name := strings.Trim(sanitize(s), "-.")
+	if len(name) <= maxLen {
+		return name
+	}
+	sum := sha256.Sum256([]byte(s))
+	return strings.TrimRight(name[:maxLen-9], "-.") + "-" + hex.EncodeToString(sum[:4])
+}
+
+// labelValue turns s into a valid label value.
+func labelValue(s string) string {
+	v := strings.Trim(sanitize(s), "-.")
+	if len(v) > 63 {
+		v = strings.TrimRight(v[:63], "-.")
+	}
+	return v
+}
+
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return '-'
+	}, s)
+}
@@ -0,0 +1,252 @@
+package dbbackup
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(s)
+	return s
+}
+
+func newOwner() *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "keystone", Namespace: "default", UID: "owner-uid"}}
+}
+
+// backupObject returns a Backup of the owner created at the given offset,
+// in the given phase.
+func backupObject(name string, age time.Duration, phase Phase) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	u.SetGroupVersionKind(BackupGVK)
+	u.SetName(name)
+	u.SetNamespace("default")
+	u.SetLabels(map[string]string{LabelOwnerUID: "owner-uid"})
+	u.SetCreationTimestamp(metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Add(-age)))
+	setPhase(u, phase)
+	return u
+}
+
+// setPhase sets the Complete condition the way the mariadb-operator does.
+func setPhase(u *unstructured.Unstructured, phase Phase) {
+	cond := map[string]interface{}{"type": "Complete", "status": "False", "reason": "JobRunning"}
+	switch phase {
+	case PhaseComplete:
+		cond["status"], cond["reason"] = "True", "JobComplete"
+	case PhaseFailed:
+		cond["reason"], cond["message"] = "JobFailed", "job failed"
+	}
+	_ = unstructured.SetNestedSlice(u.Object, []interface{}{cond}, "status", "conditions")
+}
+
+func TestBackupName(t *testing.T) {
+	tests := []struct {
+		kind, owner, revision string
+		want                  string
+	}{
+		{"Keystone", "keystone", "2025.1", "keystone-keystone-pre-2025.1"},
+		{"Keystone", "keystone", "Caracal_2024", "keystone-keystone-pre-caracal-2024"},
+		{"KeystoneRestore", "keystone", "2025.1", "keystonerestore-keystone-pre-2025.1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.want, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(BackupName(tc.kind, tc.owner, tc.revision)).To(Equal(tc.want))
+		})
+	}
+
+	g := NewGomegaWithT(t)
+	long := BackupName("Keystone", strings.Repeat("k", 70), "2025.1")
+	g.Expect(len(long)).To(BeNumerically("<=", 63))
+	g.Expect(long).To(HaveSuffix("-pre-2025.1"))
+	g.Expect(long).NotTo(Equal(BackupName("Keystone", strings.Repeat("k", 71), "2025.1")))
+	g.Expect(long).NotTo(Equal(BackupName("Keystone", strings.Repeat("k", 70), "2025.2")))
+	g.Expect(len(BackupName("Keystone", "keystone", strings.Repeat("r", 70)))).To(BeNumerically("<=", 63))
+}
+
+func TestEnsureBackup(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(newScheme()).Build()
+	storageClass := "fast"
+	req := BackupRequest{
+		Owner:    newOwner(),
+		Revision: "2025.1",
+		MariaDB:  "mariadb",
+		Database: "keystone",
+		Policy:   Policy{Enabled: true, Storage: Storage{StorageClassName: &storageClass}},
+	}
+
+	status, err := EnsureBackup(ctx, c, newScheme(), req)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(status).To(Equal(Status{Name: "configmap-keystone-pre-2025.1", Phase: PhasePending}))
+
+	backup := &unstructured.Unstructured{}
+	backup.SetGroupVersionKind(BackupGVK)
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: status.Name}, backup)).To(Succeed())
+	g.Expect(backup.GetLabels()).To(HaveKeyWithValue(LabelOwnerUID, "owner-uid"))
+	g.Expect(backup.GetLabels()).To(HaveKeyWithValue(LabelOwnerKind, "configmap"))
+	g.Expect(backup.GetAnnotations()).To(HaveKeyWithValue(AnnotationBackupOf, "keystone"))
+	g.Expect(backup.GetLabels()).To(HaveKeyWithValue(LabelRevision, "2025.1"))
+	g.Expect(nestedString(backup, "spec", "mariaDbRef", "name")).To(Equal("mariadb"))
+	g.Expect(nestedStringSlice(backup, "spec", "databases")).To(Equal([]string{"keystone"}))
+	g.Expect(nestedString(backup, "spec", "storage", "persistentVolumeClaim", "resources", "requests", "storage")).To(Equal(DefaultStorageSize))
+	g.Expect(nestedString(backup, "spec", "storage", "persistentVolumeClaim", "storageClassName")).To(Equal("fast"))
+
+	setPhase(backup, PhaseFailed)
+	g.Expect(c.Update(ctx, backup)).To(Succeed())
+
+	status, err = EnsureBackup(ctx, c, newScheme(), req)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(status).To(Equal(Status{Name: "configmap-keystone-pre-2025.1", Phase: PhaseFailed, Message: "job failed"}))
+}
+
+func TestEnsureBackupLongOwnerName(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(newScheme()).Build()
+	owner := newOwner()
+	owner.Name = strings.Repeat("keystone-", 10)
+
+	status, err := EnsureBackup(ctx, c, newScheme(), BackupRequest{Owner: owner, Revision: "2025.1", MariaDB: "mariadb", Database: "keystone"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(len(status.Name)).To(BeNumerically("<=", 63))
+
+	backup := &unstructured.Unstructured{}
+	backup.SetGroupVersionKind(BackupGVK)
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: status.Name}, backup)).To(Succeed())
+	g.Expect(backup.GetAnnotations()).To(HaveKeyWithValue(AnnotationBackupOf, owner.Name))
+	for key, value := range backup.GetLabels() {
+		g.Expect(validation.IsValidLabelValue(value)).To(BeEmpty(), key)
+	}
+}
+
+func TestEnsureBackupRequiresOwnerUID(t *testing.T) {
+	g := NewGomegaWithT(t)
+	c := fake.NewClientBuilder().WithScheme(newScheme()).Build()
+	owner := newOwner()
+	owner.UID = ""
+
+	_, err := EnsureBackup(context.Background(), c, newScheme(), BackupRequest{Owner: owner, Revision: "2025.1"})
+	g.Expect(err).To(MatchError(ContainSubstring("has no UID")))
+}
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name        string
+		retention   int32
+		backups     []*unstructured.Unstructured
+		wantDeleted []string
+	}{
+		{
+			name:      "within retention",
+			retention: 2,
+			backups: []*unstructured.Unstructured{
+				backupObject("b1", 2*time.Hour, PhaseComplete),
+				backupObject("b2", time.Hour, PhaseComplete),
+			},
+		},
+		{
+			name:      "deletes oldest completed",
+			retention: 2,
+			backups: []*unstructured.Unstructured{
+				backupObject("b1", 3*time.Hour, PhaseComplete),
+				backupObject("b2", 2*time.Hour, PhaseComplete),
+				backupObject("b3", time.Hour, PhaseComplete),
+			},
+			wantDeleted: []string{"b1"},
+		},
+		{
+			name:      "keeps failed and running backups newer than the retained ones",
+			retention: 1,
+			backups: []*unstructured.Unstructured{
+				backupObject("b1", 4*time.Hour, PhaseFailed),
+				backupObject("b2", 3*time.Hour, PhaseComplete),
+				backupObject("b3", 2*time.Hour, PhaseFailed),
+				backupObject("b4", time.Hour, PhasePending),
+			},
+			wantDeleted: []string{"b1"},
+		},
+		{
+			name:      "deletes older finished but not older running backups",
+			retention: 1,
+			backups: []*unstructured.Unstructured{
+				backupObject("b1", 4*time.Hour, PhasePending),
+				backupObject("b2", 3*time.Hour, PhaseFailed),
+				backupObject("b3", 2*time.Hour, PhaseComplete),
+				backupObject("b4", time.Hour, PhaseComplete),
+			},
+			wantDeleted: []string{"b2", "b3"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			ctx := context.Background()
+			builder := fake.NewClientBuilder().WithScheme(newScheme())
+			for _, b := range tc.backups {
+				builder = builder.WithObjects(b)
+			}
+			// A Backup of an earlier CR with the same name.
+			other := backupObject("other", 5*time.Hour, PhaseComplete)
+			other.SetLabels(map[string]string{LabelOwnerUID: "earlier-uid"})
+			c := builder.WithObjects(other).Build()
+
+			deleted, err := Prune(ctx, c, newOwner(), Policy{Retention: tc.retention})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(deleted).To(Equal(tc.wantDeleted))
+
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(BackupGVK.GroupVersion().WithKind("BackupList"))
+			g.Expect(c.List(ctx, list)).To(Succeed())
+			g.Expect(list.Items).To(HaveLen(len(tc.backups) + 1 - len(tc.wantDeleted)))
+		})
+	}
+}
+
+func nestedString(u *unstructured.Unstructured, fields ...string) string {
+	v, _, _ := unstructured.NestedString(u.Object, fields...)
+	return v
+}
+
+func nestedStringSlice(u *unstructured.Unstructured, fields ...string) []string {
+	v, _, _ := unstructured.NestedStringSlice(u.Object, fields...)
+	return v
+}
+
+func TestDeleteAll(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	// A Backup of another kind of CR with the same name.
+	other := backupObject("keystonerestore-keystone-pre-2025.1", 0, PhaseComplete)
+	other.SetLabels(map[string]string{LabelOwnerUID: "other-uid"})
+	c := fake.NewClientBuilder().WithScheme(newScheme()).WithObjects(
+		backupObject("b2", time.Hour, PhaseComplete),
+		backupObject("b1", 2*time.Hour, PhasePending),
+		other,
+	).Build()
+
+	deleted, err := DeleteAll(ctx, c, newOwner())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(deleted).To(Equal([]string{"b1", "b2"}))
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(BackupGVK.GroupVersion().WithKind("BackupList"))
+	g.Expect(c.List(ctx, list)).To(Succeed())
+	g.Expect(list.Items).To(HaveLen(1))
+	g.Expect(list.Items[0].GetName()).To(Equal("keystonerestore-keystone-pre-2025.1"))
+}
@@ -0,0 +1,26 @@
+// Package dbbackup orchestrates backups and restores of the service
+// databases through the mariadb-operator Backup and Restore resources.
+//
+// A service CR declares a Policy, typically as spec.backup. Before each
+// db_sync migration its controller calls EnsureBackup with the target
+// revision and only starts the migration once the backup is Complete:
+//
+//	status, err := dbbackup.EnsureBackup(ctx, c, r.Scheme, dbbackup.BackupRequest{
+//		Owner: keystone, Revision: "2025.2", MariaDB: "mariadb", Database: "keystone",
+//		Policy: keystone.Spec.Backup,
+//	})
+//	if err != nil || status.Phase != dbbackup.PhaseComplete {
+//		return ctrl.Result{RequeueAfter: cfg.Reconcile.ErrorRequeueInterval.Duration}, err
+//	}
+//
+// Backups are labelled with the owner's UID and kind but not owned by it, so
+// they survive the deletion of the CR. Prune deletes all but the newest
+// Policy.Retention completed backups, and DeleteAll removes every backup of
+// a CR whose deletion policy is Delete.
+//
+// A restore CR such as KeystoneRestore drives StepRestore from its
+// reconciler and stores the returned phase in its status. A restore pauses
+// the service CR (see package pause) so that its controller does not
+// interfere, scales the API Deployment down, restores the backup, and scales
+// the Deployment back up before resuming the service CR.
+package dbbackup
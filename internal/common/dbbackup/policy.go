@@ -0,0 +1,100 @@
+package dbbackup
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Defaults applied to unset Policy fields.
+const (
+	DefaultRetention   int32 = 3
+	DefaultStorageSize       = "1Gi"
+)
+
+// Policy configures the schema backups taken before database migrations.
+// It is meant to be embedded in the spec of a service CR.
+type Policy struct {
+	// Enabled makes the operator back up the database before every
+	// migration.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// Retention is the number of completed backups to keep. It defaults to
+	// DefaultRetention.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Retention int32 `json:"retention,omitempty"`
+	// Storage configures the PersistentVolumeClaim the backups are written
+	// to.
+	// +optional
+	Storage Storage `json:"storage,omitempty"`
+}
+
+// Storage configures the volume of a backup.
+type Storage struct {
+	// Size of the volume. It defaults to DefaultStorageSize.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+	// StorageClassName of the volume. The cluster default is used if unset.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+}
+
+// DeepCopyInto copies p into out.
+func (p *Policy) DeepCopyInto(out *Policy) {
+	*out = *p
+	p.Storage.DeepCopyInto(&out.Storage)
+}
+
+// DeepCopy returns a copy of p.
+func (p *Policy) DeepCopy() *Policy {
+	if p == nil {
+		return nil
+	}
+	out := new(Policy)
+	p.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies s into out.
+func (s *Storage) DeepCopyInto(out *Storage) {
+	*out = *s
+	if s.Size != nil {
+		size := s.Size.DeepCopy()
+		out.Size = &size
+	}
+	if s.StorageClassName != nil {
+		name := *s.StorageClassName
+		out.StorageClassName = &name
+	}
+}
+
+// retention returns Retention or its default.
+func (p Policy) retention() int32 {
+	if p.Retention <= 0 {
+		return DefaultRetention
+	}
+	return p.Retention
+}
+
+// size returns Storage.Size or its default.
+func (p Policy) size() resource.Quantity {
+	if p.Storage.Size == nil {
+		return resource.MustParse(DefaultStorageSize)
+	}
+	return p.Storage.Size.DeepCopy()
+}
+
+// Validate reports invalid fields. Zero values are valid and mean the
+// defaults.
+func (p Policy) Validate() error {
+	var errs []error
+	if p.Retention < 0 {
+		errs = append(errs, fmt.Errorf("retention: must not be negative, got %d", p.Retention))
+	}
+	if p.Storage.Size != nil && p.Storage.Size.Sign() <= 0 {
+		errs = append(errs, fmt.Errorf("storage.size: must be positive, got %s", p.Storage.Size))
+	}
+	return errors.Join(errs...)
+}
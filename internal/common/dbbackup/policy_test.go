@@ -0,0 +1,54 @@
+package dbbackup
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	negative := resource.MustParse("-1Gi")
+	valid := resource.MustParse("5Gi")
+
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr string
+	}{
+		{name: "zero value", policy: Policy{}},
+		{name: "explicit values", policy: Policy{Enabled: true, Retention: 5, Storage: Storage{Size: &valid}}},
+		{name: "negative retention", policy: Policy{Retention: -1}, wantErr: "retention"},
+		{name: "negative size", policy: Policy{Storage: Storage{Size: &negative}}, wantErr: "storage.size"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			err := tc.policy.Validate()
+			if tc.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+		})
+	}
+}
+
+func TestPolicyDefaults(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(Policy{}.retention()).To(Equal(DefaultRetention))
+	size := Policy{}.size()
+	g.Expect(size.String()).To(Equal(DefaultStorageSize))
+
+	custom := resource.MustParse("10Gi")
+	p := Policy{Retention: 1, Storage: Storage{Size: &custom}}
+	g.Expect(p.retention()).To(Equal(int32(1)))
+	size = p.size()
+	g.Expect(size.String()).To(Equal("10Gi"))
+
+	copied := p.DeepCopy()
+	copied.Storage.Size.Set(1)
+	g.Expect(p.Storage.Size.String()).To(Equal("10Gi"))
+}
@@ -0,0 +1,256 @@
+package dbbackup
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/c5c3/forge/internal/common/pause"
+)
+
+// RestorePhase is the progress of a restore, stored in the status of the
+// restore CR.
+type RestorePhase string
+
+// Phases of a restore. Completed and Failed are terminal.
+const (
+	RestorePhaseScalingDown RestorePhase = "ScalingDown"
+	RestorePhaseRestoring   RestorePhase = "Restoring"
+	RestorePhaseScalingUp   RestorePhase = "ScalingUp"
+	RestorePhaseCompleted   RestorePhase = "Completed"
+	RestorePhaseFailed      RestorePhase = "Failed"
+)
+
+// ReplicasAnnotation records the replicas of the API Deployment while it is
+// scaled down for a restore.
+const ReplicasAnnotation = "forge.c5c3.io/replicas-before-restore"
+
+// RestoreRequest describes the restore of a service database from a Backup.
+type RestoreRequest struct {
+	// Owner is the restore CR, e.g. a KeystoneRestore. It controls the
+	// mariadb-operator Restore, which has the same name and namespace.
+	Owner client.Object
+	// Target is the service CR whose database is restored. It is paused
+	// while the restore runs so that its controller does not scale the API
+	// back up.
+	Target client.Object
+	// Deployment is the name of the target's API Deployment.
+	Deployment string
+	// Backup is the name of the Backup to restore.
+	Backup string
+	// MariaDB is the name of the MariaDB holding the database.
+	MariaDB string
+	// Database is the name of the schema to restore.
+	Database string
+}
+
+// StepRestore advances a restore that is in phase, the empty phase meaning
+// not started, and returns the new phase and a message for the restore CR's
+// status. The caller requeues until a terminal phase is returned. All
+// objects live in the owner's namespace; scheme resolves the owner's kind.
+//
+// A failed Restore leaves the API scaled down and the target paused, since
+// the database may be partially restored; the message tells the user what
+// to check.
+func StepRestore(ctx context.Context, c client.Client, scheme *runtime.Scheme, req RestoreRequest, phase RestorePhase) (RestorePhase, string, error) {
+	switch phase {
+	case "":
+		backup := &unstructured.Unstructured{}
+		backup.SetGroupVersionKind(BackupGVK)
+		if err := c.Get(ctx, client.ObjectKey{Namespace: req.Owner.GetNamespace(), Name: req.Backup}, backup); err != nil {
+			if apierrors.IsNotFound(err) {
+				return RestorePhaseFailed, fmt.Sprintf("Backup %s not found", req.Backup), nil
+			}
+			return phase, "", fmt.Errorf("getting Backup %s: %w", req.Backup, err)
+		}
+		if backupPhase, _ := phaseOf(backup); backupPhase != PhaseComplete {
+			return RestorePhaseFailed, fmt.Sprintf("Backup %s has not completed", req.Backup), nil
+		}
+		return RestorePhaseScalingDown, "Pausing reconciliation and scaling down the API", nil
+
+	case RestorePhaseScalingDown:
+		if err := pauseTarget(ctx, c, scheme, req); err != nil {
+			return phase, "", err
+		}
+		down, err := scaleDown(ctx, c, req)
+		if err != nil || !down {
+			return phase, "Waiting for the API pods to terminate", err
+		}
+		return RestorePhaseRestoring, fmt.Sprintf("Restoring Backup %s", req.Backup), nil
+
+	case RestorePhaseRestoring:
+		restore, err := ensureRestore(ctx, c, scheme, req)
+		if err != nil {
+			return phase, "", err
+		}
+		switch restorePhase, message := phaseOf(restore); restorePhase {
+		case PhaseComplete:
+			return RestorePhaseScalingUp, "Scaling the API back up", nil
+		case PhaseFailed:
+			return RestorePhaseFailed, fmt.Sprintf("Restore failed: %s; the API stays scaled down and %s paused until the database is checked",
+				message, req.Target.GetName()), nil
+		}
+		return phase, fmt.Sprintf("Restoring Backup %s", req.Backup), nil
+
+	case RestorePhaseScalingUp:
+		up, err := scaleUp(ctx, c, req)
+		if err != nil {
+			return phase, "", err
+		}
+		if err := resumeTarget(ctx, c, scheme, req); err != nil {
+			return phase, "", err
+		}
+		if !up {
+			return phase, "Waiting for the API to become available", nil
+		}
+		return RestorePhaseCompleted, fmt.Sprintf("Restored Backup %s", req.Backup), nil
+	}
+	return phase, "", nil
+}
+
+// pausedBy is the value of pause.ByAnnotation set by req.
+func pausedBy(scheme *runtime.Scheme, owner client.Object) (string, error) {
+	gvk, err := apiutil.GVKForObject(owner, scheme)
+	if err != nil {
+		return "", fmt.Errorf("resolving kind of restore owner: %w", err)
+	}
+	return gvk.Kind + "/" + owner.GetName(), nil
+}
+
+// pauseTarget pauses the target unless it is already paused, in which case
+// the existing pause is left to its owner.
+func pauseTarget(ctx context.Context, c client.Client, scheme *runtime.Scheme, req RestoreRequest) error {
+	if pause.IsPaused(req.Target) {
+		return nil
+	}
+	by, err := pausedBy(scheme, req.Owner)
+	if err != nil {
+		return err
+	}
+	patch := client.MergeFrom(req.Target.DeepCopyObject().(client.Object))
+	annotations := req.Target.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[pause.Annotation] = "true"
+	annotations[pause.ByAnnotation] = by
+	req.Target.SetAnnotations(annotations)
+	if err := c.Patch(ctx, req.Target, patch); err != nil {
+		return fmt.Errorf("pausing %s: %w", req.Target.GetName(), err)
+	}
+	return nil
+}
+
+// resumeTarget removes the pause set by pauseTarget.
+func resumeTarget(ctx context.Context, c client.Client, scheme *runtime.Scheme, req RestoreRequest) error {
+	by, err := pausedBy(scheme, req.Owner)
+	if err != nil {
+		return err
+	}
+	if req.Target.GetAnnotations()[pause.ByAnnotation] != by {
+		return nil
+	}
+	patch := client.MergeFrom(req.Target.DeepCopyObject().(client.Object))
+	annotations := req.Target.GetAnnotations()
+	delete(annotations, pause.Annotation)
+	delete(annotations, pause.ByAnnotation)
+	req.Target.SetAnnotations(annotations)
+	if err := c.Patch(ctx, req.Target, patch); err != nil {
+		return fmt.Errorf("resuming %s: %w", req.Target.GetName(), err)
+	}
+	return nil
+}
+
+// scaleDown scales the Deployment to zero, remembering its replicas, and
+// reports whether all its pods are gone.
+func scaleDown(ctx context.Context, c client.Client, req RestoreRequest) (bool, error) {
+	deploy := &appsv1.Deployment{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: req.Owner.GetNamespace(), Name: req.Deployment}, deploy); err != nil {
+		return false, fmt.Errorf("getting Deployment %s: %w", req.Deployment, err)
+	}
+	if _, recorded := deploy.Annotations[ReplicasAnnotation]; !recorded {
+		replicas := int32(1)
+		if deploy.Spec.Replicas != nil {
+			replicas = *deploy.Spec.Replicas
+		}
+		patch := client.MergeFrom(deploy.DeepCopy())
+		if deploy.Annotations == nil {
+			deploy.Annotations = map[string]string{}
+		}
+		deploy.Annotations[ReplicasAnnotation] = strconv.Itoa(int(replicas))
+		zero := int32(0)
+		deploy.Spec.Replicas = &zero
+		if err := c.Patch(ctx, deploy, patch); err != nil {
+			return false, fmt.Errorf("scaling down Deployment %s: %w", req.Deployment, err)
+		}
+	}
+	return deploy.Status.Replicas == 0, nil
+}
+
+// scaleUp restores the replicas recorded by scaleDown and reports whether
+// the Deployment is available again.
+func scaleUp(ctx context.Context, c client.Client, req RestoreRequest) (bool, error) {
+	deploy := &appsv1.Deployment{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: req.Owner.GetNamespace(), Name: req.Deployment}, deploy); err != nil {
+		return false, fmt.Errorf("getting Deployment %s: %w", req.Deployment, err)
+	}
+	if recorded, ok := deploy.Annotations[ReplicasAnnotation]; ok {
+		replicas, err := strconv.ParseInt(recorded, 10, 32)
+		if err != nil {
+			replicas = 1
+		}
+		patch := client.MergeFrom(deploy.DeepCopy())
+		delete(deploy.Annotations, ReplicasAnnotation)
+		r := int32(replicas)
+		deploy.Spec.Replicas = &r
+		if err := c.Patch(ctx, deploy, patch); err != nil {
+			return false, fmt.Errorf("scaling up Deployment %s: %w", req.Deployment, err)
+		}
+	}
+	want := int32(1)
+	if deploy.Spec.Replicas != nil {
+		want = *deploy.Spec.Replicas
+	}
+	return deploy.Status.ObservedGeneration >= deploy.Generation && deploy.Status.AvailableReplicas >= want, nil
+}
+
+// ensureRestore creates the mariadb-operator Restore controlled by the owner
+// unless it exists and returns it.
+func ensureRestore(ctx context.Context, c client.Client, scheme *runtime.Scheme, req RestoreRequest) (*unstructured.Unstructured, error) {
+	restore := &unstructured.Unstructured{}
+	restore.SetGroupVersionKind(RestoreGVK)
+	key := client.ObjectKey{Namespace: req.Owner.GetNamespace(), Name: req.Owner.GetName()}
+	err := c.Get(ctx, key, restore)
+	if err == nil {
+		return restore, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("getting Restore %s: %w", key.Name, err)
+	}
+
+	restore = &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"mariaDbRef": map[string]interface{}{"name": req.MariaDB},
+			"backupRef":  map[string]interface{}{"name": req.Backup},
+			"database":   req.Database,
+		},
+	}}
+	restore.SetGroupVersionKind(RestoreGVK)
+	restore.SetName(key.Name)
+	restore.SetNamespace(key.Namespace)
+	if err := controllerutil.SetControllerReference(req.Owner, restore, scheme); err != nil {
+		return nil, fmt.Errorf("setting owner of Restore %s: %w", key.Name, err)
+	}
+	if err := c.Create(ctx, restore); err != nil {
+		return nil, fmt.Errorf("creating Restore %s: %w", key.Name, err)
+	}
+	return restore, nil
+}
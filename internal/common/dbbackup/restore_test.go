@@ -0,0 +1,165 @@
+package dbbackup
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/c5c3/forge/internal/common/pause"
+)
+
+func newRestoreFixture(g *WithT, backupPhase Phase, targetAnnotations map[string]string) (client.Client, RestoreRequest) {
+	target := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: "keystone", Namespace: "default", Annotations: targetAnnotations,
+	}}
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "restore-1", Namespace: "default", UID: "restore-uid"}}
+	replicas := int32(3)
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "keystone-api", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{Replicas: 3, AvailableReplicas: 3},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(newScheme()).
+		WithObjects(target, owner, deploy, backupObject("keystone-pre-2025.1", 0, backupPhase)).
+		WithStatusSubresource(deploy).
+		Build()
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(target), target)).To(Succeed())
+	return c, RestoreRequest{
+		Owner:      owner,
+		Target:     target,
+		Deployment: "keystone-api",
+		Backup:     "keystone-pre-2025.1",
+		MariaDB:    "mariadb",
+		Database:   "keystone",
+	}
+}
+
+// setDeploymentStatus stands in for the Deployment controller.
+func setDeploymentStatus(g *WithT, c client.Client, replicas int32) {
+	ctx := context.Background()
+	deploy := &appsv1.Deployment{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "keystone-api"}, deploy)).To(Succeed())
+	deploy.Status.Replicas = replicas
+	deploy.Status.AvailableReplicas = replicas
+	deploy.Status.ObservedGeneration = deploy.Generation
+	g.Expect(c.Status().Update(ctx, deploy)).To(Succeed())
+}
+
+// setRestorePhase stands in for the mariadb-operator finishing the Restore.
+func setRestorePhase(g *WithT, c client.Client, phase Phase) {
+	ctx := context.Background()
+	restore := &unstructured.Unstructured{}
+	restore.SetGroupVersionKind(RestoreGVK)
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "restore-1"}, restore)).To(Succeed())
+	setPhase(restore, phase)
+	g.Expect(c.Update(ctx, restore)).To(Succeed())
+}
+
+func step(g *WithT, c client.Client, req RestoreRequest, phase RestorePhase) RestorePhase {
+	next, message, err := StepRestore(context.Background(), c, newScheme(), req, phase)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(message).NotTo(BeEmpty())
+	return next
+}
+
+func TestStepRestore(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	c, req := newRestoreFixture(g, PhaseComplete, nil)
+
+	phase := step(g, c, req, "")
+	g.Expect(phase).To(Equal(RestorePhaseScalingDown))
+
+	// The API is paused and scaled down; pods are still terminating.
+	phase = step(g, c, req, phase)
+	g.Expect(phase).To(Equal(RestorePhaseScalingDown))
+	g.Expect(pause.IsPaused(req.Target)).To(BeTrue())
+	g.Expect(req.Target.GetAnnotations()).To(HaveKeyWithValue(pause.ByAnnotation, "ConfigMap/restore-1"))
+	deploy := &appsv1.Deployment{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "keystone-api"}, deploy)).To(Succeed())
+	g.Expect(deploy.Spec.Replicas).To(HaveValue(BeZero()))
+	g.Expect(deploy.Annotations).To(HaveKeyWithValue(ReplicasAnnotation, "3"))
+
+	setDeploymentStatus(g, c, 0)
+	phase = step(g, c, req, phase)
+	g.Expect(phase).To(Equal(RestorePhaseRestoring))
+
+	phase = step(g, c, req, phase)
+	g.Expect(phase).To(Equal(RestorePhaseRestoring))
+	restore := &unstructured.Unstructured{}
+	restore.SetGroupVersionKind(RestoreGVK)
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "restore-1"}, restore)).To(Succeed())
+	g.Expect(nestedString(restore, "spec", "backupRef", "name")).To(Equal("keystone-pre-2025.1"))
+	g.Expect(nestedString(restore, "spec", "mariaDbRef", "name")).To(Equal("mariadb"))
+	g.Expect(nestedString(restore, "spec", "database")).To(Equal("keystone"))
+	g.Expect(restore.GetOwnerReferences()).To(ConsistOf(HaveField("UID", BeEquivalentTo("restore-uid"))))
+
+	setRestorePhase(g, c, PhaseComplete)
+	phase = step(g, c, req, phase)
+	g.Expect(phase).To(Equal(RestorePhaseScalingUp))
+
+	// Replicas are restored and the target resumed; pods are starting.
+	phase = step(g, c, req, phase)
+	g.Expect(phase).To(Equal(RestorePhaseScalingUp))
+	g.Expect(pause.IsPaused(req.Target)).To(BeFalse())
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "keystone-api"}, deploy)).To(Succeed())
+	g.Expect(deploy.Spec.Replicas).To(HaveValue(Equal(int32(3))))
+	g.Expect(deploy.Annotations).NotTo(HaveKey(ReplicasAnnotation))
+
+	setDeploymentStatus(g, c, 3)
+	phase = step(g, c, req, phase)
+	g.Expect(phase).To(Equal(RestorePhaseCompleted))
+}
+
+func TestStepRestoreFailures(t *testing.T) {
+	t.Run("backup not complete", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		c, req := newRestoreFixture(g, PhasePending, nil)
+		g.Expect(step(g, c, req, "")).To(Equal(RestorePhaseFailed))
+	})
+
+	t.Run("backup missing", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		c, req := newRestoreFixture(g, PhaseComplete, nil)
+		req.Backup = "missing"
+		g.Expect(step(g, c, req, "")).To(Equal(RestorePhaseFailed))
+	})
+
+	t.Run("restore fails and leaves the API down", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		c, req := newRestoreFixture(g, PhaseComplete, nil)
+		phase := step(g, c, req, RestorePhaseScalingDown)
+		setDeploymentStatus(g, c, 0)
+		phase = step(g, c, req, phase)
+		phase = step(g, c, req, phase)
+		setRestorePhase(g, c, PhaseFailed)
+		g.Expect(step(g, c, req, phase)).To(Equal(RestorePhaseFailed))
+		g.Expect(pause.IsPaused(req.Target)).To(BeTrue())
+	})
+}
+
+func TestStepRestoreKeepsForeignPause(t *testing.T) {
+	g := NewGomegaWithT(t)
+	c, req := newRestoreFixture(g, PhaseComplete, map[string]string{
+		pause.Annotation:   "true",
+		pause.ByAnnotation: "ControlPlane/cp",
+	})
+
+	phase := step(g, c, req, RestorePhaseScalingDown)
+	g.Expect(req.Target.GetAnnotations()).To(HaveKeyWithValue(pause.ByAnnotation, "ControlPlane/cp"))
+	setDeploymentStatus(g, c, 0)
+	phase = step(g, c, req, phase)
+	g.Expect(phase).To(Equal(RestorePhaseRestoring))
+
+	g.Expect(step(g, c, req, RestorePhaseScalingUp)).To(Equal(RestorePhaseScalingUp))
+	g.Expect(pause.IsPaused(req.Target)).To(BeTrue())
+	g.Expect(req.Target.GetAnnotations()).To(HaveKeyWithValue(pause.ByAnnotation, "ControlPlane/cp"))
+}
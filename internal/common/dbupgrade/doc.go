@@ -0,0 +1,38 @@
+// Package dbupgrade drives zero-downtime upgrades of an OpenStack API whose
+// database migrations are split into expand, migrate and contract steps, as
+// with keystone-manage db_sync --expand, --migrate and --contract.
+//
+// An upgrade to a new image runs in phases recorded in Status:
+//
+//	BackingUp   a backup of the database is taken, if Request.Backup is set.
+//	Expanding   db_sync --expand adds the new schema; old code keeps working.
+//	RollingOut  the API Deployment rolls to the new image while old and new
+//	            replicas coexist.
+//	Migrating   db_sync --migrate moves the data to the new schema.
+//	Contracting db_sync --contract removes what only the old code needed.
+//	Completed   the upgrade finished.
+//
+// A failed backup keeps the upgrade in BackingUp until the Backup is deleted,
+// which takes a new one. If expand fails the Deployment is kept on the old image and the upgrade
+// ends in RolledBack. A failure of a later step ends in Failed, because the
+// new code already runs against the changed schema. Both are terminal: the
+// upgrade stays blocked until the requested image changes. The Jobs of a
+// failed upgrade are deleted, so requesting the same image again later runs
+// every step anew.
+//
+// A service controller stores the Status in its CR and calls Advance on every
+// reconcile, requeueing while Status.InProgress reports true. Advance only
+// starts upgrades while the ZeroDowntimeUpgrade feature gate is enabled in
+// Request.Gates; otherwise the controller rolls out new images itself:
+//
+//	status, err := dbupgrade.Advance(ctx, c, scheme, recorder, dbupgrade.Request{
+//		Owner: keystone, Deployment: "keystone-api", Container: "keystone-api",
+//		Image: image, JobSpec: dbSyncJobSpec, Gates: gates,
+//	}, keystone.Status.Upgrade)
+//	keystone.Status.Upgrade = status
+//
+// Advance patches only the image of the Deployment. A controller that renders
+// and applies the whole Deployment afterwards, e.g. with server-side apply,
+// must render Status.Image(image) rather than the requested image, so that
+// it does not roll out the new image while the schema is being expanded.
+package dbupgrade
@@ -0,0 +1,81 @@
+package dbupgrade
+
+// Phase is the phase of an upgrade.
+type Phase string
+
+// Phases of an upgrade. Completed, RolledBack and Failed are terminal.
+const (
+	PhaseBackingUp   Phase = "BackingUp"
+	PhaseExpanding   Phase = "Expanding"
+	PhaseRollingOut  Phase = "RollingOut"
+	PhaseMigrating   Phase = "Migrating"
+	PhaseContracting Phase = "Contracting"
+	PhaseCompleted   Phase = "Completed"
+	PhaseRolledBack  Phase = "RolledBack"
+	PhaseFailed      Phase = "Failed"
+)
+
+// Status is the observed state of the last upgrade. It is meant to be
+// embedded in the status of the service CR.
+type Status struct {
+	// Phase of the upgrade; empty if no upgrade ran yet.
+	// +optional
+	Phase Phase `json:"phase,omitempty"`
+	// FromImage is the image the API ran before the upgrade.
+	// +optional
+	FromImage string `json:"fromImage,omitempty"`
+	// ToImage is the image the API is upgraded to.
+	// +optional
+	ToImage string `json:"toImage,omitempty"`
+	// Message describes the current phase or the failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// DeepCopyInto copies s into out.
+func (s *Status) DeepCopyInto(out *Status) {
+	*out = *s
+}
+
+// DeepCopy returns a copy of s.
+func (s *Status) DeepCopy() *Status {
+	if s == nil {
+		return nil
+	}
+	out := new(Status)
+	s.DeepCopyInto(out)
+	return out
+}
+
+// InProgress reports whether an upgrade is running.
+func (s Status) InProgress() bool {
+	switch s.Phase {
+	case PhaseBackingUp, PhaseExpanding, PhaseRollingOut, PhaseMigrating, PhaseContracting:
+		return true
+	}
+	return false
+}
+
+// Blocked reports whether the last upgrade failed and further upgrades wait
+// for a new image.
+func (s Status) Blocked() bool {
+	return s.Phase == PhaseRolledBack || s.Phase == PhaseFailed
+}
+
+// Image returns the image the caller renders into the API Deployment when
+// requested is the image of its spec. While the database is backed up and
+// the schema is expanded the API keeps running FromImage, and once the new image rolled out it stays on
+// ToImage. A controller that applies the whole Deployment must render this
+// image instead of requested, or it would roll out the new image before
+// db_sync --expand ran.
+func (s Status) Image(requested string) string {
+	switch {
+	case s.Phase == PhaseBackingUp, s.Phase == PhaseExpanding:
+		return s.FromImage
+	case s.InProgress():
+		return s.ToImage
+	case s.Phase == PhaseRolledBack && requested == s.ToImage:
+		return s.FromImage
+	}
+	return requested
+}
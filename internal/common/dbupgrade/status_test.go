@@ -0,0 +1,62 @@
+package dbupgrade
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestStatusPredicates(t *testing.T) {
+	tests := []struct {
+		phase      Phase
+		inProgress bool
+		blocked    bool
+	}{
+		{"", false, false},
+		{PhaseBackingUp, true, false},
+		{PhaseExpanding, true, false},
+		{PhaseRollingOut, true, false},
+		{PhaseMigrating, true, false},
+		{PhaseContracting, true, false},
+		{PhaseCompleted, false, false},
+		{PhaseRolledBack, false, true},
+		{PhaseFailed, false, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.phase), func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			s := Status{Phase: tc.phase}
+			g.Expect(s.InProgress()).To(Equal(tc.inProgress))
+			g.Expect(s.Blocked()).To(Equal(tc.blocked))
+		})
+	}
+}
+
+func TestStatusImage(t *testing.T) {
+	const from, to, other = "keystone:2025.1", "keystone:2025.2", "keystone:2026.1"
+	tests := []struct {
+		phase     Phase
+		requested string
+		want      string
+	}{
+		{"", to, to},
+		{PhaseBackingUp, to, from},
+		{PhaseExpanding, to, from},
+		{PhaseRollingOut, to, to},
+		{PhaseMigrating, to, to},
+		{PhaseContracting, to, to},
+		{PhaseCompleted, to, to},
+		{PhaseRolledBack, to, from},
+		{PhaseRolledBack, other, other},
+		{PhaseFailed, to, to},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.phase)+"/"+tc.requested, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			s := Status{Phase: tc.phase, FromImage: from, ToImage: to}
+			g.Expect(s.Image(tc.requested)).To(Equal(tc.want))
+		})
+	}
+}
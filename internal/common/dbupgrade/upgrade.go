@@ -0,0 +1,297 @@
+package dbupgrade
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/c5c3/forge/internal/common/dbbackup"
+	"github.com/c5c3/forge/internal/common/events"
+	"github.com/c5c3/forge/internal/common/featuregate"
+)
+
+// Step is a db_sync migration step.
+type Step string
+
+// Migration steps in the order they run.
+const (
+	StepExpand   Step = "expand"
+	StepMigrate  Step = "migrate"
+	StepContract Step = "contract"
+)
+
+// Flag returns the db_sync flag selecting s, e.g. "--expand".
+func (s Step) Flag() string {
+	return "--" + string(s)
+}
+
+// Request describes the desired state of an upgradable API.
+type Request struct {
+	// Owner is the service CR. It controls the migration Jobs, which live in
+	// its namespace.
+	Owner client.Object
+	// Deployment is the name of the API Deployment.
+	Deployment string
+	// Container is the name of the API container in the Deployment.
+	Container string
+	// Image is the requested API image.
+	Image string
+	// JobSpec returns the spec of the Job running step with image.
+	JobSpec func(step Step, image string) batchv1.JobSpec
+	// Gates are the operator's feature gates. Upgrades only start while
+	// featuregate.ZeroDowntimeUpgrade is enabled.
+	Gates *featuregate.FeatureGate
+	// Backup, if set, is the backup taken before the expand step. Its
+	// Owner must be the same as the request's.
+	Backup *dbbackup.BackupRequest
+}
+
+// Advance moves the upgrade described by status one step forward and returns
+// the new status. It starts an upgrade when the Deployment's image differs
+// from req.Image and the ZeroDowntimeUpgrade feature gate is enabled, and
+// does nothing before the Deployment exists, since the initial installation
+// runs a plain db_sync. An upgrade in progress is finished even if the gate
+// was disabled meanwhile. Transitions are reported as events on the owner.
+func Advance(ctx context.Context, c client.Client, scheme *runtime.Scheme, recorder *events.Recorder, req Request, status Status) (Status, error) {
+	deploy := &appsv1.Deployment{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: req.Owner.GetNamespace(), Name: req.Deployment}, deploy); err != nil {
+		if apierrors.IsNotFound(err) && !status.InProgress() {
+			return status, nil
+		}
+		return status, fmt.Errorf("getting Deployment %s: %w", req.Deployment, err)
+	}
+	current, ok := containerImage(deploy, req.Container)
+	if !ok {
+		return status, fmt.Errorf("deployment %s has no container %s", req.Deployment, req.Container)
+	}
+
+	switch status.Phase {
+	case "", PhaseCompleted, PhaseRolledBack, PhaseFailed:
+		if status.Blocked() && req.Image == status.ToImage {
+			return status, nil
+		}
+		if current == req.Image {
+			if status.Blocked() {
+				return Status{}, nil
+			}
+			return status, nil
+		}
+		if req.Gates == nil || !req.Gates.Enabled(featuregate.ZeroDowntimeUpgrade) {
+			return status, nil
+		}
+		recorder.Event(req.Owner, events.ReasonUpgradeStarted, "Upgrading from %s to %s", current, req.Image)
+		status = Status{
+			Phase:     PhaseExpanding,
+			FromImage: current,
+			ToImage:   req.Image,
+			Message:   "Running db_sync --expand",
+		}
+		if req.Backup != nil {
+			status.Phase = PhaseBackingUp
+			status.Message = "Backing up the database"
+		}
+		return status, nil
+
+	case PhaseBackingUp:
+		if req.Backup != nil {
+			backup, err := dbbackup.EnsureBackup(ctx, c, scheme, *req.Backup)
+			if err != nil {
+				return status, err
+			}
+			switch backup.Phase {
+			case dbbackup.PhasePending:
+				status.Message = fmt.Sprintf("Backing up the database in Backup %s", backup.Name)
+				return status, nil
+			case dbbackup.PhaseFailed:
+				status.Message = fmt.Sprintf("Backup %s failed: %s; delete it to take a new one", backup.Name, backup.Message)
+				recorder.Event(req.Owner, events.ReasonUpgradeBlocked, "%s", status.Message)
+				return status, nil
+			}
+			if _, err := dbbackup.Prune(ctx, c, req.Owner, req.Backup.Policy); err != nil {
+				return status, err
+			}
+		}
+		status.Phase = PhaseExpanding
+		status.Message = "Running db_sync --expand"
+		return status, nil
+
+	case PhaseExpanding:
+		job, done, err := runStep(ctx, c, scheme, recorder, req, status, StepExpand)
+		if err != nil || !done {
+			return status, err
+		}
+		if failed, message := jobFailed(job); failed {
+			if _, err := setImage(ctx, c, deploy, req.Container, status.FromImage); err != nil {
+				return status, err
+			}
+			if err := deleteJobs(ctx, c, req, status.ToImage); err != nil {
+				return status, err
+			}
+			status.Phase = PhaseRolledBack
+			status.Message = fmt.Sprintf("db_sync --expand failed: %s; the API stays on %s", message, status.FromImage)
+			recorder.Event(req.Owner, events.ReasonUpgradeBlocked, "%s", status.Message)
+			return status, nil
+		}
+		status.Phase = PhaseRollingOut
+		status.Message = fmt.Sprintf("Rolling out %s", status.ToImage)
+		return status, nil
+
+	case PhaseRollingOut:
+		rolledOut, err := setImage(ctx, c, deploy, req.Container, status.ToImage)
+		if err != nil || !rolledOut {
+			return status, err
+		}
+		status.Phase = PhaseMigrating
+		status.Message = "Running db_sync --migrate"
+		return status, nil
+
+	case PhaseMigrating, PhaseContracting:
+		step, next, message := StepMigrate, PhaseContracting, "Running db_sync --contract"
+		if status.Phase == PhaseContracting {
+			step, next, message = StepContract, PhaseCompleted, fmt.Sprintf("Upgraded to %s", status.ToImage)
+		}
+		job, done, err := runStep(ctx, c, scheme, recorder, req, status, step)
+		if err != nil || !done {
+			return status, err
+		}
+		if failed, jobMessage := jobFailed(job); failed {
+			if err := deleteJobs(ctx, c, req, status.ToImage); err != nil {
+				return status, err
+			}
+			status.Phase = PhaseFailed
+			status.Message = fmt.Sprintf("db_sync %s failed: %s; the upgrade is blocked", step.Flag(), jobMessage)
+			recorder.Event(req.Owner, events.ReasonUpgradeBlocked, "%s", status.Message)
+			return status, nil
+		}
+		status.Phase = next
+		status.Message = message
+		if next == PhaseCompleted {
+			recorder.Event(req.Owner, events.ReasonUpgradeCompleted, "Upgraded from %s to %s", status.FromImage, status.ToImage)
+		}
+		return status, nil
+	}
+	return status, fmt.Errorf("unknown upgrade phase %q", status.Phase)
+}
+
+// JobName returns the name of the Job running step of the upgrade of owner
+// to image. Each target image gets its own Jobs.
+func JobName(owner string, step Step, image string) string {
+	sum := sha256.Sum256([]byte(image))
+	suffix := "-db-" + string(step) + "-" + hex.EncodeToString(sum[:4])
+	const maxLen = 63
+	if len(owner)+len(suffix) > maxLen {
+		owner = strings.TrimRight(owner[:maxLen-len(suffix)], "-.")
+	}
+	return owner + suffix
+}
+
+// runStep creates the Job for step unless it exists and reports whether it
+// finished.
+func runStep(ctx context.Context, c client.Client, scheme *runtime.Scheme, recorder *events.Recorder, req Request, status Status, step Step) (*batchv1.Job, bool, error) {
+	job := &batchv1.Job{}
+	key := client.ObjectKey{Namespace: req.Owner.GetNamespace(), Name: JobName(req.Owner.GetName(), step, status.ToImage)}
+	err := c.Get(ctx, key, job)
+	if apierrors.IsNotFound(err) {
+		job = &batchv1.Job{Spec: req.JobSpec(step, status.ToImage)}
+		job.Name = key.Name
+		job.Namespace = key.Namespace
+		if err := controllerutil.SetControllerReference(req.Owner, job, scheme); err != nil {
+			return nil, false, fmt.Errorf("setting owner of Job %s: %w", key.Name, err)
+		}
+		if err := c.Create(ctx, job); err != nil {
+			return nil, false, fmt.Errorf("creating Job %s: %w", key.Name, err)
+		}
+		return job, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("getting Job %s: %w", key.Name, err)
+	}
+
+	if failed, message := jobFailed(job); failed {
+		recorder.EventWithRelated(req.Owner, job, events.ReasonDBSyncFailed, "db_sync %s failed: %s", step.Flag(), message)
+		return job, true, nil
+	}
+	if jobCondition(job, batchv1.JobComplete) != nil {
+		recorder.EventWithRelated(req.Owner, job, events.ReasonDBSyncCompleted, "db_sync %s completed", step.Flag())
+		return job, true, nil
+	}
+	return job, false, nil
+}
+
+// deleteJobs deletes the Jobs of all steps of the upgrade to image, so that
+// a later upgrade to the same image runs every step again instead of finding
+// the failed Jobs. The failure stays recorded in the status message and the
+// DBSyncFailed event.
+func deleteJobs(ctx context.Context, c client.Client, req Request, image string) error {
+	for _, step := range []Step{StepExpand, StepMigrate, StepContract} {
+		job := &batchv1.Job{}
+		job.Namespace = req.Owner.GetNamespace()
+		job.Name = JobName(req.Owner.GetName(), step, image)
+		if err := c.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("deleting Job %s: %w", job.Name, err)
+		}
+	}
+	return nil
+}
+
+func jobCondition(job *batchv1.Job, t batchv1.JobConditionType) *batchv1.JobCondition {
+	for i := range job.Status.Conditions {
+		if job.Status.Conditions[i].Type == t && job.Status.Conditions[i].Status == corev1.ConditionTrue {
+			return &job.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// jobFailed reports whether job failed and why.
+func jobFailed(job *batchv1.Job) (bool, string) {
+	if cond := jobCondition(job, batchv1.JobFailed); cond != nil {
+		return true, cond.Message
+	}
+	return false, ""
+}
+
+func containerImage(deploy *appsv1.Deployment, container string) (string, bool) {
+	for _, c := range deploy.Spec.Template.Spec.Containers {
+		if c.Name == container {
+			return c.Image, true
+		}
+	}
+	return "", false
+}
+
+// setImage sets the image of the container and reports whether the
+// Deployment finished rolling out to it, with no replicas of other images
+// left.
+func setImage(ctx context.Context, c client.Client, deploy *appsv1.Deployment, container, image string) (bool, error) {
+	if current, _ := containerImage(deploy, container); current != image {
+		patch := client.MergeFrom(deploy.DeepCopy())
+		for i := range deploy.Spec.Template.Spec.Containers {
+			if deploy.Spec.Template.Spec.Containers[i].Name == container {
+				deploy.Spec.Template.Spec.Containers[i].Image = image
+			}
+		}
+		if err := c.Patch(ctx, deploy, patch); err != nil {
+			return false, fmt.Errorf("setting image of Deployment %s: %w", deploy.Name, err)
+		}
+		return false, nil
+	}
+	want := int32(1)
+	if deploy.Spec.Replicas != nil {
+		want = *deploy.Spec.Replicas
+	}
+	s := deploy.Status
+	return s.ObservedGeneration >= deploy.Generation &&
+		s.UpdatedReplicas == want && s.AvailableReplicas == want && s.Replicas == want, nil
+}
@@ -0,0 +1,349 @@
+package dbupgrade
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clientevents "k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/c5c3/forge/internal/common/dbbackup"
+	"github.com/c5c3/forge/internal/common/events"
+	"github.com/c5c3/forge/internal/common/featuregate"
+)
+
+const (
+	oldImage = "keystone:2025.1"
+	newImage = "keystone:2025.2"
+)
+
+type fixture struct {
+	c        client.Client
+	scheme   *runtime.Scheme
+	recorder *events.Recorder
+	fake     *clientevents.FakeRecorder
+	req      Request
+}
+
+func newFixture(g *WithT) *fixture {
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "keystone", Namespace: "default", UID: "owner-uid"}}
+	replicas := int32(2)
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "keystone-api", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "keystone-api", Image: oldImage}},
+			}},
+		},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(owner, deploy).
+		WithStatusSubresource(deploy, &batchv1.Job{}).
+		Build()
+	fakeRecorder := clientevents.NewFakeRecorder(20)
+	gates := featuregate.NewDefault()
+	g.Expect(gates.SetFromMap(map[string]bool{string(featuregate.ZeroDowntimeUpgrade): true})).To(Succeed())
+	return &fixture{
+		c:        c,
+		scheme:   scheme,
+		recorder: events.NewRecorder(fakeRecorder, time.Minute),
+		fake:     fakeRecorder,
+		req: Request{
+			Owner:      owner,
+			Deployment: "keystone-api",
+			Container:  "keystone-api",
+			Image:      newImage,
+			JobSpec: func(step Step, image string) batchv1.JobSpec {
+				return batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:    "db-sync",
+						Image:   image,
+						Command: []string{"keystone-manage", "db_sync", step.Flag()},
+					}},
+				}}}
+			},
+			Gates: gates,
+		},
+	}
+}
+
+func (f *fixture) advance(g *WithT, status Status) Status {
+	next, err := Advance(context.Background(), f.c, f.scheme, f.recorder, f.req, status)
+	g.Expect(err).NotTo(HaveOccurred())
+	return next
+}
+
+// finishJob stands in for the Job controller.
+func (f *fixture) finishJob(g *WithT, step Step, condition batchv1.JobConditionType) {
+	ctx := context.Background()
+	job := &batchv1.Job{}
+	g.Expect(f.c.Get(ctx, client.ObjectKey{Namespace: "default", Name: JobName("keystone", step, newImage)}, job)).To(Succeed())
+	job.Status.Conditions = []batchv1.JobCondition{{Type: condition, Status: corev1.ConditionTrue, Message: "exit code 1"}}
+	g.Expect(f.c.Status().Update(ctx, job)).To(Succeed())
+}
+
+// rollOut stands in for the Deployment controller.
+func (f *fixture) rollOut(g *WithT) {
+	ctx := context.Background()
+	deploy := f.deployment(g)
+	deploy.Status = appsv1.DeploymentStatus{
+		ObservedGeneration: deploy.Generation,
+		Replicas:           2,
+		UpdatedReplicas:    2,
+		AvailableReplicas:  2,
+	}
+	g.Expect(f.c.Status().Update(ctx, deploy)).To(Succeed())
+}
+
+func (f *fixture) deployment(g *WithT) *appsv1.Deployment {
+	deploy := &appsv1.Deployment{}
+	g.Expect(f.c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "keystone-api"}, deploy)).To(Succeed())
+	return deploy
+}
+
+func (f *fixture) image(g *WithT) string {
+	return f.deployment(g).Spec.Template.Spec.Containers[0].Image
+}
+
+func (f *fixture) jobCommand(g *WithT, step Step) []string {
+	job := &batchv1.Job{}
+	g.Expect(f.c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: JobName("keystone", step, newImage)}, job)).To(Succeed())
+	g.Expect(job.OwnerReferences).To(ConsistOf(HaveField("UID", BeEquivalentTo("owner-uid"))))
+	return job.Spec.Template.Spec.Containers[0].Command
+}
+
+func TestAdvance(t *testing.T) {
+	g := NewGomegaWithT(t)
+	f := newFixture(g)
+
+	status := f.advance(g, Status{})
+	g.Expect(status).To(Equal(Status{Phase: PhaseExpanding, FromImage: oldImage, ToImage: newImage, Message: "Running db_sync --expand"}))
+	g.Expect(status.InProgress()).To(BeTrue())
+
+	status = f.advance(g, status)
+	g.Expect(status.Phase).To(Equal(PhaseExpanding))
+	g.Expect(f.jobCommand(g, StepExpand)).To(Equal([]string{"keystone-manage", "db_sync", "--expand"}))
+	g.Expect(f.image(g)).To(Equal(oldImage))
+
+	f.finishJob(g, StepExpand, batchv1.JobComplete)
+	status = f.advance(g, status)
+	g.Expect(status.Phase).To(Equal(PhaseRollingOut))
+
+	status = f.advance(g, status)
+	g.Expect(status.Phase).To(Equal(PhaseRollingOut))
+	g.Expect(f.image(g)).To(Equal(newImage))
+
+	f.rollOut(g)
+	status = f.advance(g, status)
+	g.Expect(status.Phase).To(Equal(PhaseMigrating))
+
+	status = f.advance(g, status)
+	g.Expect(f.jobCommand(g, StepMigrate)).To(Equal([]string{"keystone-manage", "db_sync", "--migrate"}))
+	f.finishJob(g, StepMigrate, batchv1.JobComplete)
+	status = f.advance(g, status)
+	g.Expect(status.Phase).To(Equal(PhaseContracting))
+
+	status = f.advance(g, status)
+	g.Expect(f.jobCommand(g, StepContract)).To(Equal([]string{"keystone-manage", "db_sync", "--contract"}))
+	f.finishJob(g, StepContract, batchv1.JobComplete)
+	status = f.advance(g, status)
+	g.Expect(status).To(Equal(Status{Phase: PhaseCompleted, FromImage: oldImage, ToImage: newImage, Message: "Upgraded to " + newImage}))
+	g.Expect(status.InProgress()).To(BeFalse())
+
+	// Nothing to do until the image changes again.
+	g.Expect(f.advance(g, status)).To(Equal(status))
+
+	for _, prefix := range []string{
+		"Normal UpgradeStarted",
+		"Normal DBSyncCompleted",
+		"Normal DBSyncCompleted",
+		"Normal DBSyncCompleted",
+		"Normal UpgradeCompleted",
+	} {
+		g.Expect(f.fake.Events).To(Receive(HavePrefix(prefix)))
+	}
+	g.Expect(f.fake.Events).To(BeEmpty())
+}
+
+func TestAdvanceExpandFailureRollsBack(t *testing.T) {
+	g := NewGomegaWithT(t)
+	f := newFixture(g)
+
+	status := f.advance(g, f.advance(g, Status{}))
+	f.finishJob(g, StepExpand, batchv1.JobFailed)
+	status = f.advance(g, status)
+	g.Expect(status.Phase).To(Equal(PhaseRolledBack))
+	g.Expect(status.Blocked()).To(BeTrue())
+	g.Expect(status.Message).To(ContainSubstring("exit code 1"))
+	g.Expect(f.image(g)).To(Equal(oldImage))
+	g.Expect(f.fake.Events).To(Receive(HavePrefix("Normal UpgradeStarted")))
+	g.Expect(f.fake.Events).To(Receive(HavePrefix("Warning DBSyncFailed")))
+	g.Expect(f.fake.Events).To(Receive(HavePrefix("Warning UpgradeBlocked")))
+
+	// The upgrade stays blocked for the same image.
+	g.Expect(f.advance(g, status)).To(Equal(status))
+
+	// Requesting the old image again clears the block.
+	f.req.Image = oldImage
+	g.Expect(f.advance(g, status)).To(Equal(Status{}))
+}
+
+func TestAdvanceMigrateFailureBlocks(t *testing.T) {
+	g := NewGomegaWithT(t)
+	f := newFixture(g)
+
+	status := f.advance(g, f.advance(g, Status{}))
+	f.finishJob(g, StepExpand, batchv1.JobComplete)
+	status = f.advance(g, f.advance(g, status))
+	f.rollOut(g)
+	status = f.advance(g, f.advance(g, status))
+	f.finishJob(g, StepMigrate, batchv1.JobFailed)
+	status = f.advance(g, status)
+	g.Expect(status.Phase).To(Equal(PhaseFailed))
+	g.Expect(status.Message).To(ContainSubstring("--migrate"))
+	g.Expect(f.image(g)).To(Equal(newImage))
+	g.Expect(f.advance(g, status)).To(Equal(status))
+
+	// A new image starts a new upgrade from the running one.
+	f.req.Image = "keystone:2025.2-1"
+	status = f.advance(g, status)
+	g.Expect(status.Phase).To(Equal(PhaseExpanding))
+	g.Expect(status.FromImage).To(Equal(newImage))
+}
+
+func TestAdvanceRetriesSameImage(t *testing.T) {
+	g := NewGomegaWithT(t)
+	f := newFixture(g)
+
+	status := f.advance(g, f.advance(g, Status{}))
+	f.finishJob(g, StepExpand, batchv1.JobFailed)
+	status = f.advance(g, status)
+	g.Expect(status.Phase).To(Equal(PhaseRolledBack))
+
+	// The failed Jobs are gone, so they cannot fail the next attempt.
+	err := f.c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: JobName("keystone", StepExpand, newImage)}, &batchv1.Job{})
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+	// Clear the block, then request the same image again.
+	f.req.Image = oldImage
+	status = f.advance(g, status)
+	g.Expect(status).To(Equal(Status{}))
+	f.req.Image = newImage
+	status = f.advance(g, status)
+	g.Expect(status.Phase).To(Equal(PhaseExpanding))
+
+	status = f.advance(g, status)
+	g.Expect(status.Phase).To(Equal(PhaseExpanding))
+	g.Expect(f.jobCommand(g, StepExpand)).To(Equal([]string{"keystone-manage", "db_sync", "--expand"}))
+	f.finishJob(g, StepExpand, batchv1.JobComplete)
+	status = f.advance(g, status)
+	g.Expect(status.Phase).To(Equal(PhaseRollingOut))
+}
+
+// setBackupPhase stands in for the mariadb-operator and sets the Complete
+// condition of the Backup.
+func (f *fixture) setBackupPhase(g *WithT, name string, status, reason string) {
+	ctx := context.Background()
+	backup := &unstructured.Unstructured{}
+	backup.SetGroupVersionKind(dbbackup.BackupGVK)
+	g.Expect(f.c.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, backup)).To(Succeed())
+	cond := map[string]interface{}{"type": "Complete", "status": status, "reason": reason, "message": reason}
+	g.Expect(unstructured.SetNestedSlice(backup.Object, []interface{}{cond}, "status", "conditions")).To(Succeed())
+	g.Expect(f.c.Update(ctx, backup)).To(Succeed())
+}
+
+func TestAdvanceBacksUpBeforeExpand(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	f := newFixture(g)
+	f.req.Backup = &dbbackup.BackupRequest{
+		Owner:    f.req.Owner,
+		Revision: "2025.2",
+		MariaDB:  "mariadb",
+		Database: "keystone",
+		Policy:   dbbackup.Policy{Enabled: true},
+	}
+	name := dbbackup.BackupName("ConfigMap", "keystone", "2025.2")
+
+	status := f.advance(g, Status{})
+	g.Expect(status.Phase).To(Equal(PhaseBackingUp))
+	g.Expect(status.Image(newImage)).To(Equal(oldImage))
+	status = f.advance(g, status)
+	g.Expect(status.Phase).To(Equal(PhaseBackingUp))
+	g.Expect(status.Message).To(ContainSubstring(name))
+	err := f.c.Get(ctx, client.ObjectKey{Namespace: "default", Name: JobName("keystone", StepExpand, newImage)}, &batchv1.Job{})
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue(), "expand waits for the backup")
+
+	// A failed backup blocks the upgrade until it is deleted.
+	f.setBackupPhase(g, name, "False", "JobFailed")
+	status = f.advance(g, status)
+	g.Expect(status.Phase).To(Equal(PhaseBackingUp))
+	g.Expect(status.Message).To(ContainSubstring("delete it"))
+	backup := &unstructured.Unstructured{}
+	backup.SetGroupVersionKind(dbbackup.BackupGVK)
+	backup.SetNamespace("default")
+	backup.SetName(name)
+	g.Expect(f.c.Delete(ctx, backup)).To(Succeed())
+	status = f.advance(g, status)
+	g.Expect(status.Phase).To(Equal(PhaseBackingUp))
+
+	f.setBackupPhase(g, name, "True", "JobComplete")
+	status = f.advance(g, status)
+	g.Expect(status.Phase).To(Equal(PhaseExpanding))
+	status = f.advance(g, status)
+	g.Expect(f.jobCommand(g, StepExpand)).To(Equal([]string{"keystone-manage", "db_sync", "--expand"}))
+	g.Expect(f.image(g)).To(Equal(oldImage))
+}
+
+func TestAdvanceRequiresFeatureGate(t *testing.T) {
+	g := NewGomegaWithT(t)
+	f := newFixture(g)
+	enabled := f.req.Gates
+	f.req.Gates = featuregate.NewDefault()
+
+	// No upgrade starts while the gate is disabled.
+	g.Expect(f.advance(g, Status{})).To(Equal(Status{}))
+	g.Expect(f.image(g)).To(Equal(oldImage))
+
+	// An upgrade started while the gate was enabled is finished.
+	f.req.Gates = enabled
+	status := f.advance(g, Status{})
+	f.req.Gates = featuregate.NewDefault()
+	status = f.advance(g, status)
+	g.Expect(status.Phase).To(Equal(PhaseExpanding))
+	g.Expect(f.jobCommand(g, StepExpand)).To(Equal([]string{"keystone-manage", "db_sync", "--expand"}))
+}
+
+func TestAdvanceWithoutDeployment(t *testing.T) {
+	g := NewGomegaWithT(t)
+	f := newFixture(g)
+	g.Expect(f.c.Delete(context.Background(), f.deployment(g))).To(Succeed())
+
+	g.Expect(f.advance(g, Status{})).To(Equal(Status{}))
+}
+
+func TestJobName(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	name := JobName("keystone", StepExpand, newImage)
+	g.Expect(name).To(HavePrefix("keystone-db-expand-"))
+	g.Expect(name).NotTo(Equal(JobName("keystone", StepExpand, oldImage)))
+	g.Expect(len(JobName(strings.Repeat("k", 80), StepContract, newImage))).To(BeNumerically("<=", 63))
+}
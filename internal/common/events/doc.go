@@ -0,0 +1,10 @@
+// Package events provides a thin wrapper around the Kubernetes events API that
+// restricts operators to a fixed catalog of event reasons and suppresses
+// duplicate events for the same object.
+//
+// Every reason in the catalog carries its event type (Normal or Warning) and
+// action, so controllers only choose a Reason and a message. Identical events
+// for the same object are emitted at most once per deduplication window, which
+// keeps `kubectl describe` readable when a reconcile loop repeatedly observes
+// the same state.
+package events
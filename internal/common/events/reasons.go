@@ -0,0 +1,118 @@
+package events
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Reason is a machine-readable event reason from the fixed catalog below.
+// Controllers must not emit reasons outside this catalog so that alerting and
+// dashboards can rely on a stable set of values.
+type Reason string
+
+// Event reasons emitted by the operators.
+const (
+	// ReasonDatabaseProvisioned is emitted once the service database, user
+	// and grant are ready.
+	ReasonDatabaseProvisioned Reason = "DatabaseProvisioned"
+	// ReasonDatabaseProvisioningFailed is emitted when the database, user or
+	// grant could not be created.
+	ReasonDatabaseProvisioningFailed Reason = "DatabaseProvisioningFailed"
+	// ReasonDBSyncCompleted is emitted when a db_sync Job finished successfully.
+	ReasonDBSyncCompleted Reason = "DBSyncCompleted"
+	// ReasonDBSyncFailed is emitted when a db_sync Job failed.
+	ReasonDBSyncFailed Reason = "DBSyncFailed"
+	// ReasonFernetKeysRotated is emitted after the fernet key repository was
+	// rotated.
+	ReasonFernetKeysRotated Reason = "FernetKeysRotated"
+	// ReasonFernetKeyRotationFailed is emitted when a fernet key rotation
+	// could not be completed.
+	ReasonFernetKeyRotationFailed Reason = "FernetKeyRotationFailed"
+	// ReasonDependencyNotReady is emitted while a required dependency
+	// (MariaDB, Memcached, Secret, ...) is not ready yet.
+	ReasonDependencyNotReady Reason = "DependencyNotReady"
+	// ReasonDeploymentUpdated is emitted when a managed Deployment was created
+	// or its pod template changed.
+	ReasonDeploymentUpdated Reason = "DeploymentUpdated"
+	// ReasonDriftCorrected is emitted when a managed child resource was
+	// changed outside the operator and the change was reverted.
+	ReasonDriftCorrected Reason = "DriftCorrected"
+	// ReasonUpgradeStarted is emitted when an OpenStack release upgrade begins.
+	ReasonUpgradeStarted Reason = "UpgradeStarted"
+	// ReasonUpgradeCompleted is emitted when an OpenStack release upgrade
+	// finished successfully.
+	ReasonUpgradeCompleted Reason = "UpgradeCompleted"
+	// ReasonUpgradeBlocked is emitted when an upgrade cannot proceed, for
+	// example because a precondition is not met or a previous phase failed.
+	ReasonUpgradeBlocked Reason = "UpgradeBlocked"
+	// ReasonPaused is emitted when reconciliation of an object is suspended
+	// via the pause annotation.
+	ReasonPaused Reason = "Paused"
+	// ReasonResumed is emitted when reconciliation of a previously paused
+	// object resumes.
+	ReasonResumed Reason = "Resumed"
+	// ReasonReconcileFailed is emitted when a reconcile returned an error that
+	// is not covered by a more specific reason.
+	ReasonReconcileFailed Reason = "ReconcileFailed"
+)
+
+// reasonInfo describes how a catalog reason is reported.
+type reasonInfo struct {
+	eventType string
+	action    string
+}
+
+// catalog maps every known Reason to its event type and action. The action is
+// the "what was attempted" field of events.k8s.io/v1 Events.
+var catalog = map[Reason]reasonInfo{
+	ReasonDatabaseProvisioned:        {corev1.EventTypeNormal, "ProvisionDatabase"},
+	ReasonDatabaseProvisioningFailed: {corev1.EventTypeWarning, "ProvisionDatabase"},
+	ReasonDBSyncCompleted:            {corev1.EventTypeNormal, "SyncDatabase"},
+	ReasonDBSyncFailed:               {corev1.EventTypeWarning, "SyncDatabase"},
+	ReasonFernetKeysRotated:          {corev1.EventTypeNormal, "RotateFernetKeys"},
+	ReasonFernetKeyRotationFailed:    {corev1.EventTypeWarning, "RotateFernetKeys"},
+	ReasonDependencyNotReady:         {corev1.EventTypeWarning, "WaitForDependency"},
+	ReasonDeploymentUpdated:          {corev1.EventTypeNormal, "UpdateDeployment"},
+	ReasonDriftCorrected:             {corev1.EventTypeWarning, "CorrectDrift"},
+	ReasonUpgradeStarted:             {corev1.EventTypeNormal, "Upgrade"},
+	ReasonUpgradeCompleted:           {corev1.EventTypeNormal, "Upgrade"},
+	ReasonUpgradeBlocked:             {corev1.EventTypeWarning, "Upgrade"},
+	ReasonPaused:                     {corev1.EventTypeNormal, "Pause"},
+	ReasonResumed:                    {corev1.EventTypeNormal, "Resume"},
+	ReasonReconcileFailed:            {corev1.EventTypeWarning, "Reconcile"},
+}
+
+// Known reports whether r is part of the event reason catalog.
+func (r Reason) Known() bool {
+	_, ok := catalog[r]
+	return ok
+}
+
+// Type returns the Kubernetes event type (Normal or Warning) for r. Unknown
+// reasons are reported as Warning.
+func (r Reason) Type() string {
+	if info, ok := catalog[r]; ok {
+		return info.eventType
+	}
+	return corev1.EventTypeWarning
+}
+
+// Action returns the events.k8s.io/v1 action for r. Unknown reasons return
+// "Unknown".
+func (r Reason) Action() string {
+	if info, ok := catalog[r]; ok {
+		return info.action
+	}
+	return "Unknown"
+}
+
+// Reasons returns all catalog reasons in lexical order.
+func Reasons() []Reason {
+	reasons := make([]Reason, 0, len(catalog))
+	for r := range catalog {
+		reasons = append(reasons, r)
+	}
+	sort.Slice(reasons, func(i, j int) bool { return reasons[i] < reasons[j] })
+	return reasons
+}
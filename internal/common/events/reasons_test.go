@@ -0,0 +1,58 @@
+package events
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestReasons_AllKnownWithValidType(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	reasons := Reasons()
+	g.Expect(reasons).To(HaveLen(len(catalog)))
+
+	for _, r := range reasons {
+		g.Expect(r.Known()).To(BeTrue(), "reason %q", r)
+		g.Expect(r.Type()).To(BeElementOf(corev1.EventTypeNormal, corev1.EventTypeWarning), "reason %q", r)
+		g.Expect(r.Action()).NotTo(BeEmpty(), "reason %q", r)
+	}
+}
+
+func TestReasons_Sorted(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	reasons := Reasons()
+	for i := 1; i < len(reasons); i++ {
+		g.Expect(reasons[i-1] < reasons[i]).To(BeTrue(), "%q should sort before %q", reasons[i-1], reasons[i])
+	}
+}
+
+func TestReason_Type(t *testing.T) {
+	tests := []struct {
+		reason   Reason
+		expected string
+	}{
+		{ReasonDatabaseProvisioned, corev1.EventTypeNormal},
+		{ReasonFernetKeysRotated, corev1.EventTypeNormal},
+		{ReasonDBSyncFailed, corev1.EventTypeWarning},
+		{ReasonUpgradeBlocked, corev1.EventTypeWarning},
+		{Reason("NotInCatalog"), corev1.EventTypeWarning},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.reason), func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(tc.reason.Type()).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestReason_Unknown(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := Reason("NotInCatalog")
+	g.Expect(r.Known()).To(BeFalse())
+	g.Expect(r.Action()).To(Equal("Unknown"))
+}
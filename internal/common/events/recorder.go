@@ -0,0 +1,114 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultDedupWindow is the period during which an identical event for the
+// same object is suppressed.
+const DefaultDedupWindow = 5 * time.Minute
+
+// dedupKey identifies an emitted event for deduplication purposes.
+type dedupKey struct {
+	uid     types.UID
+	object  string
+	reason  Reason
+	message string
+}
+
+// Recorder emits catalog events for Kubernetes objects and suppresses
+// duplicates of the same (object, reason, message) tuple within the
+// deduplication window. It is safe for concurrent use.
+type Recorder struct {
+	recorder events.EventRecorder
+	window   time.Duration
+	now      func() time.Time
+
+	mu   sync.Mutex
+	seen map[dedupKey]time.Time
+}
+
+// NewRecorder wraps the given events.EventRecorder (typically obtained from
+// mgr.GetEventRecorder) with catalog and deduplication handling. A
+// non-positive window selects DefaultDedupWindow.
+func NewRecorder(recorder events.EventRecorder, window time.Duration) *Recorder {
+	if window <= 0 {
+		window = DefaultDedupWindow
+	}
+	return &Recorder{
+		recorder: recorder,
+		window:   window,
+		now:      time.Now,
+		seen:     make(map[dedupKey]time.Time),
+	}
+}
+
+// Event emits an event with the given reason for obj. The message is built
+// from messageFmt and args. The event type and action are taken from the
+// reason catalog. It reports whether the event was emitted (false means it
+// was suppressed as a duplicate).
+func (r *Recorder) Event(obj client.Object, reason Reason, messageFmt string, args ...interface{}) bool {
+	return r.emit(obj, nil, reason, messageFmt, args...)
+}
+
+// EventWithRelated behaves like Event but additionally records a related
+// object, for example the Job whose failure caused a DBSyncFailed event.
+func (r *Recorder) EventWithRelated(obj client.Object, related runtime.Object, reason Reason, messageFmt string, args ...interface{}) bool {
+	return r.emit(obj, related, reason, messageFmt, args...)
+}
+
+// Forget drops all deduplication state for obj so that the next event is
+// always emitted. Controllers call it when the object is deleted.
+func (r *Recorder) Forget(obj client.Object) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	uid, name := obj.GetUID(), objectName(obj)
+	for k := range r.seen {
+		if k.uid == uid && k.object == name {
+			delete(r.seen, k)
+		}
+	}
+}
+
+func (r *Recorder) emit(obj client.Object, related runtime.Object, reason Reason, messageFmt string, args ...interface{}) bool {
+	message := fmt.Sprintf(messageFmt, args...)
+	key := dedupKey{uid: obj.GetUID(), object: objectName(obj), reason: reason, message: message}
+
+	r.mu.Lock()
+	now := r.now()
+	if last, ok := r.seen[key]; ok && now.Sub(last) < r.window {
+		r.mu.Unlock()
+		return false
+	}
+	r.seen[key] = now
+	r.pruneLocked(now)
+	r.mu.Unlock()
+
+	// The message is passed as an argument rather than a format string so that
+	// a literal '%' in it is not interpreted a second time.
+	r.recorder.Eventf(obj, related, reason.Type(), string(reason), reason.Action(), "%s", message)
+	return true
+}
+
+// pruneLocked removes entries older than the deduplication window so the
+// cache does not grow without bound. r.mu must be held.
+func (r *Recorder) pruneLocked(now time.Time) {
+	for k, t := range r.seen {
+		if now.Sub(t) >= r.window {
+			delete(r.seen, k)
+		}
+	}
+}
+
+// objectName returns a namespace/name string used together with the UID to
+// identify obj; objects that have not been persisted yet have no UID.
+func objectName(obj client.Object) string {
+	return types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}.String()
+}
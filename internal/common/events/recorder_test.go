@@ -0,0 +1,94 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+)
+
+// newTestRecorder returns a Recorder backed by a buffered FakeRecorder and a
+// controllable clock.
+func newTestRecorder(window time.Duration) (*Recorder, *events.FakeRecorder, *time.Time) {
+	fake := events.NewFakeRecorder(10)
+	r := NewRecorder(fake, window)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	return r, fake, &now
+}
+
+func testObject(name, uid string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			UID:       k8stypes.UID(uid),
+		},
+	}
+}
+
+func TestRecorder_EmitsCatalogTypeAndReason(t *testing.T) {
+	g := NewGomegaWithT(t)
+	r, fake, _ := newTestRecorder(time.Minute)
+
+	emitted := r.Event(testObject("keystone", "uid-1"), ReasonDBSyncFailed, "job %s failed", "keystone-db-sync")
+
+	g.Expect(emitted).To(BeTrue())
+	g.Expect(fake.Events).To(Receive(Equal("Warning DBSyncFailed job keystone-db-sync failed")))
+}
+
+func TestRecorder_SuppressesDuplicatesWithinWindow(t *testing.T) {
+	g := NewGomegaWithT(t)
+	r, fake, now := newTestRecorder(time.Minute)
+	obj := testObject("keystone", "uid-1")
+
+	g.Expect(r.Event(obj, ReasonDatabaseProvisioned, "database ready")).To(BeTrue())
+	g.Expect(r.Event(obj, ReasonDatabaseProvisioned, "database ready")).To(BeFalse())
+	g.Expect(fake.Events).To(HaveLen(1))
+
+	*now = now.Add(time.Minute)
+	g.Expect(r.Event(obj, ReasonDatabaseProvisioned, "database ready")).To(BeTrue())
+	g.Expect(fake.Events).To(HaveLen(2))
+}
+
+func TestRecorder_DistinguishesObjectsReasonsAndMessages(t *testing.T) {
+	g := NewGomegaWithT(t)
+	r, fake, _ := newTestRecorder(time.Minute)
+
+	g.Expect(r.Event(testObject("a", "uid-a"), ReasonUpgradeBlocked, "x")).To(BeTrue())
+	g.Expect(r.Event(testObject("b", "uid-b"), ReasonUpgradeBlocked, "x")).To(BeTrue())
+	g.Expect(r.Event(testObject("a", "uid-a"), ReasonUpgradeStarted, "x")).To(BeTrue())
+	g.Expect(r.Event(testObject("a", "uid-a"), ReasonUpgradeBlocked, "y")).To(BeTrue())
+	g.Expect(fake.Events).To(HaveLen(4))
+}
+
+func TestRecorder_Forget(t *testing.T) {
+	g := NewGomegaWithT(t)
+	r, fake, _ := newTestRecorder(time.Minute)
+	obj := testObject("keystone", "uid-1")
+
+	g.Expect(r.Event(obj, ReasonFernetKeysRotated, "rotated")).To(BeTrue())
+	r.Forget(obj)
+	g.Expect(r.Event(obj, ReasonFernetKeysRotated, "rotated")).To(BeTrue())
+	g.Expect(fake.Events).To(HaveLen(2))
+}
+
+func TestRecorder_MessageNotReformatted(t *testing.T) {
+	g := NewGomegaWithT(t)
+	r, fake, _ := newTestRecorder(time.Minute)
+
+	r.Event(testObject("keystone", "uid-1"), ReasonReconcileFailed, "%s", "100% broken")
+
+	g.Expect(fake.Events).To(Receive(Equal("Warning ReconcileFailed 100% broken")))
+}
+
+func TestNewRecorder_DefaultWindow(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := NewRecorder(events.NewFakeRecorder(1), 0)
+	g.Expect(r.window).To(Equal(DefaultDedupWindow))
+}
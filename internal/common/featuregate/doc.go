@@ -0,0 +1,10 @@
+// Package featuregate provides a registry of named feature gates shared by all
+// Forge operators.
+//
+// Every gate has a maturity stage (Alpha, Beta, GA) and a default. Alpha gates
+// are off by default, Beta gates are usually on, and GA gates are locked to
+// their default so they can no longer be disabled. Operators build a
+// FeatureGate from the Features catalog, apply the values from the operator
+// configuration (file, FORGE_FEATURE_GATES and --feature-gates) and consult
+// Enabled before running risky code paths.
+package featuregate
@@ -0,0 +1,152 @@
+package featuregate
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Feature is the name of a feature gate.
+type Feature string
+
+// Stage is the maturity of a feature gate.
+type Stage string
+
+const (
+	// Alpha features are experimental and disabled by default.
+	Alpha Stage = "Alpha"
+	// Beta features are well tested and usually enabled by default.
+	Beta Stage = "Beta"
+	// GA features are stable and locked to their default.
+	GA Stage = "GA"
+)
+
+// Spec describes a feature gate.
+type Spec struct {
+	// Default is the state of the gate when it is not set explicitly.
+	Default bool
+	// Stage is the maturity of the feature.
+	Stage Stage
+}
+
+// Status reports the state of a single feature gate. It is meant to be
+// embedded in CR status (e.g. ControlPlane) and therefore carries JSON tags.
+type Status struct {
+	// Name of the feature gate.
+	Name string `json:"name"`
+	// Stage of the feature gate.
+	Stage Stage `json:"stage"`
+	// Enabled reports whether the feature is enabled.
+	Enabled bool `json:"enabled"`
+}
+
+// FeatureGate is a registry of feature gates and their current state. It is
+// safe for concurrent use.
+type FeatureGate struct {
+	mu      sync.RWMutex
+	known   map[Feature]Spec
+	enabled map[Feature]bool
+}
+
+// New returns an empty FeatureGate.
+func New() *FeatureGate {
+	return &FeatureGate{
+		known:   make(map[Feature]Spec),
+		enabled: make(map[Feature]bool),
+	}
+}
+
+// NewDefault returns a FeatureGate populated with the Features catalog.
+func NewDefault() *FeatureGate {
+	fg := New()
+	// The catalog is static and validated by tests, so Add cannot fail here.
+	if err := fg.Add(Features); err != nil {
+		panic(err)
+	}
+	return fg
+}
+
+// Add registers the given features. Re-registering a feature with a
+// different spec is an error.
+func (fg *FeatureGate) Add(features map[Feature]Spec) error {
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+	for name, spec := range features {
+		switch spec.Stage {
+		case Alpha, Beta, GA:
+		default:
+			return fmt.Errorf("featuregate: feature %q has unknown stage %q", name, spec.Stage)
+		}
+		if existing, ok := fg.known[name]; ok && existing != spec {
+			return fmt.Errorf("featuregate: feature %q already registered with a different spec", name)
+		}
+		fg.known[name] = spec
+	}
+	return nil
+}
+
+// SetFromMap sets the state of the named features. Unknown features and
+// attempts to change a GA feature away from its default are rejected; in that
+// case no feature is changed.
+func (fg *FeatureGate) SetFromMap(values map[string]bool) error {
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+	for name, enabled := range values {
+		spec, ok := fg.known[Feature(name)]
+		if !ok {
+			return fmt.Errorf("featuregate: unknown feature %q", name)
+		}
+		if spec.Stage == GA && enabled != spec.Default {
+			return fmt.Errorf("featuregate: feature %q is GA and locked to %t", name, spec.Default)
+		}
+	}
+	for name, enabled := range values {
+		fg.enabled[Feature(name)] = enabled
+	}
+	return nil
+}
+
+// Enabled reports whether the feature is enabled. Unknown features are
+// reported as disabled.
+func (fg *FeatureGate) Enabled(f Feature) bool {
+	fg.mu.RLock()
+	defer fg.mu.RUnlock()
+	return fg.enabledLocked(f)
+}
+
+// enabledLocked implements Enabled; fg.mu must be held.
+func (fg *FeatureGate) enabledLocked(f Feature) bool {
+	if v, ok := fg.enabled[f]; ok {
+		return v
+	}
+	return fg.known[f].Default
+}
+
+// Known returns all registered features in lexical order.
+func (fg *FeatureGate) Known() []Feature {
+	fg.mu.RLock()
+	defer fg.mu.RUnlock()
+	return fg.knownLocked()
+}
+
+// knownLocked implements Known; fg.mu must be held.
+func (fg *FeatureGate) knownLocked() []Feature {
+	out := make([]Feature, 0, len(fg.known))
+	for f := range fg.known {
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// Status returns the state of every registered feature in lexical order.
+func (fg *FeatureGate) Status() []Status {
+	fg.mu.RLock()
+	defer fg.mu.RUnlock()
+	known := fg.knownLocked()
+	out := make([]Status, 0, len(known))
+	for _, f := range known {
+		out = append(out, Status{Name: string(f), Stage: fg.known[f].Stage, Enabled: fg.enabledLocked(f)})
+	}
+	return out
+}
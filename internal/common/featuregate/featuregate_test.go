@@ -0,0 +1,133 @@
+package featuregate
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFeatures_CatalogIsValid(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(New().Add(Features)).To(Succeed())
+	for name, spec := range Features {
+		if spec.Stage == Alpha {
+			g.Expect(spec.Default).To(BeFalse(), "alpha feature %q must be disabled by default", name)
+		}
+	}
+}
+
+func TestNewDefault_UsesCatalogDefaults(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fg := NewDefault()
+	for name, spec := range Features {
+		g.Expect(fg.Enabled(name)).To(Equal(spec.Default), "feature %q", name)
+	}
+}
+
+func TestFeatureGate_Add(t *testing.T) {
+	tests := []struct {
+		name          string
+		features      map[Feature]Spec
+		failureSubstr string
+	}{
+		{name: "valid", features: map[Feature]Spec{"A": {Stage: Alpha}}},
+		{name: "same spec again", features: map[Feature]Spec{"Base": {Default: true, Stage: Beta}}},
+		{name: "unknown stage", features: map[Feature]Spec{"A": {Stage: "Preview"}}, failureSubstr: "unknown stage"},
+		{name: "conflicting spec", features: map[Feature]Spec{"Base": {Stage: Alpha}}, failureSubstr: "different spec"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			fg := New()
+			g.Expect(fg.Add(map[Feature]Spec{"Base": {Default: true, Stage: Beta}})).To(Succeed())
+
+			err := fg.Add(tc.features)
+			if tc.failureSubstr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tc.failureSubstr)))
+		})
+	}
+}
+
+func TestFeatureGate_SetFromMap(t *testing.T) {
+	tests := []struct {
+		name          string
+		values        map[string]bool
+		expected      map[Feature]bool
+		failureSubstr string
+	}{
+		{
+			name:     "enable alpha and disable beta",
+			values:   map[string]bool{"AlphaF": true, "BetaF": false},
+			expected: map[Feature]bool{"AlphaF": true, "BetaF": false, "GAF": true},
+		},
+		{
+			name:     "GA set to its default",
+			values:   map[string]bool{"GAF": true},
+			expected: map[Feature]bool{"AlphaF": false, "BetaF": true, "GAF": true},
+		},
+		{
+			name:          "unknown feature",
+			values:        map[string]bool{"Nope": true},
+			failureSubstr: "unknown feature",
+		},
+		{
+			name:          "GA locked",
+			values:        map[string]bool{"AlphaF": true, "GAF": false},
+			failureSubstr: "locked",
+			// No feature is changed when one value is rejected.
+			expected: map[Feature]bool{"AlphaF": false, "BetaF": true, "GAF": true},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			fg := New()
+			g.Expect(fg.Add(map[Feature]Spec{
+				"AlphaF": {Default: false, Stage: Alpha},
+				"BetaF":  {Default: true, Stage: Beta},
+				"GAF":    {Default: true, Stage: GA},
+			})).To(Succeed())
+
+			err := fg.SetFromMap(tc.values)
+			if tc.failureSubstr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.failureSubstr)))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			for f, enabled := range tc.expected {
+				g.Expect(fg.Enabled(f)).To(Equal(enabled), "feature %q", f)
+			}
+		})
+	}
+}
+
+func TestFeatureGate_UnknownIsDisabled(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(NewDefault().Enabled("DoesNotExist")).To(BeFalse())
+}
+
+func TestFeatureGate_Status(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fg := New()
+	g.Expect(fg.Add(map[Feature]Spec{
+		"B": {Default: true, Stage: Beta},
+		"A": {Default: false, Stage: Alpha},
+	})).To(Succeed())
+	g.Expect(fg.SetFromMap(map[string]bool{"A": true})).To(Succeed())
+
+	g.Expect(fg.Status()).To(Equal([]Status{
+		{Name: "A", Stage: Alpha, Enabled: true},
+		{Name: "B", Stage: Beta, Enabled: true},
+	}))
+}
@@ -0,0 +1,21 @@
+package featuregate
+
+// Feature gates known to the Forge operators. Add new gates here together with
+// their entry in Features.
+const (
+	// FernetAutoRotation rotates the Keystone fernet key repository
+	// automatically according to the configured schedule.
+	FernetAutoRotation Feature = "FernetAutoRotation"
+	// ZeroDowntimeUpgrade upgrades Keystone with expand/migrate/contract
+	// database migrations while old and new API replicas coexist.
+	ZeroDowntimeUpgrade Feature = "ZeroDowntimeUpgrade"
+	// Federation enables Keystone identity federation configuration.
+	Federation Feature = "Federation"
+)
+
+// Features is the catalog of all feature gates and their default state.
+var Features = map[Feature]Spec{
+	FernetAutoRotation:  {Default: true, Stage: Beta},
+	ZeroDowntimeUpgrade: {Default: false, Stage: Alpha},
+	Federation:          {Default: false, Stage: Alpha},
+}
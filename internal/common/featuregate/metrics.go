@@ -0,0 +1,36 @@
+package featuregate
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// enabledGauge exposes the state of every feature gate as
+// forge_feature_gate_enabled{name,stage} (1 enabled, 0 disabled).
+var enabledGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "forge_feature_gate_enabled",
+		Help: "Whether a feature gate is enabled (1) or disabled (0).",
+	},
+	[]string{"name", "stage"},
+)
+
+// registerGauge registers enabledGauge on the first RecordMetrics call rather
+// than in init, so that packages importing featuregate only for its types,
+// such as the API packages through dbupgrade, do not add collectors.
+var registerGauge sync.Once
+
+// RecordMetrics publishes the current state of all registered features on the
+// controller-runtime metrics endpoint. Call it after the gates are set.
+func (fg *FeatureGate) RecordMetrics() {
+	registerGauge.Do(func() { metrics.Registry.MustRegister(enabledGauge) })
+	for _, s := range fg.Status() {
+		v := 0.0
+		if s.Enabled {
+			v = 1
+		}
+		enabledGauge.WithLabelValues(s.Name, string(s.Stage)).Set(v)
+	}
+}
@@ -0,0 +1,35 @@
+package featuregate
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+func gaugeValue(g *WithT, name string, stage Stage) float64 {
+	m := &dto.Metric{}
+	g.Expect(enabledGauge.WithLabelValues(name, string(stage)).Write(m)).To(Succeed())
+	return m.GetGauge().GetValue()
+}
+
+func TestFeatureGate_RecordMetrics(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fg := New()
+	g.Expect(fg.Add(map[Feature]Spec{
+		"MetricsOn":  {Default: true, Stage: Beta},
+		"MetricsOff": {Default: false, Stage: Alpha},
+	})).To(Succeed())
+
+	fg.RecordMetrics()
+	g.Expect(metrics.Registry.Register(enabledGauge)).To(BeAssignableToTypeOf(prometheus.AlreadyRegisteredError{}))
+	g.Expect(gaugeValue(g, "MetricsOn", Beta)).To(Equal(1.0))
+	g.Expect(gaugeValue(g, "MetricsOff", Alpha)).To(Equal(0.0))
+
+	g.Expect(fg.SetFromMap(map[string]bool{"MetricsOff": true})).To(Succeed())
+	fg.RecordMetrics()
+	g.Expect(gaugeValue(g, "MetricsOff", Alpha)).To(Equal(1.0))
+}
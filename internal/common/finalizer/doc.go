@@ -0,0 +1,26 @@
+// Package finalizer cleans up state that Kubernetes garbage collection does
+// not cover before a CR is deleted.
+//
+// A controller calls Reconcile at the start of every reconcile with the
+// cleanup steps of its CR, such as removing catalog entries from other
+// Keystones. Reconcile adds the finalizer to live objects; once the object
+// is being deleted it runs the steps in order and removes the finalizer
+// after all of them succeeded:
+//
+//	deleted, err := finalizer.Reconcile(ctx, c, keystone,
+//		finalizer.Step{Name: "catalog", Run: removeCatalogEntries},
+//		finalizer.Step{Name: "database", Run: func(ctx context.Context) error {
+//			return finalizer.ApplyDeletionPolicy(ctx, c, keystone, keystone.Spec.DeletionPolicy, database, user, grant)
+//		}},
+//	)
+//	if err != nil || deleted {
+//		return ctrl.Result{}, err
+//	}
+//
+// The DeletionPolicy of a CR decides whether its database resources and
+// backups are deleted with it or orphaned; backups are removed with
+// dbbackup.DeleteAll. Copies of Secrets pushed to an
+// external store with a PushSecret are removed by the external-secrets
+// operator when the PushSecret, rendered with deletionPolicy Delete, is
+// garbage collected.
+package finalizer
@@ -0,0 +1,55 @@
+package finalizer
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Name is the finalizer the operators set on their CRs.
+const Name = "forge.c5c3.io/cleanup"
+
+// Step is one cleanup action run before the object is deleted. Run must be
+// idempotent, since a failing step makes all steps run again on the next
+// reconcile.
+type Step struct {
+	// Name identifies the step in errors.
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Reconcile ensures obj carries the finalizer while it exists and runs steps
+// once it is being deleted. It reports whether obj is being deleted, in
+// which case the caller must stop reconciling it. The finalizer is removed
+// only after every step succeeded; the first failing step is returned as an
+// error and retried on the next reconcile.
+func Reconcile(ctx context.Context, c client.Client, obj client.Object, steps ...Step) (bool, error) {
+	if obj.GetDeletionTimestamp().IsZero() {
+		if controllerutil.ContainsFinalizer(obj, Name) {
+			return false, nil
+		}
+		patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+		controllerutil.AddFinalizer(obj, Name)
+		if err := c.Patch(ctx, obj, patch); err != nil {
+			return false, fmt.Errorf("adding finalizer to %s: %w", obj.GetName(), err)
+		}
+		return false, nil
+	}
+
+	if !controllerutil.ContainsFinalizer(obj, Name) {
+		return true, nil
+	}
+	for _, step := range steps {
+		if err := step.Run(ctx); err != nil {
+			return true, fmt.Errorf("cleaning up %s of %s: %w", step.Name, obj.GetName(), err)
+		}
+	}
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	controllerutil.RemoveFinalizer(obj, Name)
+	if err := c.Patch(ctx, obj, patch); client.IgnoreNotFound(err) != nil {
+		return true, fmt.Errorf("removing finalizer from %s: %w", obj.GetName(), err)
+	}
+	return true, nil
+}
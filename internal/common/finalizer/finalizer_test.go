@@ -0,0 +1,75 @@
+package finalizer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newCR() *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "keystone", Namespace: "default", UID: "owner-uid"}}
+}
+
+func get(c client.Client, obj client.Object) error {
+	return c.Get(context.Background(), client.ObjectKeyFromObject(obj), obj)
+}
+
+func TestReconcile(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	cr := newCR()
+	c := fake.NewClientBuilder().WithObjects(cr).Build()
+
+	var ran []string
+	failing := errors.New("keystone unreachable")
+	steps := []Step{
+		{Name: "catalog", Run: func(context.Context) error { ran = append(ran, "catalog"); return failing }},
+		{Name: "database", Run: func(context.Context) error { ran = append(ran, "database"); return nil }},
+	}
+
+	deleted, err := Reconcile(ctx, c, cr, steps...)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(deleted).To(BeFalse())
+	g.Expect(get(c, cr)).To(Succeed())
+	g.Expect(cr.Finalizers).To(ConsistOf(Name))
+	g.Expect(ran).To(BeEmpty())
+
+	g.Expect(c.Delete(ctx, cr)).To(Succeed())
+	g.Expect(get(c, cr)).To(Succeed())
+
+	deleted, err = Reconcile(ctx, c, cr, steps...)
+	g.Expect(deleted).To(BeTrue())
+	g.Expect(err).To(MatchError(failing))
+	g.Expect(err).To(MatchError(ContainSubstring("cleaning up catalog of keystone")))
+	g.Expect(ran).To(Equal([]string{"catalog"}))
+	g.Expect(get(c, cr)).To(Succeed())
+
+	steps[0].Run = func(context.Context) error { ran = append(ran, "catalog"); return nil }
+	deleted, err = Reconcile(ctx, c, cr, steps...)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(deleted).To(BeTrue())
+	g.Expect(ran).To(Equal([]string{"catalog", "catalog", "database"}))
+	g.Expect(apierrors.IsNotFound(get(c, cr))).To(BeTrue())
+}
+
+func TestReconcileWithoutFinalizer(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := metav1.Now()
+	cr := newCR()
+	cr.DeletionTimestamp = &now
+	cr.Finalizers = []string{"other"}
+	c := fake.NewClientBuilder().WithObjects(cr).Build()
+
+	deleted, err := Reconcile(context.Background(), c, cr, Step{Name: "never", Run: func(context.Context) error {
+		return errors.New("must not run")
+	}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(deleted).To(BeTrue())
+}
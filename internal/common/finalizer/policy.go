@@ -0,0 +1,113 @@
+package finalizer
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DeletionPolicy decides what happens to the database resources and backups
+// of a CR when the CR is deleted.
+// +kubebuilder:validation:Enum=Retain;Delete
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyRetain orphans the resources so that the data survives
+	// the CR. It is the default.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyDelete deletes the resources together with the CR.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+)
+
+// OrDefault returns p, or DeletionPolicyRetain if p is empty.
+func (p DeletionPolicy) OrDefault() DeletionPolicy {
+	if p == "" {
+		return DeletionPolicyRetain
+	}
+	return p
+}
+
+// Validate reports an unknown policy. The empty policy is valid.
+func (p DeletionPolicy) Validate() error {
+	switch p {
+	case "", DeletionPolicyRetain, DeletionPolicyDelete:
+		return nil
+	}
+	return fmt.Errorf("deletionPolicy: must be %s or %s, got %q", DeletionPolicyRetain, DeletionPolicyDelete, p)
+}
+
+// cleanupKinds are the mariadb-operator kinds that only drop their SQL
+// object on deletion if spec.cleanupPolicy is Delete. The mariadb-operator
+// defaults it to Skip.
+var cleanupKinds = map[schema.GroupKind]bool{
+	{Group: "k8s.mariadb.com", Kind: "Database"}: true,
+	{Group: "k8s.mariadb.com", Kind: "User"}:     true,
+	{Group: "k8s.mariadb.com", Kind: "Grant"}:    true,
+}
+
+// ApplyDeletionPolicy deletes children, e.g. the mariadb-operator Database,
+// User and Grant of owner, if policy is Delete. Before deleting a Database,
+// User or Grant it sets spec.cleanupPolicy to Delete so that the
+// mariadb-operator drops the schema, user or grant too. Otherwise it removes
+// owner's references from the children so that garbage collection keeps
+// them. Children that no longer exist are skipped; each child only needs its
+// kind, namespace and name set.
+func ApplyDeletionPolicy(ctx context.Context, c client.Client, owner client.Object, policy DeletionPolicy, children ...client.Object) error {
+	for _, child := range children {
+		key := client.ObjectKeyFromObject(child)
+		if err := c.Get(ctx, key, child); err != nil {
+			if client.IgnoreNotFound(err) == nil {
+				continue
+			}
+			return fmt.Errorf("getting %s: %w", key.Name, err)
+		}
+
+		if policy.OrDefault() == DeletionPolicyDelete {
+			if err := setCleanupPolicy(ctx, c, child); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("setting cleanup policy of %s: %w", key.Name, err)
+			}
+			if err := c.Delete(ctx, child); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("deleting %s: %w", key.Name, err)
+			}
+			continue
+		}
+
+		refs := child.GetOwnerReferences()
+		kept := make([]metav1.OwnerReference, 0, len(refs))
+		for _, ref := range refs {
+			if ref.UID != owner.GetUID() {
+				kept = append(kept, ref)
+			}
+		}
+		if len(kept) == len(refs) {
+			continue
+		}
+		patch := client.MergeFrom(child.DeepCopyObject().(client.Object))
+		child.SetOwnerReferences(kept)
+		if err := c.Patch(ctx, child, patch); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("orphaning %s: %w", key.Name, err)
+		}
+	}
+	return nil
+}
+
+// setCleanupPolicy sets spec.cleanupPolicy of a mariadb-operator Database,
+// User or Grant to Delete. Other children are left alone.
+func setCleanupPolicy(ctx context.Context, c client.Client, child client.Object) error {
+	u, ok := child.(*unstructured.Unstructured)
+	if !ok || !cleanupKinds[u.GroupVersionKind().GroupKind()] {
+		return nil
+	}
+	if policy, _, _ := unstructured.NestedString(u.Object, "spec", "cleanupPolicy"); policy == string(DeletionPolicyDelete) {
+		return nil
+	}
+	patch := client.MergeFrom(u.DeepCopy())
+	if err := unstructured.SetNestedField(u.Object, string(DeletionPolicyDelete), "spec", "cleanupPolicy"); err != nil {
+		return err
+	}
+	return c.Patch(ctx, u, patch)
+}
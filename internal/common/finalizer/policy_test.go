@@ -0,0 +1,136 @@
+package finalizer
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestDeletionPolicy(t *testing.T) {
+	tests := []struct {
+		policy    DeletionPolicy
+		effective DeletionPolicy
+		valid     bool
+	}{
+		{"", DeletionPolicyRetain, true},
+		{DeletionPolicyRetain, DeletionPolicyRetain, true},
+		{DeletionPolicyDelete, DeletionPolicyDelete, true},
+		{"Orphan", "Orphan", false},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(tc.policy.OrDefault()).To(Equal(tc.effective))
+			if tc.valid {
+				g.Expect(tc.policy.Validate()).To(Succeed())
+			} else {
+				g.Expect(tc.policy.Validate()).To(MatchError(ContainSubstring("deletionPolicy")))
+			}
+		})
+	}
+}
+
+// ownedSecret returns a child of the test CR that is also owned by another
+// object.
+func ownedSecret(name string) *corev1.Secret {
+	controller := true
+	return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:      name,
+		Namespace: "default",
+		OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "v1", Kind: "ConfigMap", Name: "keystone", UID: "owner-uid", Controller: &controller},
+			{APIVersion: "v1", Kind: "ConfigMap", Name: "other", UID: "other-uid"},
+		},
+	}}
+}
+
+func TestApplyDeletionPolicy(t *testing.T) {
+	tests := []struct {
+		policy      DeletionPolicy
+		wantDeleted bool
+	}{
+		{"", false},
+		{DeletionPolicyRetain, false},
+		{DeletionPolicyDelete, true},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			ctx := context.Background()
+			c := fake.NewClientBuilder().WithObjects(ownedSecret("db-user"), ownedSecret("db-grant")).Build()
+			children := []client.Object{
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db-user", Namespace: "default"}},
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db-grant", Namespace: "default"}},
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "missing", Namespace: "default"}},
+			}
+
+			g.Expect(ApplyDeletionPolicy(ctx, c, newCR(), tc.policy, children...)).To(Succeed())
+
+			for _, name := range []string{"db-user", "db-grant"} {
+				secret := &corev1.Secret{}
+				err := c.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, secret)
+				if tc.wantDeleted {
+					g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+					continue
+				}
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(secret.OwnerReferences).To(ConsistOf(HaveField("UID", BeEquivalentTo("other-uid"))))
+			}
+		})
+	}
+}
+
+func TestApplyDeletionPolicySetsCleanupPolicy(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+
+	mariadbChild := func(kind string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		u.SetGroupVersionKind(schema.GroupVersionKind{Group: "k8s.mariadb.com", Version: "v1alpha1", Kind: kind})
+		u.SetNamespace("default")
+		u.SetName("keystone")
+		return u
+	}
+	var objs []client.Object
+	for _, kind := range []string{"Database", "User", "Grant"} {
+		child := mariadbChild(kind)
+		g.Expect(unstructured.SetNestedField(child.Object, "Skip", "spec", "cleanupPolicy")).To(Succeed())
+		objs = append(objs, child)
+	}
+	objs = append(objs, ownedSecret("db-credentials"))
+
+	// Record the cleanup policy each child has in the cluster when it is
+	// deleted.
+	atDeletion := map[string]string{}
+	c := fake.NewClientBuilder().WithObjects(objs...).WithInterceptorFuncs(interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				stored := mariadbChild(u.GetKind())
+				g.Expect(c.Get(ctx, client.ObjectKeyFromObject(u), stored)).To(Succeed())
+				atDeletion[u.GetKind()], _, _ = unstructured.NestedString(stored.Object, "spec", "cleanupPolicy")
+			}
+			return c.Delete(ctx, obj, opts...)
+		},
+	}).Build()
+
+	children := []client.Object{
+		mariadbChild("Database"), mariadbChild("User"), mariadbChild("Grant"),
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: "default"}},
+	}
+	g.Expect(ApplyDeletionPolicy(ctx, c, newCR(), DeletionPolicyDelete, children...)).To(Succeed())
+
+	g.Expect(atDeletion).To(Equal(map[string]string{"Database": "Delete", "User": "Delete", "Grant": "Delete"}))
+	for _, child := range children {
+		err := c.Get(ctx, client.ObjectKeyFromObject(child), child)
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	}
+}
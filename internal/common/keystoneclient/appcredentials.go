@@ -0,0 +1,64 @@
+package keystoneclient
+
+import (
+	"context"
+	"net/url"
+)
+
+// ApplicationCredential lets a service authenticate as a user without the
+// user's password. Secret is only returned on creation; Keystone generates
+// one if it is left empty.
+type ApplicationCredential struct {
+	ID           string        `json:"id,omitempty"`
+	Name         string        `json:"name,omitempty"`
+	Description  string        `json:"description,omitempty"`
+	Secret       string        `json:"secret,omitempty"`
+	ExpiresAt    *Time         `json:"expires_at,omitempty"`
+	ProjectID    string        `json:"project_id,omitempty"`
+	Roles        []IdentityRef `json:"roles,omitempty"`
+	Unrestricted bool          `json:"unrestricted,omitempty"`
+}
+
+func applicationCredentialsPath(userID string) string {
+	return "/users/" + url.PathEscape(userID) + "/application_credentials"
+}
+
+// CreateApplicationCredential creates an application credential of userID,
+// scoped to the project of the client's token. The result carries the
+// secret, which cannot be retrieved later.
+func (c *Client) CreateApplicationCredential(ctx context.Context, userID string, cred ApplicationCredential) (*ApplicationCredential, error) {
+	return createResource(ctx, c, applicationCredentialsPath(userID), "application_credential", cred)
+}
+
+// GetApplicationCredential returns the application credential id of userID,
+// without its secret.
+func (c *Client) GetApplicationCredential(ctx context.Context, userID, id string) (*ApplicationCredential, error) {
+	return getResource[ApplicationCredential](ctx, c, applicationCredentialsPath(userID)+"/"+url.PathEscape(id), "application_credential")
+}
+
+// ListApplicationCredentials lists the application credentials of userID,
+// filtered by name if it is not empty.
+func (c *Client) ListApplicationCredentials(ctx context.Context, userID, name string) ([]ApplicationCredential, error) {
+	q := url.Values{}
+	if name != "" {
+		q.Set("name", name)
+	}
+	return listResources[ApplicationCredential](ctx, c, applicationCredentialsPath(userID), "application_credentials", q)
+}
+
+// ApplicationCredentialByName returns the application credential of userID
+// named name.
+func (c *Client) ApplicationCredentialByName(ctx context.Context, userID, name string) (*ApplicationCredential, error) {
+	creds, err := c.ListApplicationCredentials(ctx, userID, name)
+	if err != nil {
+		return nil, err
+	}
+	return single(creds, "application credential", name)
+}
+
+// DeleteApplicationCredential deletes the application credential id of
+// userID. Application credentials cannot be updated; rotate them by creating
+// a new one and deleting the old one.
+func (c *Client) DeleteApplicationCredential(ctx context.Context, userID, id string) error {
+	return deleteResource(ctx, c, applicationCredentialsPath(userID)+"/"+url.PathEscape(id))
+}
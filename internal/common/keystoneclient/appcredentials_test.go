@@ -0,0 +1,77 @@
+package keystoneclient_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/c5c3/forge/internal/common/keystoneclient"
+	"github.com/c5c3/forge/internal/common/keystoneclient/fake"
+)
+
+func TestApplicationCredentials(t *testing.T) {
+	g := NewGomegaWithT(t)
+	c, srv := newClient(t, fake.Options{})
+	ctx := t.Context()
+	userID := srv.AdminUserID()
+
+	expires := keystoneclient.Time{Time: time.Now().Add(time.Hour).Truncate(time.Microsecond)}
+	cred, err := c.CreateApplicationCredential(ctx, userID, keystoneclient.ApplicationCredential{
+		Name:      "forge",
+		ExpiresAt: &expires,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cred.Secret).NotTo(BeEmpty())
+	g.Expect(cred.ProjectID).NotTo(BeEmpty())
+	g.Expect(cred.ExpiresAt.Equal(expires.Time)).To(BeTrue())
+	g.Expect(cred.Roles).To(ContainElement(HaveField("Name", fake.AdminRole)))
+
+	_, err = c.CreateApplicationCredential(ctx, userID, keystoneclient.ApplicationCredential{Name: "forge"})
+	g.Expect(keystoneclient.IsConflict(err)).To(BeTrue())
+
+	stored, err := c.ApplicationCredentialByName(ctx, userID, "forge")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(stored.ID).To(Equal(cred.ID))
+	g.Expect(stored.Secret).To(BeEmpty())
+
+	tests := []struct {
+		name string
+		auth keystoneclient.AuthOptions
+	}{
+		{
+			name: "by ID",
+			auth: keystoneclient.AuthOptions{ApplicationCredentialID: cred.ID, ApplicationCredentialSecret: cred.Secret},
+		},
+		{
+			name: "by name and user",
+			auth: keystoneclient.AuthOptions{
+				Username:                    fake.AdminUsername,
+				ApplicationCredentialName:   "forge",
+				ApplicationCredentialSecret: cred.Secret,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			credClient, err := keystoneclient.New(srv.URL, tc.auth, keystoneclient.Options{HTTPClient: srv.Client()})
+			g.Expect(err).NotTo(HaveOccurred())
+			token, err := credClient.Token(t.Context())
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(token.Methods).To(ConsistOf("application_credential"))
+			g.Expect(token.Project.ID).To(Equal(cred.ProjectID))
+		})
+	}
+
+	g.Expect(c.DeleteApplicationCredential(ctx, userID, cred.ID)).To(Succeed())
+	_, err = c.GetApplicationCredential(ctx, userID, cred.ID)
+	g.Expect(keystoneclient.IsNotFound(err)).To(BeTrue())
+
+	credClient, err := keystoneclient.New(srv.URL, tests[0].auth, keystoneclient.Options{HTTPClient: srv.Client()})
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = credClient.Token(ctx)
+	g.Expect(errors.Is(err, keystoneclient.ErrUnauthorized)).To(BeTrue())
+}
@@ -0,0 +1,252 @@
+package keystoneclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// AuthOptions are the credentials a Client authenticates with. Either a
+// password or an application credential secret must be given.
+type AuthOptions struct {
+	// UserID or Username identify the user. Username is looked up in the
+	// user domain.
+	UserID   string
+	Username string
+	Password string
+	// UserDomainID or UserDomainName identify the domain of Username. The
+	// default domain is used if neither is set.
+	UserDomainID   string
+	UserDomainName string
+
+	// ProjectID or ProjectName scope the token to a project. ProjectName is
+	// looked up in the project domain, which defaults to the default domain.
+	ProjectID         string
+	ProjectName       string
+	ProjectDomainID   string
+	ProjectDomainName string
+	// DomainID or DomainName scope the token to a domain instead.
+	DomainID   string
+	DomainName string
+
+	// ApplicationCredentialID, or ApplicationCredentialName together with
+	// the user, identify an application credential to authenticate with
+	// instead of a password. Its token is always scoped to the credential's
+	// project.
+	ApplicationCredentialID     string
+	ApplicationCredentialName   string
+	ApplicationCredentialSecret string
+}
+
+func (o AuthOptions) validate() error {
+	var errs []error
+	if o.ApplicationCredentialSecret != "" {
+		if o.ApplicationCredentialID == "" && (o.ApplicationCredentialName == "" || o.UserID == "" && o.Username == "") {
+			errs = append(errs, errors.New("auth: an application credential needs an ID, or a name and a user"))
+		}
+	} else {
+		if o.UserID == "" && o.Username == "" {
+			errs = append(errs, errors.New("auth: one of UserID or Username is required"))
+		}
+		if o.Password == "" {
+			errs = append(errs, errors.New("auth: one of Password or ApplicationCredentialSecret is required"))
+		}
+		projectScoped := o.ProjectID != "" || o.ProjectName != ""
+		domainScoped := o.DomainID != "" || o.DomainName != ""
+		if projectScoped && domainScoped {
+			errs = append(errs, errors.New("auth: project and domain scope are mutually exclusive"))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// IdentityRef references a Keystone object by ID and name, as embedded in
+// tokens and application credentials.
+type IdentityRef struct {
+	ID     string       `json:"id,omitempty"`
+	Name   string       `json:"name,omitempty"`
+	Domain *IdentityRef `json:"domain,omitempty"`
+}
+
+// Token is an issued or validated token.
+type Token struct {
+	// ID is the token itself, sent as X-Auth-Token.
+	ID        string        `json:"-"`
+	ExpiresAt Time          `json:"expires_at"`
+	IssuedAt  Time          `json:"issued_at"`
+	Methods   []string      `json:"methods,omitempty"`
+	User      IdentityRef   `json:"user"`
+	Project   *IdentityRef  `json:"project,omitempty"`
+	Domain    *IdentityRef  `json:"domain,omitempty"`
+	Roles     []IdentityRef `json:"roles,omitempty"`
+}
+
+// CatalogEntry is a service in the catalog of a token.
+type CatalogEntry struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	Name      string            `json:"name,omitempty"`
+	Endpoints []CatalogEndpoint `json:"endpoints"`
+}
+
+// CatalogEndpoint is an endpoint of a CatalogEntry.
+type CatalogEndpoint struct {
+	ID        string `json:"id"`
+	Interface string `json:"interface"`
+	RegionID  string `json:"region_id,omitempty"`
+	URL       string `json:"url"`
+}
+
+// Token returns a valid token, issuing a new one if none is cached or the
+// cached one is about to expire.
+func (c *Client) Token(ctx context.Context) (*Token, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != nil && c.now().Before(c.refreshAt(c.token)) {
+		return c.token, nil
+	}
+	token, err := c.issueToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.token = token
+	return token, nil
+}
+
+// IssueToken authenticates, caches and returns a new token regardless of the
+// cached one.
+func (c *Client) IssueToken(ctx context.Context) (*Token, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	token, err := c.issueToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.token = token
+	return token, nil
+}
+
+// refreshAt returns when token must be replaced.
+func (c *Client) refreshAt(token *Token) time.Time {
+	margin := c.expiryMargin
+	if lifetime := token.ExpiresAt.Sub(token.IssuedAt.Time); !token.IssuedAt.IsZero() && lifetime < 2*margin {
+		margin = lifetime / 2
+	}
+	return token.ExpiresAt.Add(-margin)
+}
+
+// invalidate drops token from the cache unless it was already replaced.
+func (c *Client) invalidate(token *Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == token {
+		c.token = nil
+	}
+}
+
+// issueToken sends the authentication request. The caller holds c.mu.
+func (c *Client) issueToken(ctx context.Context) (*Token, error) {
+	var out struct {
+		Token Token `json:"token"`
+	}
+	resp, err := c.call(ctx, request{
+		method:    http.MethodPost,
+		path:      "/auth/tokens",
+		query:     map[string][]string{"nocatalog": {""}},
+		body:      c.auth.requestBody(),
+		expect:    []int{http.StatusCreated},
+		anonymous: true,
+	}, &out)
+	if err != nil {
+		return nil, err
+	}
+	out.Token.ID = resp.header.Get("X-Subject-Token")
+	if out.Token.ID == "" {
+		return nil, &APIError{Method: http.MethodPost, URL: c.baseURL + "/auth/tokens", StatusCode: resp.status,
+			Message: "response has no X-Subject-Token header"}
+	}
+	return &out.Token, nil
+}
+
+// ValidateToken returns the details of subject, which may be any token. It
+// returns an error matching ErrNotFound if subject is invalid or expired.
+func (c *Client) ValidateToken(ctx context.Context, subject string) (*Token, error) {
+	var out struct {
+		Token Token `json:"token"`
+	}
+	if _, err := c.call(ctx, request{
+		method: http.MethodGet,
+		path:   "/auth/tokens",
+		query:  map[string][]string{"nocatalog": {""}},
+		header: http.Header{"X-Subject-Token": {subject}},
+		expect: []int{http.StatusOK},
+	}, &out); err != nil {
+		return nil, err
+	}
+	out.Token.ID = subject
+	return &out.Token, nil
+}
+
+// Catalog returns the service catalog visible to the client's token.
+func (c *Client) Catalog(ctx context.Context) ([]CatalogEntry, error) {
+	var out struct {
+		Catalog []CatalogEntry `json:"catalog"`
+	}
+	if _, err := c.call(ctx, request{method: http.MethodGet, path: "/auth/catalog", expect: []int{http.StatusOK}}, &out); err != nil {
+		return nil, err
+	}
+	return out.Catalog, nil
+}
+
+// requestBody builds the body of a token request.
+func (o AuthOptions) requestBody() map[string]any {
+	user := map[string]any{}
+	if o.UserID != "" {
+		user["id"] = o.UserID
+	} else if o.Username != "" {
+		user["name"] = o.Username
+		user["domain"] = ref(o.UserDomainID, o.UserDomainName)
+	}
+
+	identity := map[string]any{}
+	if o.ApplicationCredentialSecret != "" {
+		cred := map[string]any{"secret": o.ApplicationCredentialSecret}
+		if o.ApplicationCredentialID != "" {
+			cred["id"] = o.ApplicationCredentialID
+		} else {
+			cred["name"] = o.ApplicationCredentialName
+			cred["user"] = user
+		}
+		identity["methods"] = []string{"application_credential"}
+		identity["application_credential"] = cred
+		return map[string]any{"auth": map[string]any{"identity": identity}}
+	}
+
+	user["password"] = o.Password
+	identity["methods"] = []string{"password"}
+	identity["password"] = map[string]any{"user": user}
+	auth := map[string]any{"identity": identity}
+	switch {
+	case o.ProjectID != "":
+		auth["scope"] = map[string]any{"project": map[string]any{"id": o.ProjectID}}
+	case o.ProjectName != "":
+		project := map[string]any{"name": o.ProjectName, "domain": ref(o.ProjectDomainID, o.ProjectDomainName)}
+		auth["scope"] = map[string]any{"project": project}
+	case o.DomainID != "" || o.DomainName != "":
+		auth["scope"] = map[string]any{"domain": ref(o.DomainID, o.DomainName)}
+	}
+	return map[string]any{"auth": auth}
+}
+
+// ref references a domain by ID or name, defaulting to the default domain.
+func ref(id, name string) map[string]any {
+	switch {
+	case id != "":
+		return map[string]any{"id": id}
+	case name != "":
+		return map[string]any{"name": name}
+	default:
+		return map[string]any{"id": DefaultDomainID}
+	}
+}
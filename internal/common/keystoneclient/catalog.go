@@ -0,0 +1,116 @@
+package keystoneclient
+
+import (
+	"context"
+	"net/url"
+)
+
+// Endpoint interfaces.
+const (
+	InterfacePublic   = "public"
+	InterfaceInternal = "internal"
+	InterfaceAdmin    = "admin"
+)
+
+// Service is a service in the Keystone catalog. Fields left empty in an
+// update are not changed.
+type Service struct {
+	ID          string `json:"id,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Enabled     *bool  `json:"enabled,omitempty"`
+}
+
+// Endpoint is an endpoint of a Service. Fields left empty in an update are
+// not changed.
+type Endpoint struct {
+	ID        string `json:"id,omitempty"`
+	ServiceID string `json:"service_id,omitempty"`
+	Interface string `json:"interface,omitempty"`
+	URL       string `json:"url,omitempty"`
+	RegionID  string `json:"region_id,omitempty"`
+	Enabled   *bool  `json:"enabled,omitempty"`
+}
+
+// ServiceListOptions filter ListServices. Empty fields do not filter.
+type ServiceListOptions struct {
+	Type string
+	Name string
+}
+
+// EndpointListOptions filter ListEndpoints. Empty fields do not filter.
+type EndpointListOptions struct {
+	ServiceID string
+	Interface string
+	RegionID  string
+}
+
+// CreateService creates a service.
+func (c *Client) CreateService(ctx context.Context, service Service) (*Service, error) {
+	return createResource(ctx, c, "/services", "service", service)
+}
+
+// GetService returns the service with the given ID.
+func (c *Client) GetService(ctx context.Context, id string) (*Service, error) {
+	return getResource[Service](ctx, c, "/services/"+url.PathEscape(id), "service")
+}
+
+// ListServices lists the services matching opts.
+func (c *Client) ListServices(ctx context.Context, opts ServiceListOptions) ([]Service, error) {
+	q := url.Values{}
+	if opts.Type != "" {
+		q.Set("type", opts.Type)
+	}
+	if opts.Name != "" {
+		q.Set("name", opts.Name)
+	}
+	return listResources[Service](ctx, c, "/services", "services", q)
+}
+
+// UpdateService changes the non-empty fields of update on the service id.
+func (c *Client) UpdateService(ctx context.Context, id string, update Service) (*Service, error) {
+	update.ID = ""
+	return updateResource(ctx, c, "/services/"+url.PathEscape(id), "service", update)
+}
+
+// DeleteService deletes the service id together with its endpoints.
+func (c *Client) DeleteService(ctx context.Context, id string) error {
+	return deleteResource(ctx, c, "/services/"+url.PathEscape(id))
+}
+
+// CreateEndpoint creates an endpoint.
+func (c *Client) CreateEndpoint(ctx context.Context, endpoint Endpoint) (*Endpoint, error) {
+	return createResource(ctx, c, "/endpoints", "endpoint", endpoint)
+}
+
+// GetEndpoint returns the endpoint with the given ID.
+func (c *Client) GetEndpoint(ctx context.Context, id string) (*Endpoint, error) {
+	return getResource[Endpoint](ctx, c, "/endpoints/"+url.PathEscape(id), "endpoint")
+}
+
+// ListEndpoints lists the endpoints matching opts.
+func (c *Client) ListEndpoints(ctx context.Context, opts EndpointListOptions) ([]Endpoint, error) {
+	q := url.Values{}
+	if opts.ServiceID != "" {
+		q.Set("service_id", opts.ServiceID)
+	}
+	if opts.Interface != "" {
+		q.Set("interface", opts.Interface)
+	}
+	if opts.RegionID != "" {
+		q.Set("region_id", opts.RegionID)
+	}
+	return listResources[Endpoint](ctx, c, "/endpoints", "endpoints", q)
+}
+
+// UpdateEndpoint changes the non-empty fields of update on the endpoint id.
+func (c *Client) UpdateEndpoint(ctx context.Context, id string, update Endpoint) (*Endpoint, error) {
+	update.ID = ""
+	return updateResource(ctx, c, "/endpoints/"+url.PathEscape(id), "endpoint", update)
+}
+
+// DeleteEndpoint deletes the endpoint id.
+func (c *Client) DeleteEndpoint(ctx context.Context, id string) error {
+	return deleteResource(ctx, c, "/endpoints/"+url.PathEscape(id))
+}
@@ -0,0 +1,56 @@
+package keystoneclient_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/c5c3/forge/internal/common/keystoneclient"
+	"github.com/c5c3/forge/internal/common/keystoneclient/fake"
+)
+
+func TestServicesAndEndpoints(t *testing.T) {
+	g := NewGomegaWithT(t)
+	c, _ := newClient(t, fake.Options{})
+	ctx := t.Context()
+
+	service, err := c.CreateService(ctx, keystoneclient.Service{Type: "identity", Name: "keystone"})
+	g.Expect(err).NotTo(HaveOccurred())
+	services, err := c.ListServices(ctx, keystoneclient.ServiceListOptions{Type: "identity"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(services).To(HaveLen(1))
+
+	for _, iface := range []string{keystoneclient.InterfacePublic, keystoneclient.InterfaceInternal} {
+		_, err := c.CreateEndpoint(ctx, keystoneclient.Endpoint{
+			ServiceID: service.ID,
+			Interface: iface,
+			URL:       "http://keystone-api.openstack.svc:5000/v3",
+			RegionID:  "RegionOne",
+		})
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	_, err = c.CreateEndpoint(ctx, keystoneclient.Endpoint{ServiceID: service.ID, Interface: "private", URL: "http://x"})
+	g.Expect(errors.Is(err, keystoneclient.ErrBadRequest)).To(BeTrue())
+
+	public, err := c.ListEndpoints(ctx, keystoneclient.EndpointListOptions{ServiceID: service.ID, Interface: keystoneclient.InterfacePublic})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(public).To(HaveLen(1))
+
+	updated, err := c.UpdateEndpoint(ctx, public[0].ID, keystoneclient.Endpoint{URL: "https://identity.example.com/v3"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated.URL).To(Equal("https://identity.example.com/v3"))
+	g.Expect(updated.Interface).To(Equal(keystoneclient.InterfacePublic))
+
+	catalog, err := c.Catalog(ctx)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(catalog).To(HaveLen(1))
+	g.Expect(catalog[0].Type).To(Equal("identity"))
+	g.Expect(catalog[0].Endpoints).To(ContainElement(HaveField("URL", "https://identity.example.com/v3")))
+
+	// Deleting the service deletes its endpoints.
+	g.Expect(c.DeleteService(ctx, service.ID)).To(Succeed())
+	endpoints, err := c.ListEndpoints(ctx, keystoneclient.EndpointListOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(endpoints).To(BeEmpty())
+}
@@ -0,0 +1,250 @@
+package keystoneclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDomainID is the ID of the domain Keystone creates on bootstrap.
+const DefaultDomainID = "default"
+
+// DefaultTokenExpiryMargin is how long before its expiry a cached token is
+// replaced, unless the token lives shorter than twice the margin.
+const DefaultTokenExpiryMargin = 5 * time.Minute
+
+// Options configures a Client.
+type Options struct {
+	// HTTPClient sends the requests. It defaults to http.DefaultClient; pass
+	// a client trusting the Keystone CA for HTTPS endpoints.
+	HTTPClient *http.Client
+	// Retry is the retry policy for transient failures. It defaults to
+	// DefaultRetryPolicy.
+	Retry *RetryPolicy
+	// TokenExpiryMargin defaults to DefaultTokenExpiryMargin.
+	TokenExpiryMargin time.Duration
+}
+
+// Client talks to the Identity v3 API of a single Keystone. It is safe for
+// concurrent use.
+type Client struct {
+	baseURL      string
+	auth         AuthOptions
+	httpClient   *http.Client
+	retry        RetryPolicy
+	expiryMargin time.Duration
+	now          func() time.Time
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// New returns a Client for the Keystone at endpoint, e.g.
+// http://keystone-api.openstack.svc:5000 with or without the /v3 suffix. No
+// request is sent until the first call.
+func New(endpoint string, auth AuthOptions, opts Options) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parsing endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("endpoint %q: must be an absolute http or https URL", endpoint)
+	}
+	if err := auth.validate(); err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		baseURL:      strings.TrimSuffix(strings.TrimSuffix(endpoint, "/"), "/v3") + "/v3",
+		auth:         auth,
+		httpClient:   opts.HTTPClient,
+		retry:        DefaultRetryPolicy,
+		expiryMargin: opts.TokenExpiryMargin,
+		now:          time.Now,
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	if opts.Retry != nil {
+		c.retry = *opts.Retry
+	}
+	if c.expiryMargin <= 0 {
+		c.expiryMargin = DefaultTokenExpiryMargin
+	}
+	return c, nil
+}
+
+// request describes a single API call.
+type request struct {
+	method string
+	// path is relative to the /v3 base URL and starts with a slash.
+	path  string
+	query url.Values
+	// header is added to the request, e.g. X-Subject-Token.
+	header http.Header
+	// body is encoded as JSON if non-nil.
+	body any
+	// expect lists the accepted status codes; all others are errors.
+	expect []int
+	// anonymous requests are sent without a token.
+	anonymous bool
+}
+
+// response is a received response with its body read.
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// call sends req with a token and decodes the response body into out, if
+// non-nil. A 401 response with a cached token is retried once with a new
+// token.
+func (c *Client) call(ctx context.Context, req request, out any) (*response, error) {
+	for reauthenticated := false; ; reauthenticated = true {
+		var token *Token
+		if !req.anonymous {
+			var err error
+			if token, err = c.Token(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.send(ctx, req, token)
+		if err != nil {
+			return nil, err
+		}
+		if resp.status == http.StatusUnauthorized && token != nil && !reauthenticated {
+			c.invalidate(token)
+			continue
+		}
+		if !accepted(resp.status, req.expect) {
+			return resp, newAPIError(req.method, c.baseURL+req.path, resp.status, resp.body)
+		}
+		if out != nil {
+			if err := json.Unmarshal(resp.body, out); err != nil {
+				return resp, fmt.Errorf("keystone: decoding response of %s %s: %w", req.method, req.path, err)
+			}
+		}
+		return resp, nil
+	}
+}
+
+// send performs req, retrying transient failures.
+func (c *Client) send(ctx context.Context, req request, token *Token) (*response, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, fmt.Errorf("keystone: encoding request of %s %s: %w", req.method, req.path, err)
+		}
+	}
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.sendOnce(ctx, req, target, body, token)
+		status := 0
+		var header http.Header
+		if resp != nil {
+			status, header = resp.status, resp.header
+		}
+		if attempt >= c.retry.MaxAttempts || !retryable(req.method, status, err) {
+			if err != nil {
+				return nil, fmt.Errorf("keystone: %s %s: %w", req.method, target, err)
+			}
+			return resp, nil
+		}
+		if err := sleep(ctx, c.retry.backoff(attempt, header)); err != nil {
+			return nil, fmt.Errorf("keystone: %s %s: %w", req.method, target, err)
+		}
+	}
+}
+
+func (c *Client) sendOnce(ctx context.Context, req request, target string, body []byte, token *Token) (*response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range req.header {
+		httpReq.Header[k] = v
+	}
+	if token != nil {
+		httpReq.Header.Set("X-Auth-Token", token.ID)
+	}
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	return &response{status: httpResp.StatusCode, header: httpResp.Header, body: respBody}, nil
+}
+
+func accepted(status int, expect []int) bool {
+	for _, s := range expect {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Bool returns a pointer to b, for the optional Enabled fields.
+func Bool(b bool) *bool {
+	return &b
+}
+
+// timeLayout is the format Keystone uses for timestamps such as expires_at.
+const timeLayout = "2006-01-02T15:04:05.000000Z"
+
+// Time is a timestamp in Keystone's format. Keystone omits the time zone of
+// some timestamps; those are UTC.
+type Time struct {
+	time.Time
+}
+
+// MarshalJSON encodes t in UTC, or as null if t is zero.
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.UTC().Format(timeLayout))
+}
+
+// UnmarshalJSON decodes a timestamp with or without time zone.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil || s == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999"} {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			t.Time = parsed.UTC()
+			return nil
+		}
+	}
+	return fmt.Errorf("parsing time %q", s)
+}
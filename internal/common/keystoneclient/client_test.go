@@ -0,0 +1,268 @@
+package keystoneclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+var testRetry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+var testAuth = AuthOptions{Username: "admin", Password: "secret", ProjectName: "admin"}
+
+// tokenHandler issues tokens valid for lifetime and counts them.
+func tokenHandler(issued *atomic.Int32, lifetime time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		n := issued.Add(1)
+		now := time.Now().UTC()
+		w.Header().Set("X-Subject-Token", "token-"+string(rune('0'+n)))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"token":{"issued_at":"` + now.Format(timeLayout) +
+			`","expires_at":"` + now.Add(lifetime).Format(timeLayout) + `","user":{"id":"u"}}}`))
+	}
+}
+
+func newTestClient(t *testing.T, mux *http.ServeMux) *Client {
+	t.Helper()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	c, err := New(srv.URL+"/v3/", testAuth, Options{HTTPClient: srv.Client(), Retry: &testRetry})
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+	return c
+}
+
+func TestNew_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		endpoint      string
+		auth          AuthOptions
+		failureSubstr string
+	}{
+		{name: "password", endpoint: "http://keystone:5000", auth: testAuth},
+		{
+			name:     "application credential",
+			endpoint: "https://keystone:5000/v3",
+			auth:     AuthOptions{ApplicationCredentialID: "id", ApplicationCredentialSecret: "s"},
+		},
+		{name: "relative endpoint", endpoint: "keystone:5000", auth: testAuth, failureSubstr: "absolute http or https URL"},
+		{name: "no user", endpoint: "http://keystone", auth: AuthOptions{Password: "p"}, failureSubstr: "UserID or Username"},
+		{name: "no secret", endpoint: "http://keystone", auth: AuthOptions{Username: "u"}, failureSubstr: "Password or ApplicationCredentialSecret"},
+		{
+			name:          "two scopes",
+			endpoint:      "http://keystone",
+			auth:          AuthOptions{Username: "u", Password: "p", ProjectID: "p", DomainID: "d"},
+			failureSubstr: "mutually exclusive",
+		},
+		{
+			name:          "application credential name without user",
+			endpoint:      "http://keystone",
+			auth:          AuthOptions{ApplicationCredentialName: "n", ApplicationCredentialSecret: "s"},
+			failureSubstr: "an application credential needs",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			_, err := New(tc.endpoint, tc.auth, Options{})
+			if tc.failureSubstr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.failureSubstr)))
+			}
+		})
+	}
+}
+
+func TestClient_TokenCaching(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var issued atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v3/auth/tokens", tokenHandler(&issued, time.Hour))
+	c := newTestClient(t, mux)
+
+	first, err := c.Token(t.Context())
+	g.Expect(err).NotTo(HaveOccurred())
+	second, err := c.Token(t.Context())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(second).To(BeIdenticalTo(first))
+	g.Expect(issued.Load()).To(Equal(int32(1)))
+
+	// Within the expiry margin the token is replaced.
+	c.now = func() time.Time { return first.ExpiresAt.Add(-time.Minute) }
+	third, err := c.Token(t.Context())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(third.ID).NotTo(Equal(first.ID))
+	g.Expect(issued.Load()).To(Equal(int32(2)))
+}
+
+func TestClient_RefreshAt_ShortLivedToken(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	c, err := New("http://keystone", testAuth, Options{})
+	g.Expect(err).NotTo(HaveOccurred())
+	issuedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	token := &Token{IssuedAt: Time{issuedAt}, ExpiresAt: Time{issuedAt.Add(4 * time.Minute)}}
+	g.Expect(c.refreshAt(token)).To(Equal(issuedAt.Add(2 * time.Minute)))
+}
+
+func TestClient_ReauthenticatesOnUnauthorized(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var issued, calls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v3/auth/tokens", tokenHandler(&issued, time.Hour))
+	mux.HandleFunc("GET /v3/domains/default", func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		// Only the second token is accepted, as after a key rotation.
+		if r.Header.Get("X-Auth-Token") != "token-2" {
+			http.Error(w, `{"error":{"code":401,"message":"The request you have made requires authentication."}}`, http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"domain":{"id":"default","name":"Default"}}`))
+	})
+	c := newTestClient(t, mux)
+
+	domain, err := c.GetDomain(t.Context(), "default")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(domain.Name).To(Equal("Default"))
+	g.Expect(issued.Load()).To(Equal(int32(2)))
+	g.Expect(calls.Load()).To(Equal(int32(2)))
+
+	// A second 401 in a row is returned to the caller.
+	c.token.ID = "revoked"
+	issued.Store(5)
+	_, err = c.GetDomain(t.Context(), "default")
+	g.Expect(errors.Is(err, ErrUnauthorized)).To(BeTrue())
+}
+
+func TestClient_Retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		status       int
+		failures     int32
+		wantAttempts int32
+		wantErr      error
+	}{
+		{name: "GET recovers from 503", method: http.MethodGet, status: http.StatusServiceUnavailable, failures: 2, wantAttempts: 3},
+		{name: "GET gives up after max attempts", method: http.MethodGet, status: http.StatusBadGateway, failures: 5, wantAttempts: 3},
+		{name: "GET does not retry 404", method: http.MethodGet, status: http.StatusNotFound, failures: 1, wantAttempts: 1, wantErr: ErrNotFound},
+		{name: "POST retries 429", method: http.MethodPost, status: http.StatusTooManyRequests, failures: 1, wantAttempts: 2},
+		{name: "POST does not retry 502", method: http.MethodPost, status: http.StatusBadGateway, failures: 1, wantAttempts: 1},
+		{name: "DELETE retries 504", method: http.MethodDelete, status: http.StatusGatewayTimeout, failures: 1, wantAttempts: 2},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			var issued, attempts atomic.Int32
+			mux := http.NewServeMux()
+			mux.HandleFunc("POST /v3/auth/tokens", tokenHandler(&issued, time.Hour))
+			projects := func(w http.ResponseWriter, r *http.Request) {
+				if attempts.Add(1) <= tc.failures {
+					http.Error(w, "try again", tc.status)
+					return
+				}
+				switch r.Method {
+				case http.MethodDelete:
+					w.WriteHeader(http.StatusNoContent)
+				case http.MethodPost:
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte(`{"project":{"id":"p"}}`))
+				default:
+					_, _ = w.Write([]byte(`{"project":{"id":"p"}}`))
+				}
+			}
+			mux.HandleFunc("/v3/projects", projects)
+			mux.HandleFunc("/v3/projects/", projects)
+			c := newTestClient(t, mux)
+
+			var err error
+			switch tc.method {
+			case http.MethodGet:
+				_, err = c.GetProject(t.Context(), "p")
+			case http.MethodPost:
+				_, err = c.CreateProject(t.Context(), Project{Name: "p"})
+			case http.MethodDelete:
+				err = c.DeleteProject(t.Context(), "p")
+			}
+			g.Expect(attempts.Load()).To(Equal(tc.wantAttempts))
+			switch {
+			case tc.wantErr != nil:
+				g.Expect(errors.Is(err, tc.wantErr)).To(BeTrue())
+			case tc.failures >= tc.wantAttempts:
+				var apiErr *APIError
+				g.Expect(errors.As(err, &apiErr)).To(BeTrue())
+				g.Expect(apiErr.StatusCode).To(Equal(tc.status))
+			default:
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	p := RetryPolicy{MaxAttempts: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	g.Expect(p.backoff(1, nil)).To(Equal(100 * time.Millisecond))
+	g.Expect(p.backoff(3, nil)).To(Equal(400 * time.Millisecond))
+	g.Expect(p.backoff(10, nil)).To(Equal(time.Second))
+	g.Expect(p.backoff(1, http.Header{"Retry-After": {"0"}})).To(Equal(time.Duration(0)))
+	g.Expect(p.backoff(1, http.Header{"Retry-After": {"30"}})).To(Equal(time.Second))
+}
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		wantSentinel error
+		wantMessage  string
+	}{
+		{name: "keystone error document", status: http.StatusConflict, body: `{"error":{"code":409,"message":"Duplicate entry."}}`, wantSentinel: ErrConflict, wantMessage: "Duplicate entry."},
+		{name: "plain body", status: http.StatusForbidden, body: "denied\n", wantSentinel: ErrForbidden, wantMessage: "denied"},
+		{name: "bad request", status: http.StatusBadRequest, body: "", wantSentinel: ErrBadRequest},
+		{name: "server error", status: http.StatusInternalServerError, body: "boom", wantMessage: "boom"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			err := newAPIError(http.MethodGet, "http://keystone/v3/x", tc.status, []byte(tc.body))
+			g.Expect(err.Message).To(Equal(tc.wantMessage))
+			for _, sentinel := range []error{ErrBadRequest, ErrUnauthorized, ErrForbidden, ErrNotFound, ErrConflict} {
+				g.Expect(errors.Is(err, sentinel)).To(Equal(sentinel == tc.wantSentinel), "sentinel %v", sentinel)
+			}
+		})
+	}
+}
+
+func TestTime_JSON(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var tm Time
+	g.Expect(tm.UnmarshalJSON([]byte(`"2026-03-01T12:00:00.000000Z"`))).To(Succeed())
+	g.Expect(tm.Time).To(Equal(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)))
+	g.Expect(tm.UnmarshalJSON([]byte(`"2026-03-01T12:00:00.500000"`))).To(Succeed())
+	g.Expect(tm.Time).To(Equal(time.Date(2026, 3, 1, 12, 0, 0, 500000000, time.UTC)))
+	g.Expect(tm.UnmarshalJSON([]byte(`null`))).To(Succeed())
+	g.Expect(tm.IsZero()).To(BeTrue())
+	g.Expect(tm.UnmarshalJSON([]byte(`"yesterday"`))).NotTo(Succeed())
+
+	out, err := Time{time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}.MarshalJSON()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(Equal(`"2026-03-01T12:00:00.000000Z"`))
+}
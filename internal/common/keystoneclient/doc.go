@@ -0,0 +1,29 @@
+// Package keystoneclient is a client for the parts of the OpenStack Identity
+// v3 API the operators use: tokens and the service catalog,
+// domains, projects, users, roles and role assignments, services and
+// endpoints, and application credentials.
+//
+// A Client authenticates with password or application credentials and caches
+// the issued token until shortly before it expires. A request rejected with
+// 401 Unauthorized, e.g. after the fernet keys were rotated, is retried once
+// with a fresh token. Transient failures (429, 502, 503, 504 and connection
+// errors) are retried according to a RetryPolicy; POST requests, which are
+// not idempotent, are only retried when Keystone refused them with 429 or 503.
+//
+// Errors returned by Keystone are *APIError values that match the sentinel
+// errors ErrNotFound, ErrConflict, ErrUnauthorized, ErrForbidden and
+// ErrBadRequest with errors.Is:
+//
+//	c, err := keystoneclient.New("http://keystone-api.openstack.svc:5000", keystoneclient.AuthOptions{
+//		Username:    "admin",
+//		Password:    password,
+//		ProjectName: "admin",
+//	}, keystoneclient.Options{})
+//	...
+//	project, err := c.ProjectByName(ctx, keystoneclient.DefaultDomainID, "service")
+//	if errors.Is(err, keystoneclient.ErrNotFound) {
+//		project, err = c.CreateProject(ctx, keystoneclient.Project{Name: "service", DomainID: keystoneclient.DefaultDomainID})
+//	}
+//
+// The fake subpackage provides an in-memory Keystone for tests.
+package keystoneclient
@@ -0,0 +1,98 @@
+package keystoneclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors matched by *APIError with errors.Is.
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+)
+
+// APIError is an error response of the Keystone API.
+type APIError struct {
+	// Method and URL identify the failed request.
+	Method string
+	URL    string
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Message is Keystone's error message, or the raw response body if it
+	// was not a Keystone error document.
+	Message string
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("keystone: %s %s: %d %s", e.Method, e.URL, e.StatusCode, msg)
+}
+
+// Is matches the sentinel error of the response status.
+func (e *APIError) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusBadRequest:
+		return target == ErrBadRequest
+	case http.StatusUnauthorized:
+		return target == ErrUnauthorized
+	case http.StatusForbidden:
+		return target == ErrForbidden
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusConflict:
+		return target == ErrConflict
+	}
+	return false
+}
+
+// IsNotFound reports whether err is a 404 Not Found response.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsConflict reports whether err is a 409 Conflict response, which Keystone
+// returns when an object with the same name already exists.
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+// maxErrorBody bounds how much of a non-Keystone error body is kept.
+const maxErrorBody = 512
+
+// newAPIError builds an APIError from a response, extracting the message of
+// a Keystone error document ({"error": {"code": ..., "message": ...}}).
+func newAPIError(method, url string, status int, body []byte) *APIError {
+	var doc struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	msg := ""
+	if json.Unmarshal(body, &doc) == nil && doc.Error.Message != "" {
+		msg = doc.Error.Message
+	} else {
+		if len(body) > maxErrorBody {
+			body = body[:maxErrorBody]
+		}
+		msg = strings.TrimSpace(string(body))
+	}
+	return &APIError{Method: method, URL: url, StatusCode: status, Message: msg}
+}
+
+// notFound is returned by the lookups by name when no object matched.
+func notFound(kind, name string) *APIError {
+	return &APIError{
+		Method:     http.MethodGet,
+		StatusCode: http.StatusNotFound,
+		Message:    fmt.Sprintf("%s %q not found", kind, name),
+	}
+}
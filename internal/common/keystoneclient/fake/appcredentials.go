@@ -0,0 +1,106 @@
+package fake
+
+import (
+	"net/http"
+	"sort"
+)
+
+// tokenOf returns the authenticated token of r.
+func tokenOf(r *http.Request) *tokenInfo {
+	info, _ := r.Context().Value(tokenContextKey{}).(*tokenInfo)
+	return info
+}
+
+func (s *Server) createAppCred(w http.ResponseWriter, r *http.Request) {
+	cred, herr := decodeObject(r, "application_credential")
+	if herr != nil {
+		writeError(w, herr)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	userID := r.PathValue("user")
+	token := tokenOf(r)
+	if token.userID != userID {
+		writeError(w, errorf(http.StatusForbidden, "Cannot create an application credential for another user."))
+		return
+	}
+	if token.projectID == "" {
+		writeError(w, errorf(http.StatusForbidden, "An application credential requires a project-scoped token."))
+		return
+	}
+	if str(cred, "name") == "" {
+		writeError(w, errorf(http.StatusBadRequest, "'name' is a required property"))
+		return
+	}
+	for _, existing := range s.appCreds {
+		if existing.userID == userID && existing.obj["name"] == cred["name"] {
+			writeError(w, errorf(http.StatusConflict, "Conflict occurred attempting to store application_credential - Duplicate entry."))
+			return
+		}
+	}
+
+	secret := str(cred, "secret")
+	if secret == "" {
+		secret = newID()
+	}
+	delete(cred, "secret")
+	cred["id"] = newID()
+	cred["user_id"] = userID
+	cred["project_id"] = token.projectID
+	if _, ok := cred["roles"]; !ok {
+		cred["roles"] = s.rolesOn("projects", token.projectID, userID)
+	}
+	if _, ok := cred["unrestricted"]; !ok {
+		cred["unrestricted"] = false
+	}
+	s.appCreds[cred["id"].(string)] = &appCred{obj: cred, userID: userID, secret: secret}
+
+	created := make(map[string]any, len(cred)+1)
+	for k, v := range cred {
+		created[k] = v
+	}
+	created["secret"] = secret
+	writeJSON(w, http.StatusCreated, map[string]any{"application_credential": created})
+}
+
+func (s *Server) listAppCreds(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.collections["users"].items[r.PathValue("user")]; !ok {
+		writeError(w, errorf(http.StatusNotFound, "Could not find user: %s.", r.PathValue("user")))
+		return
+	}
+	creds := []map[string]any{}
+	for _, cred := range s.appCreds {
+		if cred.userID == r.PathValue("user") && matches(cred.obj, r.URL.Query()) {
+			creds = append(creds, cred.obj)
+		}
+	}
+	sort.Slice(creds, func(i, j int) bool { return str(creds[i], "name") < str(creds[j], "name") })
+	writeJSON(w, http.StatusOK, map[string]any{"application_credentials": creds})
+}
+
+func (s *Server) getAppCred(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cred, ok := s.appCreds[r.PathValue("id")]
+	if !ok || cred.userID != r.PathValue("user") {
+		writeError(w, errorf(http.StatusNotFound, "Could not find application credential: %s.", r.PathValue("id")))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"application_credential": cred.obj})
+}
+
+func (s *Server) deleteAppCred(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cred, ok := s.appCreds[r.PathValue("id")]
+	if !ok || cred.userID != r.PathValue("user") {
+		writeError(w, errorf(http.StatusNotFound, "Could not find application credential: %s.", r.PathValue("id")))
+		return
+	}
+	delete(s.appCreds, r.PathValue("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
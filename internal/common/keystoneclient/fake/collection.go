@@ -0,0 +1,167 @@
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+// collection stores the objects of one kind as JSON maps.
+type collection struct {
+	// key and plural are the envelope keys, e.g. "project" and "projects".
+	key, plural string
+	// required fields must be set on creation.
+	required []string
+	// defaults are applied on creation.
+	defaults map[string]any
+	// unique lists fields whose combined values must be unique.
+	unique []string
+	// immutable fields cannot be changed by an update.
+	immutable []string
+	// refs maps fields to the collection the referenced object is in.
+	refs map[string]string
+
+	items map[string]map[string]any
+	order []string
+}
+
+func (c *collection) put(id string, obj map[string]any) {
+	if _, ok := c.items[id]; !ok {
+		c.order = append(c.order, id)
+	}
+	c.items[id] = obj
+}
+
+// insert stores obj with defaults and a new ID and returns it.
+func (c *collection) insert(obj map[string]any) map[string]any {
+	for k, v := range c.defaults {
+		if _, ok := obj[k]; !ok {
+			obj[k] = v
+		}
+	}
+	obj["id"] = newID()
+	c.put(obj["id"].(string), obj)
+	return obj
+}
+
+func (c *collection) remove(id string) {
+	delete(c.items, id)
+	for i, existing := range c.order {
+		if existing == id {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// list returns the objects whose fields equal the query parameters.
+func (c *collection) list(query url.Values) []map[string]any {
+	items := []map[string]any{}
+	for _, id := range c.order {
+		if matches(c.items[id], query) {
+			items = append(items, c.items[id])
+		}
+	}
+	return items
+}
+
+func matches(obj map[string]any, query url.Values) bool {
+	for field := range query {
+		if v, ok := obj[field]; !ok || fmt.Sprint(v) != query.Get(field) {
+			return false
+		}
+	}
+	return true
+}
+
+// validate checks required fields, references and uniqueness of obj, which
+// is stored under id (empty for new objects).
+func (s *Server) validate(c *collection, id string, obj map[string]any) *httpError {
+	for _, field := range c.required {
+		if v, ok := obj[field].(string); !ok || v == "" {
+			return errorf(http.StatusBadRequest, "'%s' is a required property", field)
+		}
+	}
+	fields := make([]string, 0, len(c.refs))
+	for field := range c.refs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		ref, ok := obj[field].(string)
+		if !ok || ref == "" {
+			continue
+		}
+		if _, exists := s.collections[c.refs[field]].items[ref]; !exists {
+			return errorf(http.StatusBadRequest, "Could not find %s: %s.", field, ref)
+		}
+	}
+	if len(c.unique) == 0 {
+		return nil
+	}
+	for otherID, other := range c.items {
+		if otherID == id {
+			continue
+		}
+		same := true
+		for _, field := range c.unique {
+			if fmt.Sprint(other[field]) != fmt.Sprint(obj[field]) {
+				same = false
+				break
+			}
+		}
+		if same {
+			return errorf(http.StatusConflict, "Conflict occurred attempting to store %s - Duplicate entry.", c.key)
+		}
+	}
+	return nil
+}
+
+func (s *Server) newCollections() map[string]*collection {
+	newCollection := func(c collection) *collection {
+		c.items = map[string]map[string]any{}
+		return &c
+	}
+	return map[string]*collection{
+		"domains": newCollection(collection{
+			key: "domain", plural: "domains",
+			required: []string{"name"},
+			defaults: map[string]any{"enabled": true, "description": ""},
+			unique:   []string{"name"},
+		}),
+		"projects": newCollection(collection{
+			key: "project", plural: "projects",
+			required:  []string{"name"},
+			defaults:  map[string]any{"enabled": true, "description": "", "domain_id": "default"},
+			unique:    []string{"name", "domain_id"},
+			immutable: []string{"domain_id"},
+			refs:      map[string]string{"domain_id": "domains", "parent_id": "projects"},
+		}),
+		"users": newCollection(collection{
+			key: "user", plural: "users",
+			required:  []string{"name"},
+			defaults:  map[string]any{"enabled": true, "domain_id": "default"},
+			unique:    []string{"name", "domain_id"},
+			immutable: []string{"domain_id"},
+			refs:      map[string]string{"domain_id": "domains", "default_project_id": "projects"},
+		}),
+		"roles": newCollection(collection{
+			key: "role", plural: "roles",
+			required: []string{"name"},
+			unique:   []string{"name", "domain_id"},
+			refs:     map[string]string{"domain_id": "domains"},
+		}),
+		"services": newCollection(collection{
+			key: "service", plural: "services",
+			required: []string{"type"},
+			defaults: map[string]any{"enabled": true},
+		}),
+		"endpoints": newCollection(collection{
+			key: "endpoint", plural: "endpoints",
+			required: []string{"service_id", "interface", "url"},
+			defaults: map[string]any{"enabled": true},
+			refs:     map[string]string{"service_id": "services"},
+		}),
+	}
+}
@@ -0,0 +1,20 @@
+// Package fake provides an in-memory Keystone Identity v3 API for tests of
+// code using the keystoneclient package.
+//
+// A Server is an httptest.Server bootstrapped like a fresh Keystone: it has
+// the default domain, an admin project, the admin, member and reader roles
+// and an admin user with the admin role on the admin project. It implements
+// the requests keystoneclient sends, enforces name uniqueness, references
+// between objects and token expiry like Keystone, but does not enforce
+// policy: every valid token may do everything.
+//
+//	srv := fake.NewServer(fake.Options{})
+//	defer srv.Close()
+//	c, err := keystoneclient.New(srv.URL, srv.AdminAuth(), keystoneclient.Options{})
+//
+// Failures can be injected to exercise error handling and retries, and all
+// tokens can be revoked to simulate a fernet key rotation:
+//
+//	srv.Fail(fake.Fault{Method: http.MethodGet, Path: "/v3/projects", Status: http.StatusServiceUnavailable, Times: 2})
+//	srv.RevokeTokens()
+package fake
@@ -0,0 +1,302 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/c5c3/forge/internal/common/keystoneclient"
+)
+
+type tokenContextKey struct{}
+
+// handler routes requests after recording them, injecting faults and
+// authenticating the token.
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v3/auth/tokens", s.issueToken)
+	mux.HandleFunc("GET /v3/auth/tokens", s.validateToken)
+	mux.HandleFunc("GET /v3/auth/catalog", s.getCatalog)
+
+	for _, name := range []string{"domains", "projects", "users", "roles", "services", "endpoints"} {
+		name := name
+		mux.HandleFunc("GET /v3/"+name, func(w http.ResponseWriter, r *http.Request) { s.list(w, r, name) })
+		mux.HandleFunc("POST /v3/"+name, func(w http.ResponseWriter, r *http.Request) { s.create(w, r, name) })
+		mux.HandleFunc("GET /v3/"+name+"/{id}", func(w http.ResponseWriter, r *http.Request) { s.get(w, r, name) })
+		mux.HandleFunc("PATCH /v3/"+name+"/{id}", func(w http.ResponseWriter, r *http.Request) { s.update(w, r, name) })
+		mux.HandleFunc("DELETE /v3/"+name+"/{id}", func(w http.ResponseWriter, r *http.Request) { s.delete(w, r, name) })
+	}
+
+	for _, scope := range []string{"projects", "domains"} {
+		scope := scope
+		pattern := "/v3/" + scope + "/{target}/users/{user}/roles/{role}"
+		mux.HandleFunc("PUT "+pattern, func(w http.ResponseWriter, r *http.Request) { s.assignment(w, r, scope) })
+		mux.HandleFunc("HEAD "+pattern, func(w http.ResponseWriter, r *http.Request) { s.assignment(w, r, scope) })
+		mux.HandleFunc("DELETE "+pattern, func(w http.ResponseWriter, r *http.Request) { s.assignment(w, r, scope) })
+	}
+
+	mux.HandleFunc("POST /v3/users/{user}/application_credentials", s.createAppCred)
+	mux.HandleFunc("GET /v3/users/{user}/application_credentials", s.listAppCreds)
+	mux.HandleFunc("GET /v3/users/{user}/application_credentials/{id}", s.getAppCred)
+	mux.HandleFunc("DELETE /v3/users/{user}/application_credentials/{id}", s.deleteAppCred)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		if err := s.injectFault(r); err != nil {
+			s.mu.Unlock()
+			writeError(w, err)
+			return
+		}
+		var token *tokenInfo
+		if !(r.Method == http.MethodPost && r.URL.Path == "/v3/auth/tokens") {
+			var err *httpError
+			if token, err = s.authenticate(r.Header.Get("X-Auth-Token")); err != nil {
+				s.mu.Unlock()
+				writeError(w, err)
+				return
+			}
+		}
+		s.mu.Unlock()
+		mux.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenContextKey{}, token)))
+	})
+}
+
+// injectFault returns the error of the first matching fault. The caller
+// holds s.mu.
+func (s *Server) injectFault(r *http.Request) *httpError {
+	for i, f := range s.faults {
+		if (f.Method == "" || f.Method == r.Method) && strings.HasPrefix(r.URL.Path, f.Path) {
+			f.Times--
+			if f.Times <= 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+			return errorf(f.Status, "injected fault")
+		}
+	}
+	return nil
+}
+
+// authenticate resolves a token. The caller holds s.mu.
+func (s *Server) authenticate(id string) (*tokenInfo, *httpError) {
+	if info, ok := s.tokens[id]; ok && time.Now().Before(info.expiresAt) {
+		return info, nil
+	}
+	return nil, errorf(http.StatusUnauthorized, "The request you have made requires authentication.")
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.collections[name]
+	writeJSON(w, http.StatusOK, map[string]any{c.plural: c.list(r.URL.Query())})
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.collections[name]
+	obj, ok := c.items[r.PathValue("id")]
+	if !ok {
+		writeError(w, errorf(http.StatusNotFound, "Could not find %s: %s.", c.key, r.PathValue("id")))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{c.key: obj})
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request, name string) {
+	c := s.collections[name]
+	obj, herr := decodeObject(r, c.key)
+	if herr != nil {
+		writeError(w, herr)
+		return
+	}
+	delete(obj, "id")
+	password, _ := obj["password"].(string)
+	delete(obj, "password")
+	for k, v := range c.defaults {
+		if _, ok := obj[k]; !ok {
+			obj[k] = v
+		}
+	}
+	if name == "endpoints" {
+		if herr := validInterface(obj); herr != nil {
+			writeError(w, herr)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if herr := s.validate(c, "", obj); herr != nil {
+		writeError(w, herr)
+		return
+	}
+	obj = c.insert(obj)
+	if name == "users" && password != "" {
+		s.passwords[obj["id"].(string)] = password
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{c.key: obj})
+}
+
+func (s *Server) update(w http.ResponseWriter, r *http.Request, name string) {
+	c := s.collections[name]
+	patch, herr := decodeObject(r, c.key)
+	if herr != nil {
+		writeError(w, herr)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := r.PathValue("id")
+	existing, ok := c.items[id]
+	if !ok {
+		writeError(w, errorf(http.StatusNotFound, "Could not find %s: %s.", c.key, id))
+		return
+	}
+	if v, ok := patch["id"]; ok && v != id {
+		writeError(w, errorf(http.StatusBadRequest, "Cannot change ID"))
+		return
+	}
+	for _, field := range c.immutable {
+		if v, ok := patch[field]; ok && v != existing[field] {
+			writeError(w, errorf(http.StatusBadRequest, "Cannot change %s", field))
+			return
+		}
+	}
+
+	updated := make(map[string]any, len(existing)+len(patch))
+	for k, v := range existing {
+		updated[k] = v
+	}
+	password, hasPassword := patch["password"].(string)
+	delete(patch, "password")
+	for k, v := range patch {
+		updated[k] = v
+	}
+	if name == "endpoints" {
+		if herr := validInterface(updated); herr != nil {
+			writeError(w, herr)
+			return
+		}
+	}
+	if herr := s.validate(c, id, updated); herr != nil {
+		writeError(w, herr)
+		return
+	}
+	c.put(id, updated)
+	if name == "users" && hasPassword {
+		s.passwords[id] = password
+	}
+	writeJSON(w, http.StatusOK, map[string]any{c.key: updated})
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.collections[name]
+	id := r.PathValue("id")
+	obj, ok := c.items[id]
+	if !ok {
+		writeError(w, errorf(http.StatusNotFound, "Could not find %s: %s.", c.key, id))
+		return
+	}
+	if name == "domains" && obj["enabled"] == true {
+		writeError(w, errorf(http.StatusForbidden, "Cannot delete a domain that is enabled, please disable it first."))
+		return
+	}
+	s.cascadeDelete(name, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// cascadeDelete removes the object id of the collection name and everything
+// that depends on it. The caller holds s.mu.
+func (s *Server) cascadeDelete(name, id string) {
+	s.collections[name].remove(id)
+	switch name {
+	case "domains":
+		for _, dependent := range []string{"projects", "users", "roles"} {
+			for _, obj := range s.collections[dependent].list(map[string][]string{"domain_id": {id}}) {
+				s.cascadeDelete(dependent, obj["id"].(string))
+			}
+		}
+	case "services":
+		for _, obj := range s.collections["endpoints"].list(map[string][]string{"service_id": {id}}) {
+			s.collections["endpoints"].remove(obj["id"].(string))
+		}
+	case "users":
+		delete(s.passwords, id)
+		for credID, cred := range s.appCreds {
+			if cred.userID == id {
+				delete(s.appCreds, credID)
+			}
+		}
+	}
+	for key := range s.assignments {
+		parts := strings.Split(key, "/")
+		if (name == "projects" || name == "domains") && parts[0] == name && parts[1] == id ||
+			name == "users" && parts[2] == id || name == "roles" && parts[3] == id {
+			delete(s.assignments, key)
+		}
+	}
+}
+
+func (s *Server) assignment(w http.ResponseWriter, r *http.Request, scope string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	target, user, role := r.PathValue("target"), r.PathValue("user"), r.PathValue("role")
+	for _, ref := range []struct{ collection, id string }{{scope, target}, {"users", user}, {"roles", role}} {
+		if _, ok := s.collections[ref.collection].items[ref.id]; !ok {
+			writeError(w, errorf(http.StatusNotFound, "Could not find %s: %s.", s.collections[ref.collection].key, ref.id))
+			return
+		}
+	}
+
+	key := assignmentKey(scope, target, user, role)
+	switch r.Method {
+	case http.MethodPut:
+		s.assignments[key] = true
+	case http.MethodHead, http.MethodDelete:
+		if !s.assignments[key] {
+			writeError(w, errorf(http.StatusNotFound, "Could not find role assignment."))
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(s.assignments, key)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// rolesOn returns the roles userID has on the project or domain target. The
+// caller holds s.mu.
+func (s *Server) rolesOn(scope, target, userID string) []map[string]any {
+	roles := []map[string]any{}
+	for _, id := range s.collections["roles"].order {
+		if s.assignments[assignmentKey(scope, target, userID, id)] {
+			role := s.collections["roles"].items[id]
+			roles = append(roles, map[string]any{"id": id, "name": role["name"]})
+		}
+	}
+	return roles
+}
+
+func validInterface(obj map[string]any) *httpError {
+	switch obj["interface"] {
+	case keystoneclient.InterfacePublic, keystoneclient.InterfaceInternal, keystoneclient.InterfaceAdmin:
+		return nil
+	}
+	return errorf(http.StatusBadRequest, "Invalid interface %v", obj["interface"])
+}
+
+// decodeJSON decodes the request body into a generic map.
+func decodeJSON(r *http.Request) (map[string]any, *httpError) {
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, errorf(http.StatusBadRequest, "invalid JSON: %v", err)
+	}
+	return body, nil
+}
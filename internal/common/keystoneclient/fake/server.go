@@ -0,0 +1,226 @@
+package fake
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/c5c3/forge/internal/common/keystoneclient"
+)
+
+// Bootstrap credentials of the admin user.
+const (
+	AdminUsername = "admin"
+	AdminPassword = "admin-password"
+	AdminProject  = "admin"
+	AdminRole     = "admin"
+)
+
+// DefaultTokenTTL is the lifetime of issued tokens unless Options.TokenTTL
+// is set.
+const DefaultTokenTTL = time.Hour
+
+// Options configures a Server.
+type Options struct {
+	// TokenTTL is the lifetime of issued tokens.
+	TokenTTL time.Duration
+}
+
+// Fault makes the server answer matching requests with an error instead of
+// handling them.
+type Fault struct {
+	// Method matches the request method; empty matches all methods.
+	Method string
+	// Path matches requests whose URL path starts with it, e.g.
+	// "/v3/projects".
+	Path string
+	// Status is the returned HTTP status.
+	Status int
+	// Times is the number of requests that fail; it defaults to 1.
+	Times int
+}
+
+// Server is an in-memory Keystone. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	tokenTTL time.Duration
+
+	mu          sync.Mutex
+	collections map[string]*collection
+	passwords   map[string]string
+	assignments map[string]bool
+	appCreds    map[string]*appCred
+	tokens      map[string]*tokenInfo
+	faults      []*Fault
+	requests    []string
+	adminUserID string
+}
+
+// appCred is a stored application credential. Its secret is kept outside the
+// returned object.
+type appCred struct {
+	obj    map[string]any
+	userID string
+	secret string
+}
+
+// tokenInfo is the state behind an issued token.
+type tokenInfo struct {
+	userID    string
+	projectID string
+	domainID  string
+	methods   []string
+	issuedAt  time.Time
+	expiresAt time.Time
+}
+
+// NewServer starts a bootstrapped Server. Close it when done.
+func NewServer(opts Options) *Server {
+	s := &Server{
+		tokenTTL:    opts.TokenTTL,
+		passwords:   map[string]string{},
+		assignments: map[string]bool{},
+		appCreds:    map[string]*appCred{},
+		tokens:      map[string]*tokenInfo{},
+	}
+	if s.tokenTTL <= 0 {
+		s.tokenTTL = DefaultTokenTTL
+	}
+	s.collections = s.newCollections()
+	s.bootstrap()
+	s.Server = httptest.NewServer(s.handler())
+	return s
+}
+
+// bootstrap creates the objects keystone-manage bootstrap creates.
+func (s *Server) bootstrap() {
+	domains := s.collections["domains"]
+	domains.put(keystoneclient.DefaultDomainID, map[string]any{
+		"id": keystoneclient.DefaultDomainID, "name": "Default", "description": "The default domain", "enabled": true,
+	})
+	project := s.collections["projects"].insert(map[string]any{
+		"name": AdminProject, "domain_id": keystoneclient.DefaultDomainID, "enabled": true,
+	})
+	var adminRoleID string
+	for _, name := range []string{"reader", "member", AdminRole} {
+		role := s.collections["roles"].insert(map[string]any{"name": name})
+		adminRoleID = role["id"].(string)
+	}
+	user := s.collections["users"].insert(map[string]any{
+		"name": AdminUsername, "domain_id": keystoneclient.DefaultDomainID, "enabled": true,
+	})
+	s.adminUserID = user["id"].(string)
+	s.passwords[s.adminUserID] = AdminPassword
+	s.assignments[assignmentKey("projects", project["id"].(string), s.adminUserID, adminRoleID)] = true
+}
+
+// AdminAuth returns the credentials of the bootstrap admin user, scoped to
+// the admin project.
+func (s *Server) AdminAuth() keystoneclient.AuthOptions {
+	return keystoneclient.AuthOptions{
+		Username:    AdminUsername,
+		Password:    AdminPassword,
+		ProjectName: AdminProject,
+	}
+}
+
+// AdminUserID returns the ID of the bootstrap admin user.
+func (s *Server) AdminUserID() string {
+	return s.adminUserID
+}
+
+// Fail injects f.
+func (s *Server) Fail(f Fault) {
+	if f.Times <= 0 {
+		f.Times = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// RevokeTokens invalidates all issued tokens, like a fernet key rotation
+// without the previous keys.
+func (s *Server) RevokeTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = map[string]*tokenInfo{}
+}
+
+// Requests returns the received requests as "METHOD /path", oldest first.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// CountRequests returns how many requests with method had a path starting
+// with path.
+func (s *Server) CountRequests(method, path string) int {
+	n := 0
+	for _, r := range s.Requests() {
+		m, p, _ := strings.Cut(r, " ")
+		if m == method && strings.HasPrefix(p, path) {
+			n++
+		}
+	}
+	return n
+}
+
+// httpError is an error response.
+type httpError struct {
+	status  int
+	message string
+}
+
+func errorf(status int, format string, args ...any) *httpError {
+	return &httpError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+func writeError(w http.ResponseWriter, err *httpError) {
+	writeJSON(w, err.status, map[string]any{"error": map[string]any{
+		"code":    err.status,
+		"title":   http.StatusText(err.status),
+		"message": err.message,
+	}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// decodeObject decodes a request body of the form {"<key>": {...}}.
+func decodeObject(r *http.Request, key string) (map[string]any, *httpError) {
+	var body map[string]map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, errorf(http.StatusBadRequest, "invalid JSON: %v", err)
+	}
+	obj, ok := body[key]
+	if !ok || obj == nil {
+		return nil, errorf(http.StatusBadRequest, "'%s' is a required property", key)
+	}
+	return obj, nil
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func assignmentKey(scope, target, userID, roleID string) string {
+	return scope + "/" + target + "/" + userID + "/" + roleID
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000000Z")
+}
@@ -0,0 +1,267 @@
+package fake
+
+import (
+	"net/http"
+	"time"
+)
+
+// str returns m[key] if it is a string.
+func str(m map[string]any, key string) string {
+	v, _ := m[key].(string)
+	return v
+}
+
+// object returns m[key] if it is an object, or an empty map.
+func object(m map[string]any, key string) map[string]any {
+	v, ok := m[key].(map[string]any)
+	if !ok {
+		return map[string]any{}
+	}
+	return v
+}
+
+func (s *Server) issueToken(w http.ResponseWriter, r *http.Request) {
+	body, herr := decodeJSON(r)
+	if herr != nil {
+		writeError(w, herr)
+		return
+	}
+	auth := object(body, "auth")
+	identity := object(auth, "identity")
+	methods, _ := identity["methods"].([]any)
+	if len(methods) != 1 {
+		writeError(w, errorf(http.StatusBadRequest, "exactly one authentication method is supported"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info := &tokenInfo{issuedAt: time.Now()}
+	info.expiresAt = info.issuedAt.Add(s.tokenTTL)
+	switch methods[0] {
+	case "password":
+		info.methods = []string{"password"}
+		user := object(object(identity, "password"), "user")
+		if info.userID, herr = s.findUser(user); herr == nil && s.passwords[info.userID] != str(user, "password") {
+			herr = unauthorized()
+		}
+		if herr == nil {
+			info.projectID, info.domainID, herr = s.resolveScope(object(auth, "scope"))
+		}
+	case "application_credential":
+		info.methods = []string{"application_credential"}
+		var cred *appCred
+		if cred, herr = s.findAppCred(object(identity, "application_credential")); herr == nil {
+			info.userID = cred.userID
+			info.projectID = str(cred.obj, "project_id")
+		}
+	default:
+		herr = errorf(http.StatusBadRequest, "unsupported authentication method %v", methods[0])
+	}
+	if herr == nil {
+		herr = s.checkEnabled(info)
+	}
+	if herr != nil {
+		writeError(w, herr)
+		return
+	}
+
+	id := newID()
+	s.tokens[id] = info
+	w.Header().Set("X-Subject-Token", id)
+	writeJSON(w, http.StatusCreated, map[string]any{"token": s.tokenBody(info, !r.URL.Query().Has("nocatalog"))})
+}
+
+func (s *Server) validateToken(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info, herr := s.authenticate(r.Header.Get("X-Subject-Token"))
+	if herr != nil {
+		writeError(w, errorf(http.StatusNotFound, "Failed to validate token"))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"token": s.tokenBody(info, !r.URL.Query().Has("nocatalog"))})
+}
+
+func (s *Server) getCatalog(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{"catalog": s.catalog()})
+}
+
+func unauthorized() *httpError {
+	return errorf(http.StatusUnauthorized, "The request you have made requires authentication.")
+}
+
+// findDomain resolves a {"id"} or {"name"} domain reference. The caller
+// holds s.mu.
+func (s *Server) findDomain(ref map[string]any) (string, bool) {
+	domains := s.collections["domains"]
+	if id := str(ref, "id"); id != "" {
+		_, ok := domains.items[id]
+		return id, ok
+	}
+	for _, d := range domains.list(map[string][]string{"name": {str(ref, "name")}}) {
+		return d["id"].(string), true
+	}
+	return "", false
+}
+
+// findNamed resolves a reference by ID, or by name and domain, in the
+// collection name. The caller holds s.mu.
+func (s *Server) findNamed(name string, ref map[string]any) (string, bool) {
+	c := s.collections[name]
+	if id := str(ref, "id"); id != "" {
+		_, ok := c.items[id]
+		return id, ok
+	}
+	domainID, ok := s.findDomain(object(ref, "domain"))
+	if !ok {
+		return "", false
+	}
+	for _, o := range c.list(map[string][]string{"name": {str(ref, "name")}, "domain_id": {domainID}}) {
+		return o["id"].(string), true
+	}
+	return "", false
+}
+
+// findUser resolves the user of a token request. The caller holds s.mu.
+func (s *Server) findUser(user map[string]any) (string, *httpError) {
+	id, ok := s.findNamed("users", user)
+	if !ok {
+		return "", unauthorized()
+	}
+	return id, nil
+}
+
+// resolveScope resolves the scope of a password token request. The caller
+// holds s.mu.
+func (s *Server) resolveScope(scope map[string]any) (string, string, *httpError) {
+	if project, ok := scope["project"].(map[string]any); ok {
+		id, found := s.findNamed("projects", project)
+		if !found {
+			return "", "", unauthorized()
+		}
+		return id, "", nil
+	}
+	if domain, ok := scope["domain"].(map[string]any); ok {
+		id, found := s.findDomain(domain)
+		if !found {
+			return "", "", unauthorized()
+		}
+		return "", id, nil
+	}
+	return "", "", nil
+}
+
+// findAppCred resolves and checks the application credential of a token
+// request. The caller holds s.mu.
+func (s *Server) findAppCred(ref map[string]any) (*appCred, *httpError) {
+	var cred *appCred
+	if id := str(ref, "id"); id != "" {
+		cred = s.appCreds[id]
+	} else if userID, herr := s.findUser(object(ref, "user")); herr == nil {
+		for _, c := range s.appCreds {
+			if c.userID == userID && c.obj["name"] == str(ref, "name") {
+				cred = c
+			}
+		}
+	}
+	if cred == nil || cred.secret != str(ref, "secret") {
+		return nil, unauthorized()
+	}
+	if expires := str(cred.obj, "expires_at"); expires != "" {
+		if t, err := time.Parse("2006-01-02T15:04:05.000000Z", expires); err == nil && time.Now().After(t) {
+			return nil, unauthorized()
+		}
+	}
+	return cred, nil
+}
+
+// checkEnabled rejects tokens of disabled users, domains and projects and
+// scopes without a role assignment. The caller holds s.mu.
+func (s *Server) checkEnabled(info *tokenInfo) *httpError {
+	user := s.collections["users"].items[info.userID]
+	if user["enabled"] != true || s.collections["domains"].items[str(user, "domain_id")]["enabled"] != true {
+		return unauthorized()
+	}
+	switch {
+	case info.projectID != "":
+		if s.collections["projects"].items[info.projectID]["enabled"] != true ||
+			len(s.rolesOn("projects", info.projectID, info.userID)) == 0 {
+			return unauthorized()
+		}
+	case info.domainID != "":
+		if s.collections["domains"].items[info.domainID]["enabled"] != true ||
+			len(s.rolesOn("domains", info.domainID, info.userID)) == 0 {
+			return unauthorized()
+		}
+	}
+	return nil
+}
+
+// tokenBody renders the token document of info. The caller holds s.mu.
+func (s *Server) tokenBody(info *tokenInfo, withCatalog bool) map[string]any {
+	user := s.collections["users"].items[info.userID]
+	body := map[string]any{
+		"methods":    info.methods,
+		"issued_at":  formatTime(info.issuedAt),
+		"expires_at": formatTime(info.expiresAt),
+		"user": map[string]any{
+			"id":     info.userID,
+			"name":   user["name"],
+			"domain": s.domainRef(str(user, "domain_id")),
+		},
+	}
+	switch {
+	case info.projectID != "":
+		project := s.collections["projects"].items[info.projectID]
+		body["project"] = map[string]any{
+			"id":     info.projectID,
+			"name":   project["name"],
+			"domain": s.domainRef(str(project, "domain_id")),
+		}
+		body["roles"] = s.rolesOn("projects", info.projectID, info.userID)
+	case info.domainID != "":
+		body["domain"] = s.domainRef(info.domainID)
+		body["roles"] = s.rolesOn("domains", info.domainID, info.userID)
+	}
+	if withCatalog {
+		body["catalog"] = s.catalog()
+	}
+	return body
+}
+
+func (s *Server) domainRef(id string) map[string]any {
+	return map[string]any{"id": id, "name": s.collections["domains"].items[id]["name"]}
+}
+
+// catalog renders the service catalog of the enabled services and
+// endpoints. The caller holds s.mu.
+func (s *Server) catalog() []map[string]any {
+	catalog := []map[string]any{}
+	for _, service := range s.collections["services"].list(nil) {
+		if service["enabled"] != true {
+			continue
+		}
+		endpoints := []map[string]any{}
+		for _, e := range s.collections["endpoints"].list(map[string][]string{"service_id": {str(service, "id")}}) {
+			if e["enabled"] != true {
+				continue
+			}
+			endpoints = append(endpoints, map[string]any{
+				"id":        e["id"],
+				"interface": e["interface"],
+				"region_id": e["region_id"],
+				"url":       e["url"],
+			})
+		}
+		catalog = append(catalog, map[string]any{
+			"id":        service["id"],
+			"type":      service["type"],
+			"name":      service["name"],
+			"endpoints": endpoints,
+		})
+	}
+	return catalog
+}
@@ -0,0 +1,245 @@
+package keystoneclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Domain is a Keystone domain. Fields left empty in an update are not
+// changed.
+type Domain struct {
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Enabled     *bool  `json:"enabled,omitempty"`
+}
+
+// Project is a Keystone project. Fields left empty in an update are not
+// changed.
+type Project struct {
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	DomainID    string `json:"domain_id,omitempty"`
+	ParentID    string `json:"parent_id,omitempty"`
+	Description string `json:"description,omitempty"`
+	Enabled     *bool  `json:"enabled,omitempty"`
+}
+
+// User is a Keystone user. Password is only sent, never returned. Fields left
+// empty in an update are not changed.
+type User struct {
+	ID               string `json:"id,omitempty"`
+	Name             string `json:"name,omitempty"`
+	DomainID         string `json:"domain_id,omitempty"`
+	DefaultProjectID string `json:"default_project_id,omitempty"`
+	Description      string `json:"description,omitempty"`
+	Password         string `json:"password,omitempty"`
+	Enabled          *bool  `json:"enabled,omitempty"`
+}
+
+// Role is a Keystone role. Global roles have no DomainID.
+type Role struct {
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	DomainID    string `json:"domain_id,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// CreateDomain creates a domain.
+func (c *Client) CreateDomain(ctx context.Context, domain Domain) (*Domain, error) {
+	return createResource(ctx, c, "/domains", "domain", domain)
+}
+
+// GetDomain returns the domain with the given ID.
+func (c *Client) GetDomain(ctx context.Context, id string) (*Domain, error) {
+	return getResource[Domain](ctx, c, "/domains/"+url.PathEscape(id), "domain")
+}
+
+// ListDomains lists the domains matching opts. DomainID is ignored.
+func (c *Client) ListDomains(ctx context.Context, opts ListOptions) ([]Domain, error) {
+	opts.DomainID = ""
+	return listResources[Domain](ctx, c, "/domains", "domains", opts.query())
+}
+
+// DomainByName returns the domain named name.
+func (c *Client) DomainByName(ctx context.Context, name string) (*Domain, error) {
+	domains, err := c.ListDomains(ctx, ListOptions{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	return single(domains, "domain", name)
+}
+
+// UpdateDomain changes the non-empty fields of update on the domain id.
+func (c *Client) UpdateDomain(ctx context.Context, id string, update Domain) (*Domain, error) {
+	update.ID = ""
+	return updateResource(ctx, c, "/domains/"+url.PathEscape(id), "domain", update)
+}
+
+// DeleteDomain deletes the domain id. Keystone refuses to delete enabled
+// domains; disable it first with UpdateDomain.
+func (c *Client) DeleteDomain(ctx context.Context, id string) error {
+	return deleteResource(ctx, c, "/domains/"+url.PathEscape(id))
+}
+
+// CreateProject creates a project.
+func (c *Client) CreateProject(ctx context.Context, project Project) (*Project, error) {
+	return createResource(ctx, c, "/projects", "project", project)
+}
+
+// GetProject returns the project with the given ID.
+func (c *Client) GetProject(ctx context.Context, id string) (*Project, error) {
+	return getResource[Project](ctx, c, "/projects/"+url.PathEscape(id), "project")
+}
+
+// ListProjects lists the projects matching opts.
+func (c *Client) ListProjects(ctx context.Context, opts ListOptions) ([]Project, error) {
+	return listResources[Project](ctx, c, "/projects", "projects", opts.query())
+}
+
+// ProjectByName returns the project named name in the domain domainID.
+func (c *Client) ProjectByName(ctx context.Context, domainID, name string) (*Project, error) {
+	projects, err := c.ListProjects(ctx, ListOptions{Name: name, DomainID: domainID})
+	if err != nil {
+		return nil, err
+	}
+	return single(projects, "project", name)
+}
+
+// UpdateProject changes the non-empty fields of update on the project id.
+func (c *Client) UpdateProject(ctx context.Context, id string, update Project) (*Project, error) {
+	update.ID = ""
+	return updateResource(ctx, c, "/projects/"+url.PathEscape(id), "project", update)
+}
+
+// DeleteProject deletes the project id.
+func (c *Client) DeleteProject(ctx context.Context, id string) error {
+	return deleteResource(ctx, c, "/projects/"+url.PathEscape(id))
+}
+
+// CreateUser creates a user.
+func (c *Client) CreateUser(ctx context.Context, user User) (*User, error) {
+	return createResource(ctx, c, "/users", "user", user)
+}
+
+// GetUser returns the user with the given ID.
+func (c *Client) GetUser(ctx context.Context, id string) (*User, error) {
+	return getResource[User](ctx, c, "/users/"+url.PathEscape(id), "user")
+}
+
+// ListUsers lists the users matching opts.
+func (c *Client) ListUsers(ctx context.Context, opts ListOptions) ([]User, error) {
+	return listResources[User](ctx, c, "/users", "users", opts.query())
+}
+
+// UserByName returns the user named name in the domain domainID.
+func (c *Client) UserByName(ctx context.Context, domainID, name string) (*User, error) {
+	users, err := c.ListUsers(ctx, ListOptions{Name: name, DomainID: domainID})
+	if err != nil {
+		return nil, err
+	}
+	return single(users, "user", name)
+}
+
+// UpdateUser changes the non-empty fields of update on the user id, e.g. its
+// password.
+func (c *Client) UpdateUser(ctx context.Context, id string, update User) (*User, error) {
+	update.ID = ""
+	return updateResource(ctx, c, "/users/"+url.PathEscape(id), "user", update)
+}
+
+// DeleteUser deletes the user id.
+func (c *Client) DeleteUser(ctx context.Context, id string) error {
+	return deleteResource(ctx, c, "/users/"+url.PathEscape(id))
+}
+
+// CreateRole creates a role.
+func (c *Client) CreateRole(ctx context.Context, role Role) (*Role, error) {
+	return createResource(ctx, c, "/roles", "role", role)
+}
+
+// GetRole returns the role with the given ID.
+func (c *Client) GetRole(ctx context.Context, id string) (*Role, error) {
+	return getResource[Role](ctx, c, "/roles/"+url.PathEscape(id), "role")
+}
+
+// ListRoles lists the roles matching opts.
+func (c *Client) ListRoles(ctx context.Context, opts ListOptions) ([]Role, error) {
+	return listResources[Role](ctx, c, "/roles", "roles", opts.query())
+}
+
+// RoleByName returns the global role named name.
+func (c *Client) RoleByName(ctx context.Context, name string) (*Role, error) {
+	roles, err := c.ListRoles(ctx, ListOptions{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	return single(roles, "role", name)
+}
+
+// UpdateRole changes the non-empty fields of update on the role id.
+func (c *Client) UpdateRole(ctx context.Context, id string, update Role) (*Role, error) {
+	update.ID = ""
+	return updateResource(ctx, c, "/roles/"+url.PathEscape(id), "role", update)
+}
+
+// DeleteRole deletes the role id and all its assignments.
+func (c *Client) DeleteRole(ctx context.Context, id string) error {
+	return deleteResource(ctx, c, "/roles/"+url.PathEscape(id))
+}
+
+// assignmentPath returns the path of the assignment of role to user on the
+// project or domain target, scope being "projects" or "domains".
+func assignmentPath(scope, target, userID, roleID string) string {
+	return "/" + scope + "/" + url.PathEscape(target) + "/users/" + url.PathEscape(userID) + "/roles/" + url.PathEscape(roleID)
+}
+
+// AssignProjectRole grants the role roleID to userID on projectID. Granting
+// an existing assignment succeeds.
+func (c *Client) AssignProjectRole(ctx context.Context, projectID, userID, roleID string) error {
+	return c.assign(ctx, assignmentPath("projects", projectID, userID, roleID))
+}
+
+// HasProjectRole reports whether userID has the role roleID on projectID.
+func (c *Client) HasProjectRole(ctx context.Context, projectID, userID, roleID string) (bool, error) {
+	return c.checkAssignment(ctx, assignmentPath("projects", projectID, userID, roleID))
+}
+
+// RevokeProjectRole removes the role roleID of userID on projectID.
+func (c *Client) RevokeProjectRole(ctx context.Context, projectID, userID, roleID string) error {
+	return deleteResource(ctx, c, assignmentPath("projects", projectID, userID, roleID))
+}
+
+// AssignDomainRole grants the role roleID to userID on domainID. Granting an
+// existing assignment succeeds.
+func (c *Client) AssignDomainRole(ctx context.Context, domainID, userID, roleID string) error {
+	return c.assign(ctx, assignmentPath("domains", domainID, userID, roleID))
+}
+
+// HasDomainRole reports whether userID has the role roleID on domainID.
+func (c *Client) HasDomainRole(ctx context.Context, domainID, userID, roleID string) (bool, error) {
+	return c.checkAssignment(ctx, assignmentPath("domains", domainID, userID, roleID))
+}
+
+// RevokeDomainRole removes the role roleID of userID on domainID.
+func (c *Client) RevokeDomainRole(ctx context.Context, domainID, userID, roleID string) error {
+	return deleteResource(ctx, c, assignmentPath("domains", domainID, userID, roleID))
+}
+
+func (c *Client) assign(ctx context.Context, path string) error {
+	_, err := c.call(ctx, request{method: http.MethodPut, path: path, expect: []int{http.StatusNoContent}}, nil)
+	return err
+}
+
+func (c *Client) checkAssignment(ctx context.Context, path string) (bool, error) {
+	resp, err := c.call(ctx, request{
+		method: http.MethodHead,
+		path:   path,
+		expect: []int{http.StatusNoContent, http.StatusNotFound},
+	}, nil)
+	if err != nil {
+		return false, err
+	}
+	return resp.status == http.StatusNoContent, nil
+}
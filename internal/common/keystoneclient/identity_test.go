@@ -0,0 +1,176 @@
+package keystoneclient_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/c5c3/forge/internal/common/keystoneclient"
+	"github.com/c5c3/forge/internal/common/keystoneclient/fake"
+)
+
+// newClient starts a fake Keystone and returns an admin client for it.
+func newClient(t *testing.T, opts fake.Options) (*keystoneclient.Client, *fake.Server) {
+	t.Helper()
+	srv := fake.NewServer(opts)
+	t.Cleanup(srv.Close)
+	c, err := keystoneclient.New(srv.URL, srv.AdminAuth(), keystoneclient.Options{
+		HTTPClient: srv.Client(),
+		Retry:      &keystoneclient.RetryPolicy{MaxAttempts: 3},
+	})
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+	return c, srv
+}
+
+func TestToken(t *testing.T) {
+	g := NewGomegaWithT(t)
+	c, srv := newClient(t, fake.Options{})
+
+	token, err := c.Token(t.Context())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(token.User.Name).To(Equal(fake.AdminUsername))
+	g.Expect(token.Project).NotTo(BeNil())
+	g.Expect(token.Project.Name).To(Equal(fake.AdminProject))
+	g.Expect(token.Roles).To(ContainElement(HaveField("Name", fake.AdminRole)))
+
+	validated, err := c.ValidateToken(t.Context(), token.ID)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(validated.User.ID).To(Equal(srv.AdminUserID()))
+
+	_, err = c.ValidateToken(t.Context(), "bogus")
+	g.Expect(keystoneclient.IsNotFound(err)).To(BeTrue())
+
+	// After all tokens were revoked the client authenticates again.
+	srv.RevokeTokens()
+	_, err = c.ListDomains(t.Context(), keystoneclient.ListOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(srv.CountRequests(http.MethodPost, "/v3/auth/tokens")).To(Equal(2))
+}
+
+func TestToken_WrongPassword(t *testing.T) {
+	g := NewGomegaWithT(t)
+	srv := fake.NewServer(fake.Options{})
+	defer srv.Close()
+
+	auth := srv.AdminAuth()
+	auth.Password = "wrong"
+	c, err := keystoneclient.New(srv.URL, auth, keystoneclient.Options{HTTPClient: srv.Client()})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	_, err = c.Token(t.Context())
+	g.Expect(errors.Is(err, keystoneclient.ErrUnauthorized)).To(BeTrue())
+}
+
+func TestDomainsProjectsUsers(t *testing.T) {
+	g := NewGomegaWithT(t)
+	c, _ := newClient(t, fake.Options{})
+	ctx := t.Context()
+
+	domain, err := c.CreateDomain(ctx, keystoneclient.Domain{Name: "tenant"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(domain.Enabled).To(HaveValue(BeTrue()))
+	_, err = c.CreateDomain(ctx, keystoneclient.Domain{Name: "tenant"})
+	g.Expect(keystoneclient.IsConflict(err)).To(BeTrue())
+
+	project, err := c.CreateProject(ctx, keystoneclient.Project{Name: "service", DomainID: domain.ID})
+	g.Expect(err).NotTo(HaveOccurred())
+	found, err := c.ProjectByName(ctx, domain.ID, "service")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(found.ID).To(Equal(project.ID))
+	_, err = c.ProjectByName(ctx, keystoneclient.DefaultDomainID, "service")
+	g.Expect(keystoneclient.IsNotFound(err)).To(BeTrue())
+
+	updated, err := c.UpdateProject(ctx, project.ID, keystoneclient.Project{Description: "Service project"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated.Name).To(Equal("service"))
+	g.Expect(updated.Description).To(Equal("Service project"))
+
+	_, err = c.CreateProject(ctx, keystoneclient.Project{Name: "orphan", DomainID: "missing"})
+	g.Expect(errors.Is(err, keystoneclient.ErrBadRequest)).To(BeTrue())
+
+	user, err := c.CreateUser(ctx, keystoneclient.User{Name: "glance", DomainID: domain.ID, Password: "pw"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(user.Password).To(BeEmpty())
+	byName, err := c.UserByName(ctx, domain.ID, "glance")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(byName.ID).To(Equal(user.ID))
+
+	// Enabled domains cannot be deleted.
+	err = c.DeleteDomain(ctx, domain.ID)
+	g.Expect(errors.Is(err, keystoneclient.ErrForbidden)).To(BeTrue())
+	_, err = c.UpdateDomain(ctx, domain.ID, keystoneclient.Domain{Enabled: keystoneclient.Bool(false)})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(c.DeleteDomain(ctx, domain.ID)).To(Succeed())
+
+	// Deleting the domain deleted its projects and users.
+	_, err = c.GetProject(ctx, project.ID)
+	g.Expect(keystoneclient.IsNotFound(err)).To(BeTrue())
+	_, err = c.GetUser(ctx, user.ID)
+	g.Expect(keystoneclient.IsNotFound(err)).To(BeTrue())
+}
+
+func TestRoleAssignments(t *testing.T) {
+	g := NewGomegaWithT(t)
+	c, srv := newClient(t, fake.Options{})
+	ctx := t.Context()
+
+	project, err := c.CreateProject(ctx, keystoneclient.Project{Name: "service"})
+	g.Expect(err).NotTo(HaveOccurred())
+	user, err := c.CreateUser(ctx, keystoneclient.User{Name: "nova", Password: "nova-pw"})
+	g.Expect(err).NotTo(HaveOccurred())
+	role, err := c.RoleByName(ctx, "member")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	has, err := c.HasProjectRole(ctx, project.ID, user.ID, role.ID)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(has).To(BeFalse())
+
+	g.Expect(c.AssignProjectRole(ctx, project.ID, user.ID, role.ID)).To(Succeed())
+	g.Expect(c.AssignProjectRole(ctx, project.ID, user.ID, role.ID)).To(Succeed())
+	has, err = c.HasProjectRole(ctx, project.ID, user.ID, role.ID)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(has).To(BeTrue())
+
+	// The user can now authenticate scoped to the project.
+	userClient, err := keystoneclient.New(srv.URL, keystoneclient.AuthOptions{
+		Username: "nova", Password: "nova-pw", ProjectID: project.ID,
+	}, keystoneclient.Options{HTTPClient: srv.Client()})
+	g.Expect(err).NotTo(HaveOccurred())
+	token, err := userClient.Token(ctx)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(token.Project.ID).To(Equal(project.ID))
+
+	g.Expect(c.RevokeProjectRole(ctx, project.ID, user.ID, role.ID)).To(Succeed())
+	err = c.RevokeProjectRole(ctx, project.ID, user.ID, role.ID)
+	g.Expect(keystoneclient.IsNotFound(err)).To(BeTrue())
+	_, err = userClient.IssueToken(ctx)
+	g.Expect(errors.Is(err, keystoneclient.ErrUnauthorized)).To(BeTrue())
+
+	g.Expect(c.AssignDomainRole(ctx, keystoneclient.DefaultDomainID, user.ID, role.ID)).To(Succeed())
+	has, err = c.HasDomainRole(ctx, keystoneclient.DefaultDomainID, user.ID, role.ID)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(has).To(BeTrue())
+
+	err = c.AssignProjectRole(ctx, "missing", user.ID, role.ID)
+	g.Expect(keystoneclient.IsNotFound(err)).To(BeTrue())
+}
+
+func TestRetriesInjectedFaults(t *testing.T) {
+	g := NewGomegaWithT(t)
+	c, srv := newClient(t, fake.Options{})
+
+	srv.Fail(fake.Fault{Method: http.MethodGet, Path: "/v3/roles", Status: http.StatusServiceUnavailable, Times: 2})
+	_, err := c.ListRoles(t.Context(), keystoneclient.ListOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(srv.CountRequests(http.MethodGet, "/v3/roles")).To(Equal(3))
+
+	srv.Fail(fake.Fault{Method: http.MethodGet, Path: "/v3/roles", Status: http.StatusServiceUnavailable, Times: 3})
+	_, err = c.ListRoles(t.Context(), keystoneclient.ListOptions{})
+	var apiErr *keystoneclient.APIError
+	g.Expect(errors.As(err, &apiErr)).To(BeTrue())
+	g.Expect(apiErr.StatusCode).To(Equal(http.StatusServiceUnavailable))
+}
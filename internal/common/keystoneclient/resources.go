@@ -0,0 +1,111 @@
+package keystoneclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Keystone wraps single objects as {"<key>": {...}} and lists as
+// {"<key>s": [...]}. The helpers below handle the envelopes.
+
+func getResource[T any](ctx context.Context, c *Client, path, key string) (*T, error) {
+	var out map[string]json.RawMessage
+	if _, err := c.call(ctx, request{method: http.MethodGet, path: path, expect: []int{http.StatusOK}}, &out); err != nil {
+		return nil, err
+	}
+	return unwrap[T](out, key)
+}
+
+func listResources[T any](ctx context.Context, c *Client, path, key string, query url.Values) ([]T, error) {
+	var out map[string]json.RawMessage
+	if _, err := c.call(ctx, request{method: http.MethodGet, path: path, query: query, expect: []int{http.StatusOK}}, &out); err != nil {
+		return nil, err
+	}
+	items := []T{}
+	if raw, ok := out[key]; ok {
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, fmt.Errorf("keystone: decoding %s: %w", key, err)
+		}
+	}
+	return items, nil
+}
+
+func createResource[T any](ctx context.Context, c *Client, path, key string, obj T) (*T, error) {
+	var out map[string]json.RawMessage
+	if _, err := c.call(ctx, request{
+		method: http.MethodPost,
+		path:   path,
+		body:   map[string]any{key: obj},
+		expect: []int{http.StatusCreated},
+	}, &out); err != nil {
+		return nil, err
+	}
+	return unwrap[T](out, key)
+}
+
+func updateResource[T any](ctx context.Context, c *Client, path, key string, obj T) (*T, error) {
+	var out map[string]json.RawMessage
+	if _, err := c.call(ctx, request{
+		method: http.MethodPatch,
+		path:   path,
+		body:   map[string]any{key: obj},
+		expect: []int{http.StatusOK},
+	}, &out); err != nil {
+		return nil, err
+	}
+	return unwrap[T](out, key)
+}
+
+func deleteResource(ctx context.Context, c *Client, path string) error {
+	_, err := c.call(ctx, request{method: http.MethodDelete, path: path, expect: []int{http.StatusNoContent}}, nil)
+	return err
+}
+
+func unwrap[T any](out map[string]json.RawMessage, key string) (*T, error) {
+	raw, ok := out[key]
+	if !ok {
+		return nil, fmt.Errorf("keystone: response has no %q object", key)
+	}
+	obj := new(T)
+	if err := json.Unmarshal(raw, obj); err != nil {
+		return nil, fmt.Errorf("keystone: decoding %s: %w", key, err)
+	}
+	return obj, nil
+}
+
+// single returns the only element of items, a not-found error if there is
+// none, and a conflict error if the name is ambiguous.
+func single[T any](items []T, kind, name string) (*T, error) {
+	switch len(items) {
+	case 0:
+		return nil, notFound(kind, name)
+	case 1:
+		return &items[0], nil
+	default:
+		return nil, &APIError{
+			Method:     http.MethodGet,
+			StatusCode: http.StatusConflict,
+			Message:    fmt.Sprintf("%d objects of kind %s are named %q", len(items), kind, name),
+		}
+	}
+}
+
+// ListOptions filter list requests. Empty fields do not filter.
+type ListOptions struct {
+	Name     string
+	DomainID string
+}
+
+func (o ListOptions) query() url.Values {
+	q := url.Values{}
+	if o.Name != "" {
+		q.Set("name", o.Name)
+	}
+	if o.DomainID != "" {
+		q.Set("domain_id", o.DomainID)
+	}
+	return q
+}
@@ -0,0 +1,84 @@
+package keystoneclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how transient failures are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts per request, including
+	// the first. Values below 1 mean a single attempt.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. It doubles with
+	// every further retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts, including delays requested
+	// by Keystone via Retry-After.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is used when Options.Retry is nil.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 250 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+}
+
+// NoRetry makes every request a single attempt.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// backoff returns the delay before retry number retry (starting at 1). A
+// Retry-After header given in seconds takes precedence.
+func (p RetryPolicy) backoff(retry int, header http.Header) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if header != nil {
+		if s, err := strconv.Atoi(header.Get("Retry-After")); err == nil && s >= 0 {
+			d = time.Duration(s) * time.Second
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// retryable reports whether a request with method that ended with status or
+// transport error err may be retried. Non-idempotent POST requests are only
+// retried if Keystone refused them before processing.
+func retryable(method string, status int, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		return method != http.MethodPost
+	}
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return method != http.MethodPost
+	}
+	return false
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
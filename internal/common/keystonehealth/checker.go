@@ -0,0 +1,207 @@
+package keystonehealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/c5c3/forge/internal/common/keystoneclient"
+	"github.com/c5c3/forge/internal/common/operatorconfig"
+)
+
+// DefaultDomain is the Keystone domain the bootstrap admin user and project
+// are created in.
+const DefaultDomain = "Default"
+
+// Credentials authenticate the health check against Keystone, normally the
+// bootstrap admin user.
+type Credentials struct {
+	Username string
+	Password string
+	// UserDomain defaults to DefaultDomain.
+	UserDomain string
+	// Project is the project the token is scoped to. An unscoped token is
+	// requested if it is empty; Keystone then returns an empty catalog.
+	Project string
+	// ProjectDomain defaults to DefaultDomain.
+	ProjectDomain string
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	// Healthy is true if a token was issued and the catalog listed.
+	Healthy bool
+	// Reason is one of the Reason* condition reasons.
+	Reason string
+	// Message describes the outcome for humans.
+	Message string
+	// TokenLatency and CatalogLatency are the durations of the two requests.
+	// CatalogLatency is zero if the token request failed.
+	TokenLatency   time.Duration
+	CatalogLatency time.Duration
+	// CatalogEntries is the number of services in the catalog.
+	CatalogEntries int
+	// CheckedAt is when the check started.
+	CheckedAt time.Time
+}
+
+// Latency is the total duration of the check.
+func (r Result) Latency() time.Duration {
+	return r.TokenLatency + r.CatalogLatency
+}
+
+// Checker runs health checks against Keystone APIs and remembers the last
+// result of every checked object. It is safe for concurrent use.
+type Checker struct {
+	httpClient *http.Client
+	interval   time.Duration
+	timeout    time.Duration
+	now        func() time.Time
+
+	mu   sync.Mutex
+	last map[types.NamespacedName]Result
+}
+
+// NewChecker returns a Checker with the interval and timeout of cfg. If
+// httpClient is nil, http.DefaultClient is used; pass a client with the
+// Keystone CA in its TLS configuration for HTTPS endpoints.
+func NewChecker(cfg operatorconfig.APIHealthCheckConfiguration, httpClient *http.Client) *Checker {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Checker{
+		httpClient: httpClient,
+		interval:   cfg.Interval.Duration,
+		timeout:    cfg.Timeout.Duration,
+		now:        time.Now,
+		last:       map[types.NamespacedName]Result{},
+	}
+}
+
+// ServiceURL returns the in-cluster URL of a Keystone Service, e.g.
+// http://keystone-api.openstack.svc:5000.
+func ServiceURL(scheme, service, namespace string, port int32) string {
+	return fmt.Sprintf("%s://%s.%s.svc:%d", scheme, service, namespace, port)
+}
+
+// Check returns the health of the Keystone API of the object identified by
+// key, served at endpoint. It contacts the API if the previous check is older
+// than the interval and otherwise returns the previous result. The metrics
+// are updated whenever the API is contacted.
+func (c *Checker) Check(ctx context.Context, key types.NamespacedName, endpoint string, creds Credentials) Result {
+	c.mu.Lock()
+	last, ok := c.last[key]
+	c.mu.Unlock()
+	if ok && c.now().Sub(last.CheckedAt) < c.interval {
+		return last
+	}
+
+	result := c.check(ctx, endpoint, creds)
+	recordMetrics(key, result)
+
+	c.mu.Lock()
+	c.last[key] = result
+	c.mu.Unlock()
+	return result
+}
+
+// RequeueAfter returns how long the controller should wait before the next
+// check of key is due. It returns the full interval for objects that were not
+// checked yet.
+func (c *Checker) RequeueAfter(key types.NamespacedName) time.Duration {
+	c.mu.Lock()
+	last, ok := c.last[key]
+	c.mu.Unlock()
+	if !ok {
+		return c.interval
+	}
+	if d := last.CheckedAt.Add(c.interval).Sub(c.now()); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// Forget drops the cached result and the metrics of key. Controllers call it
+// when the object is deleted.
+func (c *Checker) Forget(key types.NamespacedName) {
+	c.mu.Lock()
+	delete(c.last, key)
+	c.mu.Unlock()
+	deleteMetrics(key)
+}
+
+// check issues a token and lists the catalog within the timeout. Each check
+// uses a fresh client so that a token cached by an earlier check cannot hide
+// a broken token endpoint.
+func (c *Checker) check(ctx context.Context, endpoint string, creds Credentials) Result {
+	result := Result{CheckedAt: c.now()}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	client, err := keystoneclient.New(endpoint, keystoneclient.AuthOptions{
+		Username:          creds.Username,
+		Password:          creds.Password,
+		UserDomainName:    orDefault(creds.UserDomain),
+		ProjectName:       creds.Project,
+		ProjectDomainName: projectDomain(creds),
+	}, keystoneclient.Options{HTTPClient: c.httpClient, Retry: &keystoneclient.NoRetry})
+	if err != nil {
+		result.Reason, result.Message = ReasonAPIUnreachable, fmt.Sprintf("creating client: %v", err)
+		return result
+	}
+
+	start := c.now()
+	_, err = client.IssueToken(ctx)
+	result.TokenLatency = c.now().Sub(start)
+	if err != nil {
+		result.Reason, result.Message = failure(err, ReasonTokenIssueFailed, "issuing token")
+		return result
+	}
+
+	start = c.now()
+	entries, err := client.Catalog(ctx)
+	result.CatalogLatency = c.now().Sub(start)
+	if err != nil {
+		result.Reason, result.Message = failure(err, ReasonCatalogUnavailable, "listing catalog")
+		return result
+	}
+
+	result.Healthy = true
+	result.Reason = ReasonAPIReady
+	result.CatalogEntries = len(entries)
+	result.Message = fmt.Sprintf("Issued a token and listed %d catalog entries in %s",
+		len(entries), result.Latency().Round(time.Millisecond))
+	return result
+}
+
+// failure maps err to a condition reason and message. Keystone error
+// responses get reason; everything else means the API could not be reached
+// in time.
+func failure(err error, reason, action string) (string, string) {
+	var apiErr *keystoneclient.APIError
+	if !errors.As(err, &apiErr) {
+		reason = ReasonAPIUnreachable
+	}
+	return reason, fmt.Sprintf("%s: %v", action, err)
+}
+
+// projectDomain returns the domain of the scope project. An unscoped token
+// has none.
+func projectDomain(creds Credentials) string {
+	if creds.Project == "" {
+		return ""
+	}
+	return orDefault(creds.ProjectDomain)
+}
+
+func orDefault(domain string) string {
+	if domain == "" {
+		return DefaultDomain
+	}
+	return domain
+}
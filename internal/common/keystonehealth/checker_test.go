@@ -0,0 +1,237 @@
+package keystonehealth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/c5c3/forge/internal/common/operatorconfig"
+)
+
+const testToken = "gAAAAABtest"
+
+// standIn is a minimal Keystone Identity v3 API serving the two requests a
+// health check makes.
+type standIn struct {
+	password      string
+	catalogStatus int
+	delay         time.Duration
+	requests      atomic.Int32
+}
+
+func newStandIn(t *testing.T, s *standIn) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v3/auth/tokens", func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		time.Sleep(s.delay)
+		var req struct {
+			Auth struct {
+				Identity struct {
+					Password struct {
+						User struct {
+							Name     string `json:"name"`
+							Password string `json:"password"`
+							Domain   struct {
+								Name string `json:"name"`
+							} `json:"domain"`
+						} `json:"user"`
+					} `json:"password"`
+				} `json:"identity"`
+			} `json:"auth"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		user := req.Auth.Identity.Password.User
+		if user.Name != "admin" || user.Password != s.password || user.Domain.Name != DefaultDomain {
+			http.Error(w, `{"error":{"code":401,"title":"Unauthorized","message":"The request you have made requires authentication."}}`, http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-Subject-Token", testToken)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"token":{"expires_at":%q}}`, time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+	})
+	mux.HandleFunc("GET /v3/auth/catalog", func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		if r.Header.Get("X-Auth-Token") != testToken {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		if s.catalogStatus != 0 {
+			http.Error(w, "backend unavailable", s.catalogStatus)
+			return
+		}
+		_, _ = w.Write([]byte(`{"catalog":[{"type":"identity","name":"keystone","endpoints":[]}]}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testConfig(interval, timeout time.Duration) operatorconfig.APIHealthCheckConfiguration {
+	return operatorconfig.APIHealthCheckConfiguration{
+		Interval: metav1.Duration{Duration: interval},
+		Timeout:  metav1.Duration{Duration: timeout},
+	}
+}
+
+var adminCreds = Credentials{Username: "admin", Password: "secret", Project: "admin"}
+
+func TestChecker_Check(t *testing.T) {
+	tests := []struct {
+		name        string
+		standIn     *standIn
+		suffix      string
+		creds       Credentials
+		wantHealthy bool
+		wantReason  string
+		wantMessage string
+	}{
+		{
+			name:        "healthy",
+			standIn:     &standIn{password: "secret"},
+			creds:       adminCreds,
+			wantHealthy: true,
+			wantReason:  ReasonAPIReady,
+			wantMessage: "listed 1 catalog entries",
+		},
+		{
+			name:        "endpoint with version suffix",
+			standIn:     &standIn{password: "secret"},
+			suffix:      "/v3/",
+			creds:       adminCreds,
+			wantHealthy: true,
+			wantReason:  ReasonAPIReady,
+		},
+		{
+			name:        "wrong password",
+			standIn:     &standIn{password: "other"},
+			creds:       adminCreds,
+			wantReason:  ReasonTokenIssueFailed,
+			wantMessage: "401 The request you have made requires authentication.",
+		},
+		{
+			name:        "catalog unavailable",
+			standIn:     &standIn{password: "secret", catalogStatus: http.StatusServiceUnavailable},
+			creds:       adminCreds,
+			wantReason:  ReasonCatalogUnavailable,
+			wantMessage: "backend unavailable",
+		},
+		{
+			name:        "timeout",
+			standIn:     &standIn{password: "secret", delay: 200 * time.Millisecond},
+			creds:       adminCreds,
+			wantReason:  ReasonAPIUnreachable,
+			wantMessage: "context deadline exceeded",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			srv := newStandIn(t, tc.standIn)
+			checker := NewChecker(testConfig(time.Minute, 50*time.Millisecond), srv.Client())
+
+			r := checker.Check(t.Context(), types.NamespacedName{Namespace: "check", Name: tc.name}, srv.URL+tc.suffix, tc.creds)
+			g.Expect(r.Healthy).To(Equal(tc.wantHealthy))
+			g.Expect(r.Reason).To(Equal(tc.wantReason))
+			g.Expect(r.Message).To(ContainSubstring(tc.wantMessage))
+			g.Expect(r.Latency()).To(BeNumerically(">", 0))
+			if tc.wantHealthy {
+				g.Expect(r.CatalogEntries).To(Equal(1))
+			}
+		})
+	}
+}
+
+func TestChecker_Unreachable(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	checker := NewChecker(testConfig(time.Minute, time.Second), nil)
+	r := checker.Check(t.Context(), types.NamespacedName{Namespace: "check", Name: "closed"}, srv.URL, adminCreds)
+	g.Expect(r.Healthy).To(BeFalse())
+	g.Expect(r.Reason).To(Equal(ReasonAPIUnreachable))
+}
+
+func TestChecker_CachesWithinInterval(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s := &standIn{password: "secret"}
+	srv := newStandIn(t, s)
+	checker := NewChecker(testConfig(time.Minute, time.Second), srv.Client())
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	checker.now = func() time.Time { return now }
+	key := types.NamespacedName{Namespace: "check", Name: "cache"}
+
+	g.Expect(checker.RequeueAfter(key)).To(Equal(time.Minute))
+
+	first := checker.Check(t.Context(), key, srv.URL, adminCreds)
+	g.Expect(first.Healthy).To(BeTrue())
+	g.Expect(s.requests.Load()).To(Equal(int32(2)))
+
+	now = now.Add(40 * time.Second)
+	g.Expect(checker.Check(t.Context(), key, srv.URL, adminCreds)).To(Equal(first))
+	g.Expect(s.requests.Load()).To(Equal(int32(2)))
+	g.Expect(checker.RequeueAfter(key)).To(Equal(20 * time.Second))
+
+	now = now.Add(20 * time.Second)
+	second := checker.Check(t.Context(), key, srv.URL, adminCreds)
+	g.Expect(second.CheckedAt).To(Equal(now))
+	g.Expect(s.requests.Load()).To(Equal(int32(4)))
+
+	checker.Forget(key)
+	g.Expect(checker.RequeueAfter(key)).To(Equal(time.Minute))
+}
+
+func TestChecker_Metrics(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s := &standIn{password: "secret"}
+	srv := newStandIn(t, s)
+	checker := NewChecker(testConfig(time.Nanosecond, time.Second), srv.Client())
+	key := types.NamespacedName{Namespace: "metrics", Name: "keystone"}
+
+	up := func() float64 {
+		m := &dto.Metric{}
+		g.Expect(upGauge.WithLabelValues(key.Namespace, key.Name).Write(m)).To(Succeed())
+		return m.GetGauge().GetValue()
+	}
+	checks := func(reason string) float64 {
+		m := &dto.Metric{}
+		g.Expect(checksCounter.WithLabelValues(key.Namespace, key.Name, reason).Write(m)).To(Succeed())
+		return m.GetCounter().GetValue()
+	}
+
+	checker.Check(t.Context(), key, srv.URL, adminCreds)
+	g.Expect(up()).To(Equal(1.0))
+	g.Expect(checks(ReasonAPIReady)).To(Equal(1.0))
+
+	m := &dto.Metric{}
+	g.Expect(durationHistogram.WithLabelValues(key.Namespace, key.Name, stageCatalog).(prometheus.Metric).Write(m)).To(Succeed())
+	g.Expect(m.GetHistogram().GetSampleCount()).To(Equal(uint64(1)))
+
+	s.password = "rotated"
+	time.Sleep(time.Millisecond)
+	checker.Check(t.Context(), key, srv.URL, adminCreds)
+	g.Expect(up()).To(Equal(0.0))
+	g.Expect(checks(ReasonTokenIssueFailed)).To(Equal(1.0))
+
+	checker.Forget(key)
+	g.Expect(upGauge.DeleteLabelValues(key.Namespace, key.Name)).To(BeFalse())
+}
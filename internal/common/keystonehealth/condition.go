@@ -0,0 +1,57 @@
+package keystonehealth
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConditionType is the type of the condition reporting the API health.
+	ConditionType = "KeystoneAPIReady"
+	// ReasonAPIReady is the condition reason after a successful check.
+	ReasonAPIReady = "APIReady"
+	// ReasonAPIUnreachable is the condition reason when the API did not
+	// respond within the timeout or the connection failed.
+	ReasonAPIUnreachable = "APIUnreachable"
+	// ReasonTokenIssueFailed is the condition reason when Keystone rejected
+	// the token request, e.g. because the credentials are wrong or the
+	// database is unavailable.
+	ReasonTokenIssueFailed = "TokenIssueFailed"
+	// ReasonCatalogUnavailable is the condition reason when the catalog
+	// could not be listed with the issued token.
+	ReasonCatalogUnavailable = "CatalogUnavailable"
+)
+
+// Object is a CR whose status carries standard metav1 conditions.
+type Object interface {
+	client.Object
+	GetConditions() []metav1.Condition
+	SetConditions([]metav1.Condition)
+}
+
+// Condition returns the KeystoneAPIReady condition for r.
+func (r Result) Condition(observedGeneration int64) metav1.Condition {
+	status := metav1.ConditionFalse
+	if r.Healthy {
+		status = metav1.ConditionTrue
+	}
+	return metav1.Condition{
+		Type:               ConditionType,
+		Status:             status,
+		ObservedGeneration: observedGeneration,
+		Reason:             r.Reason,
+		Message:            r.Message,
+	}
+}
+
+// SetCondition sets the KeystoneAPIReady condition of obj from r and reports
+// whether the conditions changed. Writing the status is left to the caller.
+func SetCondition(obj Object, r Result) bool {
+	conditions := obj.GetConditions()
+	if !meta.SetStatusCondition(&conditions, r.Condition(obj.GetGeneration())) {
+		return false
+	}
+	obj.SetConditions(conditions)
+	return true
+}
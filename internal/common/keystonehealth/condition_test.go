@@ -0,0 +1,63 @@
+package keystonehealth
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// testCR is a minimal CR with status conditions used to exercise SetCondition.
+type testCR struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Conditions        []metav1.Condition
+}
+
+func (o *testCR) GetConditions() []metav1.Condition  { return o.Conditions }
+func (o *testCR) SetConditions(c []metav1.Condition) { o.Conditions = c }
+
+func (o *testCR) DeepCopyObject() runtime.Object {
+	out := *o
+	o.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Conditions = append([]metav1.Condition(nil), o.Conditions...)
+	return &out
+}
+
+func TestSetCondition(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     Result
+		wantStatus metav1.ConditionStatus
+	}{
+		{
+			name:       "healthy",
+			result:     Result{Healthy: true, Reason: ReasonAPIReady, Message: "ok"},
+			wantStatus: metav1.ConditionTrue,
+		},
+		{
+			name:       "unhealthy",
+			result:     Result{Reason: ReasonTokenIssueFailed, Message: "issuing token: keystone: POST http://keystone/v3/auth/tokens: 401 Unauthorized"},
+			wantStatus: metav1.ConditionFalse,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			obj := &testCR{ObjectMeta: metav1.ObjectMeta{Name: "keystone", Generation: 3}}
+			g.Expect(SetCondition(obj, tc.result)).To(BeTrue())
+			g.Expect(SetCondition(obj, tc.result)).To(BeFalse())
+
+			cond := meta.FindStatusCondition(obj.GetConditions(), ConditionType)
+			g.Expect(cond).NotTo(BeNil())
+			g.Expect(cond.Status).To(Equal(tc.wantStatus))
+			g.Expect(cond.Reason).To(Equal(tc.result.Reason))
+			g.Expect(cond.Message).To(Equal(tc.result.Message))
+			g.Expect(cond.ObservedGeneration).To(Equal(int64(3)))
+		})
+	}
+}
@@ -0,0 +1,20 @@
+// Package keystonehealth checks that a deployed Keystone API actually serves
+// requests, beyond its pods being Ready.
+//
+// A check authenticates with the bootstrap admin credentials, which issues a
+// token, and lists the service catalog with that token. The requests are
+// sent once, without retries, through keystoneclient. Both go through the
+// Keystone Service, so a check also covers Service routing and the database
+// and memcached backends behind the API. The outcome is
+// reported as the KeystoneAPIReady condition and as Prometheus metrics on the
+// controller-runtime metrics endpoint:
+//
+//	forge_keystone_api_up{namespace,name}
+//	forge_keystone_api_check_duration_seconds{namespace,name,stage}
+//	forge_keystone_api_checks_total{namespace,name,reason}
+//
+// Controllers share one Checker, call Check on every reconcile and requeue
+// after RequeueAfter. Check only contacts the API once per configured
+// interval and returns the cached result in between, so frequent reconciles
+// do not load Keystone.
+package keystonehealth
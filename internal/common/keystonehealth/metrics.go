@@ -0,0 +1,67 @@
+package keystonehealth
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Stages of a check, used as the stage label of the duration histogram.
+const (
+	stageToken   = "token"
+	stageCatalog = "catalog"
+)
+
+var (
+	// upGauge is 1 while the last check of an API succeeded.
+	upGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "forge_keystone_api_up",
+			Help: "Whether the last health check of a Keystone API succeeded (1) or failed (0).",
+		},
+		[]string{"namespace", "name"},
+	)
+	// durationHistogram observes the latency of each request of a check.
+	durationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "forge_keystone_api_check_duration_seconds",
+			Help:    "Latency of the requests of a Keystone API health check by stage (token, catalog).",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		},
+		[]string{"namespace", "name", "stage"},
+	)
+	// checksCounter counts checks by their condition reason.
+	checksCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "forge_keystone_api_checks_total",
+			Help: "Number of Keystone API health checks by outcome.",
+		},
+		[]string{"namespace", "name", "reason"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(upGauge, durationHistogram, checksCounter)
+}
+
+// recordMetrics publishes the outcome of a check of key.
+func recordMetrics(key types.NamespacedName, r Result) {
+	up := 0.0
+	if r.Healthy {
+		up = 1
+	}
+	upGauge.WithLabelValues(key.Namespace, key.Name).Set(up)
+	durationHistogram.WithLabelValues(key.Namespace, key.Name, stageToken).Observe(r.TokenLatency.Seconds())
+	if r.CatalogLatency > 0 {
+		durationHistogram.WithLabelValues(key.Namespace, key.Name, stageCatalog).Observe(r.CatalogLatency.Seconds())
+	}
+	checksCounter.WithLabelValues(key.Namespace, key.Name, r.Reason).Inc()
+}
+
+// deleteMetrics removes all series of key.
+func deleteMetrics(key types.NamespacedName) {
+	labels := prometheus.Labels{"namespace": key.Namespace, "name": key.Name}
+	upGauge.DeletePartialMatch(labels)
+	durationHistogram.DeletePartialMatch(labels)
+	checksCounter.DeletePartialMatch(labels)
+}
@@ -0,0 +1,28 @@
+// Package operatorconfig defines the versioned configuration file shared by
+// all Forge operators and the logic to load it.
+//
+// Settings are resolved in increasing order of precedence:
+//
+//  1. built-in defaults (see New),
+//  2. the YAML configuration file passed via --config or FORGE_CONFIG,
+//  3. FORGE_* environment variables,
+//  4. command-line flags that were explicitly set.
+//
+// A minimal configuration file looks like:
+//
+//	apiVersion: config.forge.c5c3.io/v1alpha1
+//	kind: OperatorConfiguration
+//	watchNamespaces: [openstack]
+//	# or: watchNamespaceSelector: forge.c5c3.io/tenant=acme
+//	defaultImages:
+//	  "2025.2":
+//	    keystone: ghcr.io/c5c3/keystone:2025.2
+//	reconcile:
+//	  maxConcurrentReconciles: 2
+//	  requeueInterval: 5m
+//	apiHealthCheck:
+//	  interval: 1m
+//	  timeout: 10s
+//	featureGates:
+//	  FernetAutoRotation: true
+package operatorconfig
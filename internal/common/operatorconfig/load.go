@@ -0,0 +1,38 @@
+package operatorconfig
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Load reads the configuration file at path, rejects unknown fields and fills
+// unset values with the built-in defaults. An empty path returns New().
+// The result is not validated; callers apply overrides first and then call
+// Validate.
+func Load(path string) (*Configuration, error) {
+	if path == "" {
+		return New(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading operator configuration %s: %w", path, err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes a configuration document and fills unset values with the
+// built-in defaults. Unknown fields are rejected so that typos do not go
+// unnoticed.
+func Parse(data []byte) (*Configuration, error) {
+	cfg := &Configuration{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("decoding operator configuration: %w", err)
+	}
+	if cfg.APIVersion == "" || cfg.Kind == "" {
+		return nil, fmt.Errorf("decoding operator configuration: apiVersion and kind are required")
+	}
+	cfg.setDefaults()
+	return cfg, nil
+}
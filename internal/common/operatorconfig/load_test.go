@@ -0,0 +1,90 @@
+package operatorconfig
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestLoad_File(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cfg, err := Load(filepath.Join("testdata", "config.yaml"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(cfg.MetricsBindAddress).To(Equal(":9090"))
+	g.Expect(cfg.HealthProbeBindAddress).To(Equal(DefaultHealthProbeBindAddress))
+	g.Expect(cfg.LeaderElection).To(BeTrue())
+	g.Expect(cfg.WatchNamespaces).To(Equal([]string{"openstack", "openstack-staging"}))
+	g.Expect(cfg.Reconcile.MaxConcurrentReconciles).To(Equal(4))
+	g.Expect(cfg.Reconcile.RequeueInterval.Duration).To(Equal(10 * time.Minute))
+	g.Expect(cfg.Reconcile.ErrorRequeueInterval.Duration).To(Equal(DefaultErrorRequeueInterval))
+	g.Expect(cfg.APIHealthCheck.Interval.Duration).To(Equal(30 * time.Second))
+	g.Expect(cfg.APIHealthCheck.Timeout.Duration).To(Equal(5 * time.Second))
+	g.Expect(cfg.FeatureGates).To(HaveKeyWithValue("FernetAutoRotation", true))
+	g.Expect(cfg.Validate()).To(Succeed())
+
+	image, ok := cfg.Image("2025.2", "keystone")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(image).To(Equal("ghcr.io/c5c3/keystone:2025.2"))
+}
+
+func TestLoad_EmptyPathReturnsDefaults(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cfg, err := Load("")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg).To(Equal(New()))
+	g.Expect(cfg.Validate()).To(Succeed())
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	g.Expect(err).To(MatchError(ContainSubstring("reading operator configuration")))
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		failureSubstr string
+	}{
+		{
+			name: "minimal document",
+			data: "apiVersion: config.forge.c5c3.io/v1alpha1\nkind: OperatorConfiguration\n",
+		},
+		{
+			name:          "unknown field is rejected",
+			data:          "apiVersion: config.forge.c5c3.io/v1alpha1\nkind: OperatorConfiguration\nwatchNamespace: [a]\n",
+			failureSubstr: "unknown field",
+		},
+		{
+			name:          "missing apiVersion",
+			data:          "kind: OperatorConfiguration\n",
+			failureSubstr: "apiVersion and kind are required",
+		},
+		{
+			name:          "invalid duration",
+			data:          "apiVersion: config.forge.c5c3.io/v1alpha1\nkind: OperatorConfiguration\nreconcile:\n  requeueInterval: soon\n",
+			failureSubstr: "decoding operator configuration",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			cfg, err := Parse([]byte(tc.data))
+			if tc.failureSubstr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(cfg.Reconcile.MaxConcurrentReconciles).To(Equal(DefaultMaxConcurrentReconciles))
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tc.failureSubstr)))
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package operatorconfig
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables that override values from the configuration file.
+const (
+	EnvConfigFile              = "FORGE_CONFIG"
+	EnvMetricsBindAddress      = "FORGE_METRICS_BIND_ADDRESS"
+	EnvHealthProbeBindAddress  = "FORGE_HEALTH_PROBE_BIND_ADDRESS"
+	EnvLeaderElect             = "FORGE_LEADER_ELECT"
+	EnvWatchNamespaces         = "FORGE_WATCH_NAMESPACES"
+	EnvWatchNamespaceSelector  = "FORGE_WATCH_NAMESPACE_SELECTOR"
+	EnvMaxConcurrentReconciles = "FORGE_MAX_CONCURRENT_RECONCILES"
+	EnvRequeueInterval         = "FORGE_REQUEUE_INTERVAL"
+	EnvErrorRequeueInterval    = "FORGE_ERROR_REQUEUE_INTERVAL"
+	EnvAPIHealthCheckInterval  = "FORGE_API_HEALTH_CHECK_INTERVAL"
+	EnvAPIHealthCheckTimeout   = "FORGE_API_HEALTH_CHECK_TIMEOUT"
+	EnvFeatureGates            = "FORGE_FEATURE_GATES"
+)
+
+// Command-line flag names.
+const (
+	flagConfig                  = "config"
+	flagMetricsBindAddress      = "metrics-bind-address"
+	flagHealthProbeBindAddress  = "health-probe-bind-address"
+	flagLeaderElect             = "leader-elect"
+	flagWatchNamespaces         = "watch-namespaces"
+	flagWatchNamespaceSelector  = "watch-namespace-selector"
+	flagMaxConcurrentReconciles = "max-concurrent-reconciles"
+	flagRequeueInterval         = "requeue-interval"
+	flagErrorRequeueInterval    = "error-requeue-interval"
+	flagAPIHealthCheckInterval  = "api-health-check-interval"
+	flagAPIHealthCheckTimeout   = "api-health-check-timeout"
+	flagFeatureGates            = "feature-gates"
+)
+
+// Options binds the operator configuration to command-line flags and the
+// environment, analogous to zap.Options for logging.
+type Options struct {
+	// LookupEnv resolves environment variables. It defaults to os.LookupEnv
+	// and can be replaced in tests.
+	LookupEnv func(key string) (string, bool)
+
+	fs                      *flag.FlagSet
+	configFile              string
+	metricsBindAddress      string
+	healthProbeBindAddress  string
+	leaderElect             bool
+	watchNamespaces         string
+	watchNamespaceSelector  string
+	maxConcurrentReconciles int
+	requeueInterval         time.Duration
+	errorRequeueInterval    time.Duration
+	apiHealthCheckInterval  time.Duration
+	apiHealthCheckTimeout   time.Duration
+	featureGates            string
+}
+
+// BindFlags registers the configuration flags on fs. Flag defaults mirror the
+// built-in defaults; a flag only overrides the file and environment when it is
+// set explicitly.
+func (o *Options) BindFlags(fs *flag.FlagSet) {
+	o.fs = fs
+	fs.StringVar(&o.configFile, flagConfig, "",
+		"Path to the operator configuration file. Overrides "+EnvConfigFile+".")
+	fs.StringVar(&o.metricsBindAddress, flagMetricsBindAddress, DefaultMetricsBindAddress,
+		"The address the metric endpoint binds to.")
+	fs.StringVar(&o.healthProbeBindAddress, flagHealthProbeBindAddress, DefaultHealthProbeBindAddress,
+		"The address the probe endpoint binds to.")
+	fs.BoolVar(&o.leaderElect, flagLeaderElect, false,
+		"Enable leader election for controller manager.")
+	fs.StringVar(&o.watchNamespaces, flagWatchNamespaces, "",
+		"Comma-separated list of namespaces to watch. Empty watches all namespaces.")
+	fs.StringVar(&o.watchNamespaceSelector, flagWatchNamespaceSelector, "",
+		"Label selector for namespaces to watch, resolved at startup. Mutually exclusive with --"+flagWatchNamespaces+".")
+	fs.IntVar(&o.maxConcurrentReconciles, flagMaxConcurrentReconciles, DefaultMaxConcurrentReconciles,
+		"Maximum number of concurrent reconciles per controller.")
+	fs.DurationVar(&o.requeueInterval, flagRequeueInterval, DefaultRequeueInterval,
+		"Period after which a reconciled object is reconciled again.")
+	fs.DurationVar(&o.errorRequeueInterval, flagErrorRequeueInterval, DefaultErrorRequeueInterval,
+		"Delay before retrying while a dependency is not ready.")
+	fs.DurationVar(&o.apiHealthCheckInterval, flagAPIHealthCheckInterval, DefaultAPIHealthCheckInterval,
+		"Period between two health checks of a deployed OpenStack API.")
+	fs.DurationVar(&o.apiHealthCheckTimeout, flagAPIHealthCheckTimeout, DefaultAPIHealthCheckTimeout,
+		"Timeout of a single OpenStack API health check.")
+	fs.StringVar(&o.featureGates, flagFeatureGates, "",
+		"Comma-separated list of key=value pairs enabling or disabling feature gates, e.g. Federation=true.")
+}
+
+// ConfigFile returns the configuration file path selected by the --config
+// flag or, if unset, the FORGE_CONFIG environment variable.
+func (o *Options) ConfigFile() string {
+	if o.isSet(flagConfig) {
+		return o.configFile
+	}
+	if v, ok := o.lookupEnv(EnvConfigFile); ok {
+		return v
+	}
+	return o.configFile
+}
+
+// Load resolves the effective configuration: it reads the configuration file
+// (if any), applies environment overrides, then explicitly set flags, and
+// finally validates the result. It must be called after the flag set passed
+// to BindFlags has been parsed.
+func (o *Options) Load() (*Configuration, error) {
+	cfg, err := Load(o.ConfigFile())
+	if err != nil {
+		return nil, err
+	}
+	if err := o.applyEnv(cfg); err != nil {
+		return nil, err
+	}
+	if err := o.applyFlags(cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid operator configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// applyEnv overrides cfg with any FORGE_* environment variables that are set.
+func (o *Options) applyEnv(cfg *Configuration) error {
+	if v, ok := o.lookupEnv(EnvMetricsBindAddress); ok {
+		cfg.MetricsBindAddress = v
+	}
+	if v, ok := o.lookupEnv(EnvHealthProbeBindAddress); ok {
+		cfg.HealthProbeBindAddress = v
+	}
+	if v, ok := o.lookupEnv(EnvLeaderElect); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", EnvLeaderElect, err)
+		}
+		cfg.LeaderElection = b
+	}
+	if v, ok := o.lookupEnv(EnvWatchNamespaces); ok {
+		cfg.WatchNamespaces = splitList(v)
+	}
+	if v, ok := o.lookupEnv(EnvWatchNamespaceSelector); ok {
+		cfg.WatchNamespaceSelector = v
+	}
+	if v, ok := o.lookupEnv(EnvMaxConcurrentReconciles); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", EnvMaxConcurrentReconciles, err)
+		}
+		cfg.Reconcile.MaxConcurrentReconciles = n
+	}
+	if v, ok := o.lookupEnv(EnvRequeueInterval); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", EnvRequeueInterval, err)
+		}
+		cfg.Reconcile.RequeueInterval.Duration = d
+	}
+	if v, ok := o.lookupEnv(EnvErrorRequeueInterval); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", EnvErrorRequeueInterval, err)
+		}
+		cfg.Reconcile.ErrorRequeueInterval.Duration = d
+	}
+	if v, ok := o.lookupEnv(EnvAPIHealthCheckInterval); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", EnvAPIHealthCheckInterval, err)
+		}
+		cfg.APIHealthCheck.Interval.Duration = d
+	}
+	if v, ok := o.lookupEnv(EnvAPIHealthCheckTimeout); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", EnvAPIHealthCheckTimeout, err)
+		}
+		cfg.APIHealthCheck.Timeout.Duration = d
+	}
+	if v, ok := o.lookupEnv(EnvFeatureGates); ok {
+		gates, err := ParseFeatureGates(v)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", EnvFeatureGates, err)
+		}
+		cfg.mergeFeatureGates(gates)
+	}
+	return nil
+}
+
+// applyFlags overrides cfg with the flags that were set on the command line.
+func (o *Options) applyFlags(cfg *Configuration) error {
+	if o.isSet(flagMetricsBindAddress) {
+		cfg.MetricsBindAddress = o.metricsBindAddress
+	}
+	if o.isSet(flagHealthProbeBindAddress) {
+		cfg.HealthProbeBindAddress = o.healthProbeBindAddress
+	}
+	if o.isSet(flagLeaderElect) {
+		cfg.LeaderElection = o.leaderElect
+	}
+	if o.isSet(flagWatchNamespaces) {
+		cfg.WatchNamespaces = splitList(o.watchNamespaces)
+	}
+	if o.isSet(flagWatchNamespaceSelector) {
+		cfg.WatchNamespaceSelector = o.watchNamespaceSelector
+	}
+	if o.isSet(flagMaxConcurrentReconciles) {
+		cfg.Reconcile.MaxConcurrentReconciles = o.maxConcurrentReconciles
+	}
+	if o.isSet(flagRequeueInterval) {
+		cfg.Reconcile.RequeueInterval.Duration = o.requeueInterval
+	}
+	if o.isSet(flagErrorRequeueInterval) {
+		cfg.Reconcile.ErrorRequeueInterval.Duration = o.errorRequeueInterval
+	}
+	if o.isSet(flagAPIHealthCheckInterval) {
+		cfg.APIHealthCheck.Interval.Duration = o.apiHealthCheckInterval
+	}
+	if o.isSet(flagAPIHealthCheckTimeout) {
+		cfg.APIHealthCheck.Timeout.Duration = o.apiHealthCheckTimeout
+	}
+	if o.isSet(flagFeatureGates) {
+		gates, err := ParseFeatureGates(o.featureGates)
+		if err != nil {
+			return fmt.Errorf("parsing --%s: %w", flagFeatureGates, err)
+		}
+		cfg.mergeFeatureGates(gates)
+	}
+	return nil
+}
+
+// isSet reports whether the named flag was set explicitly on the command line.
+func (o *Options) isSet(name string) bool {
+	if o.fs == nil {
+		return false
+	}
+	set := false
+	o.fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func (o *Options) lookupEnv(key string) (string, bool) {
+	if o.LookupEnv != nil {
+		return o.LookupEnv(key)
+	}
+	return os.LookupEnv(key)
+}
+
+// ParseFeatureGates parses a comma-separated list of name=bool pairs such as
+// "FernetAutoRotation=true,Federation=false".
+func ParseFeatureGates(s string) (map[string]bool, error) {
+	gates := make(map[string]bool)
+	for _, pair := range splitList(s) {
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid feature gate %q, expected name=true|false", pair)
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for feature gate %q: %w", name, err)
+		}
+		gates[name] = enabled
+	}
+	return gates, nil
+}
+
+// mergeFeatureGates overrides individual feature gates, keeping the ones not
+// mentioned in gates.
+func (c *Configuration) mergeFeatureGates(gates map[string]bool) {
+	if len(gates) == 0 {
+		return
+	}
+	if c.FeatureGates == nil {
+		c.FeatureGates = make(map[string]bool, len(gates))
+	}
+	for name, enabled := range gates {
+		c.FeatureGates[name] = enabled
+	}
+}
+
+// splitList splits a comma-separated list, trimming whitespace and dropping
+// empty elements.
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,192 @@
+package operatorconfig
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// newTestOptions binds Options to a fresh flag set, parses args and resolves
+// environment variables from env only.
+func newTestOptions(t *testing.T, env map[string]string, args ...string) *Options {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	o := &Options{LookupEnv: func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}}
+	o.BindFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("parsing flags: %v", err)
+	}
+	return o
+}
+
+func TestOptions_Load_DefaultsWithoutFileEnvOrFlags(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cfg, err := newTestOptions(t, nil).Load()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg).To(Equal(New()))
+}
+
+func TestOptions_Load_Precedence(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	env := map[string]string{
+		EnvConfigFile:              filepath.Join("testdata", "config.yaml"),
+		EnvMetricsBindAddress:      ":7070",
+		EnvWatchNamespaces:         "env-a, env-b",
+		EnvMaxConcurrentReconciles: "8",
+	}
+	o := newTestOptions(t, env, "--max-concurrent-reconciles=16", "--requeue-interval=1m")
+
+	cfg, err := o.Load()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// File value, untouched by env or flags.
+	g.Expect(cfg.LeaderElection).To(BeTrue())
+	// Env overrides file.
+	g.Expect(cfg.MetricsBindAddress).To(Equal(":7070"))
+	g.Expect(cfg.WatchNamespaces).To(Equal([]string{"env-a", "env-b"}))
+	// Flags override env and file.
+	g.Expect(cfg.Reconcile.MaxConcurrentReconciles).To(Equal(16))
+	g.Expect(cfg.Reconcile.RequeueInterval.Duration).To(Equal(time.Minute))
+}
+
+func TestOptions_Load_UnsetFlagsDoNotOverrideFile(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	o := newTestOptions(t, nil, "--config", filepath.Join("testdata", "config.yaml"))
+
+	cfg, err := o.Load()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.MetricsBindAddress).To(Equal(":9090"))
+	g.Expect(cfg.Reconcile.MaxConcurrentReconciles).To(Equal(4))
+}
+
+func TestOptions_ConfigFile_FlagOverridesEnv(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	o := newTestOptions(t, map[string]string{EnvConfigFile: "/from/env.yaml"}, "--config=/from/flag.yaml")
+	g.Expect(o.ConfigFile()).To(Equal("/from/flag.yaml"))
+
+	o = newTestOptions(t, map[string]string{EnvConfigFile: "/from/env.yaml"})
+	g.Expect(o.ConfigFile()).To(Equal("/from/env.yaml"))
+}
+
+func TestOptions_Load_InvalidEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		val  string
+	}{
+		{"leader elect", EnvLeaderElect, "maybe"},
+		{"max concurrent reconciles", EnvMaxConcurrentReconciles, "many"},
+		{"requeue interval", EnvRequeueInterval, "soon"},
+		{"error requeue interval", EnvErrorRequeueInterval, "later"},
+		{"API health check interval", EnvAPIHealthCheckInterval, "often"},
+		{"API health check timeout", EnvAPIHealthCheckTimeout, "briefly"},
+		{"feature gates", EnvFeatureGates, "Federation"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			_, err := newTestOptions(t, map[string]string{tc.key: tc.val}).Load()
+			g.Expect(err).To(MatchError(ContainSubstring(tc.key)))
+		})
+	}
+}
+
+func TestOptions_Load_ValidatesResult(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	_, err := newTestOptions(t, nil, "--max-concurrent-reconciles=0").Load()
+	g.Expect(err).To(MatchError(ContainSubstring("invalid operator configuration")))
+}
+
+func TestOptions_Load_WatchNamespaceSelector(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	o := newTestOptions(t, map[string]string{EnvWatchNamespaceSelector: "tenant=env"},
+		"--watch-namespace-selector=tenant=flag")
+
+	cfg, err := o.Load()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.WatchNamespaceSelector).To(Equal("tenant=flag"))
+}
+
+func TestOptions_Load_APIHealthCheck(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	env := map[string]string{
+		EnvConfigFile:             filepath.Join("testdata", "config.yaml"),
+		EnvAPIHealthCheckInterval: "2m",
+	}
+	o := newTestOptions(t, env, "--api-health-check-timeout=30s")
+
+	cfg, err := o.Load()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.APIHealthCheck.Interval.Duration).To(Equal(2 * time.Minute))
+	g.Expect(cfg.APIHealthCheck.Timeout.Duration).To(Equal(30 * time.Second))
+}
+
+func TestOptions_Load_FeatureGatesMerge(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	env := map[string]string{
+		EnvConfigFile:   filepath.Join("testdata", "config.yaml"),
+		EnvFeatureGates: "Federation=true,ZeroDowntimeUpgrade=true",
+	}
+	o := newTestOptions(t, env, "--feature-gates=ZeroDowntimeUpgrade=false")
+
+	cfg, err := o.Load()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.FeatureGates).To(Equal(map[string]bool{
+		"FernetAutoRotation":  true,
+		"Federation":          true,
+		"ZeroDowntimeUpgrade": false,
+	}))
+}
+
+func TestOptions_Load_InvalidFeatureGatesFlag(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	_, err := newTestOptions(t, nil, "--feature-gates=Federation=maybe").Load()
+	g.Expect(err).To(MatchError(ContainSubstring("--feature-gates")))
+}
+
+func TestParseFeatureGates(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expected      map[string]bool
+		failureSubstr string
+	}{
+		{name: "empty", input: "", expected: map[string]bool{}},
+		{name: "single", input: "Federation=true", expected: map[string]bool{"Federation": true}},
+		{name: "whitespace", input: " A = false , B=1 ", expected: map[string]bool{"A": false, "B": true}},
+		{name: "missing value", input: "A", failureSubstr: "expected name=true|false"},
+		{name: "missing name", input: "=true", failureSubstr: "expected name=true|false"},
+		{name: "bad bool", input: "A=yes", failureSubstr: "invalid value"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			gates, err := ParseFeatureGates(tc.input)
+			if tc.failureSubstr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.failureSubstr)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(gates).To(Equal(tc.expected))
+		})
+	}
+}
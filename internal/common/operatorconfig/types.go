@@ -0,0 +1,191 @@
+package operatorconfig
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// APIVersion and Kind identify the configuration file format. Bump the
+// version whenever a field changes incompatibly.
+const (
+	APIVersion = "config.forge.c5c3.io/v1alpha1"
+	Kind       = "OperatorConfiguration"
+)
+
+// Built-in defaults used when neither the file, the environment nor a flag
+// provides a value.
+const (
+	DefaultMetricsBindAddress      = ":8080"
+	DefaultHealthProbeBindAddress  = ":8081"
+	DefaultMaxConcurrentReconciles = 1
+	DefaultRequeueInterval         = 5 * time.Minute
+	DefaultErrorRequeueInterval    = 30 * time.Second
+	DefaultAPIHealthCheckInterval  = time.Minute
+	DefaultAPIHealthCheckTimeout   = 10 * time.Second
+)
+
+// Configuration is the on-disk operator configuration.
+type Configuration struct {
+	// APIVersion must be APIVersion.
+	APIVersion string `json:"apiVersion"`
+	// Kind must be Kind.
+	Kind string `json:"kind"`
+
+	// MetricsBindAddress is the address the metrics endpoint binds to.
+	MetricsBindAddress string `json:"metricsBindAddress,omitempty"`
+	// HealthProbeBindAddress is the address the health probe endpoint binds to.
+	HealthProbeBindAddress string `json:"healthProbeBindAddress,omitempty"`
+	// LeaderElection enables leader election for the controller manager.
+	LeaderElection bool `json:"leaderElection,omitempty"`
+
+	// WatchNamespaces restricts the operator to the listed namespaces. An
+	// empty list watches all namespaces.
+	WatchNamespaces []string `json:"watchNamespaces,omitempty"`
+	// WatchNamespaceSelector restricts the operator to namespaces matching
+	// this label selector (e.g. "forge.c5c3.io/tenant=acme"). The matching
+	// namespaces are resolved once at startup. Mutually exclusive with
+	// WatchNamespaces.
+	WatchNamespaceSelector string `json:"watchNamespaceSelector,omitempty"`
+
+	// DefaultImages maps an OpenStack release (e.g. "2025.2") to the default
+	// container image per component (e.g. "keystone"). CRs that do not set an
+	// image explicitly fall back to these values.
+	DefaultImages map[string]map[string]string `json:"defaultImages,omitempty"`
+
+	// Reconcile tunes controller concurrency and requeue behaviour.
+	Reconcile ReconcileConfiguration `json:"reconcile,omitempty"`
+
+	// APIHealthCheck tunes the periodic checks of the OpenStack APIs the
+	// operator deploys.
+	APIHealthCheck APIHealthCheckConfiguration `json:"apiHealthCheck,omitempty"`
+
+	// FeatureGates enables or disables named feature gates.
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+}
+
+// ReconcileConfiguration tunes controller concurrency and requeue behaviour.
+type ReconcileConfiguration struct {
+	// MaxConcurrentReconciles is the number of reconciles each controller may
+	// run in parallel.
+	MaxConcurrentReconciles int `json:"maxConcurrentReconciles,omitempty"`
+	// RequeueInterval is the period after which a successfully reconciled
+	// object is reconciled again to detect drift.
+	RequeueInterval metav1.Duration `json:"requeueInterval,omitempty"`
+	// ErrorRequeueInterval is the delay before retrying while waiting for a
+	// dependency that is not ready yet.
+	ErrorRequeueInterval metav1.Duration `json:"errorRequeueInterval,omitempty"`
+}
+
+// APIHealthCheckConfiguration tunes the periodic API health checks.
+type APIHealthCheckConfiguration struct {
+	// Interval is the period between two checks of the same API.
+	Interval metav1.Duration `json:"interval,omitempty"`
+	// Timeout bounds a single check, including all requests it makes.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
+// New returns a Configuration populated with the built-in defaults.
+func New() *Configuration {
+	cfg := &Configuration{}
+	cfg.setDefaults()
+	return cfg
+}
+
+// setDefaults fills every unset field with its built-in default.
+func (c *Configuration) setDefaults() {
+	if c.APIVersion == "" {
+		c.APIVersion = APIVersion
+	}
+	if c.Kind == "" {
+		c.Kind = Kind
+	}
+	if c.MetricsBindAddress == "" {
+		c.MetricsBindAddress = DefaultMetricsBindAddress
+	}
+	if c.HealthProbeBindAddress == "" {
+		c.HealthProbeBindAddress = DefaultHealthProbeBindAddress
+	}
+	if c.Reconcile.MaxConcurrentReconciles == 0 {
+		c.Reconcile.MaxConcurrentReconciles = DefaultMaxConcurrentReconciles
+	}
+	if c.Reconcile.RequeueInterval.Duration == 0 {
+		c.Reconcile.RequeueInterval.Duration = DefaultRequeueInterval
+	}
+	if c.Reconcile.ErrorRequeueInterval.Duration == 0 {
+		c.Reconcile.ErrorRequeueInterval.Duration = DefaultErrorRequeueInterval
+	}
+	if c.APIHealthCheck.Interval.Duration == 0 {
+		c.APIHealthCheck.Interval.Duration = DefaultAPIHealthCheckInterval
+	}
+	if c.APIHealthCheck.Timeout.Duration == 0 {
+		c.APIHealthCheck.Timeout.Duration = DefaultAPIHealthCheckTimeout
+	}
+}
+
+// Validate checks the configuration for semantic errors and returns all of
+// them joined together.
+func (c *Configuration) Validate() error {
+	var errs []error
+	if c.APIVersion != APIVersion {
+		errs = append(errs, fmt.Errorf("apiVersion: unsupported value %q, expected %q", c.APIVersion, APIVersion))
+	}
+	if c.Kind != Kind {
+		errs = append(errs, fmt.Errorf("kind: unsupported value %q, expected %q", c.Kind, Kind))
+	}
+	if c.Reconcile.MaxConcurrentReconciles < 1 {
+		errs = append(errs, fmt.Errorf("reconcile.maxConcurrentReconciles: must be at least 1, got %d", c.Reconcile.MaxConcurrentReconciles))
+	}
+	if c.Reconcile.RequeueInterval.Duration <= 0 {
+		errs = append(errs, fmt.Errorf("reconcile.requeueInterval: must be positive, got %s", c.Reconcile.RequeueInterval.Duration))
+	}
+	if c.Reconcile.ErrorRequeueInterval.Duration <= 0 {
+		errs = append(errs, fmt.Errorf("reconcile.errorRequeueInterval: must be positive, got %s", c.Reconcile.ErrorRequeueInterval.Duration))
+	}
+	if c.APIHealthCheck.Interval.Duration <= 0 {
+		errs = append(errs, fmt.Errorf("apiHealthCheck.interval: must be positive, got %s", c.APIHealthCheck.Interval.Duration))
+	}
+	if c.APIHealthCheck.Timeout.Duration <= 0 {
+		errs = append(errs, fmt.Errorf("apiHealthCheck.timeout: must be positive, got %s", c.APIHealthCheck.Timeout.Duration))
+	} else if c.APIHealthCheck.Timeout.Duration > c.APIHealthCheck.Interval.Duration {
+		errs = append(errs, fmt.Errorf("apiHealthCheck.timeout: must not exceed apiHealthCheck.interval (%s), got %s",
+			c.APIHealthCheck.Interval.Duration, c.APIHealthCheck.Timeout.Duration))
+	}
+	seen := make(map[string]bool, len(c.WatchNamespaces))
+	for i, ns := range c.WatchNamespaces {
+		if ns == "" {
+			errs = append(errs, fmt.Errorf("watchNamespaces[%d]: must not be empty", i))
+			continue
+		}
+		if seen[ns] {
+			errs = append(errs, fmt.Errorf("watchNamespaces[%d]: duplicate namespace %q", i, ns))
+		}
+		seen[ns] = true
+	}
+	if c.WatchNamespaceSelector != "" {
+		if len(c.WatchNamespaces) > 0 {
+			errs = append(errs, errors.New("watchNamespaceSelector: mutually exclusive with watchNamespaces"))
+		}
+		if _, err := labels.Parse(c.WatchNamespaceSelector); err != nil {
+			errs = append(errs, fmt.Errorf("watchNamespaceSelector: %w", err))
+		}
+	}
+	for release, images := range c.DefaultImages {
+		for component, image := range images {
+			if image == "" {
+				errs = append(errs, fmt.Errorf("defaultImages[%q][%q]: must not be empty", release, component))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Image returns the default image for component in the given OpenStack
+// release, and whether one is configured.
+func (c *Configuration) Image(release, component string) (string, bool) {
+	image, ok := c.DefaultImages[release][component]
+	return image, ok
+}
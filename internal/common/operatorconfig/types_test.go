@@ -0,0 +1,130 @@
+package operatorconfig
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestConfiguration_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		mutate        func(*Configuration)
+		failureSubstr string
+	}{
+		{
+			name:   "defaults are valid",
+			mutate: func(*Configuration) {},
+		},
+		{
+			name:          "wrong apiVersion",
+			mutate:        func(c *Configuration) { c.APIVersion = "config.forge.c5c3.io/v0" },
+			failureSubstr: "apiVersion: unsupported value",
+		},
+		{
+			name:          "wrong kind",
+			mutate:        func(c *Configuration) { c.Kind = "Other" },
+			failureSubstr: "kind: unsupported value",
+		},
+		{
+			name:          "zero concurrency",
+			mutate:        func(c *Configuration) { c.Reconcile.MaxConcurrentReconciles = 0 },
+			failureSubstr: "maxConcurrentReconciles",
+		},
+		{
+			name:          "negative requeue interval",
+			mutate:        func(c *Configuration) { c.Reconcile.RequeueInterval.Duration = -1 },
+			failureSubstr: "reconcile.requeueInterval: must be positive",
+		},
+		{
+			name:          "zero requeue interval",
+			mutate:        func(c *Configuration) { c.Reconcile.RequeueInterval.Duration = 0 },
+			failureSubstr: "reconcile.requeueInterval: must be positive",
+		},
+		{
+			name:          "negative error requeue interval",
+			mutate:        func(c *Configuration) { c.Reconcile.ErrorRequeueInterval.Duration = -1 },
+			failureSubstr: "reconcile.errorRequeueInterval: must be positive",
+		},
+		{
+			name:          "zero error requeue interval",
+			mutate:        func(c *Configuration) { c.Reconcile.ErrorRequeueInterval.Duration = 0 },
+			failureSubstr: "reconcile.errorRequeueInterval: must be positive",
+		},
+		{
+			name:          "zero API health check interval",
+			mutate:        func(c *Configuration) { c.APIHealthCheck.Interval.Duration = 0 },
+			failureSubstr: "apiHealthCheck.interval",
+		},
+		{
+			name:          "negative API health check timeout",
+			mutate:        func(c *Configuration) { c.APIHealthCheck.Timeout.Duration = -1 },
+			failureSubstr: "apiHealthCheck.timeout: must be positive",
+		},
+		{
+			name: "API health check timeout exceeds interval",
+			mutate: func(c *Configuration) {
+				c.APIHealthCheck.Interval.Duration = 5 * time.Second
+				c.APIHealthCheck.Timeout.Duration = 10 * time.Second
+			},
+			failureSubstr: "must not exceed apiHealthCheck.interval",
+		},
+		{
+			name:          "empty namespace",
+			mutate:        func(c *Configuration) { c.WatchNamespaces = []string{""} },
+			failureSubstr: "watchNamespaces[0]: must not be empty",
+		},
+		{
+			name:          "duplicate namespace",
+			mutate:        func(c *Configuration) { c.WatchNamespaces = []string{"a", "a"} },
+			failureSubstr: "duplicate namespace",
+		},
+		{
+			name:   "namespace selector",
+			mutate: func(c *Configuration) { c.WatchNamespaceSelector = "forge.c5c3.io/tenant in (a,b)" },
+		},
+		{
+			name:          "invalid namespace selector",
+			mutate:        func(c *Configuration) { c.WatchNamespaceSelector = "a in (" },
+			failureSubstr: "watchNamespaceSelector:",
+		},
+		{
+			name: "namespace selector and list",
+			mutate: func(c *Configuration) {
+				c.WatchNamespaces = []string{"a"}
+				c.WatchNamespaceSelector = "tenant=a"
+			},
+			failureSubstr: "mutually exclusive",
+		},
+		{
+			name: "empty default image",
+			mutate: func(c *Configuration) {
+				c.DefaultImages = map[string]map[string]string{"2025.2": {"keystone": ""}}
+			},
+			failureSubstr: `defaultImages["2025.2"]["keystone"]`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			cfg := New()
+			tc.mutate(cfg)
+			err := cfg.Validate()
+			if tc.failureSubstr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tc.failureSubstr)))
+		})
+	}
+}
+
+func TestConfiguration_ImageNotConfigured(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	_, ok := New().Image("2025.2", "keystone")
+	g.Expect(ok).To(BeFalse())
+}
@@ -0,0 +1,10 @@
+// Package pause implements the reconcile guard that lets operators of a
+// cluster suspend reconciliation of individual custom resources.
+//
+// Setting the annotation forge.c5c3.io/paused=true on a managed CR makes its
+// controller skip all further work, set a Paused condition and emit a Paused
+// event. Removing the annotation (or setting it to false) resumes
+// reconciliation. A parent such as ControlPlane propagates its own pause to
+// the child CRs it creates via PropagateFromParent; children paused by hand
+// stay paused when the parent is resumed.
+package pause
@@ -0,0 +1,142 @@
+package pause
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/c5c3/forge/internal/common/events"
+)
+
+const (
+	// Annotation suspends reconciliation of the annotated object when set to
+	// "true".
+	Annotation = "forge.c5c3.io/paused"
+	// ByAnnotation records which parent paused the object, in the form
+	// "<Kind>/<name>". It is only set by PropagateFromParent.
+	ByAnnotation = "forge.c5c3.io/paused-by"
+
+	// ConditionType is the type of the condition reporting the pause state.
+	ConditionType = "Paused"
+	// ConditionReasonPaused is the condition reason while paused.
+	ConditionReasonPaused = "ReconciliationPaused"
+	// ConditionReasonResumed is the condition reason after resuming.
+	ConditionReasonResumed = "ReconciliationResumed"
+)
+
+// Object is a CR whose status carries standard metav1 conditions.
+type Object interface {
+	client.Object
+	GetConditions() []metav1.Condition
+	SetConditions([]metav1.Condition)
+}
+
+// IsPaused reports whether obj carries the pause annotation with a true value.
+func IsPaused(obj client.Object) bool {
+	v, ok := obj.GetAnnotations()[Annotation]
+	if !ok {
+		return false
+	}
+	paused, err := strconv.ParseBool(v)
+	return err == nil && paused
+}
+
+// Guard must be called at the start of Reconcile. If obj is paused it sets the
+// Paused condition to True, emits a Paused event and returns true; the caller
+// must then return without doing any further work. If obj is not paused but
+// was before, it sets the condition to False and emits a Resumed event. The
+// status is only written when the condition changes.
+func Guard(ctx context.Context, c client.Client, recorder *events.Recorder, obj Object) (bool, error) {
+	paused := IsPaused(obj)
+	existing := meta.FindStatusCondition(obj.GetConditions(), ConditionType)
+
+	var cond metav1.Condition
+	switch {
+	case paused:
+		cond = metav1.Condition{
+			Type:    ConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  ConditionReasonPaused,
+			Message: pausedMessage(obj),
+		}
+	case existing != nil && existing.Status == metav1.ConditionTrue:
+		cond = metav1.Condition{
+			Type:    ConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  ConditionReasonResumed,
+			Message: "Reconciliation resumed",
+		}
+	default:
+		return false, nil
+	}
+	cond.ObservedGeneration = obj.GetGeneration()
+
+	conditions := obj.GetConditions()
+	if !meta.SetStatusCondition(&conditions, cond) {
+		return paused, nil
+	}
+	obj.SetConditions(conditions)
+	if err := c.Status().Update(ctx, obj); err != nil {
+		return paused, fmt.Errorf("updating %s condition: %w", ConditionType, err)
+	}
+
+	if recorder != nil {
+		if paused {
+			recorder.Event(obj, events.ReasonPaused, "%s", cond.Message)
+		} else {
+			recorder.Event(obj, events.ReasonResumed, "%s", cond.Message)
+		}
+	}
+	return paused, nil
+}
+
+// PropagateFromParent mirrors the pause state of parent onto child, which must
+// be a CR created by the parent's controller. It is meant to be called on the
+// rendered child before it is applied. A paused parent pauses the child and
+// records itself in ByAnnotation. A resumed parent only removes the pause it
+// set itself, so children that were paused directly stay paused. It reports
+// whether child was modified. The parent's kind is resolved through scheme.
+func PropagateFromParent(parent, child client.Object, scheme *runtime.Scheme) (bool, error) {
+	gvk, err := apiutil.GVKForObject(parent, scheme)
+	if err != nil {
+		return false, fmt.Errorf("resolving kind of pause parent: %w", err)
+	}
+	by := gvk.Kind + "/" + parent.GetName()
+	annotations := child.GetAnnotations()
+
+	if IsPaused(parent) {
+		// A child that is already paused, directly or by this parent, is
+		// left alone so that a direct pause stays owned by the user.
+		if IsPaused(child) {
+			return false, nil
+		}
+		if annotations == nil {
+			annotations = make(map[string]string, 2)
+		}
+		annotations[Annotation] = "true"
+		annotations[ByAnnotation] = by
+		child.SetAnnotations(annotations)
+		return true, nil
+	}
+
+	if annotations[ByAnnotation] != by {
+		return false, nil
+	}
+	delete(annotations, Annotation)
+	delete(annotations, ByAnnotation)
+	child.SetAnnotations(annotations)
+	return true, nil
+}
+
+func pausedMessage(obj client.Object) string {
+	if by := obj.GetAnnotations()[ByAnnotation]; by != "" {
+		return fmt.Sprintf("Reconciliation paused by %s", by)
+	}
+	return fmt.Sprintf("Reconciliation paused by annotation %s", Annotation)
+}
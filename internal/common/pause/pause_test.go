@@ -0,0 +1,237 @@
+package pause
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientevents "k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/c5c3/forge/internal/common/events"
+)
+
+// testCR is a minimal CR with status conditions used to exercise Guard.
+type testCR struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Status            testCRStatus `json:"status,omitempty"`
+}
+
+type testCRStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+func (o *testCR) GetConditions() []metav1.Condition  { return o.Status.Conditions }
+func (o *testCR) SetConditions(c []metav1.Condition) { o.Status.Conditions = c }
+
+func (o *testCR) DeepCopyObject() runtime.Object {
+	out := &testCR{TypeMeta: o.TypeMeta}
+	o.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	for i := range o.Status.Conditions {
+		var c metav1.Condition
+		o.Status.Conditions[i].DeepCopyInto(&c)
+		out.Status.Conditions = append(out.Status.Conditions, c)
+	}
+	return out
+}
+
+var testGV = schema.GroupVersion{Group: "test.forge.c5c3.io", Version: "v1"}
+
+func newScheme(g *WithT) *runtime.Scheme {
+	s := runtime.NewScheme()
+	s.AddKnownTypeWithName(testGV.WithKind("ControlPlane"), &testCR{})
+	metav1.AddToGroupVersion(s, testGV)
+	g.Expect(corev1.AddToScheme(s)).To(Succeed())
+	return s
+}
+
+func newCR(name string, annotations map[string]string) *testCR {
+	return &testCR{ObjectMeta: metav1.ObjectMeta{
+		Name:        name,
+		Namespace:   "default",
+		Annotations: annotations,
+	}}
+}
+
+func setup(g *WithT, obj *testCR) (client.Client, *events.Recorder, *clientevents.FakeRecorder) {
+	c := fake.NewClientBuilder().
+		WithScheme(newScheme(g)).
+		WithObjects(obj).
+		WithStatusSubresource(obj).
+		Build()
+	fakeRecorder := clientevents.NewFakeRecorder(10)
+	return c, events.NewRecorder(fakeRecorder, time.Minute), fakeRecorder
+}
+
+func TestIsPaused(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    bool
+	}{
+		{"no annotations", nil, false},
+		{"true", map[string]string{Annotation: "true"}, true},
+		{"TRUE", map[string]string{Annotation: "TRUE"}, true},
+		{"false", map[string]string{Annotation: "false"}, false},
+		{"garbage", map[string]string{Annotation: "please"}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(IsPaused(newCR("x", tc.annotations))).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestGuard_PausedSetsConditionAndEmitsEvent(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	obj := newCR("keystone", map[string]string{Annotation: "true"})
+	c, rec, fakeRecorder := setup(g, obj)
+
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+	paused, err := Guard(ctx, c, rec, obj)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(paused).To(BeTrue())
+
+	fetched := &testCR{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(obj), fetched)).To(Succeed())
+	cond := meta.FindStatusCondition(fetched.Status.Conditions, ConditionType)
+	g.Expect(cond).NotTo(BeNil())
+	g.Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(cond.Reason).To(Equal(ConditionReasonPaused))
+	g.Expect(fakeRecorder.Events).To(Receive(HavePrefix("Normal Paused")))
+
+	// A second call does not write status or emit another event.
+	paused, err = Guard(ctx, c, rec, fetched)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(paused).To(BeTrue())
+	g.Expect(fakeRecorder.Events).To(BeEmpty())
+}
+
+func TestGuard_ResumeAfterPause(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	obj := newCR("keystone", map[string]string{Annotation: "true"})
+	c, rec, fakeRecorder := setup(g, obj)
+
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+	_, err := Guard(ctx, c, rec, obj)
+	g.Expect(err).NotTo(HaveOccurred())
+	<-fakeRecorder.Events
+
+	obj.SetAnnotations(nil)
+	paused, err := Guard(ctx, c, rec, obj)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(paused).To(BeFalse())
+
+	cond := meta.FindStatusCondition(obj.Status.Conditions, ConditionType)
+	g.Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(cond.Reason).To(Equal(ConditionReasonResumed))
+	g.Expect(fakeRecorder.Events).To(Receive(HavePrefix("Normal Resumed")))
+}
+
+func TestGuard_NeverPausedIsNoop(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	obj := newCR("keystone", nil)
+	c, rec, fakeRecorder := setup(g, obj)
+
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+	paused, err := Guard(ctx, c, rec, obj)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(paused).To(BeFalse())
+	g.Expect(obj.Status.Conditions).To(BeEmpty())
+	g.Expect(fakeRecorder.Events).To(BeEmpty())
+}
+
+func TestPropagateFromParent(t *testing.T) {
+	paused := map[string]string{Annotation: "true"}
+	pausedByParent := map[string]string{Annotation: "true", ByAnnotation: "ControlPlane/cp"}
+
+	tests := []struct {
+		name           string
+		parent         map[string]string
+		child          map[string]string
+		expectChanged  bool
+		expectedResult map[string]string
+	}{
+		{
+			name:           "paused parent pauses child",
+			parent:         paused,
+			child:          map[string]string{"keep": "me"},
+			expectChanged:  true,
+			expectedResult: map[string]string{"keep": "me", Annotation: "true", ByAnnotation: "ControlPlane/cp"},
+		},
+		{
+			name:           "paused parent keeps directly paused child",
+			parent:         paused,
+			child:          paused,
+			expectedResult: paused,
+		},
+		{
+			name:           "resumed parent resumes child it paused",
+			parent:         nil,
+			child:          pausedByParent,
+			expectChanged:  true,
+			expectedResult: map[string]string{},
+		},
+		{
+			name:           "resumed parent keeps directly paused child",
+			parent:         nil,
+			child:          paused,
+			expectedResult: paused,
+		},
+		{
+			name:           "resumed parent ignores child paused by another parent",
+			parent:         nil,
+			child:          map[string]string{Annotation: "true", ByAnnotation: "ControlPlane/other"},
+			expectedResult: map[string]string{Annotation: "true", ByAnnotation: "ControlPlane/other"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			parent := newCR("cp", tc.parent)
+			child := newCR("keystone", copyMap(tc.child))
+
+			changed, err := PropagateFromParent(parent, child, newScheme(g))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(changed).To(Equal(tc.expectChanged))
+			if len(tc.expectedResult) == 0 {
+				g.Expect(child.GetAnnotations()).To(BeEmpty())
+			} else {
+				g.Expect(child.GetAnnotations()).To(Equal(tc.expectedResult))
+			}
+		})
+	}
+}
+
+func TestPropagateFromParent_UnknownKind(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	_, err := PropagateFromParent(newCR("cp", nil), newCR("child", nil), runtime.NewScheme())
+	g.Expect(err).To(MatchError(ContainSubstring("resolving kind")))
+}
+
+func copyMap(in map[string]string) map[string]string {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,16 @@
+// Package scope determines which namespaces an operator watches and derives
+// the matching controller-runtime cache options and RBAC objects.
+//
+// An operator runs in one of two modes:
+//
+//   - cluster-wide, when no namespaces are configured. The cache watches all
+//     namespaces and the operator needs a ClusterRole.
+//   - namespace-scoped, when an explicit list or a namespace label selector is
+//     configured. The cache is restricted to those namespaces and the
+//     operator only needs a Role in each of them.
+//
+// Namespaces selected by label are resolved once at startup; namespaces that
+// start or stop matching the selector take effect after an operator restart.
+// Resolving the selector needs permission to list namespaces, which is the
+// only cluster-scoped permission required in that mode.
+package scope
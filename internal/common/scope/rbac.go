@@ -0,0 +1,123 @@
+package scope
+
+import (
+	"fmt"
+	"io"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// leaderElectionRules are the permissions controller-runtime's leader
+// election needs in the operator's own namespace.
+var leaderElectionRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{"coordination.k8s.io"},
+		Resources: []string{"leases"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	},
+	{
+		APIGroups: []string{"", "events.k8s.io"},
+		Resources: []string{"events"},
+		Verbs:     []string{"create", "patch"},
+	},
+}
+
+// RBACObjects renders the RBAC objects granting serviceAccount the given
+// rules within the scope. In ModeCluster it returns a ClusterRole and a
+// ClusterRoleBinding; in ModeNamespaced it returns a Role and a RoleBinding
+// per watched namespace, so the operator runs without cluster-wide access.
+func (s Scope) RBACObjects(name string, serviceAccount types.NamespacedName, rules []rbacv1.PolicyRule) []client.Object {
+	subjects := []rbacv1.Subject{{
+		Kind:      rbacv1.ServiceAccountKind,
+		Name:      serviceAccount.Name,
+		Namespace: serviceAccount.Namespace,
+	}}
+
+	if s.Mode() == ModeCluster {
+		return []client.Object{
+			&rbacv1.ClusterRole{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Rules:      copyRules(rules),
+			},
+			&rbacv1.ClusterRoleBinding{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: name},
+				Subjects:   subjects,
+			},
+		}
+	}
+
+	objs := make([]client.Object, 0, 2*len(s.Namespaces))
+	for _, ns := range s.Namespaces {
+		objs = append(objs,
+			&rbacv1.Role{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+				Rules:      copyRules(rules),
+			},
+			&rbacv1.RoleBinding{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name},
+				Subjects:   subjects,
+			},
+		)
+	}
+	return objs
+}
+
+// OperatorRBAC renders all RBAC objects of an operator running as
+// serviceAccount: the objects RBACObjects returns for rules, plus a Role and
+// RoleBinding named name+"-leader-election" in the service account's
+// namespace for leader election.
+func (s Scope) OperatorRBAC(name string, serviceAccount types.NamespacedName, rules []rbacv1.PolicyRule) []client.Object {
+	leader := Scope{Namespaces: []string{serviceAccount.Namespace}}
+	return append(s.RBACObjects(name, serviceAccount, rules),
+		leader.RBACObjects(name+"-leader-election", serviceAccount, leaderElectionRules)...)
+}
+
+// WriteManifest writes objs to w as a multi-document YAML stream.
+func WriteManifest(w io.Writer, objs []client.Object) error {
+	for _, obj := range objs {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return fmt.Errorf("rendering %s: %w", obj.GetName(), err)
+		}
+		unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+		data, err := yaml.Marshal(content)
+		if err != nil {
+			return fmt.Errorf("rendering %s: %w", obj.GetName(), err)
+		}
+		if _, err := fmt.Fprintf(w, "---\n%s", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NamespaceReaderRBAC renders the ClusterRole and ClusterRoleBinding that
+// allow serviceAccount to list namespaces. It is only needed when the scope
+// is resolved from a namespace label selector.
+func NamespaceReaderRBAC(name string, serviceAccount types.NamespacedName) []client.Object {
+	return Scope{}.RBACObjects(name, serviceAccount, []rbacv1.PolicyRule{{
+		APIGroups: []string{""},
+		Resources: []string{"namespaces"},
+		Verbs:     []string{"get", "list", "watch"},
+	}})
+}
+
+func copyRules(rules []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	out := make([]rbacv1.PolicyRule, len(rules))
+	for i := range rules {
+		rules[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package scope
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var (
+	testServiceAccount = types.NamespacedName{Name: "keystone-operator", Namespace: "forge-system"}
+	testRules          = []rbacv1.PolicyRule{{
+		APIGroups: []string{"apps"},
+		Resources: []string{"deployments"},
+		Verbs:     []string{"get", "list", "watch"},
+	}}
+)
+
+func TestRBACObjects_Cluster(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	objs := Scope{}.RBACObjects("keystone-operator", testServiceAccount, testRules)
+	g.Expect(objs).To(HaveLen(2))
+
+	role, ok := objs[0].(*rbacv1.ClusterRole)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(role.Rules).To(Equal(testRules))
+
+	binding, ok := objs[1].(*rbacv1.ClusterRoleBinding)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(binding.RoleRef.Kind).To(Equal("ClusterRole"))
+	g.Expect(binding.Subjects).To(ConsistOf(HaveField("Name", "keystone-operator")))
+}
+
+func TestRBACObjects_Namespaced(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	objs := Scope{Namespaces: []string{"a", "b"}}.RBACObjects("keystone-operator", testServiceAccount, testRules)
+	g.Expect(objs).To(HaveLen(4))
+
+	for i, ns := range []string{"a", "b"} {
+		role, ok := objs[2*i].(*rbacv1.Role)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(role.Namespace).To(Equal(ns))
+		g.Expect(role.Rules).To(Equal(testRules))
+
+		binding, ok := objs[2*i+1].(*rbacv1.RoleBinding)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(binding.Namespace).To(Equal(ns))
+		g.Expect(binding.RoleRef.Kind).To(Equal("Role"))
+		g.Expect(binding.Subjects[0].Namespace).To(Equal("forge-system"))
+	}
+}
+
+func TestRBACObjects_RulesAreCopied(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	rules := []rbacv1.PolicyRule{{Verbs: []string{"get"}}}
+	objs := Scope{}.RBACObjects("x", testServiceAccount, rules)
+	rules[0].Verbs[0] = "delete"
+
+	g.Expect(objs[0].(*rbacv1.ClusterRole).Rules[0].Verbs).To(Equal([]string{"get"}))
+}
+
+func TestNamespaceReaderRBAC(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	objs := NamespaceReaderRBAC("keystone-operator-namespaces", testServiceAccount)
+	g.Expect(objs).To(HaveLen(2))
+	role := objs[0].(*rbacv1.ClusterRole)
+	g.Expect(role.Rules[0].Resources).To(Equal([]string{"namespaces"}))
+	g.Expect(role.Rules[0].Verbs).To(ContainElement("list"))
+}
+
+func TestOperatorRBAC(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, s := range []Scope{{}, {Namespaces: []string{"a"}}} {
+		objs := s.OperatorRBAC("keystone-operator", testServiceAccount, testRules)
+		g.Expect(objs).To(HaveLen(4))
+		role, ok := objs[2].(*rbacv1.Role)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(role.Name).To(Equal("keystone-operator-leader-election"))
+		g.Expect(role.Namespace).To(Equal("forge-system"))
+		g.Expect(role.Rules).To(ContainElement(HaveField("Resources", ConsistOf("leases"))))
+	}
+}
+
+func TestWriteManifest(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var out bytes.Buffer
+	objs := Scope{Namespaces: []string{"a"}}.RBACObjects("keystone-operator", testServiceAccount, testRules)
+	g.Expect(WriteManifest(&out, objs)).To(Succeed())
+	g.Expect(out.String()).To(HavePrefix("---\napiVersion: rbac.authorization.k8s.io/v1\nkind: Role\n"))
+	g.Expect(strings.Count(out.String(), "---\n")).To(Equal(2))
+	g.Expect(out.String()).NotTo(ContainSubstring("creationTimestamp"))
+}
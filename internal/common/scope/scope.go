@@ -0,0 +1,112 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Mode describes whether an operator watches the whole cluster or a fixed
+// set of namespaces.
+type Mode string
+
+const (
+	// ModeCluster watches all namespaces.
+	ModeCluster Mode = "Cluster"
+	// ModeNamespaced watches only the resolved namespaces.
+	ModeNamespaced Mode = "Namespaced"
+)
+
+// Scope is the resolved set of namespaces an operator watches.
+type Scope struct {
+	// Namespaces is the sorted list of watched namespaces. It is empty in
+	// ModeCluster.
+	Namespaces []string
+}
+
+// Mode returns ModeCluster if no namespaces are set and ModeNamespaced
+// otherwise.
+func (s Scope) Mode() Mode {
+	if len(s.Namespaces) == 0 {
+		return ModeCluster
+	}
+	return ModeNamespaced
+}
+
+// CacheOptions returns cache options that restrict the manager's cache to the
+// watched namespaces. In ModeCluster the returned options are empty, which
+// makes the cache watch all namespaces.
+func (s Scope) CacheOptions() cache.Options {
+	if s.Mode() == ModeCluster {
+		return cache.Options{}
+	}
+	defaults := make(map[string]cache.Config, len(s.Namespaces))
+	for _, ns := range s.Namespaces {
+		defaults[ns] = cache.Config{}
+	}
+	return cache.Options{DefaultNamespaces: defaults}
+}
+
+// Contains reports whether namespace is watched.
+func (s Scope) Contains(namespace string) bool {
+	if s.Mode() == ModeCluster {
+		return true
+	}
+	i := sort.SearchStrings(s.Namespaces, namespace)
+	return i < len(s.Namespaces) && s.Namespaces[i] == namespace
+}
+
+// Resolve builds the Scope from an explicit namespace list or a namespace
+// label selector; the two are mutually exclusive. When selector is set the
+// namespaces are listed through reader. A selector that matches no namespace
+// is an error, since silently falling back to cluster-wide mode would widen
+// the operator's reach.
+func Resolve(ctx context.Context, reader client.Reader, namespaces []string, selector string) (Scope, error) {
+	if selector == "" {
+		return Scope{Namespaces: sortedUnique(namespaces)}, nil
+	}
+	if len(namespaces) > 0 {
+		return Scope{}, fmt.Errorf("scope: namespace list and namespace selector are mutually exclusive")
+	}
+
+	sel, err := labels.Parse(selector)
+	if err != nil {
+		return Scope{}, fmt.Errorf("scope: parsing namespace selector %q: %w", selector, err)
+	}
+	nsList := &corev1.NamespaceList{}
+	if err := reader.List(ctx, nsList, client.MatchingLabelsSelector{Selector: sel}); err != nil {
+		return Scope{}, fmt.Errorf("scope: listing namespaces for selector %q: %w", selector, err)
+	}
+	if len(nsList.Items) == 0 {
+		return Scope{}, fmt.Errorf("scope: namespace selector %q matches no namespaces", selector)
+	}
+	resolved := make([]string, 0, len(nsList.Items))
+	for i := range nsList.Items {
+		resolved = append(resolved, nsList.Items[i].Name)
+	}
+	return Scope{Namespaces: sortedUnique(resolved)}, nil
+}
+
+// sortedUnique returns a sorted copy of in without duplicates or empty
+// strings.
+func sortedUnique(in []string) []string {
+	seen := make(map[string]bool, len(in))
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
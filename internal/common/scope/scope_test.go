@@ -0,0 +1,95 @@
+package scope
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newNamespaceClient(g *WithT, namespaces ...*corev1.Namespace) client.Client {
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	objs := make([]client.Object, 0, len(namespaces))
+	for _, ns := range namespaces {
+		objs = append(objs, ns)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func namespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestResolve_ExplicitList(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, err := Resolve(context.Background(), nil, []string{"b", "a", "b", ""}, "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(s.Namespaces).To(Equal([]string{"a", "b"}))
+	g.Expect(s.Mode()).To(Equal(ModeNamespaced))
+}
+
+func TestResolve_EmptyIsClusterWide(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, err := Resolve(context.Background(), nil, nil, "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(s.Mode()).To(Equal(ModeCluster))
+	g.Expect(s.CacheOptions()).To(Equal(cache.Options{}))
+	g.Expect(s.Contains("anything")).To(BeTrue())
+}
+
+func TestResolve_Selector(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	c := newNamespaceClient(g,
+		namespace("tenant-a", map[string]string{"forge.c5c3.io/tenant": "a"}),
+		namespace("tenant-b", map[string]string{"forge.c5c3.io/tenant": "b"}),
+		namespace("kube-system", nil),
+	)
+
+	s, err := Resolve(context.Background(), c, nil, "forge.c5c3.io/tenant in (a,b)")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(s.Namespaces).To(Equal([]string{"tenant-a", "tenant-b"}))
+	g.Expect(s.Contains("tenant-a")).To(BeTrue())
+	g.Expect(s.Contains("kube-system")).To(BeFalse())
+}
+
+func TestResolve_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		namespaces    []string
+		selector      string
+		failureSubstr string
+	}{
+		{"list and selector", []string{"a"}, "tenant=a", "mutually exclusive"},
+		{"invalid selector", nil, "tenant in (", "parsing namespace selector"},
+		{"no match", nil, "tenant=none", "matches no namespaces"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			c := newNamespaceClient(g, namespace("a", map[string]string{"tenant": "a"}))
+			_, err := Resolve(context.Background(), c, tc.namespaces, tc.selector)
+			g.Expect(err).To(MatchError(ContainSubstring(tc.failureSubstr)))
+		})
+	}
+}
+
+func TestScope_CacheOptions_Namespaced(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	opts := Scope{Namespaces: []string{"a", "b"}}.CacheOptions()
+	g.Expect(opts.DefaultNamespaces).To(HaveLen(2))
+	g.Expect(opts.DefaultNamespaces).To(HaveKey("a"))
+	g.Expect(opts.DefaultNamespaces).To(HaveKey("b"))
+}
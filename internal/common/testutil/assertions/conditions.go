@@ -44,25 +44,38 @@ func EventuallyCondition(ctx context.Context, g gomega.Gomega, c client.Client,
 	g.Eventually(func(eg gomega.Gomega) {
 		eg.Expect(c.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(gomega.Succeed())
 
-		unstrMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
-		eg.Expect(err).NotTo(gomega.HaveOccurred(), "failed to convert object to unstructured")
-
-		rawConditions, found, nestedErr := unstructured.NestedSlice(unstrMap, "status", "conditions")
-		eg.Expect(nestedErr).NotTo(gomega.HaveOccurred(), "failed to read .status.conditions")
+		conditions, found, err := conditionsOf(obj)
+		eg.Expect(err).NotTo(gomega.HaveOccurred())
 		eg.Expect(found).To(gomega.BeTrue(), "object has no .status.conditions field")
 
-		conditions := make([]metav1.Condition, 0, len(rawConditions))
-		for _, raw := range rawConditions {
-			condMap, ok := raw.(map[string]interface{})
-			if !ok {
-				continue
-			}
-			var cond metav1.Condition
-			if convErr := runtime.DefaultUnstructuredConverter.FromUnstructured(condMap, &cond); convErr == nil {
-				conditions = append(conditions, cond)
-			}
-		}
-
 		AssertCondition(eg, conditions, condType, status)
 	}).WithTimeout(timeout).WithPolling(defaultPollingInterval).Should(gomega.Succeed())
 }
+
+// conditionsOf extracts .status.conditions from any object using unstructured
+// conversion. Entries that cannot be decoded as a metav1.Condition are
+// skipped; found is false if the object has no conditions field.
+func conditionsOf(obj runtime.Object) (conditions []metav1.Condition, found bool, err error) {
+	unstrMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to convert object to unstructured: %w", err)
+	}
+
+	rawConditions, found, err := unstructured.NestedSlice(unstrMap, "status", "conditions")
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read .status.conditions: %w", err)
+	}
+
+	conditions = make([]metav1.Condition, 0, len(rawConditions))
+	for _, raw := range rawConditions {
+		condMap, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var cond metav1.Condition
+		if convErr := runtime.DefaultUnstructuredConverter.FromUnstructured(condMap, &cond); convErr == nil {
+			conditions = append(conditions, cond)
+		}
+	}
+	return conditions, found, nil
+}
@@ -1,3 +1,20 @@
 // Package assertions provides custom gomega assertion helpers for Kubernetes
 // resource conditions and existence checks in tests.
+//
+// MatchGolden and AssertGolden compare rendered configuration files and
+// normalised objects against golden files in testdata/. Run the tests with
+// -update-golden (or UPDATE_GOLDEN=true) to record new golden files, and
+// review the resulting diff like any other change.
+//
+// AssertControlledBy and AssertAllControlledBy verify that the objects a
+// controller creates carry a controller OwnerReference back to their CR, and
+// AssertCascadeDeletion verifies that deleting the CR removes them, using
+// simulators.SimulateGarbageCollection in place of the garbage collector that
+// envtest lacks.
+//
+// EventuallyCondition only checks the state an object ends up in. To assert
+// how it got there, RecordConditions watches an object and records every
+// condition transition; HaveTransitionedInOrder and NeverHaveCondition then
+// check ordering (DatabaseReady became True before DeploymentReady) and
+// invariants (Ready never dropped to False during a rollout).
 package assertions
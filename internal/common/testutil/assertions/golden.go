@@ -0,0 +1,254 @@
+package assertions
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// UpdateGoldenEnv is the environment variable that, when set to a true value,
+// rewrites golden files instead of comparing against them, like the
+// -update-golden test flag.
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// NormalizedPlaceholder replaces volatile values in normalised objects.
+const NormalizedPlaceholder = "<normalized>"
+
+var updateGolden = flag.Bool("update-golden", false, "rewrite golden files with the actual output instead of comparing against them")
+
+// removedMetadataFields are server-populated metadata fields that are dropped
+// before an object is compared against a golden file.
+var removedMetadataFields = []string{"resourceVersion", "uid", "generation", "managedFields", "selfLink", "creationTimestamp", "deletionTimestamp"}
+
+// GoldenOption customises how MatchGolden normalises the actual value.
+type GoldenOption func(*goldenOptions)
+
+type goldenOptions struct {
+	ignoredFields [][]string
+	replacements  []replacement
+}
+
+type replacement struct {
+	re   *regexp.Regexp
+	repl string
+}
+
+// IgnoreFields removes the given dot-separated field paths (for example
+// "spec.template.metadata.annotations") from objects before comparison.
+// It has no effect on text input.
+func IgnoreFields(paths ...string) GoldenOption {
+	return func(o *goldenOptions) {
+		for _, p := range paths {
+			o.ignoredFields = append(o.ignoredFields, strings.Split(p, "."))
+		}
+	}
+}
+
+// ReplaceRegexp replaces every match of pattern in the rendered output with
+// repl before comparison, e.g. to mask generated passwords in an INI file.
+// repl may reference capture groups as in regexp.ReplaceAllString.
+func ReplaceRegexp(pattern, repl string) GoldenOption {
+	re := regexp.MustCompile(pattern)
+	return func(o *goldenOptions) {
+		o.replacements = append(o.replacements, replacement{re: re, repl: repl})
+	}
+}
+
+// AssertGolden asserts that actual matches the golden file at path; see
+// MatchGolden.
+func AssertGolden(g gomega.Gomega, path string, actual interface{}, opts ...GoldenOption) {
+	g.Expect(actual).To(MatchGolden(path, opts...))
+}
+
+// MatchGolden returns a matcher that compares actual against the golden file
+// at path, conventionally below testdata/.
+//
+// actual may be a string or []byte, compared as text (e.g. a rendered
+// keystone.conf or policy file), or a runtime.Object, which is normalised and
+// rendered as YAML: server-populated metadata (resourceVersion, uid,
+// generation, managedFields, creation and deletion timestamps) is removed,
+// and owner reference UIDs and all fields ending in "Time" or "Timestamp"
+// (such as condition lastTransitionTime) are replaced by
+// NormalizedPlaceholder. Text is compared with trailing whitespace trimmed
+// from each line.
+//
+// When the test runs with -update-golden or UPDATE_GOLDEN=true, the golden
+// file is (re)written with the actual output and the matcher succeeds. On
+// mismatch the failure message is a unified diff from the golden file to the
+// actual output.
+func MatchGolden(path string, opts ...GoldenOption) types.GomegaMatcher {
+	m := &goldenMatcher{path: path}
+	for _, opt := range opts {
+		opt(&m.opts)
+	}
+	return m
+}
+
+type goldenMatcher struct {
+	path string
+	opts goldenOptions
+
+	diff string
+}
+
+func (m *goldenMatcher) Match(actual interface{}) (bool, error) {
+	rendered, err := renderGolden(actual, m.opts)
+	if err != nil {
+		return false, err
+	}
+
+	if updateGoldenEnabled() {
+		if err := os.MkdirAll(filepath.Dir(m.path), 0o755); err != nil {
+			return false, fmt.Errorf("creating golden file directory: %w", err)
+		}
+		if err := os.WriteFile(m.path, rendered, 0o644); err != nil {
+			return false, fmt.Errorf("writing golden file %s: %w", m.path, err)
+		}
+		return true, nil
+	}
+
+	expected, err := os.ReadFile(m.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, fmt.Errorf("golden file %s does not exist; run the test with -update-golden or %s=true to create it", m.path, UpdateGoldenEnv)
+		}
+		return false, fmt.Errorf("reading golden file %s: %w", m.path, err)
+	}
+	expected = normalizeText(expected)
+	if bytes.Equal(expected, rendered) {
+		return true, nil
+	}
+
+	m.diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(expected)),
+		B:        difflib.SplitLines(string(rendered)),
+		FromFile: m.path,
+		ToFile:   "actual",
+		Context:  3,
+	})
+	if err != nil {
+		return false, fmt.Errorf("computing diff: %w", err)
+	}
+	return false, nil
+}
+
+func (m *goldenMatcher) FailureMessage(interface{}) string {
+	return fmt.Sprintf("output does not match golden file %s (run with -update-golden or %s=true to accept it):\n%s", m.path, UpdateGoldenEnv, m.diff)
+}
+
+func (m *goldenMatcher) NegatedFailureMessage(interface{}) string {
+	return fmt.Sprintf("expected output not to match golden file %s", m.path)
+}
+
+// updateGoldenEnabled reports whether golden files should be rewritten.
+func updateGoldenEnabled() bool {
+	if *updateGolden {
+		return true
+	}
+	v, _ := strconv.ParseBool(os.Getenv(UpdateGoldenEnv))
+	return v
+}
+
+// renderGolden converts actual to the normalised text stored in golden files.
+func renderGolden(actual interface{}, opts goldenOptions) ([]byte, error) {
+	var out []byte
+	switch v := actual.(type) {
+	case string:
+		out = []byte(v)
+	case []byte:
+		out = v
+	case runtime.Object:
+		obj, err := normalizeObject(v, opts.ignoredFields)
+		if err != nil {
+			return nil, err
+		}
+		if out, err = yaml.Marshal(obj); err != nil {
+			return nil, fmt.Errorf("rendering object as YAML: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("MatchGolden expects a string, []byte or runtime.Object, got %T", actual)
+	}
+
+	s := string(out)
+	for _, r := range opts.replacements {
+		s = r.re.ReplaceAllString(s, r.repl)
+	}
+	return normalizeText([]byte(s)), nil
+}
+
+// normalizeObject converts obj to an unstructured map and removes or masks
+// volatile fields.
+func normalizeObject(obj runtime.Object, ignored [][]string) (map[string]interface{}, error) {
+	var content map[string]interface{}
+	if u, ok := obj.(runtime.Unstructured); ok {
+		content = runtime.DeepCopyJSON(u.UnstructuredContent())
+	} else {
+		var err error
+		if content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj); err != nil {
+			return nil, fmt.Errorf("converting %T to unstructured: %w", obj, err)
+		}
+	}
+
+	for _, field := range removedMetadataFields {
+		unstructured.RemoveNestedField(content, "metadata", field)
+	}
+	if refs, found, _ := unstructured.NestedSlice(content, "metadata", "ownerReferences"); found {
+		for _, ref := range refs {
+			if m, ok := ref.(map[string]interface{}); ok {
+				if _, ok := m["uid"]; ok {
+					m["uid"] = NormalizedPlaceholder
+				}
+			}
+		}
+		_ = unstructured.SetNestedSlice(content, refs, "metadata", "ownerReferences")
+	}
+	for _, path := range ignored {
+		unstructured.RemoveNestedField(content, path...)
+	}
+	maskTimestamps(content)
+	if metadata, found, _ := unstructured.NestedMap(content, "metadata"); found && len(metadata) == 0 {
+		delete(content, "metadata")
+	}
+	return content, nil
+}
+
+// maskTimestamps replaces the non-empty values of all fields whose name ends
+// in "Time" or "Timestamp" with NormalizedPlaceholder.
+func maskTimestamps(v interface{}) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if (strings.HasSuffix(k, "Time") || strings.HasSuffix(k, "Timestamp")) && val != nil && val != "" {
+				t[k] = NormalizedPlaceholder
+				continue
+			}
+			maskTimestamps(val)
+		}
+	case []interface{}:
+		for _, val := range t {
+			maskTimestamps(val)
+		}
+	}
+}
+
+// normalizeText trims trailing whitespace from every line and ensures the
+// text ends with exactly one newline.
+func normalizeText(data []byte) []byte {
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
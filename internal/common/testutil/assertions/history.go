@@ -0,0 +1,353 @@
+package assertions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onsi/gomega/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// ConditionTransition is a change of a single condition observed by a
+// ConditionRecorder.
+type ConditionTransition struct {
+	// Type is the condition type, e.g. "Ready".
+	Type string
+	// Status is the status the condition changed to.
+	Status metav1.ConditionStatus
+	// Reason is the condition's reason after the change.
+	Reason string
+	// Message is the condition's message after the change.
+	Message string
+	// ObservedAt is when the recorder received the change. Unlike the
+	// condition's lastTransitionTime it has sub-second precision, so it
+	// orders transitions that happen within the same second.
+	ObservedAt time.Time
+}
+
+// String renders the transition as Type=Status (Reason).
+func (t ConditionTransition) String() string {
+	if t.Reason == "" {
+		return fmt.Sprintf("%s=%s", t.Type, t.Status)
+	}
+	return fmt.Sprintf("%s=%s (%s)", t.Type, t.Status, t.Reason)
+}
+
+// ConditionState identifies a condition type and status to look for in a
+// recorded history.
+type ConditionState struct {
+	Type   string
+	Status metav1.ConditionStatus
+}
+
+// String renders the state as Type=Status.
+func (s ConditionState) String() string {
+	return fmt.Sprintf("%s=%s", s.Type, s.Status)
+}
+
+func (s ConditionState) matches(t ConditionTransition) bool {
+	return t.Type == s.Type && t.Status == s.Status
+}
+
+// ConditionRecorder watches a single object and records every change of its
+// .status.conditions in the order it was observed. A condition is recorded
+// when it first appears and whenever its status or reason changes, so the
+// first entry per type is the state at the time recording started.
+//
+// Because it records every watch event, a recorder catches intermediate
+// states that polling with EventuallyCondition can miss, such as a Ready
+// condition briefly flapping to False during a rollout.
+type ConditionRecorder struct {
+	key    client.ObjectKey
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu          sync.Mutex
+	transitions []ConditionTransition
+	last        map[string]ConditionTransition
+}
+
+// RecordConditions starts recording the condition transitions of obj, which
+// must exist. obj only identifies the object and its kind; it is not
+// modified. Recording continues until ctx is cancelled or Stop is called.
+func RecordConditions(ctx context.Context, c client.WithWatch, obj client.Object) (*ConditionRecorder, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return nil, fmt.Errorf("resolving kind of %T: %w", obj, err)
+	}
+
+	key := client.ObjectKeyFromObject(obj)
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(gvk)
+	if err := c.Get(ctx, key, current); err != nil {
+		return nil, fmt.Errorf("getting %s %s: %w", gvk.Kind, key, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	r := &ConditionRecorder{
+		key:    key,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		last:   map[string]ConditionTransition{},
+	}
+	r.observe(current)
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+	// The first watch is established before returning so that no change
+	// made by the caller afterwards can be missed.
+	resourceVersion := current.GetResourceVersion()
+	w, err := r.watch(ctx, c, list, resourceVersion)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("watching %s %s: %w", gvk.Kind, key, err)
+	}
+	go r.run(ctx, c, list, w, resourceVersion)
+	return r, nil
+}
+
+func (r *ConditionRecorder) watch(ctx context.Context, c client.WithWatch, list *unstructured.UnstructuredList, resourceVersion string) (watch.Interface, error) {
+	return c.Watch(ctx, list.DeepCopy(),
+		client.InNamespace(r.key.Namespace),
+		client.MatchingFields{"metadata.name": r.key.Name},
+		&client.ListOptions{Raw: &metav1.ListOptions{ResourceVersion: resourceVersion}},
+	)
+}
+
+// run consumes w and re-establishes the watch whenever the API server closes
+// it, until ctx is cancelled.
+func (r *ConditionRecorder) run(ctx context.Context, c client.WithWatch, list *unstructured.UnstructuredList, w watch.Interface, resourceVersion string) {
+	defer close(r.done)
+
+	for {
+		resourceVersion = r.consume(ctx, w, resourceVersion)
+		w.Stop()
+		if ctx.Err() != nil {
+			return
+		}
+
+		for {
+			var err error
+			if w, err = r.watch(ctx, c, list, resourceVersion); err == nil {
+				break
+			}
+			// Retry with a fresh watch; an expired resourceVersion would
+			// otherwise fail forever.
+			resourceVersion = ""
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(defaultPollingInterval):
+			}
+		}
+	}
+}
+
+// consume records the events of w until it is closed or ctx is cancelled and
+// returns the resourceVersion to resume from.
+func (r *ConditionRecorder) consume(ctx context.Context, w watch.Interface, resourceVersion string) string {
+	for {
+		select {
+		case <-ctx.Done():
+			return resourceVersion
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return resourceVersion
+			}
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				obj, isObj := event.Object.(client.Object)
+				if !isObj || obj.GetName() != r.key.Name || obj.GetNamespace() != r.key.Namespace {
+					continue
+				}
+				r.observe(obj)
+				resourceVersion = obj.GetResourceVersion()
+			case watch.Error:
+				// Most likely 410 Gone; restart from the current state.
+				return ""
+			}
+		}
+	}
+}
+
+func (r *ConditionRecorder) observe(obj runtime.Object) {
+	conditions, _, err := conditionsOf(obj)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, cond := range conditions {
+		prev, seen := r.last[cond.Type]
+		if seen && prev.Status == cond.Status && prev.Reason == cond.Reason {
+			continue
+		}
+		t := ConditionTransition{
+			Type:       cond.Type,
+			Status:     cond.Status,
+			Reason:     cond.Reason,
+			Message:    cond.Message,
+			ObservedAt: now,
+		}
+		r.last[cond.Type] = t
+		r.transitions = append(r.transitions, t)
+	}
+}
+
+// Stop ends recording and waits for the watch to shut down. The recorded
+// history remains available.
+func (r *ConditionRecorder) Stop() {
+	r.cancel()
+	<-r.done
+}
+
+// Transitions returns a copy of all transitions recorded so far, oldest
+// first.
+func (r *ConditionRecorder) Transitions() []ConditionTransition {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ConditionTransition(nil), r.transitions...)
+}
+
+// History returns the transitions of a single condition type, oldest first.
+func (r *ConditionRecorder) History(condType string) []ConditionTransition {
+	var history []ConditionTransition
+	for _, t := range r.Transitions() {
+		if t.Type == condType {
+			history = append(history, t)
+		}
+	}
+	return history
+}
+
+// HaveTransitionedInOrder succeeds if the recorded history contains the
+// given states in the given order, possibly with other transitions in
+// between. For example, DatabaseReady becoming True before DeploymentReady:
+//
+//	g.Eventually(recorder.Transitions).Should(assertions.HaveTransitionedInOrder(
+//		assertions.ConditionState{Type: "DatabaseReady", Status: metav1.ConditionTrue},
+//		assertions.ConditionState{Type: "DeploymentReady", Status: metav1.ConditionTrue},
+//	))
+//
+// The actual value must be a *ConditionRecorder or a []ConditionTransition.
+func HaveTransitionedInOrder(states ...ConditionState) types.GomegaMatcher {
+	return &transitionOrderMatcher{states: states}
+}
+
+// NeverHaveCondition succeeds if the recorded history contains no transition
+// of condType to status, e.g. Ready never flapping to False while a rollout
+// is in progress:
+//
+//	g.Expect(recorder).To(assertions.NeverHaveCondition("Ready", metav1.ConditionFalse))
+//
+// The actual value must be a *ConditionRecorder or a []ConditionTransition.
+func NeverHaveCondition(condType string, status metav1.ConditionStatus) types.GomegaMatcher {
+	return &neverConditionMatcher{state: ConditionState{Type: condType, Status: status}}
+}
+
+type transitionOrderMatcher struct {
+	states  []ConditionState
+	history []ConditionTransition
+	// matched is the number of leading states found in order.
+	matched int
+}
+
+func (m *transitionOrderMatcher) Match(actual interface{}) (bool, error) {
+	history, err := transitionsOf(actual)
+	if err != nil {
+		return false, err
+	}
+	m.history = history
+	m.matched = 0
+	for _, t := range history {
+		if m.matched < len(m.states) && m.states[m.matched].matches(t) {
+			m.matched++
+		}
+	}
+	return m.matched == len(m.states), nil
+}
+
+func (m *transitionOrderMatcher) FailureMessage(interface{}) string {
+	return fmt.Sprintf("Expected condition history\n%s\nto contain in order\n\t%s\nbut %s was not observed after %s",
+		formatHistory(m.history), joinStates(m.states), m.states[m.matched], describePrefix(m.states[:m.matched]))
+}
+
+func (m *transitionOrderMatcher) NegatedFailureMessage(interface{}) string {
+	return fmt.Sprintf("Expected condition history\n%s\nnot to contain in order\n\t%s",
+		formatHistory(m.history), joinStates(m.states))
+}
+
+type neverConditionMatcher struct {
+	state   ConditionState
+	history []ConditionTransition
+}
+
+func (m *neverConditionMatcher) Match(actual interface{}) (bool, error) {
+	history, err := transitionsOf(actual)
+	if err != nil {
+		return false, err
+	}
+	m.history = history
+	for _, t := range history {
+		if m.state.matches(t) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (m *neverConditionMatcher) FailureMessage(interface{}) string {
+	return fmt.Sprintf("Expected condition history\n%s\nnever to contain %s", formatHistory(m.history), m.state)
+}
+
+func (m *neverConditionMatcher) NegatedFailureMessage(interface{}) string {
+	return fmt.Sprintf("Expected condition history\n%s\nto contain %s", formatHistory(m.history), m.state)
+}
+
+func transitionsOf(actual interface{}) ([]ConditionTransition, error) {
+	switch v := actual.(type) {
+	case *ConditionRecorder:
+		return v.Transitions(), nil
+	case []ConditionTransition:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("expected a *ConditionRecorder or []ConditionTransition, got %T", actual)
+	}
+}
+
+func formatHistory(history []ConditionTransition) string {
+	if len(history) == 0 {
+		return "\t<empty>"
+	}
+	lines := make([]string, 0, len(history))
+	for _, t := range history {
+		lines = append(lines, "\t"+t.ObservedAt.Format("15:04:05.000")+" "+t.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func joinStates(states []ConditionState) string {
+	parts := make([]string, 0, len(states))
+	for _, s := range states {
+		parts = append(parts, s.String())
+	}
+	return strings.Join(parts, " -> ")
+}
+
+func describePrefix(states []ConditionState) string {
+	if len(states) == 0 {
+		return "the start of recording"
+	}
+	return states[len(states)-1].String()
+}
@@ -0,0 +1,197 @@
+package assertions
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func transition(condType string, status metav1.ConditionStatus) ConditionTransition {
+	return ConditionTransition{Type: condType, Status: status}
+}
+
+func TestHaveTransitionedInOrder(t *testing.T) {
+	history := []ConditionTransition{
+		transition("Ready", metav1.ConditionFalse),
+		transition("DatabaseReady", metav1.ConditionTrue),
+		transition("DeploymentReady", metav1.ConditionTrue),
+		transition("Ready", metav1.ConditionTrue),
+	}
+
+	tests := []struct {
+		name      string
+		states    []ConditionState
+		wantMatch bool
+	}{
+		{
+			name: "states in order",
+			states: []ConditionState{
+				{Type: "DatabaseReady", Status: metav1.ConditionTrue},
+				{Type: "DeploymentReady", Status: metav1.ConditionTrue},
+			},
+			wantMatch: true,
+		},
+		{
+			name: "states with gaps in between",
+			states: []ConditionState{
+				{Type: "Ready", Status: metav1.ConditionFalse},
+				{Type: "Ready", Status: metav1.ConditionTrue},
+			},
+			wantMatch: true,
+		},
+		{
+			name: "states out of order",
+			states: []ConditionState{
+				{Type: "DeploymentReady", Status: metav1.ConditionTrue},
+				{Type: "DatabaseReady", Status: metav1.ConditionTrue},
+			},
+			wantMatch: false,
+		},
+		{
+			name: "state never observed",
+			states: []ConditionState{
+				{Type: "DatabaseReady", Status: metav1.ConditionFalse},
+			},
+			wantMatch: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			matcher := HaveTransitionedInOrder(tc.states...)
+			matched, err := matcher.Match(history)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(matched).To(gomega.Equal(tc.wantMatch))
+			if !matched {
+				g.Expect(matcher.FailureMessage(history)).To(gomega.ContainSubstring("was not observed after"))
+			}
+		})
+	}
+}
+
+func TestNeverHaveCondition(t *testing.T) {
+	tests := []struct {
+		name      string
+		history   []ConditionTransition
+		wantMatch bool
+	}{
+		{
+			name: "status never recorded",
+			history: []ConditionTransition{
+				transition("Ready", metav1.ConditionTrue),
+				transition("DeploymentReady", metav1.ConditionFalse),
+			},
+			wantMatch: true,
+		},
+		{
+			name: "status recorded",
+			history: []ConditionTransition{
+				transition("Ready", metav1.ConditionTrue),
+				transition("Ready", metav1.ConditionFalse),
+				transition("Ready", metav1.ConditionTrue),
+			},
+			wantMatch: false,
+		},
+		{
+			name:      "empty history",
+			wantMatch: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			matched, err := NeverHaveCondition("Ready", metav1.ConditionFalse).Match(tc.history)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(matched).To(gomega.Equal(tc.wantMatch))
+		})
+	}
+}
+
+func TestTransitionMatchers_RejectOtherTypes(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	_, err := NeverHaveCondition("Ready", metav1.ConditionFalse).Match("Ready=False")
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestRecordConditions(t *testing.T) {
+	g := gomega.NewWithT(t)
+	ctx := context.Background()
+
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(gvk.GroupVersion().WithKind("WidgetList"), &unstructured.UnstructuredList{})
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName("widget")
+	obj.SetNamespace("default")
+	setConditions := func(conditions ...map[string]interface{}) {
+		t.Helper()
+		raw := make([]interface{}, 0, len(conditions))
+		for _, cond := range conditions {
+			raw = append(raw, cond)
+		}
+		g.Expect(unstructured.SetNestedSlice(obj.Object, raw, "status", "conditions")).To(gomega.Succeed())
+	}
+	condition := func(condType string, status metav1.ConditionStatus, reason string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":               condType,
+			"status":             string(status),
+			"reason":             reason,
+			"lastTransitionTime": "2026-01-01T00:00:00Z",
+		}
+	}
+
+	setConditions(condition("Ready", metav1.ConditionFalse, "Pending"))
+	g.Expect(c.Create(ctx, obj)).To(gomega.Succeed())
+
+	recorder, err := RecordConditions(ctx, c, obj)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer recorder.Stop()
+
+	// A status-only update without a change must not be recorded.
+	g.Expect(c.Update(ctx, obj)).To(gomega.Succeed())
+	setConditions(condition("Ready", metav1.ConditionFalse, "Pending"), condition("DatabaseReady", metav1.ConditionTrue, "Ready"))
+	g.Expect(c.Update(ctx, obj)).To(gomega.Succeed())
+	setConditions(condition("Ready", metav1.ConditionTrue, "Ready"), condition("DatabaseReady", metav1.ConditionTrue, "Ready"))
+	g.Expect(c.Update(ctx, obj)).To(gomega.Succeed())
+
+	other := &unstructured.Unstructured{}
+	other.SetGroupVersionKind(gvk)
+	other.SetName("other")
+	other.SetNamespace("default")
+	g.Expect(unstructured.SetNestedSlice(other.Object, []interface{}{condition("Ready", metav1.ConditionUnknown, "Other")}, "status", "conditions")).To(gomega.Succeed())
+	g.Expect(c.Create(ctx, other)).To(gomega.Succeed())
+
+	g.Eventually(recorder).WithTimeout(time.Second).WithPolling(10 * time.Millisecond).Should(HaveTransitionedInOrder(
+		ConditionState{Type: "Ready", Status: metav1.ConditionFalse},
+		ConditionState{Type: "DatabaseReady", Status: metav1.ConditionTrue},
+		ConditionState{Type: "Ready", Status: metav1.ConditionTrue},
+	))
+	g.Expect(recorder).To(NeverHaveCondition("Ready", metav1.ConditionUnknown))
+	g.Expect(recorder.History("Ready")).To(gomega.HaveLen(2))
+	g.Expect(recorder.Transitions()).To(gomega.HaveLen(3))
+
+	_, err = RecordConditions(ctx, c, &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": "missing", "namespace": "default"},
+	}})
+	g.Expect(apierrors.IsNotFound(errors.Unwrap(err))).To(gomega.BeTrue())
+}
@@ -0,0 +1,97 @@
+package assertions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/c5c3/forge/internal/common/testutil/simulators"
+)
+
+// AssertControlledBy asserts that every object in objs carries a controller
+// OwnerReference pointing at owner, matching its API group, kind, name and
+// UID. The owner's kind is resolved through the client's scheme, so owner
+// must be persisted (have a UID) and its type must be registered.
+func AssertControlledBy(g gomega.Gomega, c client.Client, owner client.Object, objs ...client.Object) {
+	gvk, err := apiutil.GVKForObject(owner, c.Scheme())
+	if !g.Expect(err).NotTo(gomega.HaveOccurred(), "failed to resolve the owner's kind") {
+		return
+	}
+	g.Expect(owner.GetUID()).NotTo(gomega.BeEmpty(), "owner %s %s has no UID; fetch it from the API server first", gvk.Kind, client.ObjectKeyFromObject(owner))
+
+	for _, obj := range objs {
+		ref := metav1.GetControllerOfNoCopy(obj)
+		if !g.Expect(ref).NotTo(gomega.BeNil(), "%T %s has no controller owner reference", obj, client.ObjectKeyFromObject(obj)) {
+			continue
+		}
+
+		refGV, err := schema.ParseGroupVersion(ref.APIVersion)
+		g.Expect(err).NotTo(gomega.HaveOccurred(), "%T %s has an invalid owner apiVersion %q", obj, client.ObjectKeyFromObject(obj), ref.APIVersion)
+		g.Expect(refGV.Group == gvk.Group && ref.Kind == gvk.Kind && ref.Name == owner.GetName() && ref.UID == owner.GetUID()).To(
+			gomega.BeTrue(),
+			fmt.Sprintf("%T %s is controlled by %s %s (uid %s), expected %s %s (uid %s)",
+				obj, client.ObjectKeyFromObject(obj), ref.Kind, ref.Name, ref.UID, gvk.Kind, owner.GetName(), owner.GetUID()),
+		)
+	}
+}
+
+// AssertAllControlledBy lists the objects selected by opts into list and
+// asserts that there is at least one and that all of them are controlled by
+// owner, as AssertControlledBy does. Combine it with client.InNamespace and
+// client.MatchingLabels to select the children of a single CR.
+func AssertAllControlledBy(ctx context.Context, g gomega.Gomega, c client.Client, owner client.Object, list client.ObjectList, opts ...client.ListOption) {
+	g.Expect(c.List(ctx, list, opts...)).To(gomega.Succeed())
+
+	items, err := meta.ExtractList(list)
+	if !g.Expect(err).NotTo(gomega.HaveOccurred(), "failed to extract list items") {
+		return
+	}
+	g.Expect(items).NotTo(gomega.BeEmpty(), "expected at least one %T", list)
+
+	objs := make([]client.Object, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(client.Object)
+		g.Expect(ok).To(gomega.BeTrue(), "list item %T is not a client.Object", item)
+		objs = append(objs, obj)
+	}
+	AssertControlledBy(g, c, owner, objs...)
+}
+
+// AssertCascadeDeletion deletes owner and asserts that all dependents are
+// removed within timeout. envtest runs no garbage collector, so each poll
+// runs simulators.SimulateGarbageCollection for the dependents' kinds before
+// checking that every dependent is gone.
+func AssertCascadeDeletion(ctx context.Context, g gomega.Gomega, c client.Client, owner client.Object, timeout time.Duration, dependents ...client.Object) {
+	kinds := make([]schema.GroupVersionKind, 0, len(dependents))
+	seen := map[schema.GroupVersionKind]bool{}
+	for _, dep := range dependents {
+		gvk, err := apiutil.GVKForObject(dep, c.Scheme())
+		g.Expect(err).NotTo(gomega.HaveOccurred(), "failed to resolve the kind of %T", dep)
+		if !seen[gvk] {
+			seen[gvk] = true
+			kinds = append(kinds, gvk)
+		}
+	}
+
+	err := c.Delete(ctx, owner, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	g.Expect(client.IgnoreNotFound(err)).To(gomega.Succeed(), "failed to delete owner")
+
+	g.Eventually(func(eg gomega.Gomega) {
+		_, gcErr := simulators.SimulateGarbageCollection(ctx, c, kinds...)
+		eg.Expect(gcErr).NotTo(gomega.HaveOccurred())
+
+		for _, dep := range dependents {
+			getErr := c.Get(ctx, client.ObjectKeyFromObject(dep), dep)
+			eg.Expect(apierrors.IsNotFound(getErr)).To(gomega.BeTrue(),
+				"expected %T %s to be garbage collected, got: %v", dep, client.ObjectKeyFromObject(dep), getErr)
+		}
+	}).WithTimeout(timeout).WithPolling(defaultPollingInterval).Should(gomega.Succeed())
+}
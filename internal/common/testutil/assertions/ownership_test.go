@@ -0,0 +1,154 @@
+package assertions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func newOwnershipFixture(t *testing.T) (*runtime.Scheme, *appsv1.Deployment) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	owner := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "default", UID: "owner-uid"}}
+	return scheme, owner
+}
+
+func ownedConfigMap(t *testing.T, scheme *runtime.Scheme, name string, owner client.Object, controller bool) *corev1.ConfigMap {
+	t.Helper()
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      name,
+		Namespace: "default",
+		Labels:    map[string]string{"app": "owned"},
+	}}
+	if owner == nil {
+		return cm
+	}
+	var err error
+	if controller {
+		err = controllerutil.SetControllerReference(owner, cm, scheme)
+	} else {
+		err = controllerutil.SetOwnerReference(owner, cm, scheme)
+	}
+	if err != nil {
+		t.Fatalf("setting owner reference: %v", err)
+	}
+	return cm
+}
+
+func TestAssertControlledBy(t *testing.T) {
+	scheme, owner := newOwnershipFixture(t)
+	other := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default", UID: "other-uid"}}
+
+	tests := []struct {
+		name       string
+		obj        client.Object
+		shouldPass bool
+	}{
+		{
+			name:       "controlled by owner",
+			obj:        ownedConfigMap(t, scheme, "cm", owner, true),
+			shouldPass: true,
+		},
+		{
+			name:       "no owner reference",
+			obj:        ownedConfigMap(t, scheme, "cm", nil, false),
+			shouldPass: false,
+		},
+		{
+			name:       "owner reference without controller flag",
+			obj:        ownedConfigMap(t, scheme, "cm", owner, false),
+			shouldPass: false,
+		},
+		{
+			name:       "controlled by another object",
+			obj:        ownedConfigMap(t, scheme, "cm", other, true),
+			shouldPass: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+			failed := false
+			testG := gomega.NewGomega(func(message string, callerSkip ...int) {
+				failed = true
+			})
+
+			AssertControlledBy(testG, c, owner, tc.obj)
+			g.Expect(failed).To(gomega.Equal(!tc.shouldPass))
+		})
+	}
+}
+
+func TestAssertAllControlledBy(t *testing.T) {
+	scheme, owner := newOwnershipFixture(t)
+
+	tests := []struct {
+		name       string
+		objects    []client.Object
+		shouldPass bool
+	}{
+		{
+			name: "all children controlled",
+			objects: []client.Object{
+				ownedConfigMap(t, scheme, "a", owner, true),
+				ownedConfigMap(t, scheme, "b", owner, true),
+			},
+			shouldPass: true,
+		},
+		{
+			name: "one child orphaned",
+			objects: []client.Object{
+				ownedConfigMap(t, scheme, "a", owner, true),
+				ownedConfigMap(t, scheme, "b", nil, false),
+			},
+			shouldPass: false,
+		},
+		{
+			name:       "no children",
+			shouldPass: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+
+			failed := false
+			testG := gomega.NewGomega(func(message string, callerSkip ...int) {
+				failed = true
+			})
+
+			AssertAllControlledBy(context.Background(), testG, c, owner, &corev1.ConfigMapList{},
+				client.InNamespace("default"), client.MatchingLabels{"app": "owned"})
+			g.Expect(failed).To(gomega.Equal(!tc.shouldPass))
+		})
+	}
+}
+
+func TestAssertCascadeDeletion(t *testing.T) {
+	g := gomega.NewWithT(t)
+	scheme, owner := newOwnershipFixture(t)
+	child := ownedConfigMap(t, scheme, "child", owner, true)
+	unrelated := ownedConfigMap(t, scheme, "unrelated", nil, false)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(owner, child, unrelated).Build()
+
+	AssertCascadeDeletion(context.Background(), g, c, owner, time.Second, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "child", Namespace: "default"},
+	})
+	AssertResourceExists(context.Background(), g, c, client.ObjectKeyFromObject(unrelated), &corev1.ConfigMap{})
+}
@@ -0,0 +1,142 @@
+package builders
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GroupVersionKinds of the cert-manager resources.
+var (
+	certificateGVK   = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+	clusterIssuerGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "ClusterIssuer"}
+)
+
+// CertificateBuilder provides a fluent builder for cert-manager Certificate
+// custom resources.
+type CertificateBuilder struct {
+	unstructuredBuilder[*CertificateBuilder]
+}
+
+// NewCertificateBuilder creates a CertificateBuilder for a Certificate named
+// "certificate" in DefaultNamespace, issued by the ClusterIssuer
+// "selfsigned". Unless WithSecretName is called, the certificate is stored in
+// the Secret "<name>-tls".
+func NewCertificateBuilder() *CertificateBuilder {
+	b := &CertificateBuilder{}
+	b.init(b, certificateGVK, "certificate", DefaultNamespace, map[string]interface{}{
+		"issuerRef": map[string]interface{}{
+			"name":  "selfsigned",
+			"kind":  "ClusterIssuer",
+			"group": "cert-manager.io",
+		},
+	})
+	return b
+}
+
+// WithSecretName sets spec.secretName.
+func (b *CertificateBuilder) WithSecretName(name string) *CertificateBuilder {
+	b.setSpec(name, "secretName")
+	return b
+}
+
+// WithIssuerRef sets spec.issuerRef to the cert-manager issuer of the given
+// name and kind ("Issuer" or "ClusterIssuer").
+func (b *CertificateBuilder) WithIssuerRef(name, kind string) *CertificateBuilder {
+	b.setSpec(map[string]interface{}{"name": name, "kind": kind, "group": "cert-manager.io"}, "issuerRef")
+	return b
+}
+
+// WithCommonName sets spec.commonName.
+func (b *CertificateBuilder) WithCommonName(commonName string) *CertificateBuilder {
+	b.setSpec(commonName, "commonName")
+	return b
+}
+
+// WithDNSNames replaces spec.dnsNames.
+func (b *CertificateBuilder) WithDNSNames(names ...string) *CertificateBuilder {
+	b.setSpec(toInterfaceSlice(names), "dnsNames")
+	return b
+}
+
+// WithDuration sets spec.duration.
+func (b *CertificateBuilder) WithDuration(d time.Duration) *CertificateBuilder {
+	b.setSpec(d.String(), "duration")
+	return b
+}
+
+// WithRenewBefore sets spec.renewBefore.
+func (b *CertificateBuilder) WithRenewBefore(d time.Duration) *CertificateBuilder {
+	b.setSpec(d.String(), "renewBefore")
+	return b
+}
+
+// WithPrivateKey sets spec.privateKey.algorithm ("RSA" or "ECDSA") and
+// spec.privateKey.size.
+func (b *CertificateBuilder) WithPrivateKey(algorithm string, size int64) *CertificateBuilder {
+	b.setSpec(map[string]interface{}{"algorithm": algorithm, "size": size}, "privateKey")
+	return b
+}
+
+// Build returns a deep copy of the constructed Certificate.
+func (b *CertificateBuilder) Build() *unstructured.Unstructured {
+	obj := b.build()
+	if b.specString("secretName") == "" {
+		_ = unstructured.SetNestedField(obj.Object, obj.GetName()+"-tls", "spec", "secretName")
+	}
+	return obj
+}
+
+// Create builds the Certificate and creates it in the cluster.
+func (b *CertificateBuilder) Create(ctx context.Context, c client.Client) (*unstructured.Unstructured, error) {
+	return createUnstructured(ctx, c, b.Build())
+}
+
+// ClusterIssuerBuilder provides a fluent builder for cert-manager
+// ClusterIssuer custom resources. ClusterIssuers are cluster-scoped, so the
+// built object has no namespace.
+type ClusterIssuerBuilder struct {
+	unstructuredBuilder[*ClusterIssuerBuilder]
+}
+
+// NewClusterIssuerBuilder creates a ClusterIssuerBuilder for a self-signed
+// ClusterIssuer named "selfsigned", matching the default issuer of
+// NewCertificateBuilder.
+func NewClusterIssuerBuilder() *ClusterIssuerBuilder {
+	b := &ClusterIssuerBuilder{}
+	b.init(b, clusterIssuerGVK, "selfsigned", "", map[string]interface{}{
+		"selfSigned": map[string]interface{}{},
+	})
+	return b
+}
+
+// WithSelfSigned makes the issuer self-signed, replacing a CA configuration.
+func (b *ClusterIssuerBuilder) WithSelfSigned() *ClusterIssuerBuilder {
+	b.removeSpec("ca")
+	b.setSpec(map[string]interface{}{}, "selfSigned")
+	return b
+}
+
+// WithCA makes the issuer sign with the CA key pair stored in the given
+// Secret, replacing the self-signed configuration.
+func (b *ClusterIssuerBuilder) WithCA(secretName string) *ClusterIssuerBuilder {
+	b.removeSpec("selfSigned")
+	b.setSpec(secretName, "ca", "secretName")
+	return b
+}
+
+// Build returns a deep copy of the constructed ClusterIssuer. Any namespace
+// set with WithNamespace is dropped.
+func (b *ClusterIssuerBuilder) Build() *unstructured.Unstructured {
+	obj := b.build()
+	obj.SetNamespace("")
+	return obj
+}
+
+// Create builds the ClusterIssuer and creates it in the cluster.
+func (b *ClusterIssuerBuilder) Create(ctx context.Context, c client.Client) (*unstructured.Unstructured, error) {
+	return createUnstructured(ctx, c, b.Build())
+}
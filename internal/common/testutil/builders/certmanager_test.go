@@ -0,0 +1,59 @@
+package builders
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCertificateBuilder(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	obj := NewCertificateBuilder().
+		WithName("keystone").
+		WithIssuerRef("internal-ca", "Issuer").
+		WithCommonName("keystone").
+		WithDNSNames("keystone.openstack.svc", "keystone.example.com").
+		WithDuration(24*time.Hour).
+		WithRenewBefore(8*time.Hour).
+		WithPrivateKey("ECDSA", 256).
+		Build()
+
+	g.Expect(nestedString(obj, "spec", "secretName")).To(Equal("keystone-tls"))
+	g.Expect(nestedStringMap(obj, "spec", "issuerRef")).To(Equal(map[string]string{
+		"name": "internal-ca", "kind": "Issuer", "group": "cert-manager.io",
+	}))
+	g.Expect(nestedString(obj, "spec", "commonName")).To(Equal("keystone"))
+	g.Expect(nestedStringSlice(obj, "spec", "dnsNames")).To(Equal([]string{"keystone.openstack.svc", "keystone.example.com"}))
+	g.Expect(nestedString(obj, "spec", "duration")).To(Equal("24h0m0s"))
+	g.Expect(nestedString(obj, "spec", "renewBefore")).To(Equal("8h0m0s"))
+	g.Expect(nestedString(obj, "spec", "privateKey", "algorithm")).To(Equal("ECDSA"))
+	g.Expect(nestedInt64(obj, "spec", "privateKey", "size")).To(Equal(int64(256)))
+}
+
+func TestCertificateBuilder_WithSecretName(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	obj := NewCertificateBuilder().WithSecretName("custom").WithName("keystone").Build()
+
+	g.Expect(nestedString(obj, "spec", "secretName")).To(Equal("custom"))
+}
+
+func TestClusterIssuerBuilder(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	builder := NewClusterIssuerBuilder()
+	_, found, _ := unstructured.NestedMap(builder.Build().Object, "spec", "selfSigned")
+	g.Expect(found).To(BeTrue())
+
+	obj := builder.WithCA("root-ca").Build()
+	_, found, _ = unstructured.NestedMap(obj.Object, "spec", "selfSigned")
+	g.Expect(found).To(BeFalse())
+	g.Expect(nestedString(obj, "spec", "ca", "secretName")).To(Equal("root-ca"))
+
+	obj = builder.WithSelfSigned().Build()
+	_, found, _ = unstructured.NestedMap(obj.Object, "spec", "ca")
+	g.Expect(found).To(BeFalse())
+}
@@ -1,3 +1,13 @@
 // Package builders provides fluent builder patterns for constructing Kubernetes
 // resources in tests.
+//
+// SecretBuilder builds typed corev1.Secrets. The third-party custom resources
+// the operators depend on (MariaDB, Database, User, Grant, Memcached,
+// RabbitmqCluster, ExternalSecret, Certificate and ClusterIssuer) are built as
+// unstructured.Unstructured, matching the bundled fake CRDs, so that the
+// external operators' Go types are not needed. The builders of the operators'
+// own CRs live next to their APIs in operators/<name>/testutil/builders. Every
+// builder starts from defaults that are valid on their own, offers With*
+// modifiers, returns deep copies from Build and creates the object with
+// Create.
 package builders
@@ -0,0 +1,79 @@
+package builders
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// externalSecretGVK identifies the external-secrets ExternalSecret kind.
+var externalSecretGVK = schema.GroupVersionKind{Group: "external-secrets.io", Version: "v1beta1", Kind: "ExternalSecret"}
+
+// ExternalSecretBuilder provides a fluent builder for external-secrets
+// ExternalSecret custom resources.
+type ExternalSecretBuilder struct {
+	unstructuredBuilder[*ExternalSecretBuilder]
+}
+
+// NewExternalSecretBuilder creates an ExternalSecretBuilder for an
+// ExternalSecret named "external-secret" in DefaultNamespace that reads from
+// the ClusterSecretStore "openbao" every hour and has no data entries.
+func NewExternalSecretBuilder() *ExternalSecretBuilder {
+	b := &ExternalSecretBuilder{}
+	b.init(b, externalSecretGVK, "external-secret", DefaultNamespace, map[string]interface{}{
+		"refreshInterval": "1h",
+		"secretStoreRef": map[string]interface{}{
+			"name": "openbao",
+			"kind": "ClusterSecretStore",
+		},
+	})
+	return b
+}
+
+// WithSecretStoreRef sets spec.secretStoreRef.
+func (b *ExternalSecretBuilder) WithSecretStoreRef(name, kind string) *ExternalSecretBuilder {
+	b.setSpec(map[string]interface{}{"name": name, "kind": kind}, "secretStoreRef")
+	return b
+}
+
+// WithRefreshInterval sets spec.refreshInterval.
+func (b *ExternalSecretBuilder) WithRefreshInterval(interval time.Duration) *ExternalSecretBuilder {
+	b.setSpec(interval.String(), "refreshInterval")
+	return b
+}
+
+// WithTargetName sets spec.target.name, the name of the Secret created by
+// the external-secrets operator. It defaults to the metadata name.
+func (b *ExternalSecretBuilder) WithTargetName(name string) *ExternalSecretBuilder {
+	b.setSpec(name, "target", "name")
+	return b
+}
+
+// WithData appends a spec.data entry that stores the remote key remoteKey,
+// optionally narrowed to property, under secretKey in the target Secret.
+func (b *ExternalSecretBuilder) WithData(secretKey, remoteKey, property string) *ExternalSecretBuilder {
+	remoteRef := map[string]interface{}{"key": remoteKey}
+	if property != "" {
+		remoteRef["property"] = property
+	}
+	data, _, _ := unstructured.NestedSlice(b.obj.Object, "spec", "data")
+	data = append(data, map[string]interface{}{
+		"secretKey": secretKey,
+		"remoteRef": remoteRef,
+	})
+	b.setSpec(data, "data")
+	return b
+}
+
+// Build returns a deep copy of the constructed ExternalSecret.
+func (b *ExternalSecretBuilder) Build() *unstructured.Unstructured {
+	return b.build()
+}
+
+// Create builds the ExternalSecret and creates it in the cluster.
+func (b *ExternalSecretBuilder) Create(ctx context.Context, c client.Client) (*unstructured.Unstructured, error) {
+	return createUnstructured(ctx, c, b.Build())
+}
@@ -0,0 +1,38 @@
+package builders
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestExternalSecretBuilder(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	obj := NewExternalSecretBuilder().
+		WithSecretStoreRef("vault", "SecretStore").
+		WithRefreshInterval(15*time.Minute).
+		WithTargetName("keystone-db-credentials").
+		WithData("password", "openstack/keystone/db", "password").
+		WithData("username", "openstack/keystone/db-user", "").
+		Build()
+
+	g.Expect(nestedStringMap(obj, "spec", "secretStoreRef")).To(Equal(map[string]string{"name": "vault", "kind": "SecretStore"}))
+	g.Expect(nestedString(obj, "spec", "refreshInterval")).To(Equal("15m0s"))
+	g.Expect(nestedString(obj, "spec", "target", "name")).To(Equal("keystone-db-credentials"))
+
+	data, _, err := unstructured.NestedSlice(obj.Object, "spec", "data")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(data).To(Equal([]interface{}{
+		map[string]interface{}{
+			"secretKey": "password",
+			"remoteRef": map[string]interface{}{"key": "openstack/keystone/db", "property": "password"},
+		},
+		map[string]interface{}{
+			"secretKey": "username",
+			"remoteRef": map[string]interface{}{"key": "openstack/keystone/db-user"},
+		},
+	}))
+}
@@ -0,0 +1,255 @@
+package builders
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GroupVersionKinds of the mariadb-operator resources.
+var (
+	mariadbGVK  = schema.GroupVersionKind{Group: "k8s.mariadb.com", Version: "v1alpha1", Kind: "MariaDB"}
+	databaseGVK = schema.GroupVersionKind{Group: "k8s.mariadb.com", Version: "v1alpha1", Kind: "Database"}
+	userGVK     = schema.GroupVersionKind{Group: "k8s.mariadb.com", Version: "v1alpha1", Kind: "User"}
+	grantGVK    = schema.GroupVersionKind{Group: "k8s.mariadb.com", Version: "v1alpha1", Kind: "Grant"}
+)
+
+// MariaDBBuilder provides a fluent builder for mariadb-operator MariaDB
+// custom resources.
+type MariaDBBuilder struct {
+	unstructuredBuilder[*MariaDBBuilder]
+}
+
+// NewMariaDBBuilder creates a MariaDBBuilder for a single-replica MariaDB
+// named "mariadb" in DefaultNamespace with 1Gi of storage and the root
+// password in key "password" of Secret "mariadb-root".
+func NewMariaDBBuilder() *MariaDBBuilder {
+	b := &MariaDBBuilder{}
+	b.init(b, mariadbGVK, "mariadb", DefaultNamespace, map[string]interface{}{
+		"replicas": int64(1),
+		"rootPasswordSecretKeyRef": map[string]interface{}{
+			"name": "mariadb-root",
+			"key":  "password",
+		},
+		"storage": map[string]interface{}{"size": "1Gi"},
+	})
+	return b
+}
+
+// WithReplicas sets spec.replicas.
+func (b *MariaDBBuilder) WithReplicas(replicas int64) *MariaDBBuilder {
+	b.setSpec(replicas, "replicas")
+	return b
+}
+
+// WithImage sets spec.image.
+func (b *MariaDBBuilder) WithImage(image string) *MariaDBBuilder {
+	b.setSpec(image, "image")
+	return b
+}
+
+// WithRootPasswordSecretKeyRef sets spec.rootPasswordSecretKeyRef.
+func (b *MariaDBBuilder) WithRootPasswordSecretKeyRef(name, key string) *MariaDBBuilder {
+	b.setSpec(map[string]interface{}{"name": name, "key": key}, "rootPasswordSecretKeyRef")
+	return b
+}
+
+// WithStorageSize sets spec.storage.size, e.g. "10Gi".
+func (b *MariaDBBuilder) WithStorageSize(size string) *MariaDBBuilder {
+	b.setSpec(size, "storage", "size")
+	return b
+}
+
+// WithGalera enables or disables Galera replication (spec.galera.enabled).
+func (b *MariaDBBuilder) WithGalera(enabled bool) *MariaDBBuilder {
+	b.setSpec(enabled, "galera", "enabled")
+	return b
+}
+
+// Build returns a deep copy of the constructed MariaDB.
+func (b *MariaDBBuilder) Build() *unstructured.Unstructured {
+	return b.build()
+}
+
+// Create builds the MariaDB and creates it in the cluster.
+func (b *MariaDBBuilder) Create(ctx context.Context, c client.Client) (*unstructured.Unstructured, error) {
+	return createUnstructured(ctx, c, b.Build())
+}
+
+// DatabaseBuilder provides a fluent builder for mariadb-operator Database
+// custom resources.
+type DatabaseBuilder struct {
+	unstructuredBuilder[*DatabaseBuilder]
+}
+
+// NewDatabaseBuilder creates a DatabaseBuilder for a Database named
+// "keystone" in DefaultNamespace on MariaDB "mariadb", with the utf8
+// character set.
+func NewDatabaseBuilder() *DatabaseBuilder {
+	b := &DatabaseBuilder{}
+	b.init(b, databaseGVK, "keystone", DefaultNamespace, map[string]interface{}{
+		"mariaDbRef":   map[string]interface{}{"name": "mariadb"},
+		"characterSet": "utf8",
+		"collate":      "utf8_general_ci",
+	})
+	return b
+}
+
+// WithMariaDBRef sets spec.mariaDbRef.name.
+func (b *DatabaseBuilder) WithMariaDBRef(name string) *DatabaseBuilder {
+	b.setSpec(name, "mariaDbRef", "name")
+	return b
+}
+
+// WithDatabaseName sets spec.name, the SQL database name. It defaults to the
+// metadata name.
+func (b *DatabaseBuilder) WithDatabaseName(name string) *DatabaseBuilder {
+	b.setSpec(name, "name")
+	return b
+}
+
+// WithCharacterSet sets spec.characterSet and spec.collate.
+func (b *DatabaseBuilder) WithCharacterSet(characterSet, collate string) *DatabaseBuilder {
+	b.setSpec(characterSet, "characterSet")
+	b.setSpec(collate, "collate")
+	return b
+}
+
+// Build returns a deep copy of the constructed Database.
+func (b *DatabaseBuilder) Build() *unstructured.Unstructured {
+	return b.build()
+}
+
+// Create builds the Database and creates it in the cluster.
+func (b *DatabaseBuilder) Create(ctx context.Context, c client.Client) (*unstructured.Unstructured, error) {
+	return createUnstructured(ctx, c, b.Build())
+}
+
+// UserBuilder provides a fluent builder for mariadb-operator User custom
+// resources.
+type UserBuilder struct {
+	unstructuredBuilder[*UserBuilder]
+}
+
+// NewUserBuilder creates a UserBuilder for a User named "keystone" in
+// DefaultNamespace on MariaDB "mariadb", allowed from any host, with the
+// password in key "password" of Secret "keystone-db".
+func NewUserBuilder() *UserBuilder {
+	b := &UserBuilder{}
+	b.init(b, userGVK, "keystone", DefaultNamespace, map[string]interface{}{
+		"mariaDbRef": map[string]interface{}{"name": "mariadb"},
+		"passwordSecretKeyRef": map[string]interface{}{
+			"name": "keystone-db",
+			"key":  "password",
+		},
+		"host":               "%",
+		"maxUserConnections": int64(20),
+	})
+	return b
+}
+
+// WithMariaDBRef sets spec.mariaDbRef.name.
+func (b *UserBuilder) WithMariaDBRef(name string) *UserBuilder {
+	b.setSpec(name, "mariaDbRef", "name")
+	return b
+}
+
+// WithUsername sets spec.name, the SQL user name. It defaults to the
+// metadata name.
+func (b *UserBuilder) WithUsername(name string) *UserBuilder {
+	b.setSpec(name, "name")
+	return b
+}
+
+// WithPasswordSecretKeyRef sets spec.passwordSecretKeyRef.
+func (b *UserBuilder) WithPasswordSecretKeyRef(name, key string) *UserBuilder {
+	b.setSpec(map[string]interface{}{"name": name, "key": key}, "passwordSecretKeyRef")
+	return b
+}
+
+// WithHost sets spec.host, the host the user may connect from.
+func (b *UserBuilder) WithHost(host string) *UserBuilder {
+	b.setSpec(host, "host")
+	return b
+}
+
+// Build returns a deep copy of the constructed User.
+func (b *UserBuilder) Build() *unstructured.Unstructured {
+	return b.build()
+}
+
+// Create builds the User and creates it in the cluster.
+func (b *UserBuilder) Create(ctx context.Context, c client.Client) (*unstructured.Unstructured, error) {
+	return createUnstructured(ctx, c, b.Build())
+}
+
+// GrantBuilder provides a fluent builder for mariadb-operator Grant custom
+// resources.
+type GrantBuilder struct {
+	unstructuredBuilder[*GrantBuilder]
+}
+
+// NewGrantBuilder creates a GrantBuilder for a Grant named "keystone" in
+// DefaultNamespace on MariaDB "mariadb" that gives user "keystone" all
+// privileges on all tables of database "keystone".
+func NewGrantBuilder() *GrantBuilder {
+	b := &GrantBuilder{}
+	b.init(b, grantGVK, "keystone", DefaultNamespace, map[string]interface{}{
+		"mariaDbRef": map[string]interface{}{"name": "mariadb"},
+		"privileges": []interface{}{"ALL PRIVILEGES"},
+		"database":   "keystone",
+		"table":      "*",
+		"username":   "keystone",
+		"host":       "%",
+	})
+	return b
+}
+
+// WithMariaDBRef sets spec.mariaDbRef.name.
+func (b *GrantBuilder) WithMariaDBRef(name string) *GrantBuilder {
+	b.setSpec(name, "mariaDbRef", "name")
+	return b
+}
+
+// WithPrivileges replaces spec.privileges.
+func (b *GrantBuilder) WithPrivileges(privileges ...string) *GrantBuilder {
+	b.setSpec(toInterfaceSlice(privileges), "privileges")
+	return b
+}
+
+// WithDatabase sets spec.database.
+func (b *GrantBuilder) WithDatabase(database string) *GrantBuilder {
+	b.setSpec(database, "database")
+	return b
+}
+
+// WithTable sets spec.table.
+func (b *GrantBuilder) WithTable(table string) *GrantBuilder {
+	b.setSpec(table, "table")
+	return b
+}
+
+// WithUsername sets spec.username, the SQL user the privileges are granted
+// to.
+func (b *GrantBuilder) WithUsername(username string) *GrantBuilder {
+	b.setSpec(username, "username")
+	return b
+}
+
+// WithHost sets spec.host.
+func (b *GrantBuilder) WithHost(host string) *GrantBuilder {
+	b.setSpec(host, "host")
+	return b
+}
+
+// Build returns a deep copy of the constructed Grant.
+func (b *GrantBuilder) Build() *unstructured.Unstructured {
+	return b.build()
+}
+
+// Create builds the Grant and creates it in the cluster.
+func (b *GrantBuilder) Create(ctx context.Context, c client.Client) (*unstructured.Unstructured, error) {
+	return createUnstructured(ctx, c, b.Build())
+}
@@ -0,0 +1,75 @@
+package builders
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMariaDBBuilder(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	obj := NewMariaDBBuilder().
+		WithReplicas(3).
+		WithImage("mariadb:11.4").
+		WithRootPasswordSecretKeyRef("root", "pw").
+		WithStorageSize("10Gi").
+		WithGalera(true).
+		Build()
+
+	g.Expect(nestedInt64(obj, "spec", "replicas")).To(Equal(int64(3)))
+	g.Expect(nestedString(obj, "spec", "image")).To(Equal("mariadb:11.4"))
+	g.Expect(nestedStringMap(obj, "spec", "rootPasswordSecretKeyRef")).To(Equal(map[string]string{"name": "root", "key": "pw"}))
+	g.Expect(nestedString(obj, "spec", "storage", "size")).To(Equal("10Gi"))
+	g.Expect(nestedBool(obj, "spec", "galera", "enabled")).To(BeTrue())
+}
+
+func TestDatabaseBuilder(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	obj := NewDatabaseBuilder().
+		WithMariaDBRef("galera").
+		WithDatabaseName("keystone_db").
+		WithCharacterSet("utf8mb4", "utf8mb4_general_ci").
+		Build()
+
+	g.Expect(nestedString(obj, "spec", "mariaDbRef", "name")).To(Equal("galera"))
+	g.Expect(nestedString(obj, "spec", "name")).To(Equal("keystone_db"))
+	g.Expect(nestedString(obj, "spec", "characterSet")).To(Equal("utf8mb4"))
+	g.Expect(nestedString(obj, "spec", "collate")).To(Equal("utf8mb4_general_ci"))
+}
+
+func TestUserBuilder(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	obj := NewUserBuilder().
+		WithMariaDBRef("galera").
+		WithUsername("keystone_user").
+		WithPasswordSecretKeyRef("db-pw", "password").
+		WithHost("10.0.0.%").
+		Build()
+
+	g.Expect(nestedString(obj, "spec", "mariaDbRef", "name")).To(Equal("galera"))
+	g.Expect(nestedString(obj, "spec", "name")).To(Equal("keystone_user"))
+	g.Expect(nestedStringMap(obj, "spec", "passwordSecretKeyRef")).To(Equal(map[string]string{"name": "db-pw", "key": "password"}))
+	g.Expect(nestedString(obj, "spec", "host")).To(Equal("10.0.0.%"))
+}
+
+func TestGrantBuilder(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	obj := NewGrantBuilder().
+		WithMariaDBRef("galera").
+		WithPrivileges("SELECT", "INSERT").
+		WithDatabase("keystone_db").
+		WithTable("token").
+		WithUsername("keystone_user").
+		WithHost("%").
+		Build()
+
+	g.Expect(nestedString(obj, "spec", "mariaDbRef", "name")).To(Equal("galera"))
+	g.Expect(nestedStringSlice(obj, "spec", "privileges")).To(Equal([]string{"SELECT", "INSERT"}))
+	g.Expect(nestedString(obj, "spec", "database")).To(Equal("keystone_db"))
+	g.Expect(nestedString(obj, "spec", "table")).To(Equal("token"))
+	g.Expect(nestedString(obj, "spec", "username")).To(Equal("keystone_user"))
+}
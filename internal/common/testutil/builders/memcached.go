@@ -0,0 +1,49 @@
+package builders
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// memcachedGVK identifies the Memcached kind of the bundled fake CRD.
+var memcachedGVK = schema.GroupVersionKind{Group: "opsv1.memcached.com", Version: "v1alpha1", Kind: "Memcached"}
+
+// MemcachedBuilder provides a fluent builder for Memcached custom resources.
+type MemcachedBuilder struct {
+	unstructuredBuilder[*MemcachedBuilder]
+}
+
+// NewMemcachedBuilder creates a MemcachedBuilder for a single-replica
+// Memcached named "memcached" in DefaultNamespace.
+func NewMemcachedBuilder() *MemcachedBuilder {
+	b := &MemcachedBuilder{}
+	b.init(b, memcachedGVK, "memcached", DefaultNamespace, map[string]interface{}{
+		"replicas": int64(1),
+	})
+	return b
+}
+
+// WithReplicas sets spec.replicas.
+func (b *MemcachedBuilder) WithReplicas(replicas int64) *MemcachedBuilder {
+	b.setSpec(replicas, "replicas")
+	return b
+}
+
+// WithImage sets spec.image.
+func (b *MemcachedBuilder) WithImage(image string) *MemcachedBuilder {
+	b.setSpec(image, "image")
+	return b
+}
+
+// Build returns a deep copy of the constructed Memcached.
+func (b *MemcachedBuilder) Build() *unstructured.Unstructured {
+	return b.build()
+}
+
+// Create builds the Memcached and creates it in the cluster.
+func (b *MemcachedBuilder) Create(ctx context.Context, c client.Client) (*unstructured.Unstructured, error) {
+	return createUnstructured(ctx, c, b.Build())
+}
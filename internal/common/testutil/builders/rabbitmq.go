@@ -0,0 +1,51 @@
+package builders
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rabbitmqClusterGVK identifies the rabbitmq cluster-operator RabbitmqCluster
+// kind.
+var rabbitmqClusterGVK = schema.GroupVersionKind{Group: "rabbitmq.com", Version: "v1beta1", Kind: "RabbitmqCluster"}
+
+// RabbitmqClusterBuilder provides a fluent builder for RabbitmqCluster custom
+// resources.
+type RabbitmqClusterBuilder struct {
+	unstructuredBuilder[*RabbitmqClusterBuilder]
+}
+
+// NewRabbitmqClusterBuilder creates a RabbitmqClusterBuilder for a
+// single-replica RabbitmqCluster named "rabbitmq" in DefaultNamespace.
+func NewRabbitmqClusterBuilder() *RabbitmqClusterBuilder {
+	b := &RabbitmqClusterBuilder{}
+	b.init(b, rabbitmqClusterGVK, "rabbitmq", DefaultNamespace, map[string]interface{}{
+		"replicas": int64(1),
+	})
+	return b
+}
+
+// WithReplicas sets spec.replicas.
+func (b *RabbitmqClusterBuilder) WithReplicas(replicas int64) *RabbitmqClusterBuilder {
+	b.setSpec(replicas, "replicas")
+	return b
+}
+
+// WithImage sets spec.image.
+func (b *RabbitmqClusterBuilder) WithImage(image string) *RabbitmqClusterBuilder {
+	b.setSpec(image, "image")
+	return b
+}
+
+// Build returns a deep copy of the constructed RabbitmqCluster.
+func (b *RabbitmqClusterBuilder) Build() *unstructured.Unstructured {
+	return b.build()
+}
+
+// Create builds the RabbitmqCluster and creates it in the cluster.
+func (b *RabbitmqClusterBuilder) Create(ctx context.Context, c client.Client) (*unstructured.Unstructured, error) {
+	return createUnstructured(ctx, c, b.Build())
+}
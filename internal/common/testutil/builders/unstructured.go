@@ -0,0 +1,133 @@
+package builders
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultNamespace is the namespace the unstructured builders use unless
+// WithNamespace is called.
+const DefaultNamespace = "default"
+
+// unstructuredBuilder holds the state and the common With* modifiers shared by
+// the builders for third-party custom resources. These resources are built as
+// unstructured.Unstructured to avoid importing the external operators' Go
+// types. B is the concrete builder type, so that the promoted modifiers
+// return it and chains can mix common and kind-specific modifiers.
+type unstructuredBuilder[B any] struct {
+	obj  unstructured.Unstructured
+	self B
+}
+
+// init sets the GVK, the default name and namespace and the default spec.
+func (b *unstructuredBuilder[B]) init(self B, gvk schema.GroupVersionKind, name, namespace string, spec map[string]interface{}) {
+	b.self = self
+	b.obj.Object = map[string]interface{}{"spec": spec}
+	b.obj.SetGroupVersionKind(gvk)
+	b.obj.SetName(name)
+	b.obj.SetNamespace(namespace)
+}
+
+// setSpec sets the spec field at the given path. value must be a JSON
+// compatible type (string, bool, int64, float64, []interface{} or
+// map[string]interface{}); other types are a programming error in the test
+// and cause a panic.
+func (b *unstructuredBuilder[B]) setSpec(value interface{}, fields ...string) {
+	if err := unstructured.SetNestedField(b.obj.Object, value, append([]string{"spec"}, fields...)...); err != nil {
+		panic(fmt.Sprintf("builders: setting spec.%v: %v", fields, err))
+	}
+}
+
+// removeSpec removes the spec field at the given path.
+func (b *unstructuredBuilder[B]) removeSpec(fields ...string) {
+	unstructured.RemoveNestedField(b.obj.Object, append([]string{"spec"}, fields...)...)
+}
+
+// specString returns the string spec field at the given path.
+func (b *unstructuredBuilder[B]) specString(fields ...string) string {
+	s, _, _ := unstructured.NestedString(b.obj.Object, append([]string{"spec"}, fields...)...)
+	return s
+}
+
+// WithName sets the metadata name.
+func (b *unstructuredBuilder[B]) WithName(name string) B {
+	b.obj.SetName(name)
+	return b.self
+}
+
+// WithNamespace sets the metadata namespace. Cluster-scoped kinds such as
+// ClusterIssuer ignore it.
+func (b *unstructuredBuilder[B]) WithNamespace(namespace string) B {
+	b.obj.SetNamespace(namespace)
+	return b.self
+}
+
+// WithLabels merges the provided labels into the existing labels.
+func (b *unstructuredBuilder[B]) WithLabels(labels map[string]string) B {
+	b.obj.SetLabels(mergeStrings(b.obj.GetLabels(), labels))
+	return b.self
+}
+
+// WithAnnotations merges the provided annotations into the existing
+// annotations.
+func (b *unstructuredBuilder[B]) WithAnnotations(annotations map[string]string) B {
+	b.obj.SetAnnotations(mergeStrings(b.obj.GetAnnotations(), annotations))
+	return b.self
+}
+
+// WithOwnerRef appends an OwnerReference to the metadata.
+func (b *unstructuredBuilder[B]) WithOwnerRef(ref metav1.OwnerReference) B {
+	b.obj.SetOwnerReferences(append(b.obj.GetOwnerReferences(), ref))
+	return b.self
+}
+
+// WithSpecField sets an arbitrary spec field, for fields without a dedicated
+// modifier. value must be a JSON compatible type (string, bool, int64,
+// float64, []interface{} or map[string]interface{}).
+func (b *unstructuredBuilder[B]) WithSpecField(value interface{}, fields ...string) B {
+	b.setSpec(value, fields...)
+	return b.self
+}
+
+// build returns a deep copy of the object.
+func (b *unstructuredBuilder[B]) build() *unstructured.Unstructured {
+	return b.obj.DeepCopy()
+}
+
+// createUnstructured creates obj in the cluster.
+func createUnstructured(ctx context.Context, c client.Client, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if err := c.Create(ctx, obj); err != nil {
+		if obj.GetNamespace() == "" {
+			return nil, fmt.Errorf("creating %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		return nil, fmt.Errorf("creating %s %s/%s: %w", obj.GetKind(), obj.GetNamespace(), obj.GetName(), err)
+	}
+	return obj, nil
+}
+
+// mergeStrings returns dst with all entries of src added, allocating dst if
+// needed.
+func mergeStrings(dst, src map[string]string) map[string]string {
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+// toInterfaceSlice converts a string slice to the []interface{} form
+// required by unstructured objects.
+func toInterfaceSlice(values []string) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
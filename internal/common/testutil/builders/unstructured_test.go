@@ -0,0 +1,162 @@
+package builders
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newUnstructuredClient returns a fake client that knows the third-party
+// kinds handled by the unstructured builders.
+func newUnstructuredClient() client.Client {
+	scheme := runtime.NewScheme()
+	for _, gvk := range []schema.GroupVersionKind{
+		mariadbGVK, databaseGVK, userGVK, grantGVK, memcachedGVK,
+		rabbitmqClusterGVK, externalSecretGVK, certificateGVK, clusterIssuerGVK,
+	} {
+		scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).Build()
+}
+
+func TestUnstructuredBuilders_Defaults(t *testing.T) {
+	tests := []struct {
+		name      string
+		build     func() *unstructured.Unstructured
+		gvk       schema.GroupVersionKind
+		objName   string
+		namespace string
+	}{
+		{"MariaDB", NewMariaDBBuilder().Build, mariadbGVK, "mariadb", DefaultNamespace},
+		{"Database", NewDatabaseBuilder().Build, databaseGVK, "keystone", DefaultNamespace},
+		{"User", NewUserBuilder().Build, userGVK, "keystone", DefaultNamespace},
+		{"Grant", NewGrantBuilder().Build, grantGVK, "keystone", DefaultNamespace},
+		{"Memcached", NewMemcachedBuilder().Build, memcachedGVK, "memcached", DefaultNamespace},
+		{"RabbitmqCluster", NewRabbitmqClusterBuilder().Build, rabbitmqClusterGVK, "rabbitmq", DefaultNamespace},
+		{"ExternalSecret", NewExternalSecretBuilder().Build, externalSecretGVK, "external-secret", DefaultNamespace},
+		{"Certificate", NewCertificateBuilder().Build, certificateGVK, "certificate", DefaultNamespace},
+		{"ClusterIssuer", NewClusterIssuerBuilder().Build, clusterIssuerGVK, "selfsigned", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			obj := tt.build()
+
+			g.Expect(obj.GroupVersionKind()).To(Equal(tt.gvk))
+			g.Expect(obj.GetName()).To(Equal(tt.objName))
+			g.Expect(obj.GetNamespace()).To(Equal(tt.namespace))
+			_, found, err := unstructured.NestedMap(obj.Object, "spec")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(found).To(BeTrue())
+		})
+	}
+}
+
+func TestUnstructuredBuilder_CommonModifiers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	ownerRef := metav1.OwnerReference{APIVersion: "v1", Kind: "Service", Name: "my-svc", UID: "uid-svc"}
+	obj := NewMemcachedBuilder().
+		WithName("cache").
+		WithNamespace("openstack").
+		WithLabels(map[string]string{"app": "keystone"}).
+		WithLabels(map[string]string{"tier": "cache"}).
+		WithAnnotations(map[string]string{"note": "test"}).
+		WithOwnerRef(ownerRef).
+		WithSpecField("2Gi", "resources", "limits", "memory").
+		WithReplicas(3).
+		Build()
+
+	g.Expect(obj.GetName()).To(Equal("cache"))
+	g.Expect(obj.GetNamespace()).To(Equal("openstack"))
+	g.Expect(obj.GetLabels()).To(Equal(map[string]string{"app": "keystone", "tier": "cache"}))
+	g.Expect(obj.GetAnnotations()).To(Equal(map[string]string{"note": "test"}))
+	g.Expect(obj.GetOwnerReferences()).To(ConsistOf(ownerRef))
+	g.Expect(nestedString(obj, "spec", "resources", "limits", "memory")).To(Equal("2Gi"))
+	g.Expect(nestedInt64(obj, "spec", "replicas")).To(Equal(int64(3)))
+}
+
+func TestUnstructuredBuilder_Build_IndependentCopies(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	builder := NewMariaDBBuilder().WithName("copy-test")
+
+	obj1 := builder.Build()
+	obj2 := builder.Build()
+	g.Expect(obj1).NotTo(BeIdenticalTo(obj2))
+
+	obj1.SetName("modified")
+	g.Expect(unstructured.SetNestedField(obj1.Object, int64(5), "spec", "replicas")).To(Succeed())
+
+	g.Expect(obj2.GetName()).To(Equal("copy-test"))
+	g.Expect(nestedInt64(obj2, "spec", "replicas")).To(Equal(int64(1)))
+	g.Expect(nestedInt64(builder.Build(), "spec", "replicas")).To(Equal(int64(1)))
+}
+
+func TestUnstructuredBuilder_WithSpecField_PanicsOnInvalidValue(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(func() { NewMemcachedBuilder().WithSpecField(3, "replicas") }).To(Panic())
+}
+
+func TestUnstructuredBuilder_Create(t *testing.T) {
+	g := NewGomegaWithT(t)
+	c := newUnstructuredClient()
+
+	obj, err := NewRabbitmqClusterBuilder().WithName("created").Create(context.Background(), c)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(obj.GetName()).To(Equal("created"))
+
+	fetched := &unstructured.Unstructured{}
+	fetched.SetGroupVersionKind(rabbitmqClusterGVK)
+	g.Expect(c.Get(context.Background(), client.ObjectKey{Name: "created", Namespace: DefaultNamespace}, fetched)).To(Succeed())
+
+	_, err = NewRabbitmqClusterBuilder().WithName("created").Create(context.Background(), c)
+	g.Expect(err).To(MatchError(ContainSubstring("creating RabbitmqCluster default/created")))
+}
+
+func TestClusterIssuerBuilder_Create(t *testing.T) {
+	g := NewGomegaWithT(t)
+	c := newUnstructuredClient()
+
+	obj, err := NewClusterIssuerBuilder().WithNamespace("ignored").Create(context.Background(), c)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(obj.GetNamespace()).To(BeEmpty())
+}
+
+// The nested* helpers read a field of obj, ignoring whether it was found, so
+// that they can be used directly in assertions.
+
+func nestedString(obj *unstructured.Unstructured, fields ...string) string {
+	v, _, _ := unstructured.NestedString(obj.Object, fields...)
+	return v
+}
+
+func nestedInt64(obj *unstructured.Unstructured, fields ...string) int64 {
+	v, _, _ := unstructured.NestedInt64(obj.Object, fields...)
+	return v
+}
+
+func nestedBool(obj *unstructured.Unstructured, fields ...string) bool {
+	v, _, _ := unstructured.NestedBool(obj.Object, fields...)
+	return v
+}
+
+func nestedStringSlice(obj *unstructured.Unstructured, fields ...string) []string {
+	v, _, _ := unstructured.NestedStringSlice(obj.Object, fields...)
+	return v
+}
+
+func nestedStringMap(obj *unstructured.Unstructured, fields ...string) map[string]string {
+	v, _, _ := unstructured.NestedStringMap(obj.Object, fields...)
+	return v
+}
@@ -5,6 +5,19 @@
 // common schemes, and returns a ready-to-use rest.Config and client.Client together
 // with a teardown function that must be deferred by the caller.
 //
+// Start is the options-based variant. It additionally registers extra scheme
+// adders, installs webhooks, passes flags to the API server, locates the
+// control plane binaries in the shared bin/k8s directory and can run a
+// controller manager with the given reconcilers. Environment.Stop shuts the
+// manager down before the control plane:
+//
+//	env, err := envtest.Start(envtest.Options{
+//		SchemeAdders: []func(*runtime.Scheme) error{keystonev1alpha1.AddToScheme},
+//		Manager:      &envtest.ManagerOptions{Reconcilers: []envtest.Reconciler{reconciler}},
+//	})
+//	...
+//	defer env.Stop()
+//
 // Fake CRD manifests bundled with this package are automatically included in every
 // environment so that third-party custom resources (ESO, cert-manager, MariaDB,
 // Memcached, RabbitMQ) are available without additional configuration.
@@ -0,0 +1,270 @@
+package envtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// DefaultManagerStopTimeout bounds how long Stop waits for the manager to
+// shut down before stopping the control plane anyway.
+const DefaultManagerStopTimeout = 30 * time.Second
+
+// Options configures Start.
+type Options struct {
+	// CRDPaths lists additional directories or files containing CRD manifests.
+	// The bundled fake CRDs are always installed.
+	CRDPaths []string
+	// SchemeAdders registers additional API groups, such as the operators'
+	// own types, on top of the core groups registered by default.
+	SchemeAdders []func(*k8sruntime.Scheme) error
+	// WebhookInstallOptions installs webhook configurations. When a manager
+	// is requested, its webhook server listens on the host, port and
+	// certificates chosen by envtest.
+	WebhookInstallOptions envtest.WebhookInstallOptions
+	// APIServerFlags are set on the kube-apiserver command line, e.g.
+	// {"feature-gates": "MutatingAdmissionPolicy=true"}.
+	APIServerFlags map[string]string
+	// BinaryAssetsDirectory is the directory containing kube-apiserver, etcd
+	// and kubectl. If empty, KUBEBUILDER_ASSETS is used when set, otherwise
+	// the newest version installed in the shared directory returned by
+	// SharedBinaryAssetsPath.
+	BinaryAssetsDirectory string
+	// Manager, if non-nil, starts a controller manager running the given
+	// reconcilers against the environment.
+	Manager *ManagerOptions
+}
+
+// ManagerOptions configures the opt-in controller manager.
+type ManagerOptions struct {
+	// Options are passed to ctrl.NewManager. Scheme is always replaced by the
+	// environment's scheme; the metrics and health probe servers are disabled
+	// unless bind addresses are given; controller name validation is skipped
+	// so that several tests in one process can register the same controller.
+	Options ctrl.Options
+	// Reconcilers are registered with the manager before it starts.
+	Reconcilers []Reconciler
+	// StopTimeout bounds how long Stop waits for the manager to shut down.
+	// It defaults to DefaultManagerStopTimeout.
+	StopTimeout time.Duration
+}
+
+// Reconciler is implemented by controllers that can be registered with a
+// manager, such as the operators' reconcilers and fakeoperators.Operators.
+type Reconciler interface {
+	SetupWithManager(mgr ctrl.Manager) error
+}
+
+// Environment is a running envtest environment started by Start.
+type Environment struct {
+	// Config is the rest.Config for the API server.
+	Config *rest.Config
+	// Client is an uncached client, so reads always observe the latest state.
+	// It supports watches, e.g. for assertions.RecordConditions.
+	Client client.WithWatch
+	// Scheme contains the core API groups and Options.SchemeAdders.
+	Scheme *k8sruntime.Scheme
+	// Manager is the running controller manager, or nil if Options.Manager
+	// was not set.
+	Manager ctrl.Manager
+
+	testEnv     *envtest.Environment
+	cancel      context.CancelFunc
+	managerDone chan error
+	stopTimeout time.Duration
+}
+
+// SharedBinaryAssetsPath returns the repository-wide directory into which
+// setup-envtest installs the control plane binaries (bin/k8s at the
+// repository root), so that all modules share one download.
+func SharedBinaryAssetsPath() (string, error) {
+	_, filename, _, ok := runtime.Caller(0)
+	if !ok {
+		return "", fmt.Errorf("envtest: runtime.Caller(0) failed; cannot determine source file path")
+	}
+	return filepath.Join(filepath.Dir(filename), "..", "..", "..", "..", "bin", "k8s"), nil
+}
+
+// resolveBinaryAssetsDirectory applies the BinaryAssetsDirectory defaults.
+// An empty result lets envtest apply its own lookup.
+func resolveBinaryAssetsDirectory(dir string) string {
+	if dir != "" {
+		return dir
+	}
+	if os.Getenv("KUBEBUILDER_ASSETS") != "" {
+		return ""
+	}
+	base, err := SharedBinaryAssetsPath()
+	if err != nil {
+		return ""
+	}
+	// setup-envtest installs into <bin-dir>/k8s/<version>-<os>-<arch>.
+	entries, err := os.ReadDir(filepath.Join(base, "k8s"))
+	if err != nil {
+		return ""
+	}
+	var versions []string
+	for _, e := range entries {
+		if e.IsDir() {
+			versions = append(versions, e.Name())
+		}
+	}
+	if len(versions) == 0 {
+		return ""
+	}
+	sort.Strings(versions)
+	return filepath.Join(base, "k8s", versions[len(versions)-1])
+}
+
+// Start starts a local API server and etcd configured by opts and, if
+// requested, a controller manager running the given reconcilers. The
+// returned Environment must be stopped with Stop, typically via defer.
+func Start(opts Options) (*Environment, error) {
+	crdSubDirs, err := fakeCRDSubDirs()
+	if err != nil {
+		return nil, fmt.Errorf("envtest: enumerating CRD subdirectories: %w", err)
+	}
+	allCRDPaths := make([]string, 0, len(crdSubDirs)+len(opts.CRDPaths))
+	allCRDPaths = append(allCRDPaths, crdSubDirs...)
+	allCRDPaths = append(allCRDPaths, opts.CRDPaths...)
+
+	s := k8sruntime.NewScheme()
+	for _, sa := range schemeAdders {
+		if err := sa.add(s); err != nil {
+			return nil, fmt.Errorf("envtest: failed to register %s scheme: %w", sa.name, err)
+		}
+	}
+	for i, add := range opts.SchemeAdders {
+		if err := add(s); err != nil {
+			return nil, fmt.Errorf("envtest: failed to register scheme adder %d: %w", i, err)
+		}
+	}
+
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths:     allCRDPaths,
+		Scheme:                s,
+		WebhookInstallOptions: opts.WebhookInstallOptions,
+		BinaryAssetsDirectory: resolveBinaryAssetsDirectory(opts.BinaryAssetsDirectory),
+	}
+	apiServerArgs := testEnv.ControlPlane.GetAPIServer().Configure()
+	for flag, value := range opts.APIServerFlags {
+		apiServerArgs.Set(flag, value)
+	}
+
+	cfg, err := testEnv.Start()
+	if err != nil {
+		return nil, fmt.Errorf("envtest: failed to start environment: %w", err)
+	}
+
+	env := &Environment{Config: cfg, Scheme: s, testEnv: testEnv}
+
+	env.Client, err = client.NewWithWatch(cfg, client.Options{Scheme: s})
+	if err != nil {
+		_ = testEnv.Stop()
+		return nil, fmt.Errorf("envtest: failed to create client: %w", err)
+	}
+
+	if opts.Manager != nil {
+		if err := env.startManager(*opts.Manager); err != nil {
+			_ = testEnv.Stop()
+			return nil, err
+		}
+	}
+
+	return env, nil
+}
+
+// startManager creates the manager, registers the reconcilers, starts it in
+// the background and waits for its caches to sync.
+func (e *Environment) startManager(opts ManagerOptions) error {
+	mgrOpts := opts.Options
+	mgrOpts.Scheme = e.Scheme
+	if mgrOpts.Metrics.BindAddress == "" {
+		mgrOpts.Metrics = metricsserver.Options{BindAddress: "0"}
+	}
+	if mgrOpts.HealthProbeBindAddress == "" {
+		mgrOpts.HealthProbeBindAddress = "0"
+	}
+	if mgrOpts.Controller.SkipNameValidation == nil {
+		skipNameValidation := true
+		mgrOpts.Controller.SkipNameValidation = &skipNameValidation
+	}
+	wh := e.testEnv.WebhookInstallOptions
+	if mgrOpts.WebhookServer == nil && len(wh.Paths)+len(wh.ValidatingWebhooks)+len(wh.MutatingWebhooks) > 0 {
+		mgrOpts.WebhookServer = webhook.NewServer(webhook.Options{
+			Host:    wh.LocalServingHost,
+			Port:    wh.LocalServingPort,
+			CertDir: wh.LocalServingCertDir,
+		})
+	}
+
+	mgr, err := ctrl.NewManager(e.Config, mgrOpts)
+	if err != nil {
+		return fmt.Errorf("envtest: failed to create manager: %w", err)
+	}
+	for i, r := range opts.Reconcilers {
+		if err := r.SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("envtest: failed to set up reconciler %d (%T): %w", i, r, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.managerDone = make(chan error, 1)
+	e.stopTimeout = opts.StopTimeout
+	if e.stopTimeout <= 0 {
+		e.stopTimeout = DefaultManagerStopTimeout
+	}
+	go func() { e.managerDone <- mgr.Start(ctx) }()
+
+	synced := make(chan bool, 1)
+	go func() { synced <- mgr.GetCache().WaitForCacheSync(ctx) }()
+	select {
+	case ok := <-synced:
+		if !ok {
+			cancel()
+			<-e.managerDone
+			return fmt.Errorf("envtest: manager cache did not sync")
+		}
+	case err := <-e.managerDone:
+		cancel()
+		return fmt.Errorf("envtest: manager exited before its cache synced: %v", err)
+	}
+	e.Manager = mgr
+	return nil
+}
+
+// Stop stops the manager, if any, waits for it to shut down, and then stops
+// the control plane. It returns all errors encountered.
+func (e *Environment) Stop() error {
+	var errs []error
+	if e.cancel != nil {
+		e.cancel()
+		select {
+		case err := <-e.managerDone:
+			if err != nil {
+				errs = append(errs, fmt.Errorf("envtest: manager stopped with error: %w", err))
+			}
+		case <-time.After(e.stopTimeout):
+			errs = append(errs, fmt.Errorf("envtest: manager did not stop within %s", e.stopTimeout))
+		}
+		e.cancel = nil
+	}
+	if err := e.testEnv.Stop(); err != nil {
+		errs = append(errs, fmt.Errorf("envtest: error stopping environment: %w", err))
+	}
+	return errors.Join(errs...)
+}
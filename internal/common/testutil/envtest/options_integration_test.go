@@ -0,0 +1,112 @@
+//go:build integration
+
+package envtest
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// labelReconciler adds a "reconciled" label to every ConfigMap.
+type labelReconciler struct {
+	client client.Client
+}
+
+func (r *labelReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.client = mgr.GetClient()
+	return ctrl.NewControllerManagedBy(mgr).Named("label-configmaps").For(&corev1.ConfigMap{}).Complete(r)
+}
+
+func (r *labelReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(ctx, req.NamespacedName, cm); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if cm.Labels["reconciled"] == "true" {
+		return reconcile.Result{}, nil
+	}
+	patch := client.MergeFrom(cm.DeepCopy())
+	if cm.Labels == nil {
+		cm.Labels = map[string]string{}
+	}
+	cm.Labels["reconciled"] = "true"
+	return reconcile.Result{}, r.client.Patch(ctx, cm, patch)
+}
+
+func TestStart_WithOptions(t *testing.T) {
+	ctx := context.Background()
+
+	env, err := Start(Options{
+		SchemeAdders:   []func(*k8sruntime.Scheme) error{networkingv1.AddToScheme},
+		APIServerFlags: map[string]string{"service-cluster-ip-range": "10.123.0.0/16"},
+		Manager:        &ManagerOptions{Reconcilers: []Reconciler{&labelReconciler{}}},
+	})
+	if err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	stopped := false
+	defer func() {
+		if !stopped {
+			_ = env.Stop()
+		}
+	}()
+
+	if env.Manager == nil {
+		t.Fatal("expected a running manager")
+	}
+
+	// The extra scheme adder makes networking/v1 usable with the client.
+	np := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "deny-all", Namespace: "default"},
+	}
+	if err := env.Client.Create(ctx, np); err != nil {
+		t.Fatalf("failed to create NetworkPolicy: %v", err)
+	}
+
+	// The API server flag restricts the service IP range.
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "keystone", Namespace: "default"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 5000}}},
+	}
+	if err := env.Client.Create(ctx, svc); err != nil {
+		t.Fatalf("failed to create Service: %v", err)
+	}
+	_, ipRange, _ := net.ParseCIDR("10.123.0.0/16")
+	if ip := net.ParseIP(svc.Spec.ClusterIP); ip == nil || !ipRange.Contains(ip) {
+		t.Fatalf("expected cluster IP in %s, got %q", ipRange, svc.Spec.ClusterIP)
+	}
+
+	// The manager runs the reconciler.
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "reconcile-me", Namespace: "default"}}
+	if err := env.Client.Create(ctx, cm); err != nil {
+		t.Fatalf("failed to create ConfigMap: %v", err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if err := env.Client.Get(ctx, client.ObjectKeyFromObject(cm), cm); err != nil {
+			t.Fatalf("failed to get ConfigMap: %v", err)
+		}
+		if cm.Labels["reconciled"] == "true" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("ConfigMap was not reconciled within 10s")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	stopped = true
+	if err := env.Stop(); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+}
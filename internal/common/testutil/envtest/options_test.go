@@ -0,0 +1,37 @@
+package envtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSharedBinaryAssetsPath_IsAtRepositoryRoot(t *testing.T) {
+	path, err := SharedBinaryAssetsPath()
+	if err != nil {
+		t.Fatalf("SharedBinaryAssetsPath() returned error: %v", err)
+	}
+	if filepath.Base(path) != "k8s" || filepath.Base(filepath.Dir(path)) != "bin" {
+		t.Fatalf("SharedBinaryAssetsPath() = %q, expected it to end in bin/k8s", path)
+	}
+	root := filepath.Dir(filepath.Dir(path))
+	if _, err := os.Stat(filepath.Join(root, "go.work")); err != nil {
+		t.Fatalf("expected %s to be the repository root containing go.work: %v", root, err)
+	}
+}
+
+func TestResolveBinaryAssetsDirectory(t *testing.T) {
+	t.Run("explicit directory wins", func(t *testing.T) {
+		t.Setenv("KUBEBUILDER_ASSETS", "/from/env")
+		if got := resolveBinaryAssetsDirectory("/explicit"); got != "/explicit" {
+			t.Fatalf("expected /explicit, got %q", got)
+		}
+	})
+
+	t.Run("KUBEBUILDER_ASSETS is left to envtest", func(t *testing.T) {
+		t.Setenv("KUBEBUILDER_ASSETS", "/from/env")
+		if got := resolveBinaryAssetsDirectory(""); got != "" {
+			t.Fatalf("expected empty directory, got %q", got)
+		}
+	})
+}
@@ -12,7 +12,6 @@ import (
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/envtest"
 )
 
 // schemeAdders lists the core API groups that SetupEnvTest registers
@@ -73,46 +72,20 @@ func fakeCRDSubDirs() ([]string, error) {
 // environment after the test completes. It logs any stop error to stderr but
 // does not panic, so the test binary can still exit cleanly.
 //
-// SetupEnvTest returns an error if the environment cannot be started.
+// SetupEnvTest returns an error if the environment cannot be started. Use
+// Start for additional scheme adders, webhooks, API server flags or a
+// controller manager.
 func SetupEnvTest(crdPaths ...string) (*rest.Config, client.Client, func(), error) {
-	crdSubDirs, err := fakeCRDSubDirs()
+	env, err := Start(Options{CRDPaths: crdPaths})
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("envtest: enumerating CRD subdirectories: %w", err)
-	}
-	allCRDPaths := make([]string, 0, len(crdSubDirs)+len(crdPaths))
-	allCRDPaths = append(allCRDPaths, crdSubDirs...)
-	allCRDPaths = append(allCRDPaths, crdPaths...)
-
-	testEnv := &envtest.Environment{
-		CRDDirectoryPaths: allCRDPaths,
-	}
-
-	cfg, err := testEnv.Start()
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("envtest: failed to start environment: %w", err)
-	}
-
-	s := k8sruntime.NewScheme()
-
-	for _, sa := range schemeAdders {
-		if err := sa.add(s); err != nil {
-			// Stop the already-started environment before returning the error.
-			_ = testEnv.Stop()
-			return nil, nil, nil, fmt.Errorf("envtest: failed to register %s scheme: %w", sa.name, err)
-		}
-	}
-
-	k8sClient, err := client.New(cfg, client.Options{Scheme: s})
-	if err != nil {
-		_ = testEnv.Stop()
-		return nil, nil, nil, fmt.Errorf("envtest: failed to create client: %w", err)
+		return nil, nil, nil, err
 	}
 
 	teardown := func() {
-		if err := testEnv.Stop(); err != nil {
-			fmt.Fprintf(os.Stderr, "envtest: error stopping environment: %v\n", err)
+		if err := env.Stop(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
 		}
 	}
 
-	return cfg, k8sClient, teardown, nil
+	return env.Config, env.Client, teardown, nil
 }
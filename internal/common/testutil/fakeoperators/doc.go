@@ -0,0 +1,21 @@
+// Package fakeoperators provides lightweight controller-runtime controllers
+// that stand in for the third-party operators absent from envtest.
+//
+// The simulators package offers one-shot functions that a test must call at
+// exactly the right moment. The controllers in this package instead run in a
+// manager alongside the code under test, watch MariaDB, Memcached,
+// RabbitmqCluster, ExternalSecret, Certificate (and ClusterIssuer) and Job
+// objects, and drive every object they see to its ready or completed state
+// using the simulators.
+//
+// Each kind can be configured with a Behavior: a Delay before the object is
+// reported ready, and a Fail selector that makes the fake operator report a
+// failure instead. Behaviors can be changed while the manager is running, for
+// example to let a MariaDB fail in the middle of a test:
+//
+//	ops, err := fakeoperators.New(fakeoperators.Options{})
+//	...
+//	if err := ops.SetupWithManager(mgr); err != nil { ... }
+//	...
+//	ops.SetBehavior(fakeoperators.KindMariaDB, fakeoperators.Behavior{Fail: fakeoperators.All()})
+package fakeoperators
@@ -0,0 +1,207 @@
+package fakeoperators
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/c5c3/forge/internal/common/testutil/simulators"
+)
+
+// Kind identifies an operator that can be faked.
+type Kind string
+
+// Kinds handled by the fake operators.
+const (
+	KindMariaDB         Kind = "MariaDB"
+	KindMemcached       Kind = "Memcached"
+	KindRabbitmqCluster Kind = "RabbitmqCluster"
+	KindExternalSecret  Kind = "ExternalSecret"
+	// KindCertificate also enables a controller that reports every
+	// ClusterIssuer as ready.
+	KindCertificate Kind = "Certificate"
+	KindJob         Kind = "Job"
+)
+
+// AllKinds lists every Kind, in the order the controllers are registered.
+var AllKinds = []Kind{KindMariaDB, KindMemcached, KindRabbitmqCluster, KindExternalSecret, KindCertificate, KindJob}
+
+// Defaults used when the corresponding Behavior or Options field is unset.
+const (
+	DefaultFailureReason  = "SimulatedFailure"
+	DefaultFailureMessage = "failure injected by fake operator"
+	DefaultRetryInterval  = time.Second
+)
+
+// Selector decides whether a Behavior applies to an object.
+type Selector func(obj client.Object) bool
+
+// All selects every object.
+func All() Selector {
+	return func(client.Object) bool { return true }
+}
+
+// Named selects the objects with one of the given names, in any namespace.
+func Named(names ...string) Selector {
+	return func(obj client.Object) bool { return slices.Contains(names, obj.GetName()) }
+}
+
+// Behavior configures how a fake operator drives the objects of one kind.
+type Behavior struct {
+	// Delay is the minimum age of an object before the fake operator reports
+	// its outcome. It is measured from the creation timestamp, which the API
+	// server records with second precision.
+	Delay time.Duration
+	// Fail selects the objects for which a failure is reported instead of
+	// readiness: NotReady for MariaDB, Memcached, RabbitmqCluster and
+	// Certificate, a sync error for ExternalSecret and BackoffLimitExceeded
+	// for Job. A nil Fail selects no object.
+	Fail Selector
+	// FailureReason is the condition reason of the injected failure. It
+	// defaults to DefaultFailureReason. ExternalSecret and Job always report
+	// the reason used by their real controllers.
+	FailureReason string
+	// FailureMessage is the condition message of the injected failure. It
+	// defaults to DefaultFailureMessage.
+	FailureMessage string
+}
+
+// fails reports whether the behavior injects a failure for obj.
+func (b Behavior) fails(obj client.Object) bool {
+	return b.Fail != nil && b.Fail(obj)
+}
+
+func (b Behavior) failureReason() string {
+	if b.FailureReason == "" {
+		return DefaultFailureReason
+	}
+	return b.FailureReason
+}
+
+func (b Behavior) failureMessage() string {
+	if b.FailureMessage == "" {
+		return DefaultFailureMessage
+	}
+	return b.FailureMessage
+}
+
+// Options configures the fake operators.
+type Options struct {
+	// Kinds selects the fake operators to run. Empty runs AllKinds.
+	Kinds []Kind
+	// Behaviors sets the initial Behavior per kind. Kinds without an entry
+	// become ready immediately.
+	Behaviors map[Kind]Behavior
+	// CA signs the certificates issued for Certificate objects. If nil, a new
+	// test CA is generated.
+	CA *simulators.TestCA
+	// ExternalSecretData returns the data written to the target Secret of an
+	// ExternalSecret. If nil, every spec.data[].secretKey is set to
+	// "fake-<secretKey>".
+	ExternalSecretData func(obj client.Object) map[string][]byte
+	// RetryInterval is the delay before retrying an object whose
+	// dependencies are not ready, such as a Certificate whose ClusterIssuer
+	// is not ready yet. It defaults to DefaultRetryInterval.
+	RetryInterval time.Duration
+}
+
+// Operators is a set of fake operator controllers sharing one configuration.
+// It is safe for concurrent use.
+type Operators struct {
+	kinds              []Kind
+	ca                 *simulators.TestCA
+	externalSecretData func(obj client.Object) map[string][]byte
+	retryInterval      time.Duration
+	now                func() time.Time
+
+	mu        sync.RWMutex
+	behaviors map[Kind]Behavior
+	triggers  map[Kind][]*reconciler
+}
+
+// New validates opts and returns the fake operators. Register them with a
+// manager using SetupWithManager.
+func New(opts Options) (*Operators, error) {
+	kinds := opts.Kinds
+	if len(kinds) == 0 {
+		kinds = AllKinds
+	}
+	for _, kind := range kinds {
+		if !slices.Contains(AllKinds, kind) {
+			return nil, fmt.Errorf("fakeoperators: unknown kind %q", kind)
+		}
+	}
+
+	ca := opts.CA
+	if ca == nil && slices.Contains(kinds, KindCertificate) {
+		var err error
+		if ca, err = simulators.NewTestCA("fakeoperators test CA"); err != nil {
+			return nil, fmt.Errorf("fakeoperators: %w", err)
+		}
+	}
+
+	retryInterval := opts.RetryInterval
+	if retryInterval <= 0 {
+		retryInterval = DefaultRetryInterval
+	}
+
+	behaviors := make(map[Kind]Behavior, len(opts.Behaviors))
+	for kind, b := range opts.Behaviors {
+		behaviors[kind] = b
+	}
+
+	return &Operators{
+		kinds:              slices.Clone(kinds),
+		ca:                 ca,
+		externalSecretData: opts.ExternalSecretData,
+		retryInterval:      retryInterval,
+		now:                time.Now,
+		behaviors:          behaviors,
+		triggers:           make(map[Kind][]*reconciler),
+	}, nil
+}
+
+// CA returns the test CA that signs issued certificates, or nil if the
+// Certificate fake operator is not enabled. Tests use it to verify TLS
+// connections to components serving the issued certificates.
+func (o *Operators) CA() *simulators.TestCA {
+	return o.ca
+}
+
+// SetBehavior replaces the Behavior for kind and re-reconciles all existing
+// objects of that kind, so that for example a ready MariaDB turns NotReady
+// once a Fail selector matches it, and back again once it is removed.
+func (o *Operators) SetBehavior(kind Kind, b Behavior) {
+	o.mu.Lock()
+	o.behaviors[kind] = b
+	reconcilers := o.triggers[kind]
+	o.mu.Unlock()
+
+	for _, r := range reconcilers {
+		r.retrigger()
+	}
+}
+
+// behavior returns the current Behavior for kind.
+func (o *Operators) behavior(kind Kind) Behavior {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.behaviors[kind]
+}
+
+// SetupWithManager registers one controller per enabled kind with mgr. The
+// manager's scheme must include batch/v1 when KindJob is enabled.
+func (o *Operators) SetupWithManager(mgr manager.Manager) error {
+	for _, kind := range o.kinds {
+		for _, h := range o.handlers(kind) {
+			if err := o.newReconciler(mgr.GetClient(), h).setupWithManager(mgr); err != nil {
+				return fmt.Errorf("fakeoperators: setting up %s controller: %w", h.name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+//go:build integration
+
+package fakeoperators_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	testenvtest "github.com/c5c3/forge/internal/common/testutil/envtest"
+	"github.com/c5c3/forge/internal/common/testutil/fakeoperators"
+)
+
+const namespace = "test-fakeoperators"
+
+var (
+	cfg       *rest.Config
+	k8sClient client.Client
+
+	mariadbGVK     = schema.GroupVersionKind{Group: "k8s.mariadb.com", Version: "v1alpha1", Kind: "MariaDB"}
+	certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+)
+
+func TestMain(m *testing.M) {
+	var teardown func()
+	var err error
+	cfg, k8sClient, teardown, err = testenvtest.SetupEnvTest()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to set up envtest: %v\n", err)
+		os.Exit(1)
+	}
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+	if err := k8sClient.Create(context.Background(), ns); err != nil {
+		teardown()
+		fmt.Fprintf(os.Stderr, "failed to create namespace: %v\n", err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	teardown()
+	os.Exit(code)
+}
+
+// startOperators starts a manager running the fake operators and stops it
+// when the test finishes.
+func startOperators(t *testing.T, opts fakeoperators.Options) *fakeoperators.Operators {
+	t.Helper()
+
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:  s,
+		Metrics: metricsserver.Options{BindAddress: "0"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	ops, err := fakeoperators.New(opts)
+	if err != nil {
+		t.Fatalf("fakeoperators.New returned error: %v", err)
+	}
+	if err := ops.SetupWithManager(mgr); err != nil {
+		t.Fatalf("SetupWithManager returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- mgr.Start(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("manager returned error: %v", err)
+		}
+	})
+	return ops
+}
+
+func readyStatus(g Gomega, gvk schema.GroupVersionKind, name string) string {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	g.Expect(k8sClient.Get(context.Background(), client.ObjectKey{Name: name, Namespace: namespace}, obj)).To(Succeed())
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		if cond, ok := c.(map[string]interface{}); ok && cond["type"] == "Ready" {
+			return fmt.Sprint(cond["status"])
+		}
+	}
+	return ""
+}
+
+func createUnstructured(t *testing.T, gvk schema.GroupVersionKind, name string, spec map[string]interface{}) {
+	t.Helper()
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	if err := k8sClient.Create(context.Background(), obj); err != nil {
+		t.Fatalf("failed to create %s %s: %v", gvk.Kind, name, err)
+	}
+}
+
+func TestFakeOperators_DriveToReady(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ops := startOperators(t, fakeoperators.Options{
+		Behaviors: map[fakeoperators.Kind]fakeoperators.Behavior{
+			fakeoperators.KindMariaDB: {Delay: 2 * time.Second},
+		},
+	})
+
+	createUnstructured(t, mariadbGVK, "mariadb-ready", nil)
+	createUnstructured(t, certificateGVK, "keystone-tls", map[string]interface{}{
+		"secretName": "keystone-tls",
+		"dnsNames":   []interface{}{"keystone.example.com"},
+	})
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-sync", Namespace: namespace},
+		Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers:    []corev1.Container{{Name: "db-sync", Image: "busybox"}},
+		}}},
+	}
+	g.Expect(k8sClient.Create(context.Background(), job)).To(Succeed())
+
+	// The MariaDB is delayed, so it is not ready right away.
+	g.Consistently(func(g Gomega) string { return readyStatus(g, mariadbGVK, "mariadb-ready") }, time.Second).ShouldNot(Equal("True"))
+	g.Eventually(func(g Gomega) string { return readyStatus(g, mariadbGVK, "mariadb-ready") }, 10*time.Second).Should(Equal("True"))
+	g.Eventually(func(g Gomega) string { return readyStatus(g, certificateGVK, "keystone-tls") }, 10*time.Second).Should(Equal("True"))
+	g.Eventually(func(g Gomega) int32 {
+		got := &batchv1.Job{}
+		g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(job), got)).To(Succeed())
+		return got.Status.Succeeded
+	}, 10*time.Second).Should(Equal(int32(1)))
+
+	secret := &corev1.Secret{}
+	g.Expect(k8sClient.Get(context.Background(), client.ObjectKey{Name: "keystone-tls", Namespace: namespace}, secret)).To(Succeed())
+	g.Expect(secret.Data).To(HaveKeyWithValue("ca.crt", ops.CA().CertificatePEM))
+}
+
+func TestFakeOperators_SetBehaviorFlipsReadyObjects(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ops := startOperators(t, fakeoperators.Options{Kinds: []fakeoperators.Kind{fakeoperators.KindMariaDB}})
+
+	createUnstructured(t, mariadbGVK, "mariadb-flip", nil)
+	g.Eventually(func(g Gomega) string { return readyStatus(g, mariadbGVK, "mariadb-flip") }, 10*time.Second).Should(Equal("True"))
+
+	ops.SetBehavior(fakeoperators.KindMariaDB, fakeoperators.Behavior{Fail: fakeoperators.Named("mariadb-flip")})
+	g.Eventually(func(g Gomega) string { return readyStatus(g, mariadbGVK, "mariadb-flip") }, 10*time.Second).Should(Equal("False"))
+
+	ops.SetBehavior(fakeoperators.KindMariaDB, fakeoperators.Behavior{})
+	g.Eventually(func(g Gomega) string { return readyStatus(g, mariadbGVK, "mariadb-flip") }, 10*time.Second).Should(Equal("True"))
+}
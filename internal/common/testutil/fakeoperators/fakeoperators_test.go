@@ -0,0 +1,280 @@
+package fakeoperators
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var testCreated = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestClient(objs ...client.Object) client.Client {
+	s := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(s)
+	for _, gvk := range []schema.GroupVersionKind{mariadbGVK, memcachedGVK, rabbitmqClusterGVK, externalSecretGVK, certificateGVK, clusterIssuerGVK} {
+		s.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		s.AddKnownTypeWithName(gvk.GroupVersion().WithKind(gvk.Kind+"List"), &unstructured.UnstructuredList{})
+	}
+	return fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).WithStatusSubresource(objs...).Build()
+}
+
+func newTestObject(gvk schema.GroupVersionKind, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	u.SetGroupVersionKind(gvk)
+	u.SetName(name)
+	u.SetNamespace("default")
+	u.SetCreationTimestamp(metav1.NewTime(testCreated))
+	return u
+}
+
+// reconcileKind runs the first reconciler of kind against obj at the given
+// time after creation.
+func reconcileKind(t *testing.T, ops *Operators, c client.Client, kind Kind, obj client.Object, elapsed time.Duration) reconcile.Result {
+	t.Helper()
+	ops.now = func() time.Time { return testCreated.Add(elapsed) }
+	r := ops.newReconciler(c, ops.handlers(kind)[0])
+	result, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+	if err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	return result
+}
+
+func getObject(t *testing.T, c client.Client, obj client.Object) client.Object {
+	t.Helper()
+	out := obj.DeepCopyObject().(client.Object)
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(obj), out); err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	return out
+}
+
+func TestNew(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	ops, err := New(Options{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ops.kinds).To(Equal(AllKinds))
+	g.Expect(ops.CA()).NotTo(BeNil())
+	g.Expect(ops.retryInterval).To(Equal(DefaultRetryInterval))
+
+	ops, err = New(Options{Kinds: []Kind{KindJob}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ops.CA()).To(BeNil())
+
+	_, err = New(Options{Kinds: []Kind{"Galera"}})
+	g.Expect(err).To(MatchError(ContainSubstring(`unknown kind "Galera"`)))
+}
+
+func TestReconcileReady(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     Kind
+		obj      *unstructured.Unstructured
+		condType string
+	}{
+		{name: "MariaDB", kind: KindMariaDB, obj: newTestObject(mariadbGVK, "mariadb", nil), condType: "Ready"},
+		{name: "Memcached", kind: KindMemcached, obj: newTestObject(memcachedGVK, "memcached", nil), condType: "Ready"},
+		{name: "RabbitmqCluster", kind: KindRabbitmqCluster, obj: newTestObject(rabbitmqClusterGVK, "rabbitmq", nil), condType: "ClusterAvailable"},
+		{
+			name: "ExternalSecret", kind: KindExternalSecret, condType: "Ready",
+			obj: newTestObject(externalSecretGVK, "keystone-db", map[string]interface{}{
+				"data": []interface{}{map[string]interface{}{"secretKey": "password"}},
+			}),
+		},
+		{
+			name: "Certificate", kind: KindCertificate, condType: "Ready",
+			obj: newTestObject(certificateGVK, "keystone-tls", map[string]interface{}{
+				"secretName": "keystone-tls",
+				"dnsNames":   []interface{}{"keystone.example.com"},
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			c := newTestClient(tt.obj)
+			ops, err := New(Options{})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			result := reconcileKind(t, ops, c, tt.kind, tt.obj, 0)
+			g.Expect(result).To(Equal(reconcile.Result{}))
+
+			got := getObject(t, c, tt.obj)
+			g.Expect(conditionMatches(got, tt.condType, "True", "", "")).To(BeTrue())
+			g.Expect(ops.handlers(tt.kind)[0].done(got, false, Behavior{})).To(BeTrue())
+		})
+	}
+}
+
+func TestReconcileExternalSecretData(t *testing.T) {
+	g := NewGomegaWithT(t)
+	obj := newTestObject(externalSecretGVK, "keystone-db", map[string]interface{}{
+		"data": []interface{}{map[string]interface{}{"secretKey": "password"}},
+	})
+	c := newTestClient(obj)
+
+	ops, err := New(Options{})
+	g.Expect(err).NotTo(HaveOccurred())
+	reconcileKind(t, ops, c, KindExternalSecret, obj, 0)
+
+	secret := &corev1.Secret{}
+	g.Expect(c.Get(context.Background(), client.ObjectKey{Name: "keystone-db", Namespace: "default"}, secret)).To(Succeed())
+	g.Expect(secret.Data).To(HaveKeyWithValue("password", []byte("fake-password")))
+}
+
+func TestReconcileDelay(t *testing.T) {
+	g := NewGomegaWithT(t)
+	obj := newTestObject(mariadbGVK, "mariadb", nil)
+	c := newTestClient(obj)
+
+	ops, err := New(Options{Behaviors: map[Kind]Behavior{KindMariaDB: {Delay: 10 * time.Second}}})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	result := reconcileKind(t, ops, c, KindMariaDB, obj, 4*time.Second)
+	g.Expect(result.RequeueAfter).To(Equal(6 * time.Second))
+	g.Expect(conditionMatches(getObject(t, c, obj), "Ready", "True", "", "")).To(BeFalse())
+
+	result = reconcileKind(t, ops, c, KindMariaDB, obj, 10*time.Second)
+	g.Expect(result).To(Equal(reconcile.Result{}))
+	g.Expect(conditionMatches(getObject(t, c, obj), "Ready", "True", "", "")).To(BeTrue())
+}
+
+func TestReconcileFailureInjection(t *testing.T) {
+	g := NewGomegaWithT(t)
+	failing := newTestObject(memcachedGVK, "memcached-a", nil)
+	healthy := newTestObject(memcachedGVK, "memcached-b", nil)
+	c := newTestClient(failing, healthy)
+
+	ops, err := New(Options{Behaviors: map[Kind]Behavior{
+		KindMemcached: {Fail: Named("memcached-a"), FailureReason: "OutOfMemory"},
+	}})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	reconcileKind(t, ops, c, KindMemcached, failing, 0)
+	reconcileKind(t, ops, c, KindMemcached, healthy, 0)
+	g.Expect(conditionMatches(getObject(t, c, failing), "Ready", "False", "OutOfMemory", DefaultFailureMessage)).To(BeTrue())
+	g.Expect(conditionMatches(getObject(t, c, healthy), "Ready", "True", "", "")).To(BeTrue())
+
+	// Clearing the failure lets the object recover.
+	ops.SetBehavior(KindMemcached, Behavior{})
+	reconcileKind(t, ops, c, KindMemcached, failing, 0)
+	g.Expect(conditionMatches(getObject(t, c, failing), "Ready", "True", "", "")).To(BeTrue())
+}
+
+func TestReconcileJob(t *testing.T) {
+	g := NewGomegaWithT(t)
+	newJob := func(name string) *batchv1.Job {
+		return &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(testCreated),
+		}}
+	}
+	succeeding, failing := newJob("db-sync"), newJob("bootstrap")
+	c := newTestClient(succeeding, failing)
+
+	ops, err := New(Options{Behaviors: map[Kind]Behavior{KindJob: {Fail: Named("bootstrap")}}})
+	g.Expect(err).NotTo(HaveOccurred())
+	reconcileKind(t, ops, c, KindJob, succeeding, 0)
+	reconcileKind(t, ops, c, KindJob, failing, 0)
+
+	g.Expect(getObject(t, c, succeeding).(*batchv1.Job).Status.Succeeded).To(Equal(int32(1)))
+	g.Expect(getObject(t, c, failing).(*batchv1.Job).Status.Failed).To(Equal(int32(7)))
+
+	// A finished Job is left alone even if the behavior changes.
+	ops.SetBehavior(KindJob, Behavior{})
+	reconcileKind(t, ops, c, KindJob, failing, 0)
+	g.Expect(getObject(t, c, failing).(*batchv1.Job).Status.Succeeded).To(BeZero())
+}
+
+func TestReconcileCertificateWaitsForClusterIssuer(t *testing.T) {
+	g := NewGomegaWithT(t)
+	cert := newTestObject(certificateGVK, "keystone-tls", map[string]interface{}{
+		"secretName": "keystone-tls",
+		"dnsNames":   []interface{}{"keystone.example.com"},
+		"issuerRef":  map[string]interface{}{"name": "selfsigned", "kind": "ClusterIssuer"},
+	})
+	issuer := &unstructured.Unstructured{}
+	issuer.SetGroupVersionKind(clusterIssuerGVK)
+	issuer.SetName("selfsigned")
+	c := newTestClient(cert, issuer)
+
+	ops, err := New(Options{RetryInterval: 3 * time.Second})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	result := reconcileKind(t, ops, c, KindCertificate, cert, 0)
+	g.Expect(result.RequeueAfter).To(Equal(3 * time.Second))
+	g.Expect(conditionMatches(getObject(t, c, cert), "Ready", "False", "IssuerNotReady", "")).To(BeTrue())
+
+	issuerReconciler := ops.newReconciler(c, ops.handlers(KindCertificate)[1])
+	_, err = issuerReconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(issuer)})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	result = reconcileKind(t, ops, c, KindCertificate, cert, 0)
+	g.Expect(result).To(Equal(reconcile.Result{}))
+	g.Expect(conditionMatches(getObject(t, c, cert), "Ready", "True", "", "")).To(BeTrue())
+}
+
+func TestReconcileCertificateReissuesOnSpecChange(t *testing.T) {
+	g := NewGomegaWithT(t)
+	cert := newTestObject(certificateGVK, "keystone-tls", map[string]interface{}{
+		"secretName": "keystone-tls",
+		"dnsNames":   []interface{}{"keystone.example.com"},
+	})
+	cert.SetGeneration(1)
+	c := newTestClient(cert)
+	ops, err := New(Options{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	reconcileKind(t, ops, c, KindCertificate, cert, 0)
+	got := getObject(t, c, cert).(*unstructured.Unstructured)
+	g.Expect(ops.handlers(KindCertificate)[0].done(got, false, Behavior{})).To(BeTrue())
+
+	// A spec change bumps the generation; the Ready condition is then stale.
+	g.Expect(unstructured.SetNestedStringSlice(got.Object, []string{"keystone.example.org"}, "spec", "dnsNames")).To(Succeed())
+	got.SetGeneration(2)
+	g.Expect(c.Update(context.Background(), got)).To(Succeed())
+	got = getObject(t, c, cert).(*unstructured.Unstructured)
+	g.Expect(ops.handlers(KindCertificate)[0].done(got, false, Behavior{})).To(BeFalse())
+
+	reconcileKind(t, ops, c, KindCertificate, cert, 0)
+	got = getObject(t, c, cert).(*unstructured.Unstructured)
+	revision, _, _ := unstructured.NestedInt64(got.Object, "status", "revision")
+	g.Expect(revision).To(Equal(int64(2)))
+	g.Expect(ops.handlers(KindCertificate)[0].done(got, false, Behavior{})).To(BeTrue())
+
+	secret := &corev1.Secret{}
+	g.Expect(c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "keystone-tls"}, secret)).To(Succeed())
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	g.Expect(block).NotTo(BeNil())
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(leaf.DNSNames).To(ConsistOf("keystone.example.org"))
+}
+
+func TestSetBehaviorRetriggers(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ops, err := New(Options{Kinds: []Kind{KindMariaDB}})
+	g.Expect(err).NotTo(HaveOccurred())
+	r := ops.newReconciler(newTestClient(), ops.handlers(KindMariaDB)[0])
+
+	ops.SetBehavior(KindMariaDB, Behavior{Fail: All()})
+	// A second change while the first trigger is pending must not block.
+	ops.SetBehavior(KindMariaDB, Behavior{})
+	g.Expect(r.trigger).To(HaveLen(1))
+	g.Expect(ops.behavior(KindMariaDB).Fail).To(BeNil())
+}
@@ -0,0 +1,254 @@
+package fakeoperators
+
+import (
+	"context"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/c5c3/forge/internal/common/testutil/simulators"
+)
+
+// GroupVersionKinds of the third-party resources handled by the fake
+// operators; they match the CRDs bundled in testutil/fake_crds.
+var (
+	mariadbGVK         = schema.GroupVersionKind{Group: "k8s.mariadb.com", Version: "v1alpha1", Kind: "MariaDB"}
+	memcachedGVK       = schema.GroupVersionKind{Group: "opsv1.memcached.com", Version: "v1alpha1", Kind: "Memcached"}
+	rabbitmqClusterGVK = schema.GroupVersionKind{Group: "rabbitmq.com", Version: "v1beta1", Kind: "RabbitmqCluster"}
+	externalSecretGVK  = schema.GroupVersionKind{Group: "external-secrets.io", Version: "v1beta1", Kind: "ExternalSecret"}
+	certificateGVK     = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+	clusterIssuerGVK   = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "ClusterIssuer"}
+)
+
+// handlers returns the kindHandlers implementing the fake operator for kind.
+func (o *Operators) handlers(kind Kind) []kindHandler {
+	switch kind {
+	case KindMariaDB:
+		return []kindHandler{unstructuredHandler(kind, "fake-mariadb-operator", mariadbGVK, "Ready",
+			simulators.SimulateMariaDBReady, simulators.SimulateMariaDBNotReady)}
+	case KindMemcached:
+		return []kindHandler{unstructuredHandler(kind, "fake-memcached-operator", memcachedGVK, "Ready",
+			simulators.SimulateMemcachedReady, simulators.SimulateMemcachedNotReady)}
+	case KindRabbitmqCluster:
+		return []kindHandler{unstructuredHandler(kind, "fake-rabbitmq-cluster-operator", rabbitmqClusterGVK, "ClusterAvailable",
+			simulators.SimulateRabbitmqClusterReady, simulators.SimulateRabbitmqClusterNotReady)}
+	case KindExternalSecret:
+		return []kindHandler{o.externalSecretHandler()}
+	case KindCertificate:
+		return []kindHandler{o.certificateHandler(), clusterIssuerHandler()}
+	case KindJob:
+		return []kindHandler{jobHandler()}
+	}
+	return nil
+}
+
+// simulateFunc and simulateFailureFunc match the signatures of the
+// simulators for resources following the Ready/NotReady pattern.
+type (
+	simulateFunc        func(ctx context.Context, c client.Client, name, namespace string) error
+	simulateFailureFunc func(ctx context.Context, c client.Client, name, namespace, reason, message string) error
+)
+
+// unstructuredHandler builds a kindHandler for a custom resource whose
+// readiness is reported by the condition condType.
+func unstructuredHandler(kind Kind, name string, gvk schema.GroupVersionKind, condType string, ready simulateFunc, fail simulateFailureFunc) kindHandler {
+	return kindHandler{
+		kind:      kind,
+		name:      name,
+		newObject: newUnstructured(gvk),
+		newList:   newUnstructuredList(gvk),
+		done: func(obj client.Object, failing bool, b Behavior) bool {
+			if failing {
+				return conditionMatches(obj, condType, "False", b.failureReason(), b.failureMessage())
+			}
+			return conditionMatches(obj, condType, "True", "", "")
+		},
+		ready: func(ctx context.Context, c client.Client, obj client.Object) error {
+			return ready(ctx, c, obj.GetName(), obj.GetNamespace())
+		},
+		fail: func(ctx context.Context, c client.Client, obj client.Object, b Behavior) error {
+			return fail(ctx, c, obj.GetName(), obj.GetNamespace(), b.failureReason(), b.failureMessage())
+		},
+	}
+}
+
+// externalSecretHandler fakes the external-secrets operator. Synced
+// ExternalSecrets get a target Secret with the data from
+// Options.ExternalSecretData.
+func (o *Operators) externalSecretHandler() kindHandler {
+	return kindHandler{
+		kind:      KindExternalSecret,
+		name:      "fake-external-secrets-operator",
+		newObject: newUnstructured(externalSecretGVK),
+		newList:   newUnstructuredList(externalSecretGVK),
+		done: func(obj client.Object, failing bool, b Behavior) bool {
+			if failing {
+				return conditionMatches(obj, "Ready", "False", "SecretSyncedError", b.failureMessage())
+			}
+			return conditionMatches(obj, "Ready", "True", "", "")
+		},
+		ready: func(ctx context.Context, c client.Client, obj client.Object) error {
+			data := o.externalSecretData
+			if data == nil {
+				data = defaultExternalSecretData
+			}
+			return simulators.SimulateExternalSecretSync(ctx, c, obj.GetName(), obj.GetNamespace(), data(obj))
+		},
+		fail: func(ctx context.Context, c client.Client, obj client.Object, b Behavior) error {
+			return simulators.SimulateExternalSecretSyncError(ctx, c, obj.GetName(), obj.GetNamespace(), b.failureMessage())
+		},
+	}
+}
+
+// defaultExternalSecretData sets every spec.data[].secretKey of an
+// ExternalSecret to "fake-<secretKey>".
+func defaultExternalSecretData(obj client.Object) map[string][]byte {
+	data := map[string][]byte{}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return data
+	}
+	entries, _, _ := unstructured.NestedSlice(u.Object, "spec", "data")
+	for _, entry := range entries {
+		m, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if key, ok := m["secretKey"].(string); ok && key != "" {
+			data[key] = []byte("fake-" + key)
+		}
+	}
+	return data
+}
+
+// certificateHandler fakes cert-manager issuing Certificates with the test
+// CA. Certificates referencing a ClusterIssuer wait until it is ready, and
+// are reissued when their spec changes.
+func (o *Operators) certificateHandler() kindHandler {
+	return kindHandler{
+		kind:      KindCertificate,
+		name:      "fake-cert-manager-certificates",
+		newObject: newUnstructured(certificateGVK),
+		newList:   newUnstructuredList(certificateGVK),
+		done: func(obj client.Object, failing bool, b Behavior) bool {
+			if !conditionObserved(obj, "Ready") {
+				return false
+			}
+			if failing {
+				return conditionMatches(obj, "Ready", "False", b.failureReason(), b.failureMessage())
+			}
+			return conditionMatches(obj, "Ready", "True", "", "")
+		},
+		ready: func(ctx context.Context, c client.Client, obj client.Object) error {
+			return simulators.SimulateCertificateIssued(ctx, c, obj.GetName(), obj.GetNamespace(), o.ca)
+		},
+		fail: func(ctx context.Context, c client.Client, obj client.Object, b Behavior) error {
+			return simulators.SimulateCertificateNotReady(ctx, c, obj.GetName(), obj.GetNamespace(), b.failureReason(), b.failureMessage())
+		},
+	}
+}
+
+// clusterIssuerHandler reports every ClusterIssuer as ready. It ignores the
+// Certificate Behavior; inject failures on the Certificates instead.
+func clusterIssuerHandler() kindHandler {
+	return kindHandler{
+		kind:      KindCertificate,
+		name:      "fake-cert-manager-clusterissuers",
+		newObject: newUnstructured(clusterIssuerGVK),
+		newList:   newUnstructuredList(clusterIssuerGVK),
+		done: func(obj client.Object, _ bool, _ Behavior) bool {
+			return conditionMatches(obj, "Ready", "True", "", "")
+		},
+		ready: func(ctx context.Context, c client.Client, obj client.Object) error {
+			return simulators.SimulateClusterIssuerReady(ctx, c, obj.GetName())
+		},
+	}
+}
+
+// jobHandler fakes the Kubernetes job controller, which does not run in
+// envtest: Jobs complete, or fail with BackoffLimitExceeded.
+func jobHandler() kindHandler {
+	return kindHandler{
+		kind:      KindJob,
+		name:      "fake-job-controller",
+		newObject: func() client.Object { return &batchv1.Job{} },
+		newList:   func() client.ObjectList { return &batchv1.JobList{} },
+		done: func(obj client.Object, failing bool, _ Behavior) bool {
+			job, ok := obj.(*batchv1.Job)
+			if !ok {
+				return false
+			}
+			// Jobs are immutable once finished, like with the real controller.
+			for _, cond := range job.Status.Conditions {
+				if (cond.Type == batchv1.JobComplete || cond.Type == batchv1.JobFailed) && cond.Status == corev1.ConditionTrue {
+					return true
+				}
+			}
+			return false
+		},
+		ready: func(ctx context.Context, c client.Client, obj client.Object) error {
+			return simulators.SimulateJobComplete(ctx, c, obj.GetName(), obj.GetNamespace())
+		},
+		fail: func(ctx context.Context, c client.Client, obj client.Object, _ Behavior) error {
+			return simulators.SimulateJobFailed(ctx, c, obj.GetName(), obj.GetNamespace())
+		},
+	}
+}
+
+func newUnstructured(gvk schema.GroupVersionKind) func() client.Object {
+	return func() client.Object {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+		return u
+	}
+}
+
+func newUnstructuredList(gvk schema.GroupVersionKind) func() client.ObjectList {
+	return func() client.ObjectList {
+		l := &unstructured.UnstructuredList{}
+		l.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		return l
+	}
+}
+
+// conditionMatches reports whether obj has a condType condition with the
+// given status and, if non-empty, reason and message.
+func conditionMatches(obj client.Object, condType, status, reason, message string) bool {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return false
+	}
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok || cond["type"] != condType {
+			continue
+		}
+		return cond["status"] == status &&
+			(reason == "" || cond["reason"] == reason) &&
+			(message == "" || cond["message"] == message)
+	}
+	return false
+}
+
+// conditionObserved reports whether the condType condition of obj was
+// computed for the current generation of obj.
+func conditionObserved(obj client.Object, condType string) bool {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return false
+	}
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok || cond["type"] != condType {
+			continue
+		}
+		observed, _, _ := unstructured.NestedInt64(cond, "observedGeneration")
+		return observed == u.GetGeneration()
+	}
+	return false
+}
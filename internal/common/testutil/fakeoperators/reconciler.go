@@ -0,0 +1,138 @@
+package fakeoperators
+
+import (
+	"context"
+	"errors"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/c5c3/forge/internal/common/testutil/simulators"
+)
+
+// kindHandler describes how a fake operator drives the objects of one kind.
+type kindHandler struct {
+	// kind selects the Behavior that applies.
+	kind Kind
+	// name is the controller name.
+	name string
+	// newObject and newList return empty objects of the watched kind.
+	newObject func() client.Object
+	newList   func() client.ObjectList
+	// done reports whether obj already shows the outcome the fake operator
+	// would produce, so that its own status updates do not cause a loop.
+	done func(obj client.Object, fail bool, b Behavior) bool
+	// ready drives obj to its ready or completed state.
+	ready func(ctx context.Context, c client.Client, obj client.Object) error
+	// fail reports a failure on obj. A nil fail means the kind ignores its
+	// Behavior and always becomes ready immediately.
+	fail func(ctx context.Context, c client.Client, obj client.Object, b Behavior) error
+}
+
+// reconciler is a controller-runtime reconciler for one kindHandler.
+type reconciler struct {
+	ops     *Operators
+	client  client.Client
+	handler kindHandler
+	trigger chan event.GenericEvent
+}
+
+func (o *Operators) newReconciler(c client.Client, h kindHandler) *reconciler {
+	r := &reconciler{
+		ops:     o,
+		client:  c,
+		handler: h,
+		// A single pending trigger is enough: it re-enqueues every object.
+		trigger: make(chan event.GenericEvent, 1),
+	}
+	o.mu.Lock()
+	o.triggers[h.kind] = append(o.triggers[h.kind], r)
+	o.mu.Unlock()
+	return r
+}
+
+// setupWithManager registers the reconciler as a controller with mgr. Name
+// validation is skipped because tests commonly start several managers with
+// fake operators in one process.
+func (r *reconciler) setupWithManager(mgr ctrl.Manager) error {
+	skipNameValidation := true
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.handler.name).
+		For(r.handler.newObject()).
+		WatchesRawSource(source.Channel(r.trigger, handler.EnqueueRequestsFromMapFunc(r.enqueueAll))).
+		WithOptions(controller.Options{SkipNameValidation: &skipNameValidation}).
+		Complete(r)
+}
+
+// retrigger re-enqueues all objects of the kind, for example after the
+// Behavior changed.
+func (r *reconciler) retrigger() {
+	select {
+	case r.trigger <- event.GenericEvent{Object: r.handler.newObject()}:
+	default:
+		// A trigger is already pending.
+	}
+}
+
+// enqueueAll maps a trigger to reconcile requests for every object of the
+// kind.
+func (r *reconciler) enqueueAll(ctx context.Context, _ client.Object) []reconcile.Request {
+	list := r.handler.newList()
+	if err := r.client.List(ctx, list); err != nil {
+		log.FromContext(ctx).Error(err, "listing objects", "controller", r.handler.name)
+		return nil
+	}
+	var requests []reconcile.Request
+	_ = meta.EachListItem(list, func(item runtime.Object) error {
+		if obj, ok := item.(client.Object); ok {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+		}
+		return nil
+	})
+	return requests
+}
+
+// Reconcile drives the object to the outcome selected by the current
+// Behavior once it is older than Behavior.Delay.
+func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (ctrl.Result, error) {
+	obj := r.handler.newObject()
+	if err := r.client.Get(ctx, req.NamespacedName, obj); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !obj.GetDeletionTimestamp().IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	var b Behavior
+	if r.handler.fail != nil {
+		b = r.ops.behavior(r.handler.kind)
+	}
+	fail := b.fails(obj)
+	if r.handler.done(obj, fail, b) {
+		return ctrl.Result{}, nil
+	}
+
+	if wait := obj.GetCreationTimestamp().Add(b.Delay).Sub(r.ops.now()); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	var err error
+	if fail {
+		err = r.handler.fail(ctx, r.client, obj, b)
+	} else {
+		err = r.handler.ready(ctx, r.client, obj)
+	}
+	if errors.Is(err, simulators.ErrDependencyNotReady) {
+		log.FromContext(ctx).V(1).Info("dependency not ready, retrying", "error", err.Error())
+		return ctrl.Result{RequeueAfter: r.ops.retryInterval}, nil
+	}
+	return ctrl.Result{}, err
+}
@@ -0,0 +1,136 @@
+package simulators
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// backupGVK and restoreGVK identify the mariadb-operator Backup and Restore
+// kinds.
+var (
+	backupGVK = schema.GroupVersionKind{
+		Group:   "k8s.mariadb.com",
+		Version: "v1alpha1",
+		Kind:    "Backup",
+	}
+	restoreGVK = schema.GroupVersionKind{
+		Group:   "k8s.mariadb.com",
+		Version: "v1alpha1",
+		Kind:    "Restore",
+	}
+)
+
+// SimulateBackupComplete patches the status sub-resource of an existing
+// Backup custom resource so that a "Complete" condition with status "True"
+// is present, as the mariadb-operator does once the backup Job succeeded.
+//
+// Like the mariadb-operator, it first checks that the MariaDB referenced by
+// spec.mariaDbRef is ready; if it is not, an error wrapping
+// ErrDependencyNotReady is returned and the Backup is left unchanged.
+func SimulateBackupComplete(ctx context.Context, c client.Client, name, namespace string) error {
+	return simulateMariaDBJobOutcome(ctx, c, backupGVK, name, namespace, true, "Backup completed", nil)
+}
+
+// SimulateBackupFailed patches the status sub-resource of an existing Backup
+// so that its "Complete" condition is "False" with reason "JobFailed".
+func SimulateBackupFailed(ctx context.Context, c client.Client, name, namespace string) error {
+	return simulateMariaDBJobOutcome(ctx, c, backupGVK, name, namespace, false, "Backup failed", nil)
+}
+
+// SimulateRestoreComplete patches the status sub-resource of an existing
+// Restore custom resource so that a "Complete" condition with status "True"
+// is present.
+//
+// It first checks that the MariaDB referenced by spec.mariaDbRef is ready and
+// that the Backup referenced by spec.backupRef has completed; otherwise an
+// error wrapping ErrDependencyNotReady is returned.
+func SimulateRestoreComplete(ctx context.Context, c client.Client, name, namespace string) error {
+	return simulateMariaDBJobOutcome(ctx, c, restoreGVK, name, namespace, true, "Restore completed",
+		func(restore *unstructured.Unstructured) error {
+			return requireBackupComplete(ctx, c, restore)
+		})
+}
+
+// SimulateRestoreFailed patches the status sub-resource of an existing
+// Restore so that its "Complete" condition is "False" with reason
+// "JobFailed".
+func SimulateRestoreFailed(ctx context.Context, c client.Client, name, namespace string) error {
+	return simulateMariaDBJobOutcome(ctx, c, restoreGVK, name, namespace, false, "Restore failed", nil)
+}
+
+// simulateMariaDBJobOutcome implements the simulators of the Job-based
+// mariadb-operator resources. Successful outcomes check the referenced
+// MariaDB and the optional extra dependency first.
+func simulateMariaDBJobOutcome(ctx context.Context, c client.Client, gvk schema.GroupVersionKind, name, namespace string, complete bool, message string, extra func(*unstructured.Unstructured) error) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := c.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, obj); err != nil {
+		return fmt.Errorf("getting %s %s/%s: %w", gvk.Kind, namespace, name, err)
+	}
+
+	if complete {
+		if err := requireMariaDBReady(ctx, c, obj); err != nil {
+			return err
+		}
+		if extra != nil {
+			if err := extra(obj); err != nil {
+				return err
+			}
+		}
+	}
+
+	status, reason := "False", "JobFailed"
+	if complete {
+		status, reason = "True", "JobComplete"
+	}
+	patch := client.MergeFrom(obj.DeepCopy())
+	conditions := []interface{}{
+		map[string]interface{}{
+			"type":               "Complete",
+			"status":             status,
+			"reason":             reason,
+			"message":            message,
+			"lastTransitionTime": time.Now().UTC().Format(time.RFC3339),
+		},
+	}
+	if err := unstructured.SetNestedSlice(obj.Object, conditions, "status", "conditions"); err != nil {
+		return fmt.Errorf("setting %s status.conditions: %w", gvk.Kind, err)
+	}
+	if err := c.Status().Patch(ctx, obj, patch); err != nil {
+		return fmt.Errorf("patching %s status: %w", gvk.Kind, err)
+	}
+	return nil
+}
+
+// requireBackupComplete checks that the Backup referenced by
+// spec.backupRef.name of restore exists and has completed.
+func requireBackupComplete(ctx context.Context, c client.Client, restore *unstructured.Unstructured) error {
+	backupName, _, _ := unstructured.NestedString(restore.Object, "spec", "backupRef", "name")
+	if backupName == "" {
+		return fmt.Errorf("Restore %s/%s has no spec.backupRef.name", restore.GetNamespace(), restore.GetName())
+	}
+
+	backup := &unstructured.Unstructured{}
+	backup.SetGroupVersionKind(backupGVK)
+	if err := c.Get(ctx, client.ObjectKey{Name: backupName, Namespace: restore.GetNamespace()}, backup); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("%w: Backup %s/%s referenced by Restore %s not found",
+				ErrDependencyNotReady, restore.GetNamespace(), backupName, restore.GetName())
+		}
+		return fmt.Errorf("getting Backup %s/%s: %w", restore.GetNamespace(), backupName, err)
+	}
+	conditions, _, _ := unstructured.NestedSlice(backup.Object, "status", "conditions")
+	for _, cond := range conditions {
+		if m, ok := cond.(map[string]interface{}); ok && m["type"] == "Complete" && m["status"] == "True" {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: Backup %s/%s referenced by Restore %s has not completed",
+		ErrDependencyNotReady, restore.GetNamespace(), backupName, restore.GetName())
+}
@@ -0,0 +1,288 @@
+package simulators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// certificateGVK identifies the cert-manager Certificate kind.
+var certificateGVK = schema.GroupVersionKind{
+	Group:   "cert-manager.io",
+	Version: "v1",
+	Kind:    "Certificate",
+}
+
+// clusterIssuerGVK identifies the cert-manager ClusterIssuer kind.
+var clusterIssuerGVK = schema.GroupVersionKind{
+	Group:   "cert-manager.io",
+	Version: "v1",
+	Kind:    "ClusterIssuer",
+}
+
+// defaultCertificateDuration is the validity cert-manager uses when
+// spec.duration is not set.
+const defaultCertificateDuration = 90 * 24 * time.Hour
+
+// SimulateClusterIssuerReady creates a self-signed ClusterIssuer with the
+// given name (if it does not already exist) and sets its "Ready" condition to
+// "True".
+func SimulateClusterIssuerReady(ctx context.Context, c client.Client, name string) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(clusterIssuerGVK)
+	obj.SetName(name)
+	if err := unstructured.SetNestedMap(obj.Object, map[string]interface{}{}, "spec", "selfSigned"); err != nil {
+		return fmt.Errorf("setting ClusterIssuer spec.selfSigned: %w", err)
+	}
+
+	if err := createOrGet(ctx, c, obj, clusterIssuerGVK.Kind); err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(obj.DeepCopy())
+	if err := setReadyCondition(obj, true, "IsReady", "Signing CA verified"); err != nil {
+		return fmt.Errorf("setting ClusterIssuer status.conditions: %w", err)
+	}
+	if err := c.Status().Patch(ctx, obj, patch); err != nil {
+		return fmt.Errorf("patching ClusterIssuer status: %w", err)
+	}
+	return nil
+}
+
+// SimulateCertificateIssued issues the existing Certificate custom resource
+// with the given CA, the way cert-manager would: it generates a private key
+// and a real X.509 certificate for spec.commonName, spec.dnsNames and
+// spec.ipAddresses, valid for spec.duration (default 90 days), and writes
+// them to the kubernetes.io/tls Secret named by spec.secretName together with
+// the CA certificate (tls.crt, tls.key, ca.crt). It then sets the "Ready"
+// condition and status.notBefore, notAfter, renewalTime and revision.
+//
+// spec.privateKey.algorithm ("RSA" or "ECDSA") and spec.privateKey.size are
+// honoured. If spec.issuerRef refers to a ClusterIssuer, that issuer must
+// exist and be ready; otherwise the Certificate is marked NotReady and an
+// error wrapping ErrDependencyNotReady is returned. Calling it again renews
+// the certificate and bumps the revision.
+func SimulateCertificateIssued(ctx context.Context, c client.Client, name, namespace string, ca *TestCA) error {
+	if ca == nil {
+		return fmt.Errorf("issuing Certificate %s/%s: CA must not be nil", namespace, name)
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(certificateGVK)
+	if err := c.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, obj); err != nil {
+		return fmt.Errorf("getting Certificate %s/%s: %w", namespace, name, err)
+	}
+
+	if err := requireClusterIssuerReady(ctx, c, obj); err != nil {
+		if !errors.Is(err, ErrDependencyNotReady) {
+			return err
+		}
+		if patchErr := patchCertificateNotReady(ctx, c, obj, "IssuerNotReady", err.Error()); patchErr != nil {
+			return patchErr
+		}
+		return err
+	}
+
+	req, secretName, err := certificateRequest(obj)
+	if err != nil {
+		return err
+	}
+
+	certPEM, keyPEM, cert, err := ca.issue(req)
+	if err != nil {
+		return fmt.Errorf("issuing Certificate %s/%s: %w", namespace, name, err)
+	}
+
+	if err := writeTLSSecret(ctx, c, obj, secretName, certPEM, keyPEM, ca.CertificatePEM); err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(obj.DeepCopy())
+	revision, _, _ := unstructured.NestedInt64(obj.Object, "status", "revision")
+	renewBefore := req.duration / 3
+	if v, found, _ := unstructured.NestedString(obj.Object, "spec", "renewBefore"); found && v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("parsing Certificate %s/%s spec.renewBefore: %w", namespace, name, err)
+		}
+		renewBefore = d
+	}
+	status := map[string]interface{}{
+		"notBefore":   cert.NotBefore.UTC().Format(time.RFC3339),
+		"notAfter":    cert.NotAfter.UTC().Format(time.RFC3339),
+		"renewalTime": cert.NotAfter.Add(-renewBefore).UTC().Format(time.RFC3339),
+		"revision":    revision + 1,
+	}
+	for field, value := range status {
+		if err := unstructured.SetNestedField(obj.Object, value, "status", field); err != nil {
+			return fmt.Errorf("setting Certificate status.%s: %w", field, err)
+		}
+	}
+	if err := setReadyCondition(obj, true, "Ready", "Certificate is up to date and has not expired"); err != nil {
+		return fmt.Errorf("setting Certificate status.conditions: %w", err)
+	}
+	if err := c.Status().Patch(ctx, obj, patch); err != nil {
+		return fmt.Errorf("patching Certificate status: %w", err)
+	}
+	return nil
+}
+
+// SimulateCertificateNotReady sets the "Ready" condition of an existing
+// Certificate custom resource to "False" with the given reason and message,
+// as cert-manager does when issuance fails. The Secret of an earlier
+// successful issuance is left untouched.
+func SimulateCertificateNotReady(ctx context.Context, c client.Client, name, namespace, reason, message string) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(certificateGVK)
+	if err := c.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, obj); err != nil {
+		return fmt.Errorf("getting Certificate %s/%s: %w", namespace, name, err)
+	}
+	return patchCertificateNotReady(ctx, c, obj, reason, message)
+}
+
+// patchCertificateNotReady sets the "Ready" condition of cert to "False".
+func patchCertificateNotReady(ctx context.Context, c client.Client, cert *unstructured.Unstructured, reason, message string) error {
+	patch := client.MergeFrom(cert.DeepCopy())
+	if err := setReadyCondition(cert, false, reason, message); err != nil {
+		return fmt.Errorf("setting Certificate status.conditions: %w", err)
+	}
+	if err := c.Status().Patch(ctx, cert, patch); err != nil {
+		return fmt.Errorf("patching Certificate status: %w", err)
+	}
+	return nil
+}
+
+// requireClusterIssuerReady returns an error wrapping ErrDependencyNotReady if
+// the Certificate references a ClusterIssuer that is missing or not ready.
+// References to namespaced Issuers are not checked because no fake CRD exists
+// for them.
+func requireClusterIssuerReady(ctx context.Context, c client.Client, cert *unstructured.Unstructured) error {
+	kind, _, _ := unstructured.NestedString(cert.Object, "spec", "issuerRef", "kind")
+	if kind != clusterIssuerGVK.Kind {
+		return nil
+	}
+	name, _, _ := unstructured.NestedString(cert.Object, "spec", "issuerRef", "name")
+
+	issuer := &unstructured.Unstructured{}
+	issuer.SetGroupVersionKind(clusterIssuerGVK)
+	if err := c.Get(ctx, client.ObjectKey{Name: name}, issuer); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("ClusterIssuer %s not found: %w", name, ErrDependencyNotReady)
+		}
+		return fmt.Errorf("getting ClusterIssuer %s: %w", name, err)
+	}
+	if !isUnstructuredReady(issuer) {
+		return fmt.Errorf("ClusterIssuer %s is not ready: %w", name, ErrDependencyNotReady)
+	}
+	return nil
+}
+
+// certificateRequest translates the spec of a Certificate into a leafRequest
+// and returns it together with spec.secretName.
+func certificateRequest(cert *unstructured.Unstructured) (leafRequest, string, error) {
+	ref := cert.GetNamespace() + "/" + cert.GetName()
+
+	secretName, _, _ := unstructured.NestedString(cert.Object, "spec", "secretName")
+	if secretName == "" {
+		return leafRequest{}, "", fmt.Errorf("spec.secretName of Certificate %s is empty", ref)
+	}
+
+	req := leafRequest{duration: defaultCertificateDuration}
+	req.commonName, _, _ = unstructured.NestedString(cert.Object, "spec", "commonName")
+	req.dnsNames, _, _ = unstructured.NestedStringSlice(cert.Object, "spec", "dnsNames")
+	ips, _, _ := unstructured.NestedStringSlice(cert.Object, "spec", "ipAddresses")
+	for _, s := range ips {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return leafRequest{}, "", fmt.Errorf("invalid IP address %q in Certificate %s", s, ref)
+		}
+		req.ipAddresses = append(req.ipAddresses, ip)
+	}
+	if req.commonName == "" && len(req.dnsNames) == 0 && len(req.ipAddresses) == 0 {
+		return leafRequest{}, "", fmt.Errorf("certificate %s has no commonName, dnsNames or ipAddresses", ref)
+	}
+
+	if v, found, _ := unstructured.NestedString(cert.Object, "spec", "duration"); found && v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return leafRequest{}, "", fmt.Errorf("parsing Certificate %s spec.duration: %w", ref, err)
+		}
+		req.duration = d
+	}
+
+	req.keyAlgorithm, _, _ = unstructured.NestedString(cert.Object, "spec", "privateKey", "algorithm")
+	if size, found, _ := unstructured.NestedInt64(cert.Object, "spec", "privateKey", "size"); found {
+		req.keySize = int(size)
+	}
+	return req, secretName, nil
+}
+
+// writeTLSSecret creates or updates the kubernetes.io/tls Secret holding the
+// issued certificate, annotated the way cert-manager annotates its Secrets.
+func writeTLSSecret(ctx context.Context, c client.Client, cert *unstructured.Unstructured, secretName string, certPEM, keyPEM, caPEM []byte) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: cert.GetNamespace(),
+		},
+	}
+	key := client.ObjectKeyFromObject(secret)
+
+	err := c.Get(ctx, key, secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("getting Secret %s: %w", key, err)
+	}
+	exists := err == nil
+
+	// The type of a Secret is immutable, so a Secret of another type is
+	// replaced, keeping its labels and annotations.
+	if exists && secret.Type != corev1.SecretTypeTLS {
+		if err := c.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("deleting Secret %s of type %s: %w", key, secret.Type, err)
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        secretName,
+				Namespace:   cert.GetNamespace(),
+				Labels:      secret.Labels,
+				Annotations: secret.Annotations,
+			},
+		}
+		exists = false
+	}
+
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations["cert-manager.io/certificate-name"] = cert.GetName()
+	issuerName, _, _ := unstructured.NestedString(cert.Object, "spec", "issuerRef", "name")
+	issuerKind, _, _ := unstructured.NestedString(cert.Object, "spec", "issuerRef", "kind")
+	secret.Annotations["cert-manager.io/issuer-name"] = issuerName
+	secret.Annotations["cert-manager.io/issuer-kind"] = issuerKind
+	secret.Type = corev1.SecretTypeTLS
+	secret.Data = map[string][]byte{
+		corev1.TLSCertKey:       certPEM,
+		corev1.TLSPrivateKeyKey: keyPEM,
+		"ca.crt":                caPEM,
+	}
+
+	if exists {
+		if err := c.Update(ctx, secret); err != nil {
+			return fmt.Errorf("updating Secret %s: %w", key, err)
+		}
+		return nil
+	}
+	if err := c.Create(ctx, secret); err != nil {
+		return fmt.Errorf("creating Secret %s: %w", key, err)
+	}
+	return nil
+}
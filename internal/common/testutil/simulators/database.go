@@ -0,0 +1,29 @@
+package simulators
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// databaseGVK identifies the mariadb-operator Database kind.
+var databaseGVK = schema.GroupVersionKind{
+	Group:   "k8s.mariadb.com",
+	Version: "v1alpha1",
+	Kind:    "Database",
+}
+
+// SimulateDatabaseReady patches the status sub-resource of an existing
+// Database custom resource so that ready=true and a "Ready" condition with
+// status "True" is present.
+//
+// Like the mariadb-operator, it first checks that the MariaDB referenced by
+// spec.mariaDbRef exists and is ready. If it is not, the Database is marked
+// NotReady and an error wrapping ErrDependencyNotReady is returned, so tests
+// catch controllers that create the Database before its MariaDB. Unlike
+// SimulateMariaDBReady, the Database is not created if it is missing, since
+// its spec is owned by the controller under test.
+func SimulateDatabaseReady(ctx context.Context, c client.Client, name, namespace string) error {
+	return simulateMariaDBChildReady(ctx, c, databaseGVK, name, namespace, "MariaDBNotReady", "Database is ready", nil)
+}
@@ -7,4 +7,26 @@
 // the API server is present, so these simulators stand in for those absent
 // controllers by directly creating resources and patching status fields to the
 // expected terminal state.
+//
+// Every happy-path simulator has a failure counterpart (SimulateMariaDBNotReady,
+// SimulateMemcachedNotReady, SimulateRabbitmqClusterNotReady,
+// SimulateExternalSecretSyncError, SimulateCertificateNotReady,
+// SimulateJobFailed) so that controller tests can exercise error handling. The NotReady variants
+// can also be called on a resource that is already Ready to flip it back
+// mid-test.
+//
+// The simulators for resources that live inside a MariaDB server
+// (SimulateDatabaseReady, SimulateUserReady, SimulateGrantReady) validate their
+// dependencies like the mariadb-operator does and return an error wrapping
+// ErrDependencyNotReady when they are called too early. The same holds for
+// SimulateBackupComplete and SimulateRestoreComplete, which report the
+// "Complete" condition the mariadb-operator sets on its Job-based resources.
+//
+// SimulateCertificateIssued stands in for cert-manager. It signs a real X.509
+// certificate with a TestCA so that components consuming the resulting TLS
+// Secret can perform actual handshakes in tests.
+//
+// SimulateGarbageCollection stands in for the kube-controller-manager garbage
+// collector: it deletes objects whose owners are gone, cascading through
+// dependents of dependents.
 package simulators
@@ -3,7 +3,6 @@ package simulators
 import (
 	"context"
 	"fmt"
-	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -13,54 +12,38 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// externalSecretGVK identifies the external-secrets ExternalSecret kind.
+var externalSecretGVK = schema.GroupVersionKind{
+	Group:   "external-secrets.io",
+	Version: "v1beta1",
+	Kind:    "ExternalSecret",
+}
+
 // SimulateExternalSecretSync creates an ExternalSecret custom resource (if it
 // does not already exist), patches its status sub-resource to reflect a
 // successful sync, and also creates the target Kubernetes Secret populated with
-// targetSecretData.
+// targetSecretData. The target Secret is named after spec.target.name of an
+// existing ExternalSecret and defaults to the ExternalSecret's name, as in
+// the external-secrets operator.
 //
 // In a real cluster the external-secrets operator would watch ExternalSecret
 // objects and create the target Secret automatically.  In envtest the operator
 // is absent, so this simulator performs both actions to put the cluster in the
 // expected terminal state.
 func SimulateExternalSecretSync(ctx context.Context, c client.Client, name, namespace string, targetSecretData map[string][]byte) error {
-	// NOTE: Unlike MariaDB and Memcached, this does not use
-	// simulateUnstructuredReady because ExternalSecret has a different status
-	// shape — no status.ready boolean field, only status.conditions.
-	obj := &unstructured.Unstructured{}
-	obj.SetGroupVersionKind(schema.GroupVersionKind{
-		Group:   "external-secrets.io",
-		Version: "v1beta1",
-		Kind:    "ExternalSecret",
-	})
-	obj.SetName(name)
-	obj.SetNamespace(namespace)
-
-	if err := createOrGet(ctx, c, obj, "ExternalSecret"); err != nil {
+	obj, err := patchExternalSecretReady(ctx, c, name, namespace, true, "SecretSynced", "Secret was synced")
+	if err != nil {
 		return err
 	}
 
-	patch := client.MergeFrom(obj.DeepCopy())
-
-	conditions := []interface{}{
-		map[string]interface{}{
-			"type":               "Ready",
-			"status":             "True",
-			"reason":             "SecretSynced",
-			"message":            "Secret was synced",
-			"lastTransitionTime": time.Now().UTC().Format(time.RFC3339),
-		},
-	}
-	if err := unstructured.SetNestedSlice(obj.Object, conditions, "status", "conditions"); err != nil {
-		return fmt.Errorf("setting ExternalSecret status.conditions: %w", err)
-	}
-
-	if err := c.Status().Patch(ctx, obj, patch); err != nil {
-		return fmt.Errorf("patching ExternalSecret status: %w", err)
+	targetName, _, _ := unstructured.NestedString(obj.Object, "spec", "target", "name")
+	if targetName == "" {
+		targetName = name
 	}
 
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
+			Name:      targetName,
 			Namespace: namespace,
 		},
 		Data: targetSecretData,
@@ -83,3 +66,43 @@ func SimulateExternalSecretSync(ctx context.Context, c client.Client, name, name
 
 	return nil
 }
+
+// SimulateExternalSecretSyncError creates an ExternalSecret custom resource (if
+// it does not already exist) and patches its status sub-resource to reflect a
+// failed sync: a "Ready" condition with status "False", reason
+// "SecretSyncedError" and the given message, as reported by the
+// external-secrets operator when the provider cannot be reached or the remote
+// key is missing. The target Secret is left untouched, so a Secret from an
+// earlier successful sync remains in place, matching the real operator.
+func SimulateExternalSecretSyncError(ctx context.Context, c client.Client, name, namespace, message string) error {
+	_, err := patchExternalSecretReady(ctx, c, name, namespace, false, "SecretSyncedError", message)
+	return err
+}
+
+// patchExternalSecretReady creates the ExternalSecret if needed and sets its
+// "Ready" condition. It returns the patched ExternalSecret.
+func patchExternalSecretReady(ctx context.Context, c client.Client, name, namespace string, ready bool, reason, message string) (*unstructured.Unstructured, error) {
+	// NOTE: Unlike MariaDB and Memcached, this does not use
+	// simulateUnstructuredStatus because ExternalSecret has a different status
+	// shape — no status.ready boolean field, only status.conditions.
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(externalSecretGVK)
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+
+	if err := createOrGet(ctx, c, obj, "ExternalSecret"); err != nil {
+		return nil, err
+	}
+
+	patch := client.MergeFrom(obj.DeepCopy())
+
+	if err := setReadyCondition(obj, ready, reason, message); err != nil {
+		return nil, fmt.Errorf("setting ExternalSecret status.conditions: %w", err)
+	}
+
+	if err := c.Status().Patch(ctx, obj, patch); err != nil {
+		return nil, fmt.Errorf("patching ExternalSecret status: %w", err)
+	}
+
+	return obj, nil
+}
@@ -0,0 +1,151 @@
+package simulators
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultGarbageCollectedKinds are the dependent kinds that
+// SimulateGarbageCollection inspects when no kinds are given: the built-in
+// kinds the operators create plus the third-party kinds of the bundled fake
+// CRDs.
+var DefaultGarbageCollectedKinds = []schema.GroupVersionKind{
+	{Version: "v1", Kind: "ConfigMap"},
+	{Version: "v1", Kind: "Secret"},
+	{Version: "v1", Kind: "Service"},
+	{Version: "v1", Kind: "ServiceAccount"},
+	{Version: "v1", Kind: "PersistentVolumeClaim"},
+	{Group: "apps", Version: "v1", Kind: "Deployment"},
+	{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+	{Group: "batch", Version: "v1", Kind: "Job"},
+	{Group: "batch", Version: "v1", Kind: "CronJob"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"},
+	{Group: "policy", Version: "v1", Kind: "PodDisruptionBudget"},
+	mariadbGVK,
+	databaseGVK,
+	userGVK,
+	grantGVK,
+	backupGVK,
+	restoreGVK,
+	memcachedGVK,
+	rabbitmqClusterGVK,
+	externalSecretGVK,
+	certificateGVK,
+}
+
+// SimulateGarbageCollection emulates the Kubernetes garbage collector, which
+// does not run in envtest, with background cascading deletion semantics: an
+// object of one of the given kinds (DefaultGarbageCollectedKinds if none are
+// given) is deleted once none of the owners in its ownerReferences exists any
+// more, where an owner that was recreated with a different UID counts as
+// gone. If only some owners are gone, the dangling references are removed
+// instead. Passes are repeated until nothing changes, so dependents of
+// deleted dependents are collected as well. Owners that still exist but are
+// being deleted (e.g. held by a finalizer) keep their dependents, as in a real
+// cluster.
+//
+// Kinds that are not served by the API server are skipped. It returns the
+// objects it deleted.
+func SimulateGarbageCollection(ctx context.Context, c client.Client, kinds ...schema.GroupVersionKind) ([]client.Object, error) {
+	if len(kinds) == 0 {
+		kinds = DefaultGarbageCollectedKinds
+	}
+
+	var deleted []client.Object
+	for {
+		changed := false
+		for _, gvk := range kinds {
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+			if err := c.List(ctx, list); err != nil {
+				if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) || apierrors.IsNotFound(err) {
+					continue
+				}
+				return deleted, fmt.Errorf("listing %s: %w", gvk.Kind, err)
+			}
+
+			for i := range list.Items {
+				obj := &list.Items[i]
+				refs := obj.GetOwnerReferences()
+				if len(refs) == 0 || obj.GetDeletionTimestamp() != nil {
+					continue
+				}
+
+				var remaining []metav1.OwnerReference
+				for _, ref := range refs {
+					exists, err := ownerExists(ctx, c, obj.GetNamespace(), ref)
+					if err != nil {
+						return deleted, err
+					}
+					if exists {
+						remaining = append(remaining, ref)
+					}
+				}
+
+				switch {
+				case len(remaining) == len(refs):
+					continue
+				case len(remaining) == 0:
+					if err := c.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+						return deleted, fmt.Errorf("deleting %s %s: %w", gvk.Kind, client.ObjectKeyFromObject(obj), err)
+					}
+					deleted = append(deleted, obj)
+				default:
+					patch := client.MergeFrom(obj.DeepCopy())
+					obj.SetOwnerReferences(remaining)
+					if err := c.Patch(ctx, obj, patch); err != nil && !apierrors.IsNotFound(err) {
+						return deleted, fmt.Errorf("removing dangling owner references from %s %s: %w", gvk.Kind, client.ObjectKeyFromObject(obj), err)
+					}
+				}
+				changed = true
+			}
+		}
+		if !changed {
+			return deleted, nil
+		}
+	}
+}
+
+// ownerExists reports whether the object referenced by ref exists with the
+// referenced UID. Owners are looked up in namespace unless their kind is
+// cluster-scoped. If the client cannot map the kind (fake clients without a
+// REST mapper), both the namespaced and the cluster-scoped key are tried;
+// owners of a kind the server does not serve count as gone.
+func ownerExists(ctx context.Context, c client.Client, namespace string, ref metav1.OwnerReference) (bool, error) {
+	owner := &unstructured.Unstructured{}
+	owner.SetAPIVersion(ref.APIVersion)
+	owner.SetKind(ref.Kind)
+
+	keys := []client.ObjectKey{{Name: ref.Name}}
+	namespaced, err := c.IsObjectNamespaced(owner)
+	switch {
+	case err != nil && !meta.IsNoMatchError(err) && !runtime.IsNotRegisteredError(err):
+		return false, fmt.Errorf("resolving scope of owner kind %s: %w", ref.Kind, err)
+	case err != nil:
+		keys = []client.ObjectKey{{Namespace: namespace, Name: ref.Name}, {Name: ref.Name}}
+	case namespaced:
+		keys = []client.ObjectKey{{Namespace: namespace, Name: ref.Name}}
+	}
+
+	for _, key := range keys {
+		err := c.Get(ctx, key, owner)
+		switch {
+		case err == nil:
+			return owner.GetUID() == ref.UID, nil
+		case apierrors.IsNotFound(err), meta.IsNoMatchError(err), runtime.IsNotRegisteredError(err):
+			continue
+		default:
+			return false, fmt.Errorf("getting owner %s %s: %w", ref.Kind, key, err)
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,115 @@
+package simulators
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func ownerRef(kind, apiVersion, name string, uid types.UID) metav1.OwnerReference {
+	return metav1.OwnerReference{APIVersion: apiVersion, Kind: kind, Name: name, UID: uid}
+}
+
+func configMap(name string, refs ...metav1.OwnerReference) *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:            name,
+		Namespace:       "default",
+		OwnerReferences: refs,
+	}}
+}
+
+func TestSimulateGarbageCollection(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "live", Namespace: "default", UID: "live-uid"}}
+	liveRef := ownerRef("Deployment", "apps/v1", "live", "live-uid")
+	goneRef := ownerRef("Deployment", "apps/v1", "gone", "gone-uid")
+
+	tests := []struct {
+		name        string
+		objects     []client.Object
+		wantDeleted []string
+		wantRefs    map[string][]metav1.OwnerReference
+	}{
+		{
+			name:     "dependent of a live owner is kept",
+			objects:  []client.Object{deployment, configMap("child", liveRef)},
+			wantRefs: map[string][]metav1.OwnerReference{"child": {liveRef}},
+		},
+		{
+			name:        "dependent of a deleted owner is removed",
+			objects:     []client.Object{configMap("orphan", goneRef)},
+			wantDeleted: []string{"orphan"},
+		},
+		{
+			name:        "owner recreated with a different UID counts as gone",
+			objects:     []client.Object{deployment, configMap("stale", ownerRef("Deployment", "apps/v1", "live", "old-uid"))},
+			wantDeleted: []string{"stale"},
+		},
+		{
+			name:     "dangling reference is removed when another owner remains",
+			objects:  []client.Object{deployment, configMap("shared", liveRef, goneRef)},
+			wantRefs: map[string][]metav1.OwnerReference{"shared": {liveRef}},
+		},
+		{
+			name: "deletion cascades through dependents",
+			objects: []client.Object{
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+					Name: "middle", Namespace: "default", UID: "middle-uid",
+					OwnerReferences: []metav1.OwnerReference{goneRef},
+				}},
+				configMap("leaf", ownerRef("Secret", "v1", "middle", "middle-uid")),
+			},
+			wantDeleted: []string{"middle", "leaf"},
+		},
+		{
+			name:        "owner of an unknown kind counts as gone",
+			objects:     []client.Object{configMap("unknown", ownerRef("Widget", "example.com/v1", "w", "w-uid"))},
+			wantDeleted: []string{"unknown"},
+		},
+		{
+			name:     "object without owners is kept",
+			objects:  []client.Object{configMap("standalone")},
+			wantRefs: map[string][]metav1.OwnerReference{"standalone": nil},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			ctx := context.Background()
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+
+			deleted, err := SimulateGarbageCollection(ctx, c)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			names := make([]string, 0, len(deleted))
+			for _, obj := range deleted {
+				names = append(names, obj.GetName())
+			}
+			g.Expect(names).To(ConsistOf(tc.wantDeleted))
+
+			for name, refs := range tc.wantRefs {
+				cm := &corev1.ConfigMap{}
+				g.Expect(c.Get(ctx, client.ObjectKey{Name: name, Namespace: "default"}, cm)).To(Succeed())
+				g.Expect(cm.OwnerReferences).To(Equal(refs))
+			}
+			for _, name := range tc.wantDeleted {
+				cm := &corev1.ConfigMap{}
+				err := c.Get(ctx, client.ObjectKey{Name: name, Namespace: "default"}, cm)
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}
+		})
+	}
+}
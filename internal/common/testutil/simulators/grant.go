@@ -0,0 +1,66 @@
+package simulators
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// grantGVK identifies the mariadb-operator Grant kind.
+var grantGVK = schema.GroupVersionKind{
+	Group:   "k8s.mariadb.com",
+	Version: "v1alpha1",
+	Kind:    "Grant",
+}
+
+// SimulateGrantReady patches the status sub-resource of an existing Grant
+// custom resource so that ready=true and a "Ready" condition with status
+// "True" is present.
+//
+// Like the mariadb-operator, it first checks that the MariaDB referenced by
+// spec.mariaDbRef is ready and that a ready User for spec.username exists in
+// the Grant's namespace. A User matches when its spec.name, or its metadata
+// name if spec.name is unset, equals spec.username. If either dependency is
+// not ready the Grant is marked NotReady and an error wrapping
+// ErrDependencyNotReady is returned.
+func SimulateGrantReady(ctx context.Context, c client.Client, name, namespace string) error {
+	return simulateMariaDBChildReady(ctx, c, grantGVK, name, namespace, "DependencyNotReady", "Grant is ready",
+		func(grant *unstructured.Unstructured) error {
+			return requireGrantUserReady(ctx, c, grant)
+		})
+}
+
+// requireGrantUserReady checks that the User referenced by spec.username of
+// grant exists and is ready.
+func requireGrantUserReady(ctx context.Context, c client.Client, grant *unstructured.Unstructured) error {
+	username, _, _ := unstructured.NestedString(grant.Object, "spec", "username")
+	if username == "" {
+		return fmt.Errorf("spec.username of Grant %s/%s is empty", grant.GetNamespace(), grant.GetName())
+	}
+
+	users := &unstructured.UnstructuredList{}
+	users.SetGroupVersionKind(userGVK.GroupVersion().WithKind(userGVK.Kind + "List"))
+	if err := c.List(ctx, users, client.InNamespace(grant.GetNamespace())); err != nil {
+		return fmt.Errorf("listing Users in namespace %s: %w", grant.GetNamespace(), err)
+	}
+	for i := range users.Items {
+		user := &users.Items[i]
+		sqlName, _, _ := unstructured.NestedString(user.Object, "spec", "name")
+		if sqlName == "" {
+			sqlName = user.GetName()
+		}
+		if sqlName != username {
+			continue
+		}
+		if !isUnstructuredReady(user) {
+			return fmt.Errorf("%w: User %s/%s for Grant %s is not ready",
+				ErrDependencyNotReady, user.GetNamespace(), user.GetName(), grant.GetName())
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: no User with username %q for Grant %s/%s",
+		ErrDependencyNotReady, username, grant.GetNamespace(), grant.GetName())
+}
@@ -2,6 +2,7 @@ package simulators
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -35,6 +36,20 @@ func createOrGet(ctx context.Context, c client.Client, obj client.Object, kind s
 // shared implementation for simulators of operators that follow the standard
 // ready+condition pattern (e.g. MariaDB, Memcached).
 func simulateUnstructuredReady(ctx context.Context, c client.Client, gvk schema.GroupVersionKind, name, namespace, condReason, condMessage string) error {
+	return simulateUnstructuredStatus(ctx, c, gvk, name, namespace, true, condReason, condMessage)
+}
+
+// simulateUnstructuredNotReady is the failure counterpart of
+// simulateUnstructuredReady: it sets ready=false and a "Ready" condition with
+// status "False" and the given reason. Called on a resource that is already
+// Ready, it flips the resource back to NotReady.
+func simulateUnstructuredNotReady(ctx context.Context, c client.Client, gvk schema.GroupVersionKind, name, namespace, condReason, condMessage string) error {
+	return simulateUnstructuredStatus(ctx, c, gvk, name, namespace, false, condReason, condMessage)
+}
+
+// simulateUnstructuredStatus creates the resource if needed and patches
+// status.ready and the "Ready" condition to match ready.
+func simulateUnstructuredStatus(ctx context.Context, c client.Client, gvk schema.GroupVersionKind, name, namespace string, ready bool, condReason, condMessage string) error {
 	obj := &unstructured.Unstructured{}
 	obj.SetGroupVersionKind(gvk)
 	obj.SetName(name)
@@ -46,26 +61,92 @@ func simulateUnstructuredReady(ctx context.Context, c client.Client, gvk schema.
 
 	patch := client.MergeFrom(obj.DeepCopy())
 
-	if err := unstructured.SetNestedField(obj.Object, true, "status", "ready"); err != nil {
+	if err := unstructured.SetNestedField(obj.Object, ready, "status", "ready"); err != nil {
 		return fmt.Errorf("setting %s status.ready: %w", gvk.Kind, err)
 	}
 
+	if err := setReadyCondition(obj, ready, condReason, condMessage); err != nil {
+		return fmt.Errorf("setting %s status.conditions: %w", gvk.Kind, err)
+	}
+
+	if err := c.Status().Patch(ctx, obj, patch); err != nil {
+		return fmt.Errorf("patching %s status: %w", gvk.Kind, err)
+	}
+
+	return nil
+}
+
+// setReadyCondition replaces status.conditions of obj with a single "Ready"
+// condition whose status reflects ready. The condition records the
+// generation of obj it was computed for.
+func setReadyCondition(obj *unstructured.Unstructured, ready bool, reason, message string) error {
+	status := "False"
+	if ready {
+		status = "True"
+	}
 	conditions := []interface{}{
 		map[string]interface{}{
 			"type":               "Ready",
-			"status":             "True",
-			"reason":             condReason,
-			"message":            condMessage,
+			"status":             status,
+			"reason":             reason,
+			"message":            message,
 			"lastTransitionTime": time.Now().UTC().Format(time.RFC3339),
+			"observedGeneration": obj.GetGeneration(),
 		},
 	}
-	if err := unstructured.SetNestedSlice(obj.Object, conditions, "status", "conditions"); err != nil {
-		return fmt.Errorf("setting %s status.conditions: %w", gvk.Kind, err)
+	return unstructured.SetNestedSlice(obj.Object, conditions, "status", "conditions")
+}
+
+// ErrDependencyNotReady is returned (wrapped) by simulators that validate
+// dependencies, such as SimulateDatabaseReady, when a referenced resource is
+// missing or not ready. Tests can detect it with errors.Is.
+var ErrDependencyNotReady = errors.New("dependency not ready")
+
+// isUnstructuredReady reports whether obj has status.ready=true or a "Ready"
+// condition with status "True".
+func isUnstructuredReady(obj *unstructured.Unstructured) bool {
+	if ready, found, _ := unstructured.NestedBool(obj.Object, "status", "ready"); found && ready {
+		return true
+	}
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condMap, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condMap["type"] == "Ready" && condMap["status"] == "True" {
+			return true
+		}
 	}
+	return false
+}
 
-	if err := c.Status().Patch(ctx, obj, patch); err != nil {
-		return fmt.Errorf("patching %s status: %w", gvk.Kind, err)
+// simulateMariaDBChildReady implements the simulators for mariadb-operator
+// resources that live inside a MariaDB server (Database, User, Grant). The
+// resource must already exist. Before reporting Ready it checks that the
+// referenced MariaDB is ready and runs the optional extra check; if a
+// dependency is not ready it marks the resource NotReady with reason
+// notReadyReason and returns an error wrapping ErrDependencyNotReady.
+func simulateMariaDBChildReady(ctx context.Context, c client.Client, gvk schema.GroupVersionKind, name, namespace, notReadyReason, readyMessage string, extra func(*unstructured.Unstructured) error) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := c.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, obj); err != nil {
+		return fmt.Errorf("getting %s %s/%s: %w", gvk.Kind, namespace, name, err)
 	}
 
-	return nil
+	depErr := requireMariaDBReady(ctx, c, obj)
+	if depErr == nil && extra != nil {
+		depErr = extra(obj)
+	}
+	if depErr != nil {
+		if !errors.Is(depErr, ErrDependencyNotReady) {
+			return depErr
+		}
+		if err := simulateUnstructuredNotReady(ctx, c, gvk, name, namespace, notReadyReason, depErr.Error()); err != nil {
+			return err
+		}
+		return depErr
+	}
+
+	return simulateUnstructuredReady(ctx, c, gvk, name, namespace, "Created", readyMessage)
 }
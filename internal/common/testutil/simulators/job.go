@@ -19,23 +19,7 @@ import (
 // environments where no Pods are actually scheduled and therefore Jobs never
 // transition to a completed state on their own.
 func SimulateJobComplete(ctx context.Context, c client.Client, name, namespace string) error {
-	job := &batchv1.Job{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
-		},
-		Spec: batchv1.JobSpec{
-			Template: corev1.PodTemplateSpec{
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
-						{Name: "job", Image: "busybox"},
-					},
-					RestartPolicy: corev1.RestartPolicyNever,
-				},
-			},
-		},
-	}
-
+	job := newSimulatedJob(name, namespace)
 	if err := createOrGet(ctx, c, job, "Job"); err != nil {
 		return err
 	}
@@ -69,3 +53,76 @@ func SimulateJobComplete(ctx context.Context, c client.Client, name, namespace s
 
 	return nil
 }
+
+// SimulateJobFailed creates a Kubernetes Job (if it does not already exist) and
+// patches its status sub-resource to reflect the terminal state the job
+// controller reports once spec.backoffLimit is exceeded: status.failed is set
+// to backoffLimit+1 and "FailureTarget" and "Failed" conditions with reason
+// "BackoffLimitExceeded" are present.
+//
+// This lets controller tests exercise their handling of failed db_sync or
+// bootstrap Jobs without scheduling any Pods.
+func SimulateJobFailed(ctx context.Context, c client.Client, name, namespace string) error {
+	job := newSimulatedJob(name, namespace)
+	if err := createOrGet(ctx, c, job, "Job"); err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(job.DeepCopy())
+
+	// The API server defaults spec.backoffLimit to 6; fall back to that value
+	// for objects that were not read back from the API server.
+	backoffLimit := int32(6)
+	if job.Spec.BackoffLimit != nil {
+		backoffLimit = *job.Spec.BackoffLimit
+	}
+
+	now := metav1.NewTime(time.Now().UTC())
+	job.Status.Failed = backoffLimit + 1
+	job.Status.Active = 0
+	if job.Status.StartTime == nil {
+		job.Status.StartTime = &now
+	}
+	job.Status.Conditions = []batchv1.JobCondition{
+		{
+			Type:               batchv1.JobFailureTarget,
+			Status:             corev1.ConditionTrue,
+			Reason:             batchv1.JobReasonBackoffLimitExceeded,
+			Message:            "Job has reached the specified backoff limit",
+			LastTransitionTime: now,
+		},
+		{
+			Type:               batchv1.JobFailed,
+			Status:             corev1.ConditionTrue,
+			Reason:             batchv1.JobReasonBackoffLimitExceeded,
+			Message:            "Job has reached the specified backoff limit",
+			LastTransitionTime: now,
+		},
+	}
+
+	if err := c.Status().Patch(ctx, job, patch); err != nil {
+		return fmt.Errorf("patching Job status: %w", err)
+	}
+
+	return nil
+}
+
+// newSimulatedJob returns a minimal Job that passes API server validation.
+func newSimulatedJob(name, namespace string) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "job", Image: "busybox"},
+					},
+					RestartPolicy: corev1.RestartPolicyNever,
+				},
+			},
+		},
+	}
+}
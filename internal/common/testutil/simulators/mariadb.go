@@ -2,20 +2,65 @@ package simulators
 
 import (
 	"context"
+	"fmt"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// mariadbGVK identifies the mariadb-operator MariaDB kind.
+var mariadbGVK = schema.GroupVersionKind{
+	Group:   "k8s.mariadb.com",
+	Version: "v1alpha1",
+	Kind:    "MariaDB",
+}
+
 // SimulateMariaDBReady creates a MariaDB custom resource (if it does not already
 // exist) and patches its status sub-resource so that ready=true and a "Ready"
 // condition with status "True" is present.  This simulates the behaviour of the
 // mariadb-operator controller in envtest environments where the operator is not
 // running.
 func SimulateMariaDBReady(ctx context.Context, c client.Client, name, namespace string) error {
-	return simulateUnstructuredReady(ctx, c, schema.GroupVersionKind{
-		Group:   "k8s.mariadb.com",
-		Version: "v1alpha1",
-		Kind:    "MariaDB",
-	}, name, namespace, "Ready", "MariaDB is ready")
+	return simulateUnstructuredReady(ctx, c, mariadbGVK, name, namespace, "Ready", "MariaDB is ready")
+}
+
+// SimulateMariaDBNotReady creates a MariaDB custom resource (if it does not
+// already exist) and patches its status sub-resource so that ready=false and a
+// "Ready" condition with status "False" and the given reason and message is
+// present. Called on a MariaDB that is already Ready, it flips it back to
+// NotReady, e.g. to simulate a Galera node loss in the middle of a test.
+func SimulateMariaDBNotReady(ctx context.Context, c client.Client, name, namespace, reason, message string) error {
+	return simulateUnstructuredNotReady(ctx, c, mariadbGVK, name, namespace, reason, message)
+}
+
+// requireMariaDBReady checks that the MariaDB referenced by spec.mariaDbRef of
+// obj exists and is ready. spec.mariaDbRef.namespace defaults to the
+// namespace of obj, as in the mariadb-operator. The returned error wraps
+// ErrDependencyNotReady when the MariaDB is missing or not ready.
+func requireMariaDBReady(ctx context.Context, c client.Client, obj *unstructured.Unstructured) error {
+	refName, _, _ := unstructured.NestedString(obj.Object, "spec", "mariaDbRef", "name")
+	if refName == "" {
+		return fmt.Errorf("%s %s/%s has no spec.mariaDbRef.name", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+	}
+	refNamespace, _, _ := unstructured.NestedString(obj.Object, "spec", "mariaDbRef", "namespace")
+	if refNamespace == "" {
+		refNamespace = obj.GetNamespace()
+	}
+
+	mariadb := &unstructured.Unstructured{}
+	mariadb.SetGroupVersionKind(mariadbGVK)
+	if err := c.Get(ctx, client.ObjectKey{Name: refName, Namespace: refNamespace}, mariadb); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("%w: MariaDB %s/%s referenced by %s %s not found",
+				ErrDependencyNotReady, refNamespace, refName, obj.GetKind(), obj.GetName())
+		}
+		return fmt.Errorf("getting MariaDB %s/%s: %w", refNamespace, refName, err)
+	}
+	if !isUnstructuredReady(mariadb) {
+		return fmt.Errorf("%w: MariaDB %s/%s referenced by %s %s is not ready",
+			ErrDependencyNotReady, refNamespace, refName, obj.GetKind(), obj.GetName())
+	}
+	return nil
 }
@@ -0,0 +1,115 @@
+package simulators
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rabbitmqClusterGVK identifies the rabbitmq cluster-operator RabbitmqCluster
+// kind.
+var rabbitmqClusterGVK = schema.GroupVersionKind{
+	Group:   "rabbitmq.com",
+	Version: "v1beta1",
+	Kind:    "RabbitmqCluster",
+}
+
+// rabbitmqConditionTypes are the conditions the rabbitmq cluster-operator
+// reports on a RabbitmqCluster.
+var rabbitmqConditionTypes = []string{"AllReplicasReady", "ClusterAvailable", "ReconcileSuccess"}
+
+// SimulateRabbitmqClusterReady creates a RabbitmqCluster custom resource (if it
+// does not already exist) and puts it into the state the rabbitmq
+// cluster-operator reports for a healthy cluster: the AllReplicasReady,
+// ClusterAvailable and ReconcileSuccess conditions are "True", and
+// status.defaultUser.secretReference points to a "<name>-default-user" Secret
+// holding the username and password, which is created as well.
+func SimulateRabbitmqClusterReady(ctx context.Context, c client.Client, name, namespace string) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-default-user",
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"username": []byte("default_user"),
+			"password": []byte("default_password"),
+		},
+	}
+	if err := createOrGet(ctx, c, secret, "Secret"); err != nil {
+		return err
+	}
+
+	defaultUser := map[string]interface{}{
+		"secretReference": map[string]interface{}{
+			"name":      secret.Name,
+			"namespace": namespace,
+			"keys": map[string]interface{}{
+				"username": "username",
+				"password": "password",
+			},
+		},
+	}
+	return patchRabbitmqClusterStatus(ctx, c, name, namespace, true, "Ready", "RabbitmqCluster is ready", defaultUser)
+}
+
+// SimulateRabbitmqClusterNotReady creates a RabbitmqCluster custom resource (if
+// it does not already exist) and sets its AllReplicasReady, ClusterAvailable
+// and ReconcileSuccess conditions to "False" with the given reason and
+// message. Called on a RabbitmqCluster that is already ready, it flips it back
+// to NotReady.
+func SimulateRabbitmqClusterNotReady(ctx context.Context, c client.Client, name, namespace, reason, message string) error {
+	return patchRabbitmqClusterStatus(ctx, c, name, namespace, false, reason, message, nil)
+}
+
+// patchRabbitmqClusterStatus creates the RabbitmqCluster if needed and sets all
+// of its conditions to ready. A non-nil defaultUser replaces
+// status.defaultUser.
+func patchRabbitmqClusterStatus(ctx context.Context, c client.Client, name, namespace string, ready bool, reason, message string, defaultUser map[string]interface{}) error {
+	// RabbitmqCluster has neither status.ready nor a "Ready" condition, so
+	// simulateUnstructuredStatus cannot be used.
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(rabbitmqClusterGVK)
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+
+	if err := createOrGet(ctx, c, obj, rabbitmqClusterGVK.Kind); err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(obj.DeepCopy())
+
+	status := "False"
+	if ready {
+		status = "True"
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	conditions := make([]interface{}, 0, len(rabbitmqConditionTypes))
+	for _, condType := range rabbitmqConditionTypes {
+		conditions = append(conditions, map[string]interface{}{
+			"type":               condType,
+			"status":             status,
+			"reason":             reason,
+			"message":            message,
+			"lastTransitionTime": now,
+		})
+	}
+	if err := unstructured.SetNestedSlice(obj.Object, conditions, "status", "conditions"); err != nil {
+		return fmt.Errorf("setting RabbitmqCluster status.conditions: %w", err)
+	}
+	if defaultUser != nil {
+		if err := unstructured.SetNestedMap(obj.Object, defaultUser, "status", "defaultUser"); err != nil {
+			return fmt.Errorf("setting RabbitmqCluster status.defaultUser: %w", err)
+		}
+	}
+
+	if err := c.Status().Patch(ctx, obj, patch); err != nil {
+		return fmt.Errorf("patching RabbitmqCluster status: %w", err)
+	}
+	return nil
+}
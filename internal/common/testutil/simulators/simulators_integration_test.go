@@ -4,12 +4,18 @@ package simulators_test
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -37,6 +43,41 @@ var (
 		Version: "v1beta1",
 		Kind:    "ExternalSecret",
 	}
+	databaseGVK = schema.GroupVersionKind{
+		Group:   "k8s.mariadb.com",
+		Version: "v1alpha1",
+		Kind:    "Database",
+	}
+	userGVK = schema.GroupVersionKind{
+		Group:   "k8s.mariadb.com",
+		Version: "v1alpha1",
+		Kind:    "User",
+	}
+	grantGVK = schema.GroupVersionKind{
+		Group:   "k8s.mariadb.com",
+		Version: "v1alpha1",
+		Kind:    "Grant",
+	}
+	backupGVK = schema.GroupVersionKind{
+		Group:   "k8s.mariadb.com",
+		Version: "v1alpha1",
+		Kind:    "Backup",
+	}
+	restoreGVK = schema.GroupVersionKind{
+		Group:   "k8s.mariadb.com",
+		Version: "v1alpha1",
+		Kind:    "Restore",
+	}
+	rabbitmqClusterGVK = schema.GroupVersionKind{
+		Group:   "rabbitmq.com",
+		Version: "v1beta1",
+		Kind:    "RabbitmqCluster",
+	}
+	certificateGVK = schema.GroupVersionKind{
+		Group:   "cert-manager.io",
+		Version: "v1",
+		Kind:    "Certificate",
+	}
 )
 
 func TestMain(m *testing.M) {
@@ -191,10 +232,584 @@ func TestSimulateJobComplete_Idempotent(t *testing.T) {
 	assertJobComplete(t, ctx, k8sClient, name, namespace)
 }
 
+func TestSimulateMariaDBNotReady(t *testing.T) {
+	ctx := context.Background()
+	name := "test-mariadb-notready"
+	namespace := "test-simulators"
+
+	if err := simulators.SimulateMariaDBNotReady(ctx, k8sClient, name, namespace, "GaleraNotReady", "Galera cluster has no primary component"); err != nil {
+		t.Fatalf("SimulateMariaDBNotReady returned error: %v", err)
+	}
+
+	assertUnstructuredNotReady(t, ctx, k8sClient, mariadbGVK, name, namespace, "GaleraNotReady")
+}
+
+func TestSimulateMariaDBReady_FlipsToNotReady(t *testing.T) {
+	ctx := context.Background()
+	name := "test-mariadb-flip"
+	namespace := "test-simulators"
+
+	if err := simulators.SimulateMariaDBReady(ctx, k8sClient, name, namespace); err != nil {
+		t.Fatalf("SimulateMariaDBReady returned error: %v", err)
+	}
+	assertUnstructuredReady(t, ctx, k8sClient, mariadbGVK, name, namespace)
+
+	if err := simulators.SimulateMariaDBNotReady(ctx, k8sClient, name, namespace, "NodeLost", "a node left the cluster"); err != nil {
+		t.Fatalf("SimulateMariaDBNotReady returned error: %v", err)
+	}
+	assertUnstructuredNotReady(t, ctx, k8sClient, mariadbGVK, name, namespace, "NodeLost")
+
+	// And back to Ready again.
+	if err := simulators.SimulateMariaDBReady(ctx, k8sClient, name, namespace); err != nil {
+		t.Fatalf("SimulateMariaDBReady returned error: %v", err)
+	}
+	assertUnstructuredReady(t, ctx, k8sClient, mariadbGVK, name, namespace)
+}
+
+func TestSimulateMemcachedNotReady(t *testing.T) {
+	ctx := context.Background()
+	name := "test-memcached-notready"
+	namespace := "test-simulators"
+
+	if err := simulators.SimulateMemcachedReady(ctx, k8sClient, name, namespace); err != nil {
+		t.Fatalf("SimulateMemcachedReady returned error: %v", err)
+	}
+	if err := simulators.SimulateMemcachedNotReady(ctx, k8sClient, name, namespace, "PodsUnavailable", "0/3 pods ready"); err != nil {
+		t.Fatalf("SimulateMemcachedNotReady returned error: %v", err)
+	}
+
+	assertUnstructuredNotReady(t, ctx, k8sClient, memcachedGVK, name, namespace, "PodsUnavailable")
+}
+
+func TestSimulateExternalSecretSyncError(t *testing.T) {
+	ctx := context.Background()
+	name := "test-externalsecret-error"
+	namespace := "test-simulators"
+
+	targetData := map[string][]byte{"password": []byte("s3cret")}
+	if err := simulators.SimulateExternalSecretSync(ctx, k8sClient, name, namespace, targetData); err != nil {
+		t.Fatalf("SimulateExternalSecretSync returned error: %v", err)
+	}
+
+	if err := simulators.SimulateExternalSecretSyncError(ctx, k8sClient, name, namespace, "could not get secret data from provider"); err != nil {
+		t.Fatalf("SimulateExternalSecretSyncError returned error: %v", err)
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(externalSecretGVK)
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, obj); err != nil {
+		t.Fatalf("failed to get ExternalSecret %s/%s: %v", namespace, name, err)
+	}
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	assertConditionReason(t, conditions, "Ready", "False", "SecretSyncedError")
+
+	// The previously synced Secret is left in place.
+	assertSecretData(t, ctx, k8sClient, name, namespace, targetData)
+}
+
+func TestSimulateJobFailed(t *testing.T) {
+	ctx := context.Background()
+	name := "test-job-failed"
+	namespace := "test-simulators"
+
+	if err := simulators.SimulateJobFailed(ctx, k8sClient, name, namespace); err != nil {
+		t.Fatalf("SimulateJobFailed returned error: %v", err)
+	}
+
+	job := &batchv1.Job{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, job); err != nil {
+		t.Fatalf("failed to get Job %s/%s: %v", namespace, name, err)
+	}
+	if job.Spec.BackoffLimit == nil || job.Status.Failed != *job.Spec.BackoffLimit+1 {
+		t.Fatalf("expected Job status.failed=backoffLimit+1, got %d", job.Status.Failed)
+	}
+
+	foundFailed := false
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue && cond.Reason == batchv1.JobReasonBackoffLimitExceeded {
+			foundFailed = true
+			break
+		}
+	}
+	if !foundFailed {
+		t.Fatalf("expected Failed=True condition with reason BackoffLimitExceeded on Job %s/%s, got %v", namespace, name, job.Status.Conditions)
+	}
+}
+
+func TestSimulateDatabaseReady_RequiresReadyMariaDB(t *testing.T) {
+	ctx := context.Background()
+	namespace := "test-simulators"
+	mariadbName := "test-db-mariadb"
+
+	createMariaDBChild(t, ctx, databaseGVK, "test-database", namespace, map[string]interface{}{
+		"mariaDbRef": map[string]interface{}{"name": mariadbName},
+		"name":       "keystone",
+	})
+
+	// The MariaDB does not exist yet, so the Database must not become ready.
+	err := simulators.SimulateDatabaseReady(ctx, k8sClient, "test-database", namespace)
+	if !errors.Is(err, simulators.ErrDependencyNotReady) {
+		t.Fatalf("expected ErrDependencyNotReady, got %v", err)
+	}
+	assertUnstructuredNotReady(t, ctx, k8sClient, databaseGVK, "test-database", namespace, "MariaDBNotReady")
+
+	// A MariaDB that exists but is not ready is rejected as well.
+	if err := simulators.SimulateMariaDBNotReady(ctx, k8sClient, mariadbName, namespace, "Provisioning", "starting"); err != nil {
+		t.Fatalf("SimulateMariaDBNotReady returned error: %v", err)
+	}
+	err = simulators.SimulateDatabaseReady(ctx, k8sClient, "test-database", namespace)
+	if !errors.Is(err, simulators.ErrDependencyNotReady) {
+		t.Fatalf("expected ErrDependencyNotReady, got %v", err)
+	}
+
+	if err := simulators.SimulateMariaDBReady(ctx, k8sClient, mariadbName, namespace); err != nil {
+		t.Fatalf("SimulateMariaDBReady returned error: %v", err)
+	}
+	if err := simulators.SimulateDatabaseReady(ctx, k8sClient, "test-database", namespace); err != nil {
+		t.Fatalf("SimulateDatabaseReady returned error: %v", err)
+	}
+	assertUnstructuredReady(t, ctx, k8sClient, databaseGVK, "test-database", namespace)
+}
+
+func TestSimulateDatabaseReady_MissingDatabase(t *testing.T) {
+	ctx := context.Background()
+
+	if err := simulators.SimulateDatabaseReady(ctx, k8sClient, "test-database-missing", "test-simulators"); err == nil {
+		t.Fatal("expected an error for a Database that does not exist")
+	}
+}
+
+func TestSimulateBackupAndRestore(t *testing.T) {
+	ctx := context.Background()
+	namespace := "test-simulators"
+	mariadbName := "test-backup-mariadb"
+
+	createMariaDBChild(t, ctx, backupGVK, "test-backup", namespace, map[string]interface{}{
+		"mariaDbRef": map[string]interface{}{"name": mariadbName},
+		"databases":  []interface{}{"keystone"},
+	})
+	createMariaDBChild(t, ctx, restoreGVK, "test-restore", namespace, map[string]interface{}{
+		"mariaDbRef": map[string]interface{}{"name": mariadbName},
+		"backupRef":  map[string]interface{}{"name": "test-backup"},
+	})
+
+	err := simulators.SimulateBackupComplete(ctx, k8sClient, "test-backup", namespace)
+	if !errors.Is(err, simulators.ErrDependencyNotReady) {
+		t.Fatalf("expected ErrDependencyNotReady for a missing MariaDB, got %v", err)
+	}
+	if err := simulators.SimulateMariaDBReady(ctx, k8sClient, mariadbName, namespace); err != nil {
+		t.Fatalf("SimulateMariaDBReady returned error: %v", err)
+	}
+
+	// The Restore must wait for its Backup.
+	err = simulators.SimulateRestoreComplete(ctx, k8sClient, "test-restore", namespace)
+	if !errors.Is(err, simulators.ErrDependencyNotReady) {
+		t.Fatalf("expected ErrDependencyNotReady for an incomplete Backup, got %v", err)
+	}
+
+	if err := simulators.SimulateBackupFailed(ctx, k8sClient, "test-backup", namespace); err != nil {
+		t.Fatalf("SimulateBackupFailed returned error: %v", err)
+	}
+	assertConditionReason(t, getUnstructuredConditions(t, ctx, backupGVK, "test-backup", namespace), "Complete", "False", "JobFailed")
+
+	if err := simulators.SimulateBackupComplete(ctx, k8sClient, "test-backup", namespace); err != nil {
+		t.Fatalf("SimulateBackupComplete returned error: %v", err)
+	}
+	assertConditionReason(t, getUnstructuredConditions(t, ctx, backupGVK, "test-backup", namespace), "Complete", "True", "JobComplete")
+
+	if err := simulators.SimulateRestoreComplete(ctx, k8sClient, "test-restore", namespace); err != nil {
+		t.Fatalf("SimulateRestoreComplete returned error: %v", err)
+	}
+	assertCondition(t, getUnstructuredConditions(t, ctx, restoreGVK, "test-restore", namespace), "Complete", "True")
+}
+
+func TestSimulateUserReady(t *testing.T) {
+	ctx := context.Background()
+	namespace := "test-simulators"
+	mariadbName := "test-user-mariadb"
+
+	createMariaDBChild(t, ctx, userGVK, "test-user", namespace, map[string]interface{}{
+		"mariaDbRef": map[string]interface{}{"name": mariadbName},
+	})
+
+	err := simulators.SimulateUserReady(ctx, k8sClient, "test-user", namespace)
+	if !errors.Is(err, simulators.ErrDependencyNotReady) {
+		t.Fatalf("expected ErrDependencyNotReady, got %v", err)
+	}
+
+	if err := simulators.SimulateMariaDBReady(ctx, k8sClient, mariadbName, namespace); err != nil {
+		t.Fatalf("SimulateMariaDBReady returned error: %v", err)
+	}
+	if err := simulators.SimulateUserReady(ctx, k8sClient, "test-user", namespace); err != nil {
+		t.Fatalf("SimulateUserReady returned error: %v", err)
+	}
+	assertUnstructuredReady(t, ctx, k8sClient, userGVK, "test-user", namespace)
+}
+
+func TestSimulateGrantReady_RequiresReadyUser(t *testing.T) {
+	ctx := context.Background()
+	namespace := "test-simulators"
+	mariadbName := "test-grant-mariadb"
+
+	if err := simulators.SimulateMariaDBReady(ctx, k8sClient, mariadbName, namespace); err != nil {
+		t.Fatalf("SimulateMariaDBReady returned error: %v", err)
+	}
+	createMariaDBChild(t, ctx, grantGVK, "test-grant", namespace, map[string]interface{}{
+		"mariaDbRef": map[string]interface{}{"name": mariadbName},
+		"database":   "keystone",
+		"table":      "*",
+		"username":   "keystone-grant-user",
+		"privileges": []interface{}{"ALL PRIVILEGES"},
+	})
+
+	// No User for the username exists yet.
+	err := simulators.SimulateGrantReady(ctx, k8sClient, "test-grant", namespace)
+	if !errors.Is(err, simulators.ErrDependencyNotReady) {
+		t.Fatalf("expected ErrDependencyNotReady, got %v", err)
+	}
+
+	// The User exists (its SQL name set via spec.name) but is not ready.
+	createMariaDBChild(t, ctx, userGVK, "test-grant-user", namespace, map[string]interface{}{
+		"mariaDbRef": map[string]interface{}{"name": mariadbName},
+		"name":       "keystone-grant-user",
+	})
+	err = simulators.SimulateGrantReady(ctx, k8sClient, "test-grant", namespace)
+	if !errors.Is(err, simulators.ErrDependencyNotReady) {
+		t.Fatalf("expected ErrDependencyNotReady, got %v", err)
+	}
+	assertUnstructuredNotReady(t, ctx, k8sClient, grantGVK, "test-grant", namespace, "DependencyNotReady")
+
+	if err := simulators.SimulateUserReady(ctx, k8sClient, "test-grant-user", namespace); err != nil {
+		t.Fatalf("SimulateUserReady returned error: %v", err)
+	}
+	if err := simulators.SimulateGrantReady(ctx, k8sClient, "test-grant", namespace); err != nil {
+		t.Fatalf("SimulateGrantReady returned error: %v", err)
+	}
+	assertUnstructuredReady(t, ctx, k8sClient, grantGVK, "test-grant", namespace)
+}
+
+func TestSimulateCertificateIssued(t *testing.T) {
+	ctx := context.Background()
+	namespace := "test-simulators"
+	issuerName := "test-cluster-issuer"
+
+	ca, err := simulators.NewTestCA("forge test CA")
+	if err != nil {
+		t.Fatalf("NewTestCA returned error: %v", err)
+	}
+
+	createCertificate(t, ctx, "test-cert", namespace, map[string]interface{}{
+		"secretName": "test-cert-tls",
+		"issuerRef":  map[string]interface{}{"name": issuerName, "kind": "ClusterIssuer", "group": "cert-manager.io"},
+		"dnsNames":   []interface{}{"keystone.test-simulators.svc", "keystone.example.com"},
+		"duration":   "24h",
+	})
+
+	// The ClusterIssuer does not exist yet.
+	err = simulators.SimulateCertificateIssued(ctx, k8sClient, "test-cert", namespace, ca)
+	if !errors.Is(err, simulators.ErrDependencyNotReady) {
+		t.Fatalf("expected ErrDependencyNotReady, got %v", err)
+	}
+	assertUnstructuredNotReady(t, ctx, k8sClient, certificateGVK, "test-cert", namespace, "IssuerNotReady")
+
+	if err := simulators.SimulateClusterIssuerReady(ctx, k8sClient, issuerName); err != nil {
+		t.Fatalf("SimulateClusterIssuerReady returned error: %v", err)
+	}
+	if err := simulators.SimulateCertificateIssued(ctx, k8sClient, "test-cert", namespace, ca); err != nil {
+		t.Fatalf("SimulateCertificateIssued returned error: %v", err)
+	}
+	assertUnstructuredReady(t, ctx, k8sClient, certificateGVK, "test-cert", namespace)
+
+	secret := &corev1.Secret{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: "test-cert-tls", Namespace: namespace}, secret); err != nil {
+		t.Fatalf("failed to get Secret: %v", err)
+	}
+	if secret.Type != corev1.SecretTypeTLS {
+		t.Errorf("expected Secret type %s, got %s", corev1.SecretTypeTLS, secret.Type)
+	}
+	if _, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]); err != nil {
+		t.Fatalf("tls.crt and tls.key do not form a key pair: %v", err)
+	}
+	if string(secret.Data["ca.crt"]) != string(ca.CertificatePEM) {
+		t.Error("expected ca.crt to contain the test CA certificate")
+	}
+
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	if block == nil {
+		t.Fatal("tls.crt does not contain a PEM block")
+	}
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse tls.crt: %v", err)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: "keystone.example.com", Roots: ca.CertPool()}); err != nil {
+		t.Fatalf("issued certificate does not verify against the test CA: %v", err)
+	}
+	if got := leaf.NotAfter.Sub(leaf.NotBefore); got != 24*time.Hour {
+		t.Errorf("expected validity of 24h, got %s", got)
+	}
+
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(certificateGVK)
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: "test-cert", Namespace: namespace}, cert); err != nil {
+		t.Fatalf("failed to get Certificate: %v", err)
+	}
+	notAfter, _, _ := unstructured.NestedString(cert.Object, "status", "notAfter")
+	if notAfter != leaf.NotAfter.UTC().Format(time.RFC3339) {
+		t.Errorf("expected status.notAfter %s, got %q", leaf.NotAfter.UTC().Format(time.RFC3339), notAfter)
+	}
+	renewalTime, _, _ := unstructured.NestedString(cert.Object, "status", "renewalTime")
+	if want := leaf.NotAfter.Add(-8 * time.Hour).UTC().Format(time.RFC3339); renewalTime != want {
+		t.Errorf("expected status.renewalTime %s, got %q", want, renewalTime)
+	}
+
+	// Issuing again renews the certificate and bumps the revision.
+	if err := simulators.SimulateCertificateIssued(ctx, k8sClient, "test-cert", namespace, ca); err != nil {
+		t.Fatalf("SimulateCertificateIssued (renewal) returned error: %v", err)
+	}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: "test-cert", Namespace: namespace}, cert); err != nil {
+		t.Fatalf("failed to get Certificate: %v", err)
+	}
+	if revision, _, _ := unstructured.NestedInt64(cert.Object, "status", "revision"); revision != 2 {
+		t.Errorf("expected status.revision 2 after renewal, got %d", revision)
+	}
+}
+
+func TestSimulateCertificateIssued_ReplacesOpaqueSecret(t *testing.T) {
+	ctx := context.Background()
+	namespace := "test-simulators"
+	issuerName := "test-opaque-issuer"
+
+	ca, err := simulators.NewTestCA("forge test CA")
+	if err != nil {
+		t.Fatalf("NewTestCA returned error: %v", err)
+	}
+
+	opaque := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cert-opaque-tls",
+			Namespace: namespace,
+			Labels:    map[string]string{"app": "keystone"},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{"stale": []byte("data")},
+	}
+	if err := k8sClient.Create(ctx, opaque); err != nil {
+		t.Fatalf("failed to create Secret: %v", err)
+	}
+
+	createCertificate(t, ctx, "test-cert-opaque", namespace, map[string]interface{}{
+		"secretName": "test-cert-opaque-tls",
+		"issuerRef":  map[string]interface{}{"name": issuerName, "kind": "ClusterIssuer", "group": "cert-manager.io"},
+		"dnsNames":   []interface{}{"keystone.example.com"},
+	})
+	if err := simulators.SimulateClusterIssuerReady(ctx, k8sClient, issuerName); err != nil {
+		t.Fatalf("SimulateClusterIssuerReady returned error: %v", err)
+	}
+	if err := simulators.SimulateCertificateIssued(ctx, k8sClient, "test-cert-opaque", namespace, ca); err != nil {
+		t.Fatalf("SimulateCertificateIssued returned error: %v", err)
+	}
+
+	secret := &corev1.Secret{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: "test-cert-opaque-tls", Namespace: namespace}, secret); err != nil {
+		t.Fatalf("failed to get Secret: %v", err)
+	}
+	if secret.Type != corev1.SecretTypeTLS {
+		t.Errorf("expected Secret type %s, got %s", corev1.SecretTypeTLS, secret.Type)
+	}
+	if secret.UID == opaque.UID {
+		t.Error("expected the Opaque Secret to be replaced")
+	}
+	if secret.Labels["app"] != "keystone" {
+		t.Errorf("expected the labels of the Opaque Secret to be kept, got %v", secret.Labels)
+	}
+	if _, ok := secret.Data["stale"]; ok {
+		t.Error("expected the data of the Opaque Secret to be dropped")
+	}
+	if _, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]); err != nil {
+		t.Fatalf("tls.crt and tls.key do not form a key pair: %v", err)
+	}
+}
+
+func TestSimulateCertificateIssued_MissingSecretName(t *testing.T) {
+	ctx := context.Background()
+	namespace := "test-simulators"
+
+	ca, err := simulators.NewTestCA("forge test CA")
+	if err != nil {
+		t.Fatalf("NewTestCA returned error: %v", err)
+	}
+	createCertificate(t, ctx, "test-cert-no-secret", namespace, map[string]interface{}{
+		"dnsNames": []interface{}{"keystone.example.com"},
+	})
+
+	if err := simulators.SimulateCertificateIssued(ctx, k8sClient, "test-cert-no-secret", namespace, ca); err == nil {
+		t.Fatal("expected an error for a Certificate without spec.secretName")
+	}
+}
+
+func TestSimulateExternalSecretSync_TargetName(t *testing.T) {
+	ctx := context.Background()
+	name := "test-externalsecret-target"
+	namespace := "test-simulators"
+
+	es := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"target": map[string]interface{}{"name": "keystone-db-credentials"},
+		},
+	}}
+	es.SetGroupVersionKind(externalSecretGVK)
+	es.SetName(name)
+	es.SetNamespace(namespace)
+	if err := k8sClient.Create(ctx, es); err != nil {
+		t.Fatalf("failed to create ExternalSecret: %v", err)
+	}
+
+	targetData := map[string][]byte{"password": []byte("s3cret")}
+	if err := simulators.SimulateExternalSecretSync(ctx, k8sClient, name, namespace, targetData); err != nil {
+		t.Fatalf("SimulateExternalSecretSync returned error: %v", err)
+	}
+	assertSecretData(t, ctx, k8sClient, "keystone-db-credentials", namespace, targetData)
+}
+
+func TestSimulateRabbitmqClusterReady(t *testing.T) {
+	ctx := context.Background()
+	name := "test-rabbitmq"
+	namespace := "test-simulators"
+
+	if err := simulators.SimulateRabbitmqClusterNotReady(ctx, k8sClient, name, namespace, "Provisioning", "waiting for replicas"); err != nil {
+		t.Fatalf("SimulateRabbitmqClusterNotReady returned error: %v", err)
+	}
+	conditions := getUnstructuredConditions(t, ctx, rabbitmqClusterGVK, name, namespace)
+	assertConditionReason(t, conditions, "ClusterAvailable", "False", "Provisioning")
+
+	if err := simulators.SimulateRabbitmqClusterReady(ctx, k8sClient, name, namespace); err != nil {
+		t.Fatalf("SimulateRabbitmqClusterReady returned error: %v", err)
+	}
+	conditions = getUnstructuredConditions(t, ctx, rabbitmqClusterGVK, name, namespace)
+	for _, condType := range []string{"AllReplicasReady", "ClusterAvailable", "ReconcileSuccess"} {
+		assertCondition(t, conditions, condType, "True")
+	}
+	assertSecretData(t, ctx, k8sClient, name+"-default-user", namespace, map[string][]byte{
+		"username": []byte("default_user"),
+		"password": []byte("default_password"),
+	})
+}
+
+func TestSimulateCertificateNotReady(t *testing.T) {
+	ctx := context.Background()
+	namespace := "test-simulators"
+
+	createCertificate(t, ctx, "test-cert-failed", namespace, map[string]interface{}{
+		"secretName": "test-cert-failed-tls",
+		"dnsNames":   []interface{}{"keystone.example.com"},
+	})
+	if err := simulators.SimulateCertificateNotReady(ctx, k8sClient, "test-cert-failed", namespace, "Failed", "issuer rejected the request"); err != nil {
+		t.Fatalf("SimulateCertificateNotReady returned error: %v", err)
+	}
+	assertUnstructuredNotReady(t, ctx, k8sClient, certificateGVK, "test-cert-failed", namespace, "Failed")
+}
+
 // ---------------------------------------------------------------------------
 // Test helpers
 // ---------------------------------------------------------------------------
 
+// createMariaDBChild creates a mariadb-operator resource (Database, User or
+// Grant) with the given spec, as the controller under test would.
+func TestSimulateGarbageCollection(t *testing.T) {
+	ctx := context.Background()
+	name := "test-gc-owner"
+	namespace := "test-simulators"
+
+	if err := simulators.SimulateMariaDBReady(ctx, k8sClient, name, namespace); err != nil {
+		t.Fatalf("SimulateMariaDBReady returned error: %v", err)
+	}
+	owner := &unstructured.Unstructured{}
+	owner.SetGroupVersionKind(mariadbGVK)
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, owner); err != nil {
+		t.Fatalf("failed to get MariaDB: %v", err)
+	}
+
+	controller := true
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-child",
+			Namespace: namespace,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: mariadbGVK.GroupVersion().String(),
+				Kind:       mariadbGVK.Kind,
+				Name:       name,
+				UID:        owner.GetUID(),
+				Controller: &controller,
+			}},
+		},
+	}
+	if err := k8sClient.Create(ctx, secret); err != nil {
+		t.Fatalf("failed to create dependent Secret: %v", err)
+	}
+
+	if _, err := simulators.SimulateGarbageCollection(ctx, k8sClient); err != nil {
+		t.Fatalf("SimulateGarbageCollection returned error: %v", err)
+	}
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(secret), &corev1.Secret{}); err != nil {
+		t.Fatalf("expected Secret to survive while its owner exists: %v", err)
+	}
+
+	if err := k8sClient.Delete(ctx, owner); err != nil {
+		t.Fatalf("failed to delete MariaDB: %v", err)
+	}
+	if _, err := simulators.SimulateGarbageCollection(ctx, k8sClient); err != nil {
+		t.Fatalf("SimulateGarbageCollection returned error: %v", err)
+	}
+	err := k8sClient.Get(ctx, client.ObjectKeyFromObject(secret), &corev1.Secret{})
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("expected Secret to be garbage collected, got: %v", err)
+	}
+}
+
+func createMariaDBChild(t *testing.T, ctx context.Context, gvk schema.GroupVersionKind, name, namespace string, spec map[string]interface{}) {
+	t.Helper()
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	if err := k8sClient.Create(ctx, obj); err != nil {
+		t.Fatalf("failed to create %s %s/%s: %v", gvk.Kind, namespace, name, err)
+	}
+}
+
+// createCertificate creates a cert-manager Certificate with the given spec, as
+// the controller under test would.
+func createCertificate(t *testing.T, ctx context.Context, name, namespace string, spec map[string]interface{}) {
+	t.Helper()
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetGroupVersionKind(certificateGVK)
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	if err := k8sClient.Create(ctx, obj); err != nil {
+		t.Fatalf("failed to create Certificate %s/%s: %v", namespace, name, err)
+	}
+}
+
+// getUnstructuredConditions fetches an unstructured CR by GVK and returns its
+// status.conditions.
+func getUnstructuredConditions(t *testing.T, ctx context.Context, gvk schema.GroupVersionKind, name, namespace string) []interface{} {
+	t.Helper()
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, obj); err != nil {
+		t.Fatalf("failed to get %s %s/%s: %v", gvk.Kind, namespace, name, err)
+	}
+	conditions, _, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil {
+		t.Fatalf("failed to read status.conditions of %s %s/%s: %v", gvk.Kind, namespace, name, err)
+	}
+	return conditions
+}
+
 // assertUnstructuredReady fetches an unstructured CR by GVK and verifies that
 // status.ready is true and a Ready=True condition is present.
 func assertUnstructuredReady(t *testing.T, ctx context.Context, c client.Client, gvk schema.GroupVersionKind, name, namespace string) {
@@ -231,6 +846,37 @@ func assertUnstructuredReady(t *testing.T, ctx context.Context, c client.Client,
 	assertCondition(t, conditions, "Ready", "True")
 }
 
+// assertUnstructuredNotReady fetches an unstructured CR by GVK and verifies that
+// status.ready is false and a Ready=False condition with the given reason is
+// present.
+func assertUnstructuredNotReady(t *testing.T, ctx context.Context, c client.Client, gvk schema.GroupVersionKind, name, namespace, reason string) {
+	t.Helper()
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := c.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, obj); err != nil {
+		t.Fatalf("failed to get %s %s/%s: %v", gvk.Kind, namespace, name, err)
+	}
+
+	ready, found, err := unstructured.NestedBool(obj.Object, "status", "ready")
+	if err != nil {
+		t.Fatalf("error reading %s status.ready: %v", gvk.Kind, err)
+	}
+	if !found {
+		t.Fatalf("%s status.ready field not found", gvk.Kind)
+	}
+	if ready {
+		t.Fatalf("expected %s status.ready to be false, got true", gvk.Kind)
+	}
+
+	conditions, _, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil {
+		t.Fatalf("error reading %s status.conditions: %v", gvk.Kind, err)
+	}
+
+	assertConditionReason(t, conditions, "Ready", "False", reason)
+}
+
 // assertExternalSecretConditions fetches an ExternalSecret CR and verifies that
 // a Ready=True condition is present in status.conditions.
 func assertExternalSecretConditions(t *testing.T, ctx context.Context, c client.Client, name, namespace string) {
@@ -317,3 +963,19 @@ func assertCondition(t *testing.T, conditions []interface{}, condType, condStatu
 	}
 	t.Fatalf("expected condition type=%q status=%q not found in conditions: %v", condType, condStatus, conditions)
 }
+
+// assertConditionReason checks that the given conditions slice contains a
+// condition with the specified type, status and reason values.
+func assertConditionReason(t *testing.T, conditions []interface{}, condType, condStatus, reason string) {
+	t.Helper()
+	for _, c := range conditions {
+		condMap, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condMap["type"] == condType && condMap["status"] == condStatus && condMap["reason"] == reason {
+			return
+		}
+	}
+	t.Fatalf("expected condition type=%q status=%q reason=%q not found in conditions: %v", condType, condStatus, reason, conditions)
+}
@@ -0,0 +1,165 @@
+package simulators
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+// TestCA is an in-memory certificate authority used by SimulateCertificateIssued
+// to sign real X.509 certificates in tests.
+type TestCA struct {
+	// Certificate is the self-signed CA certificate.
+	Certificate *x509.Certificate
+	// CertificatePEM is Certificate in PEM encoding, as written to ca.crt.
+	CertificatePEM []byte
+
+	key crypto.Signer
+}
+
+// NewTestCA generates a self-signed ECDSA P-256 CA with the given common name,
+// valid for ten years.
+func NewTestCA(commonName string) (*TestCA, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating CA key: %w", err)
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, fmt.Errorf("creating CA certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("parsing CA certificate: %w", err)
+	}
+	return &TestCA{
+		Certificate:    cert,
+		CertificatePEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:            key,
+	}, nil
+}
+
+// CertPool returns a pool containing only the CA certificate, suitable for
+// verifying certificates issued by the CA.
+func (ca *TestCA) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Certificate)
+	return pool
+}
+
+// leafRequest describes a certificate to be issued by the test CA.
+type leafRequest struct {
+	commonName   string
+	dnsNames     []string
+	ipAddresses  []net.IP
+	duration     time.Duration
+	keyAlgorithm string
+	keySize      int
+}
+
+// issue generates a key pair and a leaf certificate signed by the CA. It
+// returns the PEM encoded certificate and private key and the parsed
+// certificate.
+func (ca *TestCA) issue(req leafRequest) ([]byte, []byte, *x509.Certificate, error) {
+	key, keyPEM, err := generateKey(req.keyAlgorithm, req.keySize)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: req.commonName},
+		DNSNames:     req.dnsNames,
+		IPAddresses:  req.ipAddresses,
+		NotBefore:    now,
+		NotAfter:     now.Add(req.duration),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Certificate, key.Public(), ca.key)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("signing certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("parsing issued certificate: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), keyPEM, cert, nil
+}
+
+// generateKey creates a private key using the cert-manager algorithm names
+// ("RSA", the default, or "ECDSA") and returns it together with its PEM
+// encoding in the format cert-manager writes by default (PKCS#1 for RSA,
+// SEC 1 for ECDSA).
+func generateKey(algorithm string, size int) (crypto.Signer, []byte, error) {
+	switch algorithm {
+	case "", "RSA":
+		if size == 0 {
+			size = 2048
+		}
+		key, err := rsa.GenerateKey(rand.Reader, size)
+		if err != nil {
+			return nil, nil, fmt.Errorf("generating RSA key: %w", err)
+		}
+		return key, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), nil
+	case "ECDSA":
+		var curve elliptic.Curve
+		switch size {
+		case 0, 256:
+			curve = elliptic.P256()
+		case 384:
+			curve = elliptic.P384()
+		case 521:
+			curve = elliptic.P521()
+		default:
+			return nil, nil, fmt.Errorf("unsupported ECDSA key size %d", size)
+		}
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("generating ECDSA key: %w", err)
+		}
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("encoding ECDSA key: %w", err)
+		}
+		return key, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported private key algorithm %q", algorithm)
+	}
+}
+
+// randomSerial returns a random 128-bit certificate serial number.
+func randomSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("generating serial number: %w", err)
+	}
+	return serial, nil
+}
@@ -0,0 +1,66 @@
+package simulators
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestTestCAIssue(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		size      int
+		wantRSA   bool
+		wantErr   bool
+	}{
+		{name: "default is RSA", wantRSA: true},
+		{name: "ECDSA P-384", algorithm: "ECDSA", size: 384},
+		{name: "unsupported algorithm", algorithm: "DSA", wantErr: true},
+		{name: "unsupported ECDSA size", algorithm: "ECDSA", size: 128, wantErr: true},
+	}
+
+	ca, err := NewTestCA("forge test CA")
+	if err != nil {
+		t.Fatalf("NewTestCA returned error: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			certPEM, keyPEM, cert, err := ca.issue(leafRequest{
+				commonName:   "keystone",
+				dnsNames:     []string{"keystone.openstack.svc"},
+				ipAddresses:  []net.IP{net.ParseIP("10.0.0.1")},
+				duration:     time.Hour,
+				keyAlgorithm: tt.algorithm,
+				keySize:      tt.size,
+			})
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			pair, err := tls.X509KeyPair(certPEM, keyPEM)
+			g.Expect(err).NotTo(HaveOccurred())
+			if tt.wantRSA {
+				g.Expect(pair.PrivateKey).To(BeAssignableToTypeOf(&rsa.PrivateKey{}))
+			} else {
+				g.Expect(pair.PrivateKey).To(BeAssignableToTypeOf(&ecdsa.PrivateKey{}))
+			}
+
+			g.Expect(cert.NotAfter.Sub(cert.NotBefore)).To(Equal(time.Hour))
+			g.Expect(cert.IPAddresses).To(HaveLen(1))
+			_, err = cert.Verify(x509.VerifyOptions{DNSName: "keystone.openstack.svc", Roots: ca.CertPool()})
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
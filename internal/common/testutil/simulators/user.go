@@ -0,0 +1,26 @@
+package simulators
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// userGVK identifies the mariadb-operator User kind.
+var userGVK = schema.GroupVersionKind{
+	Group:   "k8s.mariadb.com",
+	Version: "v1alpha1",
+	Kind:    "User",
+}
+
+// SimulateUserReady patches the status sub-resource of an existing User
+// custom resource so that ready=true and a "Ready" condition with status
+// "True" is present.
+//
+// Like the mariadb-operator, it first checks that the MariaDB referenced by
+// spec.mariaDbRef exists and is ready. If it is not, the User is marked
+// NotReady and an error wrapping ErrDependencyNotReady is returned.
+func SimulateUserReady(ctx context.Context, c client.Client, name, namespace string) error {
+	return simulateMariaDBChildReady(ctx, c, userGVK, name, namespace, "MariaDBNotReady", "User is ready", nil)
+}
@@ -0,0 +1,20 @@
+package v1alpha1
+
+// Condition types of a ControlPlane CR.
+const (
+	// ConditionReady is true when every other condition is true.
+	ConditionReady = "Ready"
+	// ConditionInfrastructureReady is true when the database, messaging
+	// and cache clusters are ready.
+	ConditionInfrastructureReady = "InfrastructureReady"
+	// ConditionKeystoneReady is true when the Keystone CR is ready.
+	ConditionKeystoneReady = "KeystoneReady"
+)
+
+// ConditionDependencies maps each condition type to the condition types it
+// waits for. A condition that is not true because one of its dependencies is
+// not true is blocked by that dependency.
+var ConditionDependencies = map[string][]string{
+	ConditionReady:         {ConditionKeystoneReady},
+	ConditionKeystoneReady: {ConditionInfrastructureReady},
+}
@@ -0,0 +1,171 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/c5c3/forge/internal/common/featuregate"
+	"github.com/c5c3/forge/internal/common/finalizer"
+)
+
+// UpdatePhase is the phase of a release update of a control plane.
+// +kubebuilder:validation:Enum=Idle;Validating;UpdatingInfra;UpdatingKeystone;Verifying;Complete;RollingBack
+type UpdatePhase string
+
+// Phases of a release update. An update starts in Validating and ends in
+// Complete, or in RollingBack if a service failed to update.
+const (
+	UpdatePhaseIdle             UpdatePhase = "Idle"
+	UpdatePhaseValidating       UpdatePhase = "Validating"
+	UpdatePhaseUpdatingInfra    UpdatePhase = "UpdatingInfra"
+	UpdatePhaseUpdatingKeystone UpdatePhase = "UpdatingKeystone"
+	UpdatePhaseVerifying        UpdatePhase = "Verifying"
+	UpdatePhaseComplete         UpdatePhase = "Complete"
+	UpdatePhaseRollingBack      UpdatePhase = "RollingBack"
+)
+
+// ControlPlaneSpec is the desired state of an OpenStack control plane.
+type ControlPlaneSpec struct {
+	// OpenStackRelease is the OpenStack release all services run, e.g.
+	// "2025.2".
+	// +kubebuilder:validation:Pattern=`^\d{4}\.\d$`
+	OpenStackRelease string `json:"openStackRelease"`
+	// Region is the region of the service endpoints. It defaults to
+	// "RegionOne".
+	// +optional
+	Region string `json:"region,omitempty"`
+	// Infrastructure configures the database, messaging and cache clusters
+	// the services share.
+	// +optional
+	Infrastructure InfrastructureSpec `json:"infrastructure,omitempty"`
+	// Services configures the OpenStack services.
+	// +optional
+	Services ServicesSpec `json:"services,omitempty"`
+	// DeletionPolicy is passed on to the service CRs. It defaults to
+	// Retain.
+	// +optional
+	DeletionPolicy finalizer.DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// InfrastructureSpec configures the shared infrastructure clusters.
+type InfrastructureSpec struct {
+	// Database configures the MariaDB Galera cluster.
+	// +optional
+	Database DatabaseSpec `json:"database,omitempty"`
+	// Messaging configures the RabbitMQ cluster.
+	// +optional
+	Messaging ClusterSpec `json:"messaging,omitempty"`
+	// Cache configures the memcached cluster.
+	// +optional
+	Cache ClusterSpec `json:"cache,omitempty"`
+}
+
+// ClusterSpec sizes an infrastructure cluster.
+type ClusterSpec struct {
+	// Replicas is the number of cluster members. It defaults to 3.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+}
+
+// DatabaseSpec sizes the database cluster.
+type DatabaseSpec struct {
+	ClusterSpec `json:",inline"`
+	// StorageSize is the size of each member's volume. It defaults to
+	// "10Gi".
+	// +optional
+	StorageSize *resource.Quantity `json:"storageSize,omitempty"`
+}
+
+// ServicesSpec configures the OpenStack services of the control plane.
+type ServicesSpec struct {
+	// Keystone configures the identity service.
+	// +optional
+	Keystone KeystoneServiceSpec `json:"keystone,omitempty"`
+}
+
+// KeystoneServiceSpec is projected into the Keystone CR of the control
+// plane.
+type KeystoneServiceSpec struct {
+	// Enabled deploys Keystone. It defaults to true.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// Replicas is the number of API pods.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// FernetRotationInterval is how often the fernet keys are rotated, as
+	// a duration in whole days, e.g. "168h".
+	// +optional
+	FernetRotationInterval *metav1.Duration `json:"fernetRotationInterval,omitempty"`
+}
+
+// ServiceStatus is the observed state of a service of the control plane.
+type ServiceStatus struct {
+	// Ready reports whether the service CR is ready.
+	Ready bool `json:"ready"`
+	// OpenStackRelease is the release the service runs.
+	// +optional
+	OpenStackRelease string `json:"openStackRelease,omitempty"`
+}
+
+// ControlPlaneStatus is the observed state of an OpenStack control plane.
+type ControlPlaneStatus struct {
+	// ObservedGeneration is the generation the status was computed for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions are the ConditionTypes of the ControlPlane CR.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// OpenStackRelease is the release every service has been updated to.
+	// +optional
+	OpenStackRelease string `json:"openStackRelease,omitempty"`
+	// UpdatePhase is the phase of the current release update.
+	// +optional
+	UpdatePhase UpdatePhase `json:"updatePhase,omitempty"`
+	// Services maps service names, e.g. "keystone", to their state.
+	// +optional
+	Services map[string]ServiceStatus `json:"services,omitempty"`
+	// FeatureGates is the state of the c5c3 operator's feature gates.
+	// +optional
+	FeatureGates []featuregate.Status `json:"featureGates,omitempty"`
+}
+
+// ControlPlane is an OpenStack control plane and its shared infrastructure.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.updatePhase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type ControlPlane struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ControlPlaneSpec   `json:"spec,omitempty"`
+	Status ControlPlaneStatus `json:"status,omitempty"`
+}
+
+// GetConditions returns the status conditions.
+func (cp *ControlPlane) GetConditions() []metav1.Condition {
+	return cp.Status.Conditions
+}
+
+// SetConditions replaces the status conditions.
+func (cp *ControlPlane) SetConditions(conditions []metav1.Condition) {
+	cp.Status.Conditions = conditions
+}
+
+// ControlPlaneList is a list of control planes.
+// +kubebuilder:object:root=true
+type ControlPlaneList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ControlPlane `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ControlPlane{}, &ControlPlaneList{})
+}
@@ -0,0 +1,57 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/c5c3/forge/internal/common/featuregate"
+)
+
+func TestAddToScheme(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(AddToScheme(scheme)).To(Succeed())
+	g.Expect(scheme.Recognizes(GroupVersion.WithKind("ControlPlane"))).To(BeTrue())
+	g.Expect(scheme.Recognizes(GroupVersion.WithKind("ControlPlaneList"))).To(BeTrue())
+}
+
+func TestControlPlaneDeepCopy(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	replicas := int32(3)
+	enabled := true
+	size := resource.MustParse("10Gi")
+	in := &ControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "production"},
+		Spec: ControlPlaneSpec{
+			OpenStackRelease: "2025.2",
+			Infrastructure: InfrastructureSpec{
+				Database: DatabaseSpec{ClusterSpec: ClusterSpec{Replicas: &replicas}, StorageSize: &size},
+			},
+			Services: ServicesSpec{Keystone: KeystoneServiceSpec{Enabled: &enabled}},
+		},
+		Status: ControlPlaneStatus{
+			Services:     map[string]ServiceStatus{"keystone": {Ready: true}},
+			FeatureGates: []featuregate.Status{{Name: "FernetAutoRotation", Stage: featuregate.Beta, Enabled: true}},
+		},
+	}
+
+	out := in.DeepCopyObject().(*ControlPlane)
+	g.Expect(out).To(Equal(in))
+
+	*out.Spec.Infrastructure.Database.Replicas = 1
+	out.Spec.Infrastructure.Database.StorageSize.Set(1)
+	*out.Spec.Services.Keystone.Enabled = false
+	out.Status.Services["keystone"] = ServiceStatus{}
+	out.Status.FeatureGates[0].Enabled = false
+	g.Expect(*in.Spec.Infrastructure.Database.Replicas).To(Equal(int32(3)))
+	g.Expect(in.Spec.Infrastructure.Database.StorageSize.String()).To(Equal("10Gi"))
+	g.Expect(*in.Spec.Services.Keystone.Enabled).To(BeTrue())
+	g.Expect(in.Status.Services["keystone"].Ready).To(BeTrue())
+	g.Expect(in.Status.FeatureGates[0].Enabled).To(BeTrue())
+}
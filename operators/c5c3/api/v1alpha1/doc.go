@@ -0,0 +1,9 @@
+// Package v1alpha1 contains the ControlPlane API of the c5c3 operator.
+//
+// kubectl-forge imports the package, so it must not depend on controller
+// code. DeepCopy methods and the CRD in config/crd/bases are generated from
+// the markers by make generate manifests.
+//
+// +kubebuilder:object:generate=true
+// +groupName=c5c3.openstack.c5c3.io
+package v1alpha1
@@ -0,0 +1,17 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is the group and version of the ControlPlane API.
+	GroupVersion = schema.GroupVersion{Group: "c5c3.openstack.c5c3.io", Version: "v1alpha1"}
+
+	// SchemeBuilder registers the ControlPlane types with a scheme.
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the ControlPlane types to a scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/c5c3/forge/internal/common/events"
+	"github.com/c5c3/forge/internal/common/featuregate"
+	"github.com/c5c3/forge/internal/common/operatorconfig"
+	"github.com/c5c3/forge/internal/common/pause"
+	c5c3v1alpha1 "github.com/c5c3/forge/operators/c5c3/api/v1alpha1"
+	keystonev1alpha1 "github.com/c5c3/forge/operators/keystone/api/v1alpha1"
+)
+
+// ControlPlaneReconciler reconciles ControlPlane CRs.
+type ControlPlaneReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// Config provides the requeue interval.
+	Config   *operatorconfig.Configuration
+	Recorder *events.Recorder
+	// Gates are the operator's feature gates; their state is reported in
+	// status.featureGates.
+	Gates *featuregate.FeatureGate
+}
+
+// SetupWithManager registers the reconciler with mgr. The Keystone CRs a
+// ControlPlane controls are watched so that its pause state reaches them.
+func (r *ControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&c5c3v1alpha1.ControlPlane{}).
+		Owns(&keystonev1alpha1.Keystone{}).
+		Complete(r)
+}
+
+// Reconcile records the feature gates in the status of a ControlPlane. The
+// pause state of the ControlPlane is propagated to the child CRs it controls
+// before a paused ControlPlane is left alone.
+func (r *ControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	cp := &c5c3v1alpha1.ControlPlane{}
+	if err := r.Get(ctx, req.NamespacedName, cp); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if err := r.propagatePause(ctx, cp); err != nil {
+		r.Recorder.Event(cp, events.ReasonReconcileFailed, "%s", err)
+		return ctrl.Result{}, err
+	}
+	if paused, err := pause.Guard(ctx, r.Client, r.Recorder, cp); paused || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Drift no watch reports is corrected after the requeue interval.
+	result := ctrl.Result{RequeueAfter: r.Config.Reconcile.RequeueInterval.Duration}
+	status := cp.Status.DeepCopy()
+	status.FeatureGates = r.Gates.Status()
+	if equality.Semantic.DeepEqual(status, &cp.Status) {
+		return result, nil
+	}
+	cp.Status = *status
+	if err := r.Status().Update(ctx, cp); err != nil {
+		err = fmt.Errorf("updating status: %w", err)
+		r.Recorder.Event(cp, events.ReasonReconcileFailed, "%s", err)
+		return ctrl.Result{}, err
+	}
+	return result, nil
+}
+
+// propagatePause mirrors the pause state of cp onto the Keystone CRs it
+// controls (see pause.PropagateFromParent).
+func (r *ControlPlaneReconciler) propagatePause(ctx context.Context, cp *c5c3v1alpha1.ControlPlane) error {
+	list := &keystonev1alpha1.KeystoneList{}
+	if err := r.List(ctx, list, client.InNamespace(cp.Namespace)); err != nil {
+		return fmt.Errorf("listing Keystones: %w", err)
+	}
+	for i := range list.Items {
+		k := &list.Items[i]
+		if !metav1.IsControlledBy(k, cp) {
+			continue
+		}
+		patch := client.MergeFrom(k.DeepCopy())
+		changed, err := pause.PropagateFromParent(cp, k, r.Scheme)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			continue
+		}
+		if err := r.Patch(ctx, k, patch); err != nil {
+			return fmt.Errorf("propagating pause to Keystone %s: %w", k.Name, err)
+		}
+	}
+	return nil
+}
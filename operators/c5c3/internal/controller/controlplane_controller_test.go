@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clientevents "k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/c5c3/forge/internal/common/events"
+	"github.com/c5c3/forge/internal/common/featuregate"
+	"github.com/c5c3/forge/internal/common/operatorconfig"
+	"github.com/c5c3/forge/internal/common/pause"
+	"github.com/c5c3/forge/internal/common/testutil/assertions"
+	c5c3v1alpha1 "github.com/c5c3/forge/operators/c5c3/api/v1alpha1"
+	keystonev1alpha1 "github.com/c5c3/forge/operators/keystone/api/v1alpha1"
+)
+
+var controlPlaneKey = client.ObjectKey{Namespace: "openstack", Name: "production"}
+
+func newControlPlane() *c5c3v1alpha1.ControlPlane {
+	return &c5c3v1alpha1.ControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "production", Namespace: "openstack", UID: "controlplane-uid"},
+		Spec:       c5c3v1alpha1.ControlPlaneSpec{OpenStackRelease: "2025.2"},
+	}
+}
+
+// newKeystone returns a Keystone CR, controlled by the ControlPlane of
+// newControlPlane if controlled is set.
+func newKeystone(name string, controlled bool, annotations map[string]string) *keystonev1alpha1.Keystone {
+	k := &keystonev1alpha1.Keystone{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "openstack", Annotations: annotations},
+	}
+	if controlled {
+		k.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(newControlPlane(),
+			c5c3v1alpha1.GroupVersion.WithKind("ControlPlane"))}
+	}
+	return k
+}
+
+// fixture is a ControlPlaneReconciler on a fake client.
+type fixture struct {
+	r    *ControlPlaneReconciler
+	c    client.Client
+	fake *clientevents.FakeRecorder
+}
+
+func newFixture(g *WithT, gates *featuregate.FeatureGate, funcs interceptor.Funcs, objs ...client.Object) *fixture {
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(c5c3v1alpha1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(keystonev1alpha1.AddToScheme(scheme)).To(Succeed())
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&c5c3v1alpha1.ControlPlane{}).
+		WithInterceptorFuncs(funcs).
+		Build()
+	fakeRecorder := clientevents.NewFakeRecorder(10)
+	return &fixture{
+		r: &ControlPlaneReconciler{
+			Client:   c,
+			Scheme:   scheme,
+			Config:   operatorconfig.New(),
+			Recorder: events.NewRecorder(fakeRecorder, time.Minute),
+			Gates:    gates,
+		},
+		c:    c,
+		fake: fakeRecorder,
+	}
+}
+
+// events returns the events recorded since the last call.
+func (f *fixture) events() []string {
+	var recorded []string
+	for len(f.fake.Events) > 0 {
+		recorded = append(recorded, <-f.fake.Events)
+	}
+	return recorded
+}
+
+// reconcile reconciles the ControlPlane and returns it as stored.
+func (f *fixture) reconcile(g *WithT) *c5c3v1alpha1.ControlPlane {
+	ctx := context.Background()
+	_, err := f.r.Reconcile(ctx, ctrl.Request{NamespacedName: controlPlaneKey})
+	g.Expect(err).NotTo(HaveOccurred())
+	cp := &c5c3v1alpha1.ControlPlane{}
+	g.Expect(f.c.Get(ctx, controlPlaneKey, cp)).To(Succeed())
+	return cp
+}
+
+func TestReconcileReportsFeatureGates(t *testing.T) {
+	tests := []struct {
+		name  string
+		gates map[string]bool
+		want  featuregate.Status
+	}{
+		{
+			name: "default",
+			want: featuregate.Status{Name: "ZeroDowntimeUpgrade", Stage: featuregate.Alpha, Enabled: false},
+		},
+		{
+			name:  "overridden",
+			gates: map[string]bool{"ZeroDowntimeUpgrade": true},
+			want:  featuregate.Status{Name: "ZeroDowntimeUpgrade", Stage: featuregate.Alpha, Enabled: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			gates := featuregate.NewDefault()
+			g.Expect(gates.SetFromMap(tt.gates)).To(Succeed())
+			f := newFixture(g, gates, interceptor.Funcs{}, newControlPlane())
+
+			cp := f.reconcile(g)
+			g.Expect(cp.Status.FeatureGates).To(HaveLen(len(gates.Known())))
+			g.Expect(cp.Status.FeatureGates).To(ContainElement(tt.want))
+		})
+	}
+}
+
+func TestReconcilePausePropagates(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	cp := newControlPlane()
+	cp.Annotations = map[string]string{pause.Annotation: "true"}
+	f := newFixture(g, featuregate.NewDefault(), interceptor.Funcs{}, cp,
+		newKeystone("keystone", true, nil),
+		newKeystone("manual", true, map[string]string{pause.Annotation: "true"}),
+		newKeystone("other", false, nil))
+	keystone := func(name string) *keystonev1alpha1.Keystone {
+		k := &keystonev1alpha1.Keystone{}
+		g.Expect(f.c.Get(ctx, client.ObjectKey{Namespace: "openstack", Name: name}, k)).To(Succeed())
+		return k
+	}
+
+	cp = f.reconcile(g)
+	assertions.AssertCondition(g, cp.Status.Conditions, pause.ConditionType, metav1.ConditionTrue)
+	g.Expect(cp.Status.FeatureGates).To(BeEmpty(), "a paused ControlPlane is not reconciled")
+	g.Expect(f.events()).To(ConsistOf("Normal Paused Reconciliation paused by annotation " + pause.Annotation))
+	g.Expect(keystone("keystone").Annotations).To(HaveKeyWithValue(pause.ByAnnotation, "ControlPlane/production"))
+	g.Expect(pause.IsPaused(keystone("keystone"))).To(BeTrue())
+	g.Expect(keystone("manual").Annotations).NotTo(HaveKey(pause.ByAnnotation))
+	g.Expect(pause.IsPaused(keystone("other"))).To(BeFalse())
+
+	delete(cp.Annotations, pause.Annotation)
+	g.Expect(f.c.Update(ctx, cp)).To(Succeed())
+	cp = f.reconcile(g)
+	assertions.AssertCondition(g, cp.Status.Conditions, pause.ConditionType, metav1.ConditionFalse)
+	g.Expect(cp.Status.FeatureGates).NotTo(BeEmpty())
+	g.Expect(pause.IsPaused(keystone("keystone"))).To(BeFalse())
+	g.Expect(pause.IsPaused(keystone("manual"))).To(BeTrue(), "a child paused by hand stays paused")
+}
+
+func TestReconcileRequeues(t *testing.T) {
+	g := NewGomegaWithT(t)
+	f := newFixture(g, featuregate.NewDefault(), interceptor.Funcs{}, newControlPlane())
+	want := ctrl.Result{RequeueAfter: f.r.Config.Reconcile.RequeueInterval.Duration}
+
+	for _, name := range []string{"status changed", "status unchanged"} {
+		result, err := f.r.Reconcile(context.Background(), ctrl.Request{NamespacedName: controlPlaneKey})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result).To(Equal(want), name)
+	}
+}
+
+func TestReconcileMissingControlPlane(t *testing.T) {
+	g := NewGomegaWithT(t)
+	f := newFixture(g, featuregate.NewDefault(), interceptor.Funcs{})
+
+	_, err := f.r.Reconcile(context.Background(), ctrl.Request{NamespacedName: controlPlaneKey})
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
+func TestReconcileRecordsFailure(t *testing.T) {
+	g := NewGomegaWithT(t)
+	f := newFixture(g, featuregate.NewDefault(), interceptor.Funcs{
+		SubResourceUpdate: func(context.Context, client.Client, string, client.Object, ...client.SubResourceUpdateOption) error {
+			return errors.New("conflict")
+		},
+	}, newControlPlane())
+
+	_, err := f.r.Reconcile(context.Background(), ctrl.Request{NamespacedName: controlPlaneKey})
+	g.Expect(err).To(MatchError("updating status: conflict"))
+	g.Expect(f.events()).To(ConsistOf("Warning ReconcileFailed updating status: conflict"))
+}
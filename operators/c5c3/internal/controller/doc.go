@@ -0,0 +1,11 @@
+// Package controller implements the reconciler of ControlPlane CRs.
+//
+// The reconciler records the state of the operator's feature gates in
+// status.featureGates, so that users can see which features a control plane
+// is managed with. Failures are reported as ReconcileFailed events (see
+// package events).
+//
+// Pausing a ControlPlane with the forge.c5c3.io/paused annotation also pauses
+// the Keystone CRs it controls; resuming it only resumes the children it
+// paused itself (see package pause).
+package controller
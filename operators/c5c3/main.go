@@ -1,15 +1,24 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 
-	_ "github.com/c5c3/forge/internal/common" // validates workspace replace directive
+	"github.com/c5c3/forge/internal/common/events"
+	"github.com/c5c3/forge/internal/common/featuregate"
+	"github.com/c5c3/forge/internal/common/operatorconfig"
+	"github.com/c5c3/forge/internal/common/scope"
+	c5c3v1alpha1 "github.com/c5c3/forge/operators/c5c3/api/v1alpha1"
+	"github.com/c5c3/forge/operators/c5c3/internal/controller"
+	keystonev1alpha1 "github.com/c5c3/forge/operators/keystone/api/v1alpha1"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlconfig "sigs.k8s.io/controller-runtime/pkg/config"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
@@ -22,17 +31,16 @@ var (
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(c5c3v1alpha1.AddToScheme(scheme))
+	utilruntime.Must(keystonev1alpha1.AddToScheme(scheme))
 }
 
 func main() {
-	var metricsAddr string
-	var probeAddr string
-	var enableLeaderElection bool
-
-	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
-	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
-	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
-		"Enable leader election for controller manager.")
+	var configOpts operatorconfig.Options
+	configOpts.BindFlags(flag.CommandLine)
+	var renderRBAC bool
+	flag.BoolVar(&renderRBAC, "render-rbac", false,
+		"Print the RBAC manifests for the configured watch namespaces and exit.")
 
 	opts := zap.Options{Development: true}
 	opts.BindFlags(flag.CommandLine)
@@ -40,20 +48,85 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	// Settings are resolved from defaults, the configuration file, FORGE_*
+	// environment variables and explicitly set flags, in that order.
+	cfg, err := configOpts.Load()
+	if err != nil {
+		setupLog.Error(err, "unable to load operator configuration")
+		os.Exit(1)
+	}
+	setupLog.Info("loaded operator configuration", "file", configOpts.ConfigFile(), "config", cfg)
+
+	if renderRBAC {
+		// Only a namespace selector needs the cluster to resolve the scope.
+		var reader client.Reader
+		if cfg.WatchNamespaceSelector != "" {
+			if reader, err = client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme}); err != nil {
+				setupLog.Error(err, "unable to create setup client")
+				os.Exit(1)
+			}
+		}
+		if err := writeRBAC(context.Background(), os.Stdout, reader, cfg); err != nil {
+			setupLog.Error(err, "unable to render RBAC")
+			os.Exit(1)
+		}
+		return
+	}
+
+	gates := featuregate.NewDefault()
+	if err := gates.SetFromMap(cfg.FeatureGates); err != nil {
+		setupLog.Error(err, "invalid feature gates")
+		os.Exit(1)
+	}
+	gates.RecordMetrics()
+	setupLog.Info("feature gates", "gates", gates.Status())
+
+	ctx := ctrl.SetupSignalHandler()
+	restConfig := ctrl.GetConfigOrDie()
+
+	// The watch scope is resolved with a direct client because the manager's
+	// cache depends on its result.
+	setupClient, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create setup client")
+		os.Exit(1)
+	}
+	watchScope, err := scope.Resolve(ctx, setupClient, cfg.WatchNamespaces, cfg.WatchNamespaceSelector)
+	if err != nil {
+		setupLog.Error(err, "unable to resolve watch namespaces")
+		os.Exit(1)
+	}
+	setupLog.Info("resolved watch scope", "mode", watchScope.Mode(), "namespaces", watchScope.Namespaces)
+
 	// Metrics uses metricsserver.Options (struct-based API) instead of the
 	// deprecated MetricsBindAddress string field per controller-runtime v0.23+.
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme:                 scheme,
-		Metrics:                metricsserver.Options{BindAddress: metricsAddr},
-		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
+		Cache:                  watchScope.CacheOptions(),
+		Metrics:                metricsserver.Options{BindAddress: cfg.MetricsBindAddress},
+		HealthProbeBindAddress: cfg.HealthProbeBindAddress,
+		LeaderElection:         cfg.LeaderElection,
 		LeaderElectionID:       "c5c3.openstack.c5c3.io",
+		Controller: ctrlconfig.Controller{
+			MaxConcurrentReconciles: cfg.Reconcile.MaxConcurrentReconciles,
+		},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	recorder := events.NewRecorder(mgr.GetEventRecorder("c5c3-operator"), events.DefaultDedupWindow)
+	if err := (&controller.ControlPlaneReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Config:   cfg,
+		Recorder: recorder,
+		Gates:    gates,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ControlPlane")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -66,7 +139,7 @@ func main() {
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
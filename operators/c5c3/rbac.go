@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"io"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/c5c3/forge/internal/common/operatorconfig"
+	"github.com/c5c3/forge/internal/common/scope"
+)
+
+// rbacName is the name of the operator's Roles or ClusterRoles.
+const rbacName = "forge-c5c3-operator"
+
+// serviceAccount is the identity the operator runs as in the default
+// deployment.
+var serviceAccount = types.NamespacedName{Namespace: "forge-system", Name: "c5c3-operator"}
+
+// rbacRules are the permissions of the c5c3 operator within its watch scope.
+// All resources are namespaced, so the rules work with both a ClusterRole
+// and per-namespace Roles.
+var rbacRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{"c5c3.openstack.c5c3.io"},
+		Resources: []string{"controlplanes"},
+		Verbs:     []string{"get", "list", "watch", "update", "patch"},
+	},
+	{
+		APIGroups: []string{"c5c3.openstack.c5c3.io"},
+		Resources: []string{"controlplanes/status", "controlplanes/finalizers"},
+		Verbs:     []string{"get", "update", "patch"},
+	},
+	{
+		APIGroups: []string{"keystone.openstack.c5c3.io"},
+		Resources: []string{"keystones"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"configmaps", "secrets"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	},
+	{
+		APIGroups: []string{"", "events.k8s.io"},
+		Resources: []string{"events"},
+		Verbs:     []string{"create", "patch"},
+	},
+	{
+		APIGroups: []string{"k8s.mariadb.com"},
+		Resources: []string{"mariadbs"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	},
+	{
+		APIGroups: []string{"opsv1.memcached.com"},
+		Resources: []string{"memcacheds"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	},
+	{
+		APIGroups: []string{"rabbitmq.com"},
+		Resources: []string{"rabbitmqclusters"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	},
+	{
+		APIGroups: []string{"external-secrets.io"},
+		Resources: []string{"externalsecrets", "pushsecrets"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	},
+	{
+		APIGroups: []string{"cert-manager.io"},
+		Resources: []string{"certificates"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	},
+}
+
+// writeRBAC writes the RBAC manifests matching the watch scope configured in
+// cfg to w. A namespace selector is resolved through reader, which may be nil
+// otherwise; the manifests then also grant reading namespaces so that the
+// operator can resolve the selector at startup.
+func writeRBAC(ctx context.Context, w io.Writer, reader client.Reader, cfg *operatorconfig.Configuration) error {
+	s, err := scope.Resolve(ctx, reader, cfg.WatchNamespaces, cfg.WatchNamespaceSelector)
+	if err != nil {
+		return err
+	}
+	objs := s.OperatorRBAC(rbacName, serviceAccount, rbacRules)
+	if cfg.WatchNamespaceSelector != "" {
+		objs = append(objs, scope.NamespaceReaderRBAC(rbacName+"-namespace-reader", serviceAccount)...)
+	}
+	return scope.WriteManifest(w, objs)
+}
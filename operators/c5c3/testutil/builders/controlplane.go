@@ -0,0 +1,157 @@
+package builders
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/c5c3/forge/internal/common/finalizer"
+	commonbuilders "github.com/c5c3/forge/internal/common/testutil/builders"
+	c5c3v1alpha1 "github.com/c5c3/forge/operators/c5c3/api/v1alpha1"
+	keystonebuilders "github.com/c5c3/forge/operators/keystone/testutil/builders"
+)
+
+// ControlPlaneBuilder provides a fluent builder pattern for constructing
+// ControlPlane CRs, primarily intended for use in tests.
+type ControlPlaneBuilder struct {
+	controlPlane c5c3v1alpha1.ControlPlane
+}
+
+// NewControlPlaneBuilder creates a ControlPlaneBuilder for a ControlPlane
+// named "controlplane" in the shared builders' DefaultNamespace that runs
+// the Keystone builders' DefaultOpenStackRelease with the infrastructure and
+// service defaults.
+func NewControlPlaneBuilder() *ControlPlaneBuilder {
+	b := &ControlPlaneBuilder{}
+	b.controlPlane.SetGroupVersionKind(c5c3v1alpha1.GroupVersion.WithKind("ControlPlane"))
+	b.controlPlane.Name = "controlplane"
+	b.controlPlane.Namespace = commonbuilders.DefaultNamespace
+	b.controlPlane.Spec.OpenStackRelease = keystonebuilders.DefaultOpenStackRelease
+	return b
+}
+
+// WithName sets the metadata name of the ControlPlane.
+func (b *ControlPlaneBuilder) WithName(name string) *ControlPlaneBuilder {
+	b.controlPlane.Name = name
+	return b
+}
+
+// WithNamespace sets the metadata namespace of the ControlPlane.
+func (b *ControlPlaneBuilder) WithNamespace(namespace string) *ControlPlaneBuilder {
+	b.controlPlane.Namespace = namespace
+	return b
+}
+
+// WithLabels merges the provided labels into the ControlPlane's existing
+// labels.
+func (b *ControlPlaneBuilder) WithLabels(labels map[string]string) *ControlPlaneBuilder {
+	b.controlPlane.Labels = mergeStrings(b.controlPlane.Labels, labels)
+	return b
+}
+
+// WithAnnotations merges the provided annotations into the ControlPlane's
+// existing annotations.
+func (b *ControlPlaneBuilder) WithAnnotations(annotations map[string]string) *ControlPlaneBuilder {
+	b.controlPlane.Annotations = mergeStrings(b.controlPlane.Annotations, annotations)
+	return b
+}
+
+// WithOpenStackRelease sets spec.openStackRelease.
+func (b *ControlPlaneBuilder) WithOpenStackRelease(release string) *ControlPlaneBuilder {
+	b.controlPlane.Spec.OpenStackRelease = release
+	return b
+}
+
+// WithRegion sets spec.region.
+func (b *ControlPlaneBuilder) WithRegion(region string) *ControlPlaneBuilder {
+	b.controlPlane.Spec.Region = region
+	return b
+}
+
+// WithDatabase sets the number of database members and the size of their
+// volumes.
+func (b *ControlPlaneBuilder) WithDatabase(replicas int32, storageSize string) *ControlPlaneBuilder {
+	size := resource.MustParse(storageSize)
+	b.controlPlane.Spec.Infrastructure.Database = c5c3v1alpha1.DatabaseSpec{
+		ClusterSpec: c5c3v1alpha1.ClusterSpec{Replicas: &replicas},
+		StorageSize: &size,
+	}
+	return b
+}
+
+// WithMessagingReplicas sets spec.infrastructure.messaging.replicas.
+func (b *ControlPlaneBuilder) WithMessagingReplicas(replicas int32) *ControlPlaneBuilder {
+	b.controlPlane.Spec.Infrastructure.Messaging.Replicas = &replicas
+	return b
+}
+
+// WithCacheReplicas sets spec.infrastructure.cache.replicas.
+func (b *ControlPlaneBuilder) WithCacheReplicas(replicas int32) *ControlPlaneBuilder {
+	b.controlPlane.Spec.Infrastructure.Cache.Replicas = &replicas
+	return b
+}
+
+// WithKeystoneEnabled sets spec.services.keystone.enabled.
+func (b *ControlPlaneBuilder) WithKeystoneEnabled(enabled bool) *ControlPlaneBuilder {
+	b.controlPlane.Spec.Services.Keystone.Enabled = &enabled
+	return b
+}
+
+// WithKeystoneReplicas sets spec.services.keystone.replicas.
+func (b *ControlPlaneBuilder) WithKeystoneReplicas(replicas int32) *ControlPlaneBuilder {
+	b.controlPlane.Spec.Services.Keystone.Replicas = &replicas
+	return b
+}
+
+// WithFernetRotationInterval sets spec.services.keystone.fernetRotationInterval.
+func (b *ControlPlaneBuilder) WithFernetRotationInterval(interval metav1.Duration) *ControlPlaneBuilder {
+	b.controlPlane.Spec.Services.Keystone.FernetRotationInterval = &interval
+	return b
+}
+
+// WithDeletionPolicy sets spec.deletionPolicy.
+func (b *ControlPlaneBuilder) WithDeletionPolicy(policy finalizer.DeletionPolicy) *ControlPlaneBuilder {
+	b.controlPlane.Spec.DeletionPolicy = policy
+	return b
+}
+
+// WithConditions replaces status.conditions. Create does not persist the
+// status; tests update the status subresource after creating the
+// ControlPlane.
+func (b *ControlPlaneBuilder) WithConditions(conditions ...metav1.Condition) *ControlPlaneBuilder {
+	b.controlPlane.Status.Conditions = conditions
+	return b
+}
+
+// Build returns a deep copy of the constructed ControlPlane. Calling Build
+// multiple times returns independent objects that can be modified without
+// affecting each other or the builder's internal state.
+func (b *ControlPlaneBuilder) Build() *c5c3v1alpha1.ControlPlane {
+	return b.controlPlane.DeepCopy()
+}
+
+// Create builds the ControlPlane and creates it in the cluster using the
+// provided controller-runtime client, whose scheme must include the
+// ControlPlane API.
+func (b *ControlPlaneBuilder) Create(ctx context.Context, c client.Client) (*c5c3v1alpha1.ControlPlane, error) {
+	controlPlane := b.Build()
+	if err := c.Create(ctx, controlPlane); err != nil {
+		return nil, fmt.Errorf("creating ControlPlane %s/%s: %w", controlPlane.Namespace, controlPlane.Name, err)
+	}
+	return controlPlane, nil
+}
+
+// mergeStrings returns dst with all entries of src added, allocating dst if
+// needed.
+func mergeStrings(dst, src map[string]string) map[string]string {
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
@@ -0,0 +1,89 @@
+package builders
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	commonbuilders "github.com/c5c3/forge/internal/common/testutil/builders"
+	c5c3v1alpha1 "github.com/c5c3/forge/operators/c5c3/api/v1alpha1"
+	keystonebuilders "github.com/c5c3/forge/operators/keystone/testutil/builders"
+)
+
+func TestControlPlaneBuilder_Defaults(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cp := NewControlPlaneBuilder().Build()
+
+	g.Expect(cp.GroupVersionKind()).To(Equal(c5c3v1alpha1.GroupVersion.WithKind("ControlPlane")))
+	g.Expect(cp.Name).To(Equal("controlplane"))
+	g.Expect(cp.Namespace).To(Equal(commonbuilders.DefaultNamespace))
+	g.Expect(cp.Spec.OpenStackRelease).To(Equal(keystonebuilders.DefaultOpenStackRelease))
+}
+
+func TestControlPlaneBuilder_FullChain(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cp := NewControlPlaneBuilder().
+		WithName("production").
+		WithNamespace("openstack").
+		WithOpenStackRelease("2026.1").
+		WithRegion("RegionTwo").
+		WithDatabase(3, "20Gi").
+		WithMessagingReplicas(3).
+		WithCacheReplicas(2).
+		WithKeystoneEnabled(true).
+		WithKeystoneReplicas(2).
+		WithFernetRotationInterval(metav1.Duration{Duration: 168 * time.Hour}).
+		Build()
+
+	g.Expect(cp.Name).To(Equal("production"))
+	g.Expect(cp.Namespace).To(Equal("openstack"))
+	g.Expect(cp.Spec.OpenStackRelease).To(Equal("2026.1"))
+	g.Expect(cp.Spec.Region).To(Equal("RegionTwo"))
+	g.Expect(*cp.Spec.Infrastructure.Database.Replicas).To(Equal(int32(3)))
+	g.Expect(cp.Spec.Infrastructure.Database.StorageSize.Equal(resource.MustParse("20Gi"))).To(BeTrue())
+	g.Expect(*cp.Spec.Infrastructure.Messaging.Replicas).To(Equal(int32(3)))
+	g.Expect(*cp.Spec.Infrastructure.Cache.Replicas).To(Equal(int32(2)))
+	g.Expect(*cp.Spec.Services.Keystone.Enabled).To(BeTrue())
+	g.Expect(*cp.Spec.Services.Keystone.Replicas).To(Equal(int32(2)))
+	g.Expect(cp.Spec.Services.Keystone.FernetRotationInterval.Duration).To(Equal(168 * time.Hour))
+}
+
+func TestControlPlaneBuilder_Build_IndependentCopies(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	builder := NewControlPlaneBuilder().WithCacheReplicas(3).WithDatabase(3, "10Gi")
+	first := builder.Build()
+	*first.Spec.Infrastructure.Cache.Replicas = 1
+	first.Spec.Infrastructure.Database.StorageSize.Set(1)
+
+	second := builder.Build()
+	g.Expect(*second.Spec.Infrastructure.Cache.Replicas).To(Equal(int32(3)))
+	g.Expect(second.Spec.Infrastructure.Database.StorageSize.String()).To(Equal("10Gi"))
+}
+
+func TestControlPlaneBuilder_Create(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(c5c3v1alpha1.AddToScheme(scheme)).To(Succeed())
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	created, err := NewControlPlaneBuilder().WithNamespace("openstack").Create(context.Background(), c)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	fetched := &c5c3v1alpha1.ControlPlane{}
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(created), fetched)).To(Succeed())
+	g.Expect(fetched.Spec.OpenStackRelease).To(Equal(keystonebuilders.DefaultOpenStackRelease))
+
+	_, err = NewControlPlaneBuilder().WithNamespace("openstack").Create(context.Background(), c)
+	g.Expect(err).To(MatchError(ContainSubstring("creating ControlPlane openstack/controlplane")))
+}
@@ -0,0 +1,6 @@
+// Package builders provides a fluent builder for ControlPlane CRs in tests.
+//
+// It complements the builders of the dependency CRs in
+// internal/common/testutil/builders and the Keystone builder in
+// operators/keystone/testutil/builders.
+package builders
@@ -0,0 +1,30 @@
+package v1alpha1
+
+// Condition types of a Keystone CR.
+const (
+	// ConditionReady is true when every other condition is true.
+	ConditionReady = "Ready"
+	// ConditionDatabaseReady is true when the database, its user and grant
+	// exist, the schema is migrated and the admin user is bootstrapped.
+	ConditionDatabaseReady = "DatabaseReady"
+	// ConditionCacheReady is true when the memcached servers are known.
+	ConditionCacheReady = "CacheReady"
+	// ConditionFernetKeysReady is true when the fernet and credential keys
+	// exist and spec.fernet.rotationSchedule is valid.
+	ConditionFernetKeysReady = "FernetKeysReady"
+	// ConditionDeploymentReady is true when all API pods are available.
+	ConditionDeploymentReady = "DeploymentReady"
+	// ConditionAPIReady is true when the API issues tokens and lists the
+	// catalog. It has the same value as keystonehealth.ConditionType, which
+	// sets it.
+	ConditionAPIReady = "KeystoneAPIReady"
+)
+
+// ConditionDependencies maps each condition type to the condition types it
+// waits for. A condition that is not true because one of its dependencies is
+// not true is blocked by that dependency.
+var ConditionDependencies = map[string][]string{
+	ConditionReady:           {ConditionAPIReady},
+	ConditionAPIReady:        {ConditionDeploymentReady},
+	ConditionDeploymentReady: {ConditionDatabaseReady, ConditionCacheReady, ConditionFernetKeysReady},
+}
@@ -0,0 +1,10 @@
+// Package v1alpha1 contains the Keystone API of the keystone operator.
+//
+// The c5c3 operator, which creates Keystone CRs, and kubectl-forge import
+// the package, so it must not depend on controller code. DeepCopy methods
+// and the CRD in config/crd/bases are generated from the markers by make
+// generate manifests.
+//
+// +kubebuilder:object:generate=true
+// +groupName=keystone.openstack.c5c3.io
+package v1alpha1
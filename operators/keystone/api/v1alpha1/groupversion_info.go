@@ -0,0 +1,17 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is the group and version of the Keystone API.
+	GroupVersion = schema.GroupVersion{Group: "keystone.openstack.c5c3.io", Version: "v1alpha1"}
+
+	// SchemeBuilder registers the Keystone types with a scheme.
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the Keystone types to a scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
@@ -0,0 +1,24 @@
+package v1alpha1
+
+import (
+	"fmt"
+
+	"github.com/c5c3/forge/internal/common/operatorconfig"
+)
+
+// ImageComponent is the component name of Keystone in the operator's default
+// images.
+const ImageComponent = "keystone"
+
+// ResolveImage returns spec.image if set, and otherwise the default image the
+// operator configuration cfg has for spec.openStackRelease.
+func (s *KeystoneSpec) ResolveImage(cfg *operatorconfig.Configuration) (string, error) {
+	if s.Image != "" {
+		return s.Image, nil
+	}
+	image, ok := cfg.Image(s.OpenStackRelease, ImageComponent)
+	if !ok {
+		return "", fmt.Errorf("no default %s image configured for release %q; set spec.image", ImageComponent, s.OpenStackRelease)
+	}
+	return image, nil
+}
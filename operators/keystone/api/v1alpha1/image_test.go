@@ -0,0 +1,52 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/c5c3/forge/internal/common/operatorconfig"
+)
+
+func TestResolveImage(t *testing.T) {
+	cfg := operatorconfig.New()
+	cfg.DefaultImages = map[string]map[string]string{
+		"2025.2": {ImageComponent: "registry.example/keystone:2025.2"},
+	}
+
+	tests := []struct {
+		name    string
+		spec    KeystoneSpec
+		want    string
+		wantErr string
+	}{
+		{
+			name: "release default",
+			spec: KeystoneSpec{OpenStackRelease: "2025.2"},
+			want: "registry.example/keystone:2025.2",
+		},
+		{
+			name: "explicit image wins",
+			spec: KeystoneSpec{OpenStackRelease: "2025.2", Image: "registry.example/keystone:custom"},
+			want: "registry.example/keystone:custom",
+		},
+		{
+			name:    "release without default",
+			spec:    KeystoneSpec{OpenStackRelease: "2026.1"},
+			wantErr: `release "2026.1"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			image, err := tt.spec.ResolveImage(cfg)
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(image).To(Equal(tt.want))
+		})
+	}
+}
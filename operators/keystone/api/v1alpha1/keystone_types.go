@@ -0,0 +1,181 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/c5c3/forge/internal/common/confighash"
+	"github.com/c5c3/forge/internal/common/dbbackup"
+	"github.com/c5c3/forge/internal/common/dbupgrade"
+	"github.com/c5c3/forge/internal/common/finalizer"
+)
+
+// Defaults applied to unset KeystoneSpec fields.
+const (
+	DefaultReplicas         int32 = 3
+	DefaultRotationSchedule       = "0 0 * * 0"
+	DefaultMaxActiveKeys    int32 = 3
+	DefaultAdminUser              = "admin"
+	DefaultRegion                 = "RegionOne"
+)
+
+// FernetKeysRotatedAtAnnotation is set on the fernet key Secret to the time
+// of the last key rotation in RFC 3339 format. It survives operator
+// restarts and event expiry, unlike the FernetKeysRotated event.
+const FernetKeysRotatedAtAnnotation = "forge.c5c3.io/fernet-keys-rotated-at"
+
+// KeystoneSpec is the desired state of a Keystone deployment.
+type KeystoneSpec struct {
+	// Replicas is the number of API pods. It defaults to DefaultReplicas.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// OpenStackRelease is the OpenStack release to run, e.g. "2025.2". The
+	// image defaults to the operator's default image for the release.
+	// +kubebuilder:validation:Pattern=`^\d{4}\.\d$`
+	OpenStackRelease string `json:"openStackRelease"`
+	// Image overrides the default image of the release.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Database is the MariaDB database Keystone stores its data in.
+	Database DatabaseSpec `json:"database"`
+	// Cache is the memcached cluster Keystone caches tokens in.
+	// +optional
+	Cache CacheSpec `json:"cache,omitempty"`
+	// Fernet configures the rotation of the fernet keys.
+	// +optional
+	Fernet FernetSpec `json:"fernet,omitempty"`
+	// Bootstrap configures the admin user, project and endpoints created
+	// when Keystone is first deployed.
+	Bootstrap BootstrapSpec `json:"bootstrap"`
+	// Backup configures the database backups taken before migrations.
+	// +optional
+	Backup dbbackup.Policy `json:"backup,omitempty"`
+	// DeletionPolicy decides whether the database and its backups are
+	// deleted together with the Keystone CR. It defaults to Retain.
+	// +optional
+	DeletionPolicy finalizer.DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// DatabaseSpec selects the database of a Keystone deployment. Exactly one of
+// ClusterRef and Host is set.
+// +kubebuilder:validation:XValidation:rule="has(self.clusterRef) != has(self.host)",message="exactly one of clusterRef and host must be set"
+type DatabaseSpec struct {
+	// ClusterRef is the MariaDB CR in the Keystone CR's namespace the
+	// operator creates the Database, User and Grant in.
+	// +optional
+	ClusterRef *corev1.LocalObjectReference `json:"clusterRef,omitempty"`
+	// Host is an external database server the credentials in SecretRef
+	// give access to.
+	// +optional
+	Host string `json:"host,omitempty"`
+	// Name is the name of the database. It defaults to "keystone".
+	// +optional
+	Name string `json:"name,omitempty"`
+	// SecretRef is the Secret with the username and password of the
+	// database user.
+	SecretRef corev1.LocalObjectReference `json:"secretRef"`
+}
+
+// CacheSpec selects the memcached servers of a Keystone deployment.
+type CacheSpec struct {
+	// ClusterRef is the Memcached CR in the Keystone CR's namespace.
+	// +optional
+	ClusterRef *corev1.LocalObjectReference `json:"clusterRef,omitempty"`
+	// Servers are external memcached servers as host:port.
+	// +optional
+	Servers []string `json:"servers,omitempty"`
+}
+
+// FernetSpec configures the rotation of the fernet keys.
+type FernetSpec struct {
+	// RotationSchedule is the cron schedule of the key rotation. It
+	// defaults to DefaultRotationSchedule.
+	// +optional
+	RotationSchedule string `json:"rotationSchedule,omitempty"`
+	// MaxActiveKeys is the number of keys kept after a rotation. It
+	// defaults to DefaultMaxActiveKeys.
+	// +kubebuilder:validation:Minimum=3
+	// +optional
+	MaxActiveKeys int32 `json:"maxActiveKeys,omitempty"`
+}
+
+// BootstrapSpec configures the resources keystone-manage bootstrap creates.
+type BootstrapSpec struct {
+	// AdminUser is the name of the admin user. It defaults to
+	// DefaultAdminUser.
+	// +optional
+	AdminUser string `json:"adminUser,omitempty"`
+	// AdminPasswordSecretRef is the key of the Secret holding the admin
+	// password.
+	AdminPasswordSecretRef corev1.SecretKeySelector `json:"adminPasswordSecretRef"`
+	// Region is the region of the identity endpoints. It defaults to
+	// DefaultRegion.
+	// +optional
+	Region string `json:"region,omitempty"`
+}
+
+// KeystoneStatus is the observed state of a Keystone deployment.
+type KeystoneStatus struct {
+	// ObservedGeneration is the generation the status was computed for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions are the ConditionTypes of the Keystone CR.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Endpoint is the URL of the identity API.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+	// OpenStackRelease is the release the API pods run.
+	// +optional
+	OpenStackRelease string `json:"openStackRelease,omitempty"`
+	// Image is the image the API pods run.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Upgrade is the state of the last database upgrade.
+	// +optional
+	Upgrade dbupgrade.Status `json:"upgrade,omitempty"`
+	// LastRollout records the last configuration change that restarted the
+	// API pods.
+	// +optional
+	LastRollout confighash.LastRollout `json:"lastRollout,omitempty"`
+}
+
+// Keystone is a Keystone identity service deployment.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Endpoint",type=string,JSONPath=`.status.endpoint`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type Keystone struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KeystoneSpec   `json:"spec,omitempty"`
+	Status KeystoneStatus `json:"status,omitempty"`
+}
+
+// GetConditions returns the status conditions.
+func (k *Keystone) GetConditions() []metav1.Condition {
+	return k.Status.Conditions
+}
+
+// SetConditions replaces the status conditions.
+func (k *Keystone) SetConditions(conditions []metav1.Condition) {
+	k.Status.Conditions = conditions
+}
+
+// KeystoneList is a list of Keystone deployments.
+// +kubebuilder:object:root=true
+type KeystoneList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Keystone `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Keystone{}, &KeystoneList{})
+}
@@ -0,0 +1,50 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestAddToScheme(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(AddToScheme(scheme)).To(Succeed())
+	g.Expect(scheme.Recognizes(GroupVersion.WithKind("Keystone"))).To(BeTrue())
+	g.Expect(scheme.Recognizes(GroupVersion.WithKind("KeystoneList"))).To(BeTrue())
+	g.Expect(scheme.Recognizes(GroupVersion.WithKind("KeystoneRestore"))).To(BeTrue())
+	g.Expect(scheme.Recognizes(GroupVersion.WithKind("KeystoneRestoreList"))).To(BeTrue())
+}
+
+func TestKeystoneDeepCopy(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	replicas := int32(3)
+	in := &Keystone{
+		ObjectMeta: metav1.ObjectMeta{Name: "keystone", Labels: map[string]string{"a": "b"}},
+		Spec: KeystoneSpec{
+			Replicas: &replicas,
+			Database: DatabaseSpec{ClusterRef: &corev1.LocalObjectReference{Name: "mariadb"}},
+			Cache:    CacheSpec{Servers: []string{"memcached:11211"}},
+		},
+		Status: KeystoneStatus{Conditions: []metav1.Condition{{Type: ConditionReady, Status: metav1.ConditionTrue}}},
+	}
+
+	out := in.DeepCopyObject().(*Keystone)
+	g.Expect(out).To(Equal(in))
+
+	*out.Spec.Replicas = 1
+	out.Spec.Database.ClusterRef.Name = "other"
+	out.Spec.Cache.Servers[0] = "other"
+	out.Status.Conditions[0].Status = metav1.ConditionFalse
+	out.Labels["a"] = "c"
+	g.Expect(*in.Spec.Replicas).To(Equal(int32(3)))
+	g.Expect(in.Spec.Database.ClusterRef.Name).To(Equal("mariadb"))
+	g.Expect(in.Spec.Cache.Servers).To(Equal([]string{"memcached:11211"}))
+	g.Expect(in.Status.Conditions[0].Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(in.Labels).To(HaveKeyWithValue("a", "b"))
+}
@@ -0,0 +1,75 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/c5c3/forge/internal/common/dbbackup"
+)
+
+// KeystoneRestoreSpec is the desired state of a Keystone database restore.
+type KeystoneRestoreSpec struct {
+	// KeystoneRef is the Keystone CR in the same namespace whose database
+	// is restored. Its spec.database.clusterRef must be set.
+	KeystoneRef corev1.LocalObjectReference `json:"keystoneRef"`
+	// Backup is the name of the mariadb-operator Backup to restore, e.g.
+	// one the operator took before an upgrade.
+	// +kubebuilder:validation:MinLength=1
+	Backup string `json:"backup"`
+}
+
+// KeystoneRestoreStatus is the observed state of a Keystone database
+// restore.
+type KeystoneRestoreStatus struct {
+	// Phase is the progress of the restore; empty until it starts.
+	// +optional
+	Phase dbbackup.RestorePhase `json:"phase,omitempty"`
+	// Message describes the current phase or the failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Conditions holds the Paused condition while the restore is paused.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// KeystoneRestore restores the database of a Keystone deployment from a
+// backup. The Keystone CR is paused and its API scaled down while the
+// restore runs.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Keystone",type=string,JSONPath=`.spec.keystoneRef.name`
+// +kubebuilder:printcolumn:name="Backup",type=string,JSONPath=`.spec.backup`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type KeystoneRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KeystoneRestoreSpec   `json:"spec,omitempty"`
+	Status KeystoneRestoreStatus `json:"status,omitempty"`
+}
+
+// GetConditions returns the status conditions.
+func (r *KeystoneRestore) GetConditions() []metav1.Condition {
+	return r.Status.Conditions
+}
+
+// SetConditions replaces the status conditions.
+func (r *KeystoneRestore) SetConditions(conditions []metav1.Condition) {
+	r.Status.Conditions = conditions
+}
+
+// KeystoneRestoreList is a list of Keystone database restores.
+// +kubebuilder:object:root=true
+type KeystoneRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []KeystoneRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KeystoneRestore{}, &KeystoneRestoreList{})
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	keystonev1alpha1 "github.com/c5c3/forge/operators/keystone/api/v1alpha1"
+)
+
+// Reasons of the conditions the controller sets.
+const (
+	reasonReady                  = "Ready"
+	reasonNotReady               = "NotReady"
+	reasonWaitingForDependencies = "WaitingForDependencies"
+	reasonReconcileFailed        = "ReconcileFailed"
+	reasonImageNotResolved       = "ImageNotResolved"
+	reasonCacheNotReady          = "MemcachedNotReady"
+	reasonCacheNotConfigured     = "CacheNotConfigured"
+	reasonSecretNotFound         = "SecretNotFound"
+	reasonInvalidSecret          = "InvalidSecret"
+	reasonDatabaseNotReady       = "DatabaseNotReady"
+	reasonDBSyncRunning          = "DBSyncRunning"
+	reasonDBSyncFailed           = "DBSyncFailed"
+	reasonBootstrapRunning       = "BootstrapRunning"
+	reasonBootstrapFailed        = "BootstrapFailed"
+	reasonRollingOut             = "RollingOut"
+	reasonInvalidSchedule        = "InvalidRotationSchedule"
+)
+
+// setCondition sets a condition of k for its current generation and
+// reports whether it changed.
+func setCondition(k *keystonev1alpha1.Keystone, condType string, status metav1.ConditionStatus, reason, messageFmt string, args ...interface{}) bool {
+	return meta.SetStatusCondition(&k.Status.Conditions, metav1.Condition{
+		Type:               condType,
+		Status:             status,
+		Reason:             reason,
+		Message:            fmt.Sprintf(messageFmt, args...),
+		ObservedGeneration: k.Generation,
+	})
+}
+
+// setWaiting sets condType to false because some of the conditions it
+// depends on, as listed in ConditionDependencies, are not true.
+func setWaiting(k *keystonev1alpha1.Keystone, condType string) {
+	var waiting []string
+	for _, dep := range keystonev1alpha1.ConditionDependencies[condType] {
+		if !meta.IsStatusConditionTrue(k.Status.Conditions, dep) {
+			waiting = append(waiting, dep)
+		}
+	}
+	setCondition(k, condType, metav1.ConditionFalse, reasonWaitingForDependencies,
+		"Waiting for %s", strings.Join(waiting, ", "))
+}
+
+// setReady sets the Ready condition, which is true when every other
+// condition the controller manages is true.
+func setReady(k *keystonev1alpha1.Keystone) {
+	var notReady []string
+	for _, condType := range managedConditions {
+		if !meta.IsStatusConditionTrue(k.Status.Conditions, condType) {
+			notReady = append(notReady, condType)
+		}
+	}
+	if len(notReady) > 0 {
+		setCondition(k, keystonev1alpha1.ConditionReady, metav1.ConditionFalse, reasonNotReady,
+			"Not ready: %s", strings.Join(notReady, ", "))
+		return
+	}
+	setCondition(k, keystonev1alpha1.ConditionReady, metav1.ConditionTrue, reasonReady, "Keystone is ready")
+}
+
+// managedConditions are the conditions Ready summarizes, in the order the
+// controller evaluates them.
+var managedConditions = []string{
+	keystonev1alpha1.ConditionCacheReady,
+	keystonev1alpha1.ConditionFernetKeysReady,
+	keystonev1alpha1.ConditionDatabaseReady,
+	keystonev1alpha1.ConditionDeploymentReady,
+	keystonev1alpha1.ConditionAPIReady,
+}
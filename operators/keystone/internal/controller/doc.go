@@ -0,0 +1,37 @@
+// Package controller implements the reconciler of Keystone CRs.
+//
+// A Keystone CR is reconciled into the children of one Keystone deployment,
+// all named after the CR and living in its namespace:
+//
+//	<name>-config            ConfigMap with keystone.conf
+//	<name>-fernet-keys       Secret with the fernet token keys
+//	<name>-credential-keys   Secret with the credential encryption keys
+//	<name>-db-sync-<hash>    Job running keystone-manage db_sync for an image
+//	<name>-bootstrap-<hash>  Job running keystone-manage bootstrap
+//	<name>-api               Deployment and Service of the API
+//
+// With spec.database.clusterRef the mariadb-operator Database, User and
+// Grant, all named <name>, are created too. The pod template of the
+// Deployment is stamped with the hashes of the ConfigMap and Secrets it
+// mounts (see package confighash), so changing any of them rolls the API
+// pods, and status.lastRollout names the input that caused the last rollout.
+//
+// Once all API pods are available, the API is checked with the bootstrap
+// admin credentials (see package keystonehealth) and the CR is requeued for
+// the next check; the result is the KeystoneAPIReady condition.
+//
+// With the FernetAutoRotation feature gate, the fernet keys are rotated as
+// keystone-manage fernet_rotate does, on the cron schedule of spec.fernet
+// (see package cronschedule). The time of the last rotation is kept in the
+// forge.c5c3.io/fernet-keys-rotated-at annotation of the key Secret, and
+// each rotation rolls the API pods.
+//
+// Image changes run as database upgrades (see package dbupgrade) while the
+// ZeroDowntimeUpgrade feature gate is enabled. With spec.backup.enabled and
+// a MariaDB CR, each upgrade first backs up the database. A KeystoneRestore
+// CR restores such a backup; its reconciler pauses the Keystone CR and
+// scales the API down while the restore runs.
+//
+// Both reconcilers skip CRs paused with the forge.c5c3.io/paused annotation
+// and report the pause in a Paused condition (see package pause).
+package controller
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// newKey returns a fernet key: 32 random bytes, URL-safe base64 encoded.
+// Credential keys have the same format.
+func newKey() ([]byte, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("generating key: %w", err)
+	}
+	key := make([]byte, base64.URLEncoding.EncodedLen(len(raw)))
+	base64.URLEncoding.Encode(key, raw)
+	return key, nil
+}
+
+// newKeyRepository returns a key repository as keystone-manage
+// fernet_setup and credential_setup create it: the staged key 0 and the
+// primary key 1. Each key is a file named after its index, so the Secret
+// holding the repository is mounted as the key directory.
+func newKeyRepository() (map[string][]byte, error) {
+	keys := map[string][]byte{}
+	for i := range 2 {
+		key, err := newKey()
+		if err != nil {
+			return nil, err
+		}
+		keys[strconv.Itoa(i)] = key
+	}
+	return keys, nil
+}
+
+// validKeyRepository reports whether keys holds a staged and a primary key
+// and nothing else.
+func validKeyRepository(keys map[string][]byte) bool {
+	if len(keys["0"]) == 0 || len(keys) < 2 {
+		return false
+	}
+	for name, key := range keys {
+		if _, err := strconv.ParseUint(name, 10, 31); err != nil || len(key) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// rotateKeys rotates the fernet key repository keys as keystone-manage
+// fernet_rotate does: the staged key 0 becomes the primary key with the next
+// index, a new key is staged, and the oldest secondary keys are dropped so
+// that at most maxActive keys remain. keys must be valid; it is not
+// modified.
+func rotateKeys(keys map[string][]byte, maxActive int32) (map[string][]byte, error) {
+	var indexes []int
+	for name := range keys {
+		if i, _ := strconv.Atoi(name); i > 0 {
+			indexes = append(indexes, i)
+		}
+	}
+	slices.Sort(indexes)
+	staged, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+	rotated := maps.Clone(keys)
+	rotated[strconv.Itoa(indexes[len(indexes)-1]+1)] = keys["0"]
+	rotated["0"] = staged
+	// The staged and the primary key are never dropped.
+	for _, i := range indexes[:max(len(rotated)-max(int(maxActive), 2), 0)] {
+		delete(rotated, strconv.Itoa(i))
+	}
+	return rotated, nil
+}
@@ -0,0 +1,569 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/c5c3/forge/internal/common/apply"
+	"github.com/c5c3/forge/internal/common/confighash"
+	"github.com/c5c3/forge/internal/common/cronschedule"
+	"github.com/c5c3/forge/internal/common/dbbackup"
+	"github.com/c5c3/forge/internal/common/dbupgrade"
+	"github.com/c5c3/forge/internal/common/events"
+	"github.com/c5c3/forge/internal/common/featuregate"
+	"github.com/c5c3/forge/internal/common/finalizer"
+	"github.com/c5c3/forge/internal/common/keystonehealth"
+	"github.com/c5c3/forge/internal/common/operatorconfig"
+	"github.com/c5c3/forge/internal/common/pause"
+	keystonev1alpha1 "github.com/c5c3/forge/operators/keystone/api/v1alpha1"
+)
+
+// KeystoneReconciler reconciles Keystone CRs.
+type KeystoneReconciler struct {
+	client.Client
+	// APIReader reads the key Secrets uncached, so that keys are never
+	// generated again because the cache has not seen a new Secret yet.
+	APIReader client.Reader
+	Scheme    *runtime.Scheme
+	// Config provides the default images and the requeue intervals.
+	Config   *operatorconfig.Configuration
+	Recorder *events.Recorder
+	// Applier applies the children with the operator's field manager.
+	Applier *apply.Applier
+	// Health checks the API once all its pods are available.
+	Health *keystonehealth.Checker
+	// Gates are the operator's feature gates. ZeroDowntimeUpgrade makes
+	// image changes run as expand, migrate and contract upgrades.
+	Gates *featuregate.FeatureGate
+
+	// now returns the current time. It defaults to time.Now.
+	now func() time.Time
+}
+
+// SetupWithManager registers the reconciler with mgr. Besides the Keystone
+// CRs and their children it watches the database and admin Secrets the CRs
+// reference.
+func (r *KeystoneReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&keystonev1alpha1.Keystone{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&corev1.Secret{}).
+		Owns(&corev1.Service{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&batchv1.Job{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.keystonesForSecret)).
+		Complete(r)
+}
+
+// keystonesForSecret returns the Keystone CRs that reference secret.
+func (r *KeystoneReconciler) keystonesForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	list := &keystonev1alpha1.KeystoneList{}
+	if err := r.List(ctx, list, client.InNamespace(secret.GetNamespace())); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "listing Keystones for Secret", "secret", secret.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range list.Items {
+		k := &list.Items[i]
+		if k.Spec.Database.SecretRef.Name == secret.GetName() ||
+			k.Spec.Bootstrap.AdminPasswordSecretRef.Name == secret.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(k)})
+		}
+	}
+	return requests
+}
+
+// Reconcile brings the children of a Keystone CR to the state its spec
+// describes and records the outcome in its status. Before a Keystone CR is
+// deleted, its database resources and backups are deleted or orphaned as its
+// spec.deletionPolicy says. A paused Keystone CR, e.g. one being restored, is
+// left alone, including its deletion.
+func (r *KeystoneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	keystone := &keystonev1alpha1.Keystone{}
+	if err := r.Get(ctx, req.NamespacedName, keystone); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.Health.Forget(req.NamespacedName)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if paused, err := pause.Guard(ctx, r.Client, r.Recorder, keystone); paused || err != nil {
+		return ctrl.Result{}, err
+	}
+	deleted, err := finalizer.Reconcile(ctx, r.Client, keystone,
+		finalizer.Step{Name: "database", Run: func(ctx context.Context) error {
+			return finalizer.ApplyDeletionPolicy(ctx, r.Client, keystone, keystone.Spec.DeletionPolicy, databaseRefs(keystone)...)
+		}},
+		finalizer.Step{Name: "backups", Run: func(ctx context.Context) error {
+			if keystone.Spec.DeletionPolicy.OrDefault() != finalizer.DeletionPolicyDelete {
+				return nil
+			}
+			_, err := dbbackup.DeleteAll(ctx, r.Client, keystone)
+			return err
+		}},
+	)
+	if err != nil || deleted {
+		if deleted {
+			r.Recorder.Forget(keystone)
+		}
+		return ctrl.Result{}, err
+	}
+
+	status := keystone.Status.DeepCopy()
+	result, err := r.reconcile(ctx, keystone)
+	if err != nil {
+		setCondition(keystone, keystonev1alpha1.ConditionReady, metav1.ConditionFalse, reasonReconcileFailed, "%s", err)
+		r.Recorder.Event(keystone, events.ReasonReconcileFailed, "%s", err)
+	}
+	// Drift no watch reports is corrected at the latest after the requeue
+	// interval.
+	if requeue := r.Config.Reconcile.RequeueInterval.Duration; err == nil &&
+		(result.RequeueAfter == 0 || result.RequeueAfter > requeue) {
+		result.RequeueAfter = requeue
+	}
+	keystone.Status.ObservedGeneration = keystone.Generation
+	if !equality.Semantic.DeepEqual(status, &keystone.Status) {
+		if updateErr := r.Status().Update(ctx, keystone); updateErr != nil {
+			return ctrl.Result{}, errors.Join(err, fmt.Errorf("updating status: %w", updateErr))
+		}
+	}
+	return result, err
+}
+
+// reconcile runs the steps of a reconcile in dependency order. A step that
+// waits for something sets its condition and makes reconcile return early
+// with a requeue, leaving the later conditions waiting.
+func (r *KeystoneReconciler) reconcile(ctx context.Context, k *keystonev1alpha1.Keystone) (ctrl.Result, error) {
+	wait := ctrl.Result{RequeueAfter: r.Config.Reconcile.ErrorRequeueInterval.Duration}
+	defer setReady(k)
+
+	image, err := k.Spec.ResolveImage(r.Config)
+	if err != nil {
+		// Only a change of the spec or the operator configuration helps.
+		setCondition(k, keystonev1alpha1.ConditionDeploymentReady, metav1.ConditionFalse, reasonImageNotResolved, "%s", err)
+		setWaiting(k, keystonev1alpha1.ConditionAPIReady)
+		return ctrl.Result{}, nil
+	}
+
+	cacheReady, err := r.reconcileCache(ctx, k)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	nextRotation, err := r.reconcileKeys(ctx, k)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if _, err := r.Applier.Apply(ctx, k, renderConfigMap(k)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("applying ConfigMap %s: %w", configMapName(k), err)
+	}
+	// An upgrade decides which image the Deployment runs; see dbupgrade.
+	var backup *dbbackup.BackupRequest
+	if ref := k.Spec.Database.ClusterRef; ref != nil && k.Spec.Backup.Enabled {
+		backup = &dbbackup.BackupRequest{
+			Owner:    k,
+			Revision: k.Spec.OpenStackRelease,
+			MariaDB:  ref.Name,
+			Database: databaseName(k),
+			Policy:   k.Spec.Backup,
+		}
+	}
+	upgrade, err := dbupgrade.Advance(ctx, r.Client, r.Scheme, r.Recorder, dbupgrade.Request{
+		Owner:      k,
+		Deployment: apiName(k),
+		Container:  apiContainer,
+		Image:      image,
+		JobSpec: func(step dbupgrade.Step, image string) batchv1.JobSpec {
+			return dbSyncJobSpec(k, image, step.Flag())
+		},
+		Gates:  r.Gates,
+		Backup: backup,
+	}, k.Status.Upgrade)
+	k.Status.Upgrade = upgrade
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	image = upgrade.Image(image)
+
+	dbReady, err := r.reconcileDatabase(ctx, k, image)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !cacheReady || !dbReady {
+		setWaiting(k, keystonev1alpha1.ConditionDeploymentReady)
+		setWaiting(k, keystonev1alpha1.ConditionAPIReady)
+		return wait, nil
+	}
+
+	available, err := r.reconcileDeployment(ctx, k, image)
+	if err != nil || !available {
+		setWaiting(k, keystonev1alpha1.ConditionAPIReady)
+		return wait, err
+	}
+	k.Status.Image = image
+	k.Status.OpenStackRelease = k.Spec.OpenStackRelease
+
+	result, err := r.checkAPI(ctx, k)
+	if upgrade.InProgress() {
+		result.RequeueAfter = min(result.RequeueAfter, wait.RequeueAfter)
+	}
+	if nextRotation > 0 && (result.RequeueAfter == 0 || nextRotation < result.RequeueAfter) {
+		result.RequeueAfter = nextRotation
+	}
+	return result, err
+}
+
+// checkAPI checks that the API of k issues tokens for the bootstrap admin
+// and requeues k for the next check.
+func (r *KeystoneReconciler) checkAPI(ctx context.Context, k *keystonev1alpha1.Keystone) (ctrl.Result, error) {
+	ref := k.Spec.Bootstrap.AdminPasswordSecretRef
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: k.Namespace, Name: ref.Name}, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("getting Secret %s: %w", ref.Name, err)
+		}
+		setCondition(k, keystonev1alpha1.ConditionAPIReady, metav1.ConditionFalse, reasonSecretNotFound,
+			"Admin password Secret %s not found", ref.Name)
+		return ctrl.Result{RequeueAfter: r.Config.Reconcile.ErrorRequeueInterval.Duration}, nil
+	}
+
+	key := client.ObjectKeyFromObject(k)
+	result := r.Health.Check(ctx, key, k.Status.Endpoint, keystonehealth.Credentials{
+		Username: adminUser(k),
+		Password: string(secret.Data[ref.Key]),
+		Project:  adminProject,
+	})
+	keystonehealth.SetCondition(k, result)
+	return ctrl.Result{RequeueAfter: r.Health.RequeueAfter(key)}, nil
+}
+
+// reconcileCache checks that the memcached servers of k are known and, for
+// a Memcached CR, ready.
+func (r *KeystoneReconciler) reconcileCache(ctx context.Context, k *keystonev1alpha1.Keystone) (bool, error) {
+	ref := k.Spec.Cache.ClusterRef
+	if ref == nil {
+		if len(k.Spec.Cache.Servers) == 0 {
+			setCondition(k, keystonev1alpha1.ConditionCacheReady, metav1.ConditionTrue, reasonCacheNotConfigured,
+				"No memcached servers configured; tokens are not cached")
+			return true, nil
+		}
+		setCondition(k, keystonev1alpha1.ConditionCacheReady, metav1.ConditionTrue, reasonReady,
+			"Using memcached servers %s", strings.Join(k.Spec.Cache.Servers, ", "))
+		return true, nil
+	}
+
+	memcached := &unstructured.Unstructured{}
+	memcached.SetGroupVersionKind(memcachedGVK)
+	err := r.Get(ctx, client.ObjectKey{Namespace: k.Namespace, Name: ref.Name}, memcached)
+	if client.IgnoreNotFound(err) != nil {
+		return false, fmt.Errorf("getting Memcached %s: %w", ref.Name, err)
+	}
+	if err != nil || !isReady(memcached) {
+		setCondition(k, keystonev1alpha1.ConditionCacheReady, metav1.ConditionFalse, reasonCacheNotReady,
+			"Memcached %s is not ready", ref.Name)
+		r.Recorder.Event(k, events.ReasonDependencyNotReady, "Memcached %s is not ready", ref.Name)
+		return false, nil
+	}
+	setCondition(k, keystonev1alpha1.ConditionCacheReady, metav1.ConditionTrue, reasonReady,
+		"Memcached %s is ready", ref.Name)
+	return true, nil
+}
+
+// reconcileKeys creates the fernet and credential key repositories of k.
+// Existing keys are kept. With the FernetAutoRotation feature gate, the
+// fernet keys are rotated on the schedule of spec.fernet, and reconcileKeys
+// returns the time until the next rotation.
+func (r *KeystoneReconciler) reconcileKeys(ctx context.Context, k *keystonev1alpha1.Keystone) (time.Duration, error) {
+	var schedule cronschedule.Schedule
+	var scheduleErr error
+	rotate := r.Gates != nil && r.Gates.Enabled(featuregate.FernetAutoRotation)
+	if rotate {
+		schedule, scheduleErr = cronschedule.Parse(rotationSchedule(k))
+		rotate = scheduleErr == nil
+	}
+
+	var next time.Duration
+	for _, name := range []string{fernetKeysName(k), credentialKeysName(k)} {
+		live := &corev1.Secret{}
+		err := r.APIReader.Get(ctx, client.ObjectKey{Namespace: k.Namespace, Name: name}, live)
+		if client.IgnoreNotFound(err) != nil {
+			return 0, fmt.Errorf("getting Secret %s: %w", name, err)
+		}
+		keys := live.Data
+		rotatedAt := live.Annotations[keystonev1alpha1.FernetKeysRotatedAtAnnotation]
+		if !validKeyRepository(keys) {
+			if keys, err = newKeyRepository(); err != nil {
+				return 0, err
+			}
+			rotatedAt = ""
+		}
+		var annotations map[string]string
+		if name == fernetKeysName(k) && rotate {
+			// A new repository, or one whose last rotation is unknown,
+			// counts as rotated now.
+			now := r.clock().UTC()
+			last, err := time.Parse(time.RFC3339, rotatedAt)
+			if err != nil {
+				last = now
+			}
+			due := schedule.Next(last)
+			if !due.IsZero() && !now.Before(due) {
+				if keys, err = rotateKeys(keys, maxActiveKeys(k)); err != nil {
+					r.Recorder.Event(k, events.ReasonFernetKeyRotationFailed, "%s", err)
+					return 0, err
+				}
+				last, due = now, schedule.Next(now)
+				r.Recorder.Event(k, events.ReasonFernetKeysRotated, "Rotated the fernet keys in Secret %s", name)
+			}
+			if !due.IsZero() {
+				next = due.Sub(now)
+			}
+			annotations = map[string]string{keystonev1alpha1.FernetKeysRotatedAtAnnotation: last.Format(time.RFC3339)}
+		} else if rotatedAt != "" {
+			annotations = map[string]string{keystonev1alpha1.FernetKeysRotatedAtAnnotation: rotatedAt}
+		}
+		if _, err := r.Applier.Apply(ctx, k, renderKeySecret(k, name, keys, annotations)); err != nil {
+			return 0, fmt.Errorf("applying Secret %s: %w", name, err)
+		}
+	}
+	if scheduleErr != nil {
+		setCondition(k, keystonev1alpha1.ConditionFernetKeysReady, metav1.ConditionFalse, reasonInvalidSchedule,
+			"Fernet keys are not rotated: %s", scheduleErr)
+		r.Recorder.Event(k, events.ReasonFernetKeyRotationFailed, "Invalid spec.fernet.rotationSchedule: %s", scheduleErr)
+		return 0, nil
+	}
+	setCondition(k, keystonev1alpha1.ConditionFernetKeysReady, metav1.ConditionTrue, reasonReady,
+		"Fernet and credential keys exist")
+	return next, nil
+}
+
+// reconcileDatabase provisions the database of k, migrates its schema for
+// image and bootstraps the admin user, and reports whether all of that is
+// done. The schema of an image an upgrade runs from or to is migrated by
+// the upgrade instead.
+func (r *KeystoneReconciler) reconcileDatabase(ctx context.Context, k *keystonev1alpha1.Keystone, image string) (bool, error) {
+	secretName := k.Spec.Database.SecretRef.Name
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: k.Namespace, Name: secretName}, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, fmt.Errorf("getting Secret %s: %w", secretName, err)
+		}
+		setCondition(k, keystonev1alpha1.ConditionDatabaseReady, metav1.ConditionFalse, reasonSecretNotFound,
+			"Database Secret %s not found", secretName)
+		r.Recorder.Event(k, events.ReasonDependencyNotReady, "Database Secret %s not found", secretName)
+		return false, nil
+	}
+	username := string(secret.Data[dbUsernameKey])
+	if username == "" || len(secret.Data[dbPasswordKey]) == 0 {
+		setCondition(k, keystonev1alpha1.ConditionDatabaseReady, metav1.ConditionFalse, reasonInvalidSecret,
+			"Database Secret %s must contain the keys %s and %s", secretName, dbUsernameKey, dbPasswordKey)
+		return false, nil
+	}
+
+	var prevReason string
+	if cond := meta.FindStatusCondition(k.Status.Conditions, keystonev1alpha1.ConditionDatabaseReady); cond != nil {
+		prevReason = cond.Reason
+	}
+	if ref := k.Spec.Database.ClusterRef; ref != nil {
+		var notReady []string
+		for _, obj := range renderDatabase(k, username) {
+			if _, err := r.Applier.Apply(ctx, k, obj); err != nil {
+				return false, fmt.Errorf("applying %s %s: %w", obj.GetKind(), obj.GetName(), err)
+			}
+			if !isReady(obj) {
+				notReady = append(notReady, obj.GetKind())
+			}
+		}
+		if len(notReady) > 0 {
+			setCondition(k, keystonev1alpha1.ConditionDatabaseReady, metav1.ConditionFalse, reasonDatabaseNotReady,
+				"Waiting for the %s of MariaDB %s", strings.Join(notReady, ", "), ref.Name)
+			return false, nil
+		}
+		if prevReason == reasonDatabaseNotReady {
+			r.Recorder.Event(k, events.ReasonDatabaseProvisioned, "Database %s provisioned on MariaDB %s", databaseName(k), ref.Name)
+		}
+	}
+
+	if !migratedByUpgrade(k.Status.Upgrade, image) {
+		done, err := r.runJob(ctx, k, jobName(k, "db-sync", image), dbSyncJobSpec(k, image),
+			reasonDBSyncRunning, reasonDBSyncFailed, "db_sync", events.ReasonDBSyncFailed)
+		if err != nil || !done {
+			return false, err
+		}
+		if prevReason == reasonDBSyncRunning {
+			r.Recorder.Event(k, events.ReasonDBSyncCompleted, "db_sync for %s completed", image)
+		}
+	}
+
+	// Bootstrapping is idempotent and independent of the release, so it only
+	// runs again when its parameters change, not on upgrades.
+	endpoint := keystonehealth.ServiceURL("http", apiName(k), k.Namespace, apiPort)
+	bootstrap := strings.Join([]string{adminUser(k), region(k), endpoint,
+		k.Spec.Bootstrap.AdminPasswordSecretRef.Name, k.Spec.Bootstrap.AdminPasswordSecretRef.Key}, "\n")
+	done, err := r.runJob(ctx, k, jobName(k, "bootstrap", bootstrap), bootstrapJobSpec(k, image, endpoint),
+		reasonBootstrapRunning, reasonBootstrapFailed, "keystone-manage bootstrap", events.ReasonReconcileFailed)
+	if err != nil || !done {
+		return false, err
+	}
+
+	setCondition(k, keystonev1alpha1.ConditionDatabaseReady, metav1.ConditionTrue, reasonReady,
+		"Database %s is migrated for %s", databaseName(k), image)
+	return true, nil
+}
+
+// runJob creates the Job name with spec unless it exists and reports
+// whether it completed. While it runs or after it failed, the DatabaseReady
+// condition is set with the running or failed reason. A failed Job is not
+// retried; its failure is reported as a failedEvent event.
+func (r *KeystoneReconciler) runJob(ctx context.Context, k *keystonev1alpha1.Keystone, name string, spec batchv1.JobSpec,
+	running, failed, task string, failedEvent events.Reason) (bool, error) {
+	job := &batchv1.Job{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: k.Namespace, Name: name}, job)
+	if apierrors.IsNotFound(err) {
+		job = &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: k.Namespace, Labels: commonLabels(k)},
+			Spec:       spec,
+		}
+		if err := controllerutil.SetControllerReference(k, job, r.Scheme); err != nil {
+			return false, fmt.Errorf("setting owner of Job %s: %w", name, err)
+		}
+		if err := r.Create(ctx, job); err != nil && !apierrors.IsAlreadyExists(err) {
+			return false, fmt.Errorf("creating Job %s: %w", name, err)
+		}
+		setCondition(k, keystonev1alpha1.ConditionDatabaseReady, metav1.ConditionFalse, running, "Running %s in Job %s", task, name)
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("getting Job %s: %w", name, err)
+	}
+
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			return true, nil
+		case batchv1.JobFailed:
+			setCondition(k, keystonev1alpha1.ConditionDatabaseReady, metav1.ConditionFalse, failed,
+				"%s failed in Job %s: %s; delete the Job to retry", task, name, cond.Message)
+			r.Recorder.EventWithRelated(k, job, failedEvent, "%s failed: %s", task, cond.Message)
+			return false, nil
+		}
+	}
+	setCondition(k, keystonev1alpha1.ConditionDatabaseReady, metav1.ConditionFalse, running, "Running %s in Job %s", task, name)
+	return false, nil
+}
+
+// reconcileDeployment applies the API Deployment with image and the
+// Service, and reports whether all API pods are available. The pod template
+// carries the hashes of the inputs the pods mount, so that a change of any
+// of them rolls the pods; status.lastRollout records which one did.
+func (r *KeystoneReconciler) reconcileDeployment(ctx context.Context, k *keystonev1alpha1.Keystone, image string) (bool, error) {
+	hashes, err := confighash.Compute(ctx, r.Client, k.Namespace,
+		confighash.ConfigMap(configMapName(k)),
+		confighash.Secret(fernetKeysName(k)),
+		confighash.Secret(credentialKeysName(k)),
+		confighash.Secret(k.Spec.Database.SecretRef.Name))
+	if err != nil {
+		return false, err
+	}
+
+	live := &appsv1.Deployment{}
+	var triggers []string
+	liveImage := ""
+	err = r.Get(ctx, client.ObjectKey{Namespace: k.Namespace, Name: apiName(k)}, live)
+	switch {
+	case err == nil:
+		triggers = confighash.Changed(&live.Spec.Template, hashes)
+		liveImage = containerImage(live)
+	case !apierrors.IsNotFound(err):
+		return false, fmt.Errorf("getting Deployment %s: %w", apiName(k), err)
+	}
+
+	deploy := renderDeployment(k, image)
+	confighash.Stamp(&deploy.Spec.Template, hashes)
+	result, err := r.Applier.Apply(ctx, k, deploy)
+	if err != nil {
+		return false, fmt.Errorf("applying Deployment %s: %w", deploy.Name, err)
+	}
+	switch {
+	case result.Created:
+		r.Recorder.Event(k, events.ReasonDeploymentUpdated, "Created Deployment %s with %s", deploy.Name, image)
+	case liveImage != image:
+		r.Recorder.Event(k, events.ReasonDeploymentUpdated, "Rolling Deployment %s to %s", deploy.Name, image)
+	case len(triggers) > 0:
+		r.Recorder.Event(k, events.ReasonDeploymentUpdated, "Rolling Deployment %s after a change of %s",
+			deploy.Name, strings.Join(triggers, ", "))
+	}
+	k.Status.LastRollout = k.Status.LastRollout.Observe(hashes, triggers, metav1.NewTime(r.clock()))
+
+	if _, err := r.Applier.Apply(ctx, k, renderService(k)); err != nil {
+		return false, fmt.Errorf("applying Service %s: %w", apiName(k), err)
+	}
+	k.Status.Endpoint = keystonehealth.ServiceURL("http", apiName(k), k.Namespace, apiPort)
+
+	want := replicas(k)
+	s := deploy.Status
+	if s.ObservedGeneration < deploy.Generation || s.UpdatedReplicas != want || s.AvailableReplicas != want || s.Replicas != want {
+		setCondition(k, keystonev1alpha1.ConditionDeploymentReady, metav1.ConditionFalse, reasonRollingOut,
+			"%d of %d replicas of %s are updated and available", min(s.UpdatedReplicas, s.AvailableReplicas), want, image)
+		return false, nil
+	}
+	setCondition(k, keystonev1alpha1.ConditionDeploymentReady, metav1.ConditionTrue, reasonReady,
+		"%d replicas of %s are available", want, image)
+	return true, nil
+}
+
+// migratedByUpgrade reports whether the schema for image is migrated by the
+// expand, migrate and contract Jobs of the upgrade s rather than a plain
+// db_sync. The image an upgrade starts from ran before, so its schema is
+// migrated too.
+func migratedByUpgrade(s dbupgrade.Status, image string) bool {
+	return s.Phase != "" && (image == s.FromImage || image == s.ToImage)
+}
+
+func (r *KeystoneReconciler) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+func containerImage(deploy *appsv1.Deployment) string {
+	for _, c := range deploy.Spec.Template.Spec.Containers {
+		if c.Name == apiContainer {
+			return c.Image
+		}
+	}
+	return ""
+}
+
+// isReady reports whether obj has a Ready condition with status True, as
+// the mariadb-operator and memcached-operator resources report it.
+func isReady(obj *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, raw := range conditions {
+		cond, ok := raw.(map[string]interface{})
+		if ok && cond["type"] == "Ready" {
+			return cond["status"] == string(metav1.ConditionTrue)
+		}
+	}
+	return false
+}
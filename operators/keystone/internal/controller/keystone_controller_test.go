@@ -0,0 +1,559 @@
+package controller
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clientevents "k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/c5c3/forge/internal/common/apply"
+	"github.com/c5c3/forge/internal/common/confighash"
+	"github.com/c5c3/forge/internal/common/dbbackup"
+	"github.com/c5c3/forge/internal/common/dbupgrade"
+	"github.com/c5c3/forge/internal/common/events"
+	"github.com/c5c3/forge/internal/common/featuregate"
+	"github.com/c5c3/forge/internal/common/finalizer"
+	keystonefake "github.com/c5c3/forge/internal/common/keystoneclient/fake"
+	"github.com/c5c3/forge/internal/common/keystonehealth"
+	"github.com/c5c3/forge/internal/common/operatorconfig"
+	"github.com/c5c3/forge/internal/common/pause"
+	"github.com/c5c3/forge/internal/common/testutil/assertions"
+	keystonev1alpha1 "github.com/c5c3/forge/operators/keystone/api/v1alpha1"
+)
+
+const testImage = "ghcr.io/c5c3/keystone:2025.2"
+
+var testTime = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+type fixture struct {
+	c    client.Client
+	r    *KeystoneReconciler
+	fake *clientevents.FakeRecorder
+	api  *keystonefake.Server
+}
+
+func newKeystone() *keystonev1alpha1.Keystone {
+	return &keystonev1alpha1.Keystone{
+		ObjectMeta: metav1.ObjectMeta{Name: "keystone", Namespace: "openstack", UID: "keystone-uid", Generation: 1},
+		Spec: keystonev1alpha1.KeystoneSpec{
+			OpenStackRelease: "2025.2",
+			Database: keystonev1alpha1.DatabaseSpec{
+				Host:      "mariadb.db.svc",
+				SecretRef: corev1.LocalObjectReference{Name: "keystone-db"},
+			},
+			Cache: keystonev1alpha1.CacheSpec{Servers: []string{"memcached.openstack.svc:11211"}},
+			Bootstrap: keystonev1alpha1.BootstrapSpec{
+				AdminPasswordSecretRef: corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "keystone-admin"},
+					Key:                  "password",
+				},
+			},
+		},
+	}
+}
+
+func newSecret(name string, data map[string]string) *corev1.Secret {
+	s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "openstack"}, Data: map[string][]byte{}}
+	for k, v := range data {
+		s.Data[k] = []byte(v)
+	}
+	return s
+}
+
+func newFixture(t *testing.T, objs ...client.Object) *fixture {
+	g := NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(keystonev1alpha1.AddToScheme(scheme)).To(Succeed())
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&keystonev1alpha1.Keystone{}, &appsv1.Deployment{}, &batchv1.Job{}).
+		Build()
+	cfg := operatorconfig.New()
+	cfg.DefaultImages = map[string]map[string]string{"2025.2": {"keystone": testImage}}
+	fakeRecorder := clientevents.NewFakeRecorder(50)
+	recorder := events.NewRecorder(fakeRecorder, time.Minute)
+
+	// The Keystone API stands in for every Service the checker contacts.
+	srv := keystonefake.NewServer(keystonefake.Options{})
+	t.Cleanup(srv.Close)
+	httpClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+		},
+	}}
+	return &fixture{
+		c: c,
+		r: &KeystoneReconciler{
+			Client:    c,
+			APIReader: c,
+			Scheme:    scheme,
+			Config:    cfg,
+			Recorder:  recorder,
+			Applier:   apply.NewApplier(c, scheme, recorder, apply.FieldManager("keystone")),
+			Health:    keystonehealth.NewChecker(cfg.APIHealthCheck, httpClient),
+			now:       func() time.Time { return testTime },
+		},
+		fake: fakeRecorder,
+		api:  srv,
+	}
+}
+
+// newReadyFixture returns a fixture with a Keystone CR and the Secrets it
+// references.
+func newReadyFixture(t *testing.T) *fixture {
+	return newFixture(t, newKeystone(),
+		newSecret("keystone-db", map[string]string{"username": "keystone", "password": "secret"}),
+		newSecret("keystone-admin", map[string]string{"password": keystonefake.AdminPassword}))
+}
+
+func (f *fixture) reconcile(g *WithT) ctrl.Result {
+	result, err := f.r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "openstack", Name: "keystone"}})
+	g.Expect(err).NotTo(HaveOccurred())
+	return result
+}
+
+func (f *fixture) keystone(g *WithT) *keystonev1alpha1.Keystone {
+	k := &keystonev1alpha1.Keystone{}
+	g.Expect(f.c.Get(context.Background(), client.ObjectKey{Namespace: "openstack", Name: "keystone"}, k)).To(Succeed())
+	return k
+}
+
+func (f *fixture) deployment(g *WithT) *appsv1.Deployment {
+	deploy := &appsv1.Deployment{}
+	g.Expect(f.c.Get(context.Background(), client.ObjectKey{Namespace: "openstack", Name: "keystone-api"}, deploy)).To(Succeed())
+	return deploy
+}
+
+// finishJobs stands in for the Job controller and completes every Job.
+func (f *fixture) finishJobs(g *WithT) {
+	ctx := context.Background()
+	jobs := &batchv1.JobList{}
+	g.Expect(f.c.List(ctx, jobs, client.InNamespace("openstack"))).To(Succeed())
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+		g.Expect(f.c.Status().Update(ctx, job)).To(Succeed())
+	}
+}
+
+// rollOut stands in for the Deployment controller.
+func (f *fixture) rollOut(g *WithT) {
+	deploy := f.deployment(g)
+	deploy.Status = appsv1.DeploymentStatus{
+		ObservedGeneration: deploy.Generation,
+		Replicas:           *deploy.Spec.Replicas,
+		UpdatedReplicas:    *deploy.Spec.Replicas,
+		AvailableReplicas:  *deploy.Spec.Replicas,
+	}
+	g.Expect(f.c.Status().Update(context.Background(), deploy)).To(Succeed())
+}
+
+// events drains the recorded events.
+func (f *fixture) events() []string {
+	var recorded []string
+	for len(f.fake.Events) > 0 {
+		recorded = append(recorded, <-f.fake.Events)
+	}
+	return recorded
+}
+
+// deploy reconciles until the Keystone is ready.
+func (f *fixture) deploy(g *WithT) {
+	f.reconcile(g)
+	f.finishJobs(g)
+	f.reconcile(g)
+	f.finishJobs(g)
+	f.reconcile(g)
+	f.rollOut(g)
+	f.reconcile(g)
+}
+
+func TestReconcileDeploysKeystone(t *testing.T) {
+	g := NewGomegaWithT(t)
+	f := newReadyFixture(t)
+	ctx := context.Background()
+
+	result := f.reconcile(g)
+	g.Expect(result.RequeueAfter).To(Equal(f.r.Config.Reconcile.ErrorRequeueInterval.Duration))
+	k := f.keystone(g)
+	assertions.AssertCondition(g, k.Status.Conditions, keystonev1alpha1.ConditionDatabaseReady, metav1.ConditionFalse)
+	g.Expect(condition(k, keystonev1alpha1.ConditionDatabaseReady).Reason).To(Equal(reasonDBSyncRunning))
+	assertions.AssertCondition(g, k.Status.Conditions, keystonev1alpha1.ConditionDeploymentReady, metav1.ConditionFalse)
+	g.Expect(condition(k, keystonev1alpha1.ConditionDeploymentReady).Message).To(Equal("Waiting for DatabaseReady"))
+
+	cm := &corev1.ConfigMap{}
+	g.Expect(f.c.Get(ctx, client.ObjectKey{Namespace: "openstack", Name: "keystone-config"}, cm)).To(Succeed())
+	assertions.AssertGolden(g, "testdata/keystone.conf", cm.Data["keystone.conf"])
+
+	fernet := &corev1.Secret{}
+	g.Expect(f.c.Get(ctx, client.ObjectKey{Namespace: "openstack", Name: "keystone-fernet-keys"}, fernet)).To(Succeed())
+	g.Expect(fernet.Data).To(HaveLen(2))
+	keys := fernet.Data
+
+	f.deploy(g)
+	k = f.keystone(g)
+	assertions.AssertCondition(g, k.Status.Conditions, keystonev1alpha1.ConditionReady, metav1.ConditionTrue)
+	g.Expect(k.Status.Image).To(Equal(testImage))
+	g.Expect(k.Status.OpenStackRelease).To(Equal("2025.2"))
+	g.Expect(k.Status.Endpoint).To(Equal("http://keystone-api.openstack.svc:5000"))
+	g.Expect(k.Status.ObservedGeneration).To(Equal(int64(1)))
+	assertions.AssertCondition(g, k.Status.Conditions, keystonev1alpha1.ConditionAPIReady, metav1.ConditionTrue)
+	assertions.AssertControlledBy(g, f.c, k, cm, fernet, f.deployment(g))
+
+	g.Expect(f.c.Get(ctx, client.ObjectKey{Namespace: "openstack", Name: "keystone-fernet-keys"}, fernet)).To(Succeed())
+	g.Expect(fernet.Data).To(Equal(keys), "existing keys are kept")
+	g.Expect(f.events()).To(ContainElements(
+		"Normal DBSyncCompleted db_sync for "+testImage+" completed",
+		"Normal DeploymentUpdated Created Deployment keystone-api with "+testImage,
+	))
+}
+
+func TestReconcileRollsAPIOnConfigChange(t *testing.T) {
+	g := NewGomegaWithT(t)
+	f := newReadyFixture(t)
+	ctx := context.Background()
+	f.deploy(g)
+
+	k := f.keystone(g)
+	template := f.deployment(g).Spec.Template
+	g.Expect(template.Annotations).To(HaveKeyWithValue(confighash.HashAnnotation, k.Status.LastRollout.Hash))
+	g.Expect(k.Status.LastRollout.Triggers).To(BeEmpty(), "the first rollout has no trigger")
+
+	secret := &corev1.Secret{}
+	g.Expect(f.c.Get(ctx, client.ObjectKey{Namespace: "openstack", Name: "keystone-db"}, secret)).To(Succeed())
+	secret.Data["password"] = []byte("rotated")
+	g.Expect(f.c.Update(ctx, secret)).To(Succeed())
+	f.reconcile(g)
+
+	k = f.keystone(g)
+	g.Expect(k.Status.LastRollout.Triggers).To(Equal([]string{"Secret/keystone-db"}))
+	g.Expect(k.Status.LastRollout.Time.Time).To(BeTemporally("==", testTime))
+	g.Expect(f.deployment(g).Spec.Template.Annotations[confighash.HashAnnotation]).To(Equal(k.Status.LastRollout.Hash))
+	g.Expect(k.Status.LastRollout.Hash).NotTo(Equal(template.Annotations[confighash.HashAnnotation]))
+	g.Expect(f.events()).To(ContainElement("Normal DeploymentUpdated Rolling Deployment keystone-api after a change of Secret/keystone-db"))
+}
+
+func TestReconcileRotatesFernetKeys(t *testing.T) {
+	g := NewGomegaWithT(t)
+	k := newKeystone()
+	k.Spec.Fernet.RotationSchedule = "0 * * * *"
+	f := newFixture(t, k,
+		newSecret("keystone-db", map[string]string{"username": "keystone", "password": "secret"}),
+		newSecret("keystone-admin", map[string]string{"password": keystonefake.AdminPassword}))
+	f.r.Gates = featuregate.NewDefault()
+	now := testTime
+	f.r.now = func() time.Time { return now }
+	fernet := func() *corev1.Secret {
+		secret := &corev1.Secret{}
+		g.Expect(f.c.Get(context.Background(), client.ObjectKey{Namespace: "openstack", Name: "keystone-fernet-keys"}, secret)).To(Succeed())
+		return secret
+	}
+	f.deploy(g)
+	secret := fernet()
+	g.Expect(secret.Annotations).To(HaveKeyWithValue(keystonev1alpha1.FernetKeysRotatedAtAnnotation, "2026-01-02T03:04:05Z"))
+	keys := secret.Data
+	g.Expect(f.reconcile(g).RequeueAfter).To(BeNumerically("<=", 55*time.Minute+55*time.Second))
+	g.Expect(fernet().Data).To(Equal(keys), "keys are not rotated before the schedule is due")
+	f.events()
+
+	now = time.Date(2026, 1, 2, 4, 0, 0, 0, time.UTC)
+	f.reconcile(g)
+	secret = fernet()
+	g.Expect(secret.Annotations).To(HaveKeyWithValue(keystonev1alpha1.FernetKeysRotatedAtAnnotation, "2026-01-02T04:00:00Z"))
+	g.Expect(secret.Data).To(HaveLen(3))
+	g.Expect(secret.Data).To(HaveKeyWithValue("1", keys["1"]))
+	g.Expect(secret.Data).To(HaveKeyWithValue("2", keys["0"]), "the staged key becomes the primary key")
+	g.Expect(secret.Data["0"]).NotTo(Equal(keys["0"]))
+	g.Expect(f.keystone(g).Status.LastRollout.Triggers).To(Equal([]string{"Secret/keystone-fernet-keys"}))
+	g.Expect(f.events()).To(ContainElement("Normal FernetKeysRotated Rotated the fernet keys in Secret keystone-fernet-keys"))
+
+	now = now.Add(time.Hour)
+	f.reconcile(g)
+	g.Expect(fernet().Data).To(SatisfyAll(HaveLen(3), HaveKey("0"), HaveKey("2"), HaveKey("3")),
+		"the oldest key is dropped beyond spec.fernet.maxActiveKeys")
+}
+
+func TestReconcileInvalidRotationSchedule(t *testing.T) {
+	g := NewGomegaWithT(t)
+	k := newKeystone()
+	k.Spec.Fernet.RotationSchedule = "every sunday"
+	f := newFixture(t, k,
+		newSecret("keystone-db", map[string]string{"username": "keystone", "password": "secret"}),
+		newSecret("keystone-admin", map[string]string{"password": keystonefake.AdminPassword}))
+	f.r.Gates = featuregate.NewDefault()
+	f.deploy(g)
+
+	k = f.keystone(g)
+	assertions.AssertCondition(g, k.Status.Conditions, keystonev1alpha1.ConditionFernetKeysReady, metav1.ConditionFalse)
+	g.Expect(condition(k, keystonev1alpha1.ConditionFernetKeysReady).Reason).To(Equal(reasonInvalidSchedule))
+	assertions.AssertCondition(g, k.Status.Conditions, keystonev1alpha1.ConditionReady, metav1.ConditionFalse)
+	g.Expect(f.events()).To(ContainElement(
+		`Warning FernetKeyRotationFailed Invalid spec.fernet.rotationSchedule: schedule "every sunday": expected 5 fields, got 2`))
+}
+
+func TestRotateKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      []string
+		maxActive int32
+		want      []string
+	}{
+		{name: "new repository", keys: []string{"0", "1"}, maxActive: 3, want: []string{"0", "1", "2"}},
+		{name: "full repository", keys: []string{"0", "1", "2"}, maxActive: 3, want: []string{"0", "2", "3"}},
+		{name: "gaps", keys: []string{"0", "4", "7", "9"}, maxActive: 3, want: []string{"0", "9", "10"}},
+		{name: "larger maximum", keys: []string{"0", "1", "2"}, maxActive: 5, want: []string{"0", "1", "2", "3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			keys := map[string][]byte{}
+			for _, name := range tt.keys {
+				keys[name] = []byte("key-" + name)
+			}
+			rotated, err := rotateKeys(keys, tt.maxActive)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(rotated).To(HaveLen(len(tt.want)))
+			for _, name := range tt.want {
+				g.Expect(rotated).To(HaveKey(name))
+			}
+			g.Expect(rotated[tt.want[len(tt.want)-1]]).To(Equal(keys["0"]), "the staged key becomes the primary key")
+			g.Expect(validKeyRepository(rotated)).To(BeTrue())
+			g.Expect(keys).To(HaveLen(len(tt.keys)), "keys is not modified")
+		})
+	}
+}
+
+func TestReconcilePaused(t *testing.T) {
+	g := NewGomegaWithT(t)
+	f := newReadyFixture(t)
+	ctx := context.Background()
+	f.deploy(g)
+	hash := f.deployment(g).Spec.Template.Annotations[confighash.HashAnnotation]
+	f.events()
+
+	k := f.keystone(g)
+	k.Annotations = map[string]string{pause.Annotation: "true"}
+	g.Expect(f.c.Update(ctx, k)).To(Succeed())
+	secret := &corev1.Secret{}
+	g.Expect(f.c.Get(ctx, client.ObjectKey{Namespace: "openstack", Name: "keystone-db"}, secret)).To(Succeed())
+	secret.Data["password"] = []byte("rotated")
+	g.Expect(f.c.Update(ctx, secret)).To(Succeed())
+
+	g.Expect(f.reconcile(g)).To(Equal(ctrl.Result{}))
+	k = f.keystone(g)
+	assertions.AssertCondition(g, k.Status.Conditions, pause.ConditionType, metav1.ConditionTrue)
+	g.Expect(f.deployment(g).Spec.Template.Annotations[confighash.HashAnnotation]).To(Equal(hash), "a paused Keystone is not rolled")
+	g.Expect(f.events()).To(ConsistOf("Normal Paused Reconciliation paused by annotation " + pause.Annotation))
+
+	delete(k.Annotations, pause.Annotation)
+	g.Expect(f.c.Update(ctx, k)).To(Succeed())
+	f.reconcile(g)
+	k = f.keystone(g)
+	assertions.AssertCondition(g, k.Status.Conditions, pause.ConditionType, metav1.ConditionFalse)
+	assertions.AssertCondition(g, k.Status.Conditions, keystonev1alpha1.ConditionReady, metav1.ConditionTrue)
+	g.Expect(f.deployment(g).Spec.Template.Annotations[confighash.HashAnnotation]).NotTo(Equal(hash))
+	g.Expect(f.events()).To(ContainElement("Normal Resumed Reconciliation resumed"))
+}
+
+func TestReconcileReportsUnhealthyAPI(t *testing.T) {
+	g := NewGomegaWithT(t)
+	f := newReadyFixture(t)
+	f.api.Fail(keystonefake.Fault{Method: http.MethodPost, Path: "/v3/auth/tokens", Status: http.StatusServiceUnavailable, Times: 1})
+
+	f.deploy(g)
+	result := f.reconcile(g)
+	g.Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+	g.Expect(result.RequeueAfter).To(BeNumerically("<=", f.r.Config.APIHealthCheck.Interval.Duration))
+	k := f.keystone(g)
+	assertions.AssertCondition(g, k.Status.Conditions, keystonev1alpha1.ConditionDeploymentReady, metav1.ConditionTrue)
+	assertions.AssertCondition(g, k.Status.Conditions, keystonev1alpha1.ConditionAPIReady, metav1.ConditionFalse)
+	g.Expect(condition(k, keystonev1alpha1.ConditionAPIReady).Reason).To(Equal(keystonehealth.ReasonTokenIssueFailed))
+	assertions.AssertCondition(g, k.Status.Conditions, keystonev1alpha1.ConditionReady, metav1.ConditionFalse)
+}
+
+func TestReconcileUpgrade(t *testing.T) {
+	const newImage = "ghcr.io/c5c3/keystone:2026.1"
+	tests := []struct {
+		name         string
+		zeroDowntime bool
+	}{
+		{name: "zero downtime", zeroDowntime: true},
+		{name: "plain db_sync"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			ctx := context.Background()
+			f := newReadyFixture(t)
+			f.r.Gates = featuregate.NewDefault()
+			g.Expect(f.r.Gates.SetFromMap(map[string]bool{string(featuregate.ZeroDowntimeUpgrade): tt.zeroDowntime})).To(Succeed())
+			f.deploy(g)
+
+			k := f.keystone(g)
+			k.Spec.Image = newImage
+			g.Expect(f.c.Update(ctx, k)).To(Succeed())
+			f.reconcile(g)
+
+			// The API keeps running the old image until the schema allows
+			// the new one.
+			g.Expect(containerImage(f.deployment(g))).To(Equal(testImage))
+			plainSync := &batchv1.Job{}
+			err := f.c.Get(ctx, client.ObjectKey{Namespace: "openstack", Name: jobName(k, "db-sync", newImage)}, plainSync)
+			if tt.zeroDowntime {
+				g.Expect(f.keystone(g).Status.Upgrade.Phase).To(Equal(dbupgrade.PhaseExpanding))
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue(), "an upgrade runs no plain db_sync")
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(f.keystone(g).Status.Upgrade.Phase).To(BeEmpty())
+			}
+
+			for range 10 {
+				f.finishJobs(g)
+				f.reconcile(g)
+				f.rollOut(g)
+				f.reconcile(g)
+			}
+			k = f.keystone(g)
+			g.Expect(containerImage(f.deployment(g))).To(Equal(newImage))
+			g.Expect(k.Status.Image).To(Equal(newImage))
+			assertions.AssertCondition(g, k.Status.Conditions, keystonev1alpha1.ConditionReady, metav1.ConditionTrue)
+			if tt.zeroDowntime {
+				g.Expect(k.Status.Upgrade.Phase).To(Equal(dbupgrade.PhaseCompleted))
+				g.Expect(apierrors.IsNotFound(f.c.Get(ctx, client.ObjectKey{Namespace: "openstack", Name: jobName(k, "db-sync", newImage)}, plainSync))).To(BeTrue())
+			}
+		})
+	}
+}
+
+func TestReconcileDeletion(t *testing.T) {
+	tests := []struct {
+		policy  finalizer.DeletionPolicy
+		deleted bool
+	}{
+		{policy: ""},
+		{policy: finalizer.DeletionPolicyRetain},
+		{policy: finalizer.DeletionPolicyDelete, deleted: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			ctx := context.Background()
+			k := newKeystone()
+			k.Spec.Database.ClusterRef = &corev1.LocalObjectReference{Name: "mariadb"}
+			k.Spec.DeletionPolicy = tt.policy
+			backup := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			backup.SetGroupVersionKind(dbbackup.BackupGVK)
+			backup.SetNamespace("openstack")
+			backup.SetName("keystone-keystone-pre-2025.2")
+			backup.SetLabels(map[string]string{dbbackup.LabelOwnerUID: "keystone-uid"})
+			f := newFixture(t, k, backup,
+				newSecret("keystone-db", map[string]string{"username": "keystone", "password": "secret"}))
+
+			f.reconcile(g)
+			k = f.keystone(g)
+			g.Expect(k.Finalizers).To(ContainElement(finalizer.Name))
+			database := &unstructured.Unstructured{}
+			database.SetGroupVersionKind(databaseGVK)
+			g.Expect(f.c.Get(ctx, client.ObjectKey{Namespace: "openstack", Name: "keystone"}, database)).To(Succeed())
+			g.Expect(database.GetOwnerReferences()).To(HaveLen(1))
+
+			g.Expect(f.c.Delete(ctx, k)).To(Succeed())
+			f.reconcile(g)
+			g.Expect(apierrors.IsNotFound(f.c.Get(ctx, client.ObjectKeyFromObject(k), k))).To(BeTrue())
+
+			err := f.c.Get(ctx, client.ObjectKey{Namespace: "openstack", Name: "keystone"}, database)
+			if tt.deleted {
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				g.Expect(apierrors.IsNotFound(f.c.Get(ctx, client.ObjectKeyFromObject(backup), backup))).To(BeTrue())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(database.GetOwnerReferences()).To(BeEmpty(), "garbage collection keeps a retained Database")
+			g.Expect(f.c.Get(ctx, client.ObjectKeyFromObject(backup), backup)).To(Succeed())
+		})
+	}
+}
+
+func TestReconcileWaitsForDependencies(t *testing.T) {
+	tests := []struct {
+		name     string
+		objs     []client.Object
+		condType string
+		reason   string
+	}{
+		{
+			name:     "missing database Secret",
+			condType: keystonev1alpha1.ConditionDatabaseReady,
+			reason:   reasonSecretNotFound,
+		},
+		{
+			name:     "database Secret without password",
+			objs:     []client.Object{newSecret("keystone-db", map[string]string{"username": "keystone"})},
+			condType: keystonev1alpha1.ConditionDatabaseReady,
+			reason:   reasonInvalidSecret,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			f := newFixture(t, append(tt.objs, newKeystone())...)
+
+			result := f.reconcile(g)
+			g.Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			k := f.keystone(g)
+			assertions.AssertCondition(g, k.Status.Conditions, tt.condType, metav1.ConditionFalse)
+			g.Expect(condition(k, tt.condType).Reason).To(Equal(tt.reason))
+			g.Expect(condition(k, keystonev1alpha1.ConditionDeploymentReady).Reason).To(Equal(reasonWaitingForDependencies))
+			assertions.AssertCondition(g, k.Status.Conditions, keystonev1alpha1.ConditionReady, metav1.ConditionFalse)
+		})
+	}
+}
+
+func TestReconcileUnresolvedImage(t *testing.T) {
+	g := NewGomegaWithT(t)
+	k := newKeystone()
+	k.Spec.OpenStackRelease = "2024.1"
+	f := newFixture(t, k)
+
+	g.Expect(f.reconcile(g)).To(Equal(ctrl.Result{RequeueAfter: f.r.Config.Reconcile.RequeueInterval.Duration}),
+		"only drift detection requeues")
+	k = f.keystone(g)
+	g.Expect(condition(k, keystonev1alpha1.ConditionDeploymentReady).Reason).To(Equal(reasonImageNotResolved))
+	assertions.AssertCondition(g, k.Status.Conditions, keystonev1alpha1.ConditionReady, metav1.ConditionFalse)
+}
+
+func TestJobName(t *testing.T) {
+	g := NewGomegaWithT(t)
+	k := newKeystone()
+	g.Expect(jobName(k, "db-sync", "a")).To(MatchRegexp(`^keystone-db-sync-[0-9a-f]{8}$`))
+	g.Expect(jobName(k, "db-sync", "a")).NotTo(Equal(jobName(k, "db-sync", "b")))
+
+	k.Name = "a-very-long-keystone-name-that-does-not-leave-room-for-the-suffix"
+	g.Expect(len(jobName(k, "bootstrap", "a"))).To(BeNumerically("<=", 63))
+}
+
+func condition(k *keystonev1alpha1.Keystone, condType string) metav1.Condition {
+	for _, cond := range k.Status.Conditions {
+		if cond.Type == condType {
+			return cond
+		}
+	}
+	return metav1.Condition{}
+}
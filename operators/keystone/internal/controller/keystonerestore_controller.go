@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/c5c3/forge/internal/common/dbbackup"
+	"github.com/c5c3/forge/internal/common/events"
+	"github.com/c5c3/forge/internal/common/operatorconfig"
+	"github.com/c5c3/forge/internal/common/pause"
+	keystonev1alpha1 "github.com/c5c3/forge/operators/keystone/api/v1alpha1"
+)
+
+// KeystoneRestoreReconciler reconciles KeystoneRestore CRs by driving
+// dbbackup.StepRestore until the restore completes or fails.
+type KeystoneRestoreReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// Config provides the interval at which running restores are polled.
+	Config   *operatorconfig.Configuration
+	Recorder *events.Recorder
+}
+
+// SetupWithManager registers the reconciler with mgr. The mariadb-operator
+// Restore is polled rather than watched, so that the operator starts without
+// the mariadb-operator CRDs.
+func (r *KeystoneRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&keystonev1alpha1.KeystoneRestore{}).
+		Complete(r)
+}
+
+// Reconcile advances a restore by one phase and records it in the status.
+// A paused restore stays in its phase, with the Keystone CR paused and its
+// API scaled down.
+func (r *KeystoneRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	restore := &keystonev1alpha1.KeystoneRestore{}
+	if err := r.Get(ctx, req.NamespacedName, restore); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if paused, err := pause.Guard(ctx, r.Client, r.Recorder, restore); paused || err != nil {
+		return ctrl.Result{}, err
+	}
+	phase := restore.Status.Phase
+	if phase == dbbackup.RestorePhaseCompleted || phase == dbbackup.RestorePhaseFailed {
+		return ctrl.Result{}, nil
+	}
+	poll := ctrl.Result{RequeueAfter: r.Config.Reconcile.ErrorRequeueInterval.Duration}
+
+	keystone := &keystonev1alpha1.Keystone{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: restore.Namespace, Name: restore.Spec.KeystoneRef.Name}, keystone)
+	switch {
+	case apierrors.IsNotFound(err):
+		return poll, r.setStatus(ctx, restore, phase, fmt.Sprintf("Keystone %s not found", restore.Spec.KeystoneRef.Name))
+	case err != nil:
+		return ctrl.Result{}, fmt.Errorf("getting Keystone %s: %w", restore.Spec.KeystoneRef.Name, err)
+	case keystone.Spec.Database.ClusterRef == nil:
+		return ctrl.Result{}, r.setStatus(ctx, restore, dbbackup.RestorePhaseFailed,
+			fmt.Sprintf("Keystone %s has no spec.database.clusterRef; only databases on a MariaDB CR can be restored", keystone.Name))
+	}
+
+	next, message, err := dbbackup.StepRestore(ctx, r.Client, r.Scheme, dbbackup.RestoreRequest{
+		Owner:      restore,
+		Target:     keystone,
+		Deployment: apiName(keystone),
+		Backup:     restore.Spec.Backup,
+		MariaDB:    keystone.Spec.Database.ClusterRef.Name,
+		Database:   databaseName(keystone),
+	}, phase)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.setStatus(ctx, restore, next, message); err != nil {
+		return ctrl.Result{}, err
+	}
+	if next == dbbackup.RestorePhaseCompleted || next == dbbackup.RestorePhaseFailed {
+		return ctrl.Result{}, nil
+	}
+	return poll, nil
+}
+
+// setStatus writes phase and message to the status of restore if they
+// changed.
+func (r *KeystoneRestoreReconciler) setStatus(ctx context.Context, restore *keystonev1alpha1.KeystoneRestore, phase dbbackup.RestorePhase, message string) error {
+	if restore.Status.Phase == phase && restore.Status.Message == message {
+		return nil
+	}
+	restore.Status.Phase = phase
+	restore.Status.Message = message
+	if err := r.Status().Update(ctx, restore); err != nil {
+		return fmt.Errorf("updating status: %w", err)
+	}
+	return nil
+}
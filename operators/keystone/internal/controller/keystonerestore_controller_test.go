@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clientevents "k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/c5c3/forge/internal/common/dbbackup"
+	"github.com/c5c3/forge/internal/common/events"
+	"github.com/c5c3/forge/internal/common/operatorconfig"
+	"github.com/c5c3/forge/internal/common/pause"
+	"github.com/c5c3/forge/internal/common/testutil/assertions"
+	keystonev1alpha1 "github.com/c5c3/forge/operators/keystone/api/v1alpha1"
+)
+
+func newRestoreReconciler(g *WithT, objs ...client.Object) (*KeystoneRestoreReconciler, client.Client) {
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(keystonev1alpha1.AddToScheme(scheme)).To(Succeed())
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&keystonev1alpha1.KeystoneRestore{}, &appsv1.Deployment{}).
+		Build()
+	recorder := events.NewRecorder(clientevents.NewFakeRecorder(10), time.Minute)
+	return &KeystoneRestoreReconciler{Client: c, Scheme: scheme, Config: operatorconfig.New(), Recorder: recorder}, c
+}
+
+func newKeystoneRestore() *keystonev1alpha1.KeystoneRestore {
+	return &keystonev1alpha1.KeystoneRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "restore-1", Namespace: "openstack", UID: "restore-uid"},
+		Spec: keystonev1alpha1.KeystoneRestoreSpec{
+			KeystoneRef: corev1.LocalObjectReference{Name: "keystone"},
+			Backup:      "keystone-keystone-pre-2025.2",
+		},
+	}
+}
+
+// newCompleteBackup returns a Backup the mariadb-operator completed.
+func newCompleteBackup(name string) *unstructured.Unstructured {
+	backup := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	backup.SetGroupVersionKind(dbbackup.BackupGVK)
+	backup.SetName(name)
+	backup.SetNamespace("openstack")
+	_ = unstructured.SetNestedSlice(backup.Object, []interface{}{
+		map[string]interface{}{"type": "Complete", "status": "True", "reason": "JobComplete"},
+	}, "status", "conditions")
+	return backup
+}
+
+func reconcileRestore(g *WithT, r *KeystoneRestoreReconciler, c client.Client) *keystonev1alpha1.KeystoneRestore {
+	ctx := context.Background()
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "openstack", Name: "restore-1"}})
+	g.Expect(err).NotTo(HaveOccurred())
+	restore := &keystonev1alpha1.KeystoneRestore{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "openstack", Name: "restore-1"}, restore)).To(Succeed())
+	return restore
+}
+
+func TestReconcileRestore(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	k := newKeystone()
+	k.Spec.Database.ClusterRef = &corev1.LocalObjectReference{Name: "mariadb"}
+	replicas := int32(3)
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "keystone-api", Namespace: "openstack"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{Replicas: 3, AvailableReplicas: 3},
+	}
+	r, c := newRestoreReconciler(g, k, deploy, newKeystoneRestore(), newCompleteBackup("keystone-keystone-pre-2025.2"))
+
+	restore := reconcileRestore(g, r, c)
+	g.Expect(restore.Status.Phase).To(Equal(dbbackup.RestorePhaseScalingDown))
+
+	restore = reconcileRestore(g, r, c)
+	g.Expect(restore.Status.Phase).To(Equal(dbbackup.RestorePhaseScalingDown))
+	g.Expect(restore.Status.Message).To(Equal("Waiting for the API pods to terminate"))
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(k), k)).To(Succeed())
+	g.Expect(pause.IsPaused(k)).To(BeTrue())
+	g.Expect(k.Annotations).To(HaveKeyWithValue(pause.ByAnnotation, "KeystoneRestore/restore-1"))
+}
+
+func TestReconcileRestorePaused(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+	k := newKeystone()
+	k.Spec.Database.ClusterRef = &corev1.LocalObjectReference{Name: "mariadb"}
+	restore := newKeystoneRestore()
+	restore.Annotations = map[string]string{pause.Annotation: "true"}
+	r, c := newRestoreReconciler(g, k, restore, newCompleteBackup("keystone-keystone-pre-2025.2"))
+
+	restore = reconcileRestore(g, r, c)
+	g.Expect(restore.Status.Phase).To(BeEmpty())
+	assertions.AssertCondition(g, restore.Status.Conditions, pause.ConditionType, metav1.ConditionTrue)
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(k), k)).To(Succeed())
+	g.Expect(pause.IsPaused(k)).To(BeFalse(), "a paused restore does not start")
+}
+
+func TestReconcileRestoreFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		objs    []client.Object
+		phase   dbbackup.RestorePhase
+		message string
+	}{
+		{
+			name:    "missing Keystone",
+			message: "Keystone keystone not found",
+		},
+		{
+			name:    "Keystone without MariaDB",
+			objs:    []client.Object{newKeystone()},
+			phase:   dbbackup.RestorePhaseFailed,
+			message: "Keystone keystone has no spec.database.clusterRef; only databases on a MariaDB CR can be restored",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			r, c := newRestoreReconciler(g, append(tt.objs, newKeystoneRestore())...)
+
+			restore := reconcileRestore(g, r, c)
+			g.Expect(restore.Status.Phase).To(Equal(tt.phase))
+			g.Expect(restore.Status.Message).To(Equal(tt.message))
+		})
+	}
+}
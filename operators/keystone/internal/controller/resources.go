@@ -0,0 +1,421 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	keystonev1alpha1 "github.com/c5c3/forge/operators/keystone/api/v1alpha1"
+)
+
+// GroupVersionKinds of the resources of other operators a Keystone uses.
+var (
+	databaseGVK  = schema.GroupVersionKind{Group: "k8s.mariadb.com", Version: "v1alpha1", Kind: "Database"}
+	userGVK      = schema.GroupVersionKind{Group: "k8s.mariadb.com", Version: "v1alpha1", Kind: "User"}
+	grantGVK     = schema.GroupVersionKind{Group: "k8s.mariadb.com", Version: "v1alpha1", Kind: "Grant"}
+	memcachedGVK = schema.GroupVersionKind{Group: "opsv1.memcached.com", Version: "v1alpha1", Kind: "Memcached"}
+)
+
+const (
+	// apiContainer is the name of the API container in the Deployment.
+	apiContainer = "keystone-api"
+	// apiPort is the port the API listens on and the Service exposes.
+	apiPort int32 = 5000
+
+	// configKey is the key of keystone.conf in the ConfigMap.
+	configKey = "keystone.conf"
+	// Paths the configuration and the key repositories are mounted at.
+	configPath         = "/etc/keystone/keystone.conf"
+	fernetKeysPath     = "/etc/keystone/fernet-keys"
+	credentialKeysPath = "/etc/keystone/credential-keys"
+
+	// Keys of the Secret named by spec.database.secretRef.
+	dbUsernameKey = "username"
+	dbPasswordKey = "password"
+
+	// defaultDatabaseName is the schema used unless spec.database.name is
+	// set.
+	defaultDatabaseName = "keystone"
+	// adminProject is the project keystone-manage bootstrap creates for the
+	// admin user.
+	adminProject = "admin"
+
+	mysqlPort     = "3306"
+	memcachedPort = "11211"
+)
+
+func configMapName(k *keystonev1alpha1.Keystone) string {
+	return k.Name + "-config"
+}
+
+func fernetKeysName(k *keystonev1alpha1.Keystone) string {
+	return k.Name + "-fernet-keys"
+}
+
+func credentialKeysName(k *keystonev1alpha1.Keystone) string {
+	return k.Name + "-credential-keys"
+}
+
+func apiName(k *keystonev1alpha1.Keystone) string {
+	return k.Name + "-api"
+}
+
+// jobName returns the name of a Job of k running task, e.g. "db-sync". Jobs
+// are immutable, so every content their spec depends on, such as the image,
+// gets its own Job.
+func jobName(k *keystonev1alpha1.Keystone, task, content string) string {
+	sum := sha256.Sum256([]byte(content))
+	suffix := "-" + task + "-" + hex.EncodeToString(sum[:4])
+	const maxLen = 63
+	name := k.Name
+	if len(name)+len(suffix) > maxLen {
+		name = strings.TrimRight(name[:maxLen-len(suffix)], "-.")
+	}
+	return name + suffix
+}
+
+// selectorLabels select the API pods of k.
+func selectorLabels(k *keystonev1alpha1.Keystone) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":      "keystone",
+		"app.kubernetes.io/instance":  k.Name,
+		"app.kubernetes.io/component": "api",
+	}
+}
+
+// commonLabels are set on every child of k.
+func commonLabels(k *keystonev1alpha1.Keystone) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":       "keystone",
+		"app.kubernetes.io/instance":   k.Name,
+		"app.kubernetes.io/managed-by": "forge-keystone-operator",
+	}
+}
+
+func replicas(k *keystonev1alpha1.Keystone) int32 {
+	if k.Spec.Replicas != nil {
+		return *k.Spec.Replicas
+	}
+	return keystonev1alpha1.DefaultReplicas
+}
+
+func databaseName(k *keystonev1alpha1.Keystone) string {
+	if k.Spec.Database.Name != "" {
+		return k.Spec.Database.Name
+	}
+	return defaultDatabaseName
+}
+
+func adminUser(k *keystonev1alpha1.Keystone) string {
+	if k.Spec.Bootstrap.AdminUser != "" {
+		return k.Spec.Bootstrap.AdminUser
+	}
+	return keystonev1alpha1.DefaultAdminUser
+}
+
+func region(k *keystonev1alpha1.Keystone) string {
+	if k.Spec.Bootstrap.Region != "" {
+		return k.Spec.Bootstrap.Region
+	}
+	return keystonev1alpha1.DefaultRegion
+}
+
+func rotationSchedule(k *keystonev1alpha1.Keystone) string {
+	if k.Spec.Fernet.RotationSchedule != "" {
+		return k.Spec.Fernet.RotationSchedule
+	}
+	return keystonev1alpha1.DefaultRotationSchedule
+}
+
+func maxActiveKeys(k *keystonev1alpha1.Keystone) int32 {
+	if k.Spec.Fernet.MaxActiveKeys != 0 {
+		return k.Spec.Fernet.MaxActiveKeys
+	}
+	return keystonev1alpha1.DefaultMaxActiveKeys
+}
+
+// databaseAddress returns the host:port of the database server of k. A
+// MariaDB CR is reached through the Service of the same name.
+func databaseAddress(k *keystonev1alpha1.Keystone) string {
+	if ref := k.Spec.Database.ClusterRef; ref != nil {
+		return net.JoinHostPort(fmt.Sprintf("%s.%s.svc", ref.Name, k.Namespace), mysqlPort)
+	}
+	if _, _, err := net.SplitHostPort(k.Spec.Database.Host); err == nil {
+		return k.Spec.Database.Host
+	}
+	return net.JoinHostPort(k.Spec.Database.Host, mysqlPort)
+}
+
+// cacheServers returns the memcached servers of k as host:port. A Memcached
+// CR is reached through the Service of the same name.
+func cacheServers(k *keystonev1alpha1.Keystone) []string {
+	if ref := k.Spec.Cache.ClusterRef; ref != nil {
+		return []string{net.JoinHostPort(fmt.Sprintf("%s.%s.svc", ref.Name, k.Namespace), memcachedPort)}
+	}
+	return k.Spec.Cache.Servers
+}
+
+// renderConfig renders keystone.conf. The database connection contains the
+// password, so it is passed through the environment instead; see dbEnv.
+func renderConfig(k *keystonev1alpha1.Keystone) string {
+	var b strings.Builder
+	b.WriteString("[DEFAULT]\ndebug = false\n")
+	if servers := cacheServers(k); len(servers) > 0 {
+		fmt.Fprintf(&b, "\n[cache]\nenabled = true\nbackend = oslo_cache.memcache_pool\nmemcache_servers = %s\n",
+			strings.Join(servers, ","))
+	}
+	fmt.Fprintf(&b, "\n[credential]\nkey_repository = %s\n", credentialKeysPath)
+	b.WriteString("\n[database]\nmax_retries = -1\n")
+	fmt.Fprintf(&b, "\n[fernet_tokens]\nkey_repository = %s\nmax_active_keys = %d\n", fernetKeysPath, maxActiveKeys(k))
+	b.WriteString("\n[token]\nprovider = fernet\n")
+	return b.String()
+}
+
+func renderConfigMap(k *keystonev1alpha1.Keystone) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapName(k),
+			Namespace: k.Namespace,
+			Labels:    commonLabels(k),
+		},
+		Data: map[string]string{configKey: renderConfig(k)},
+	}
+}
+
+// renderKeySecret renders a key repository Secret with the given keys.
+func renderKeySecret(k *keystonev1alpha1.Keystone, name string, keys map[string][]byte, annotations map[string]string) *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   k.Namespace,
+			Labels:      commonLabels(k),
+			Annotations: annotations,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: keys,
+	}
+}
+
+// dbEnv passes the database credentials and the connection URL to
+// keystone.conf through oslo.config's environment source. The credentials
+// are interpolated by the kubelet, so they must not contain characters that
+// need escaping in a URL.
+func dbEnv(k *keystonev1alpha1.Keystone) []corev1.EnvVar {
+	secretKey := func(key string) *corev1.EnvVarSource {
+		return &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: k.Spec.Database.SecretRef,
+			Key:                  key,
+		}}
+	}
+	return []corev1.EnvVar{
+		{Name: "DB_USERNAME", ValueFrom: secretKey(dbUsernameKey)},
+		{Name: "DB_PASSWORD", ValueFrom: secretKey(dbPasswordKey)},
+		{
+			Name: "OS_DATABASE__CONNECTION",
+			Value: fmt.Sprintf("mysql+pymysql://$(DB_USERNAME):$(DB_PASSWORD)@%s/%s",
+				databaseAddress(k), databaseName(k)),
+		},
+	}
+}
+
+// volumes are the configuration and key repositories every Keystone pod
+// mounts.
+func volumes(k *keystonev1alpha1.Keystone) []corev1.Volume {
+	return []corev1.Volume{
+		{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: configMapName(k)},
+		}}},
+		{Name: "fernet-keys", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+			SecretName: fernetKeysName(k),
+		}}},
+		{Name: "credential-keys", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+			SecretName: credentialKeysName(k),
+		}}},
+	}
+}
+
+func volumeMounts() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{Name: "config", MountPath: configPath, SubPath: configKey, ReadOnly: true},
+		{Name: "fernet-keys", MountPath: fernetKeysPath, ReadOnly: true},
+		{Name: "credential-keys", MountPath: credentialKeysPath, ReadOnly: true},
+	}
+}
+
+// renderDeployment renders the API Deployment running image. The pod
+// template is stamped with the config hashes by the caller.
+func renderDeployment(k *keystonev1alpha1.Keystone, image string) *appsv1.Deployment {
+	replicas := replicas(k)
+	podLabels := selectorLabels(k)
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      apiName(k),
+			Namespace: k.Namespace,
+			Labels:    commonLabels(k),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: selectorLabels(k)},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:         apiContainer,
+						Image:        image,
+						Ports:        []corev1.ContainerPort{{Name: "http", ContainerPort: apiPort, Protocol: corev1.ProtocolTCP}},
+						Env:          dbEnv(k),
+						VolumeMounts: volumeMounts(),
+						ReadinessProbe: &corev1.Probe{
+							ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{
+								Path: "/v3", Port: intstr.FromString("http"),
+							}},
+							PeriodSeconds: 10,
+						},
+					}},
+					Volumes: volumes(k),
+				},
+			},
+		},
+	}
+}
+
+func renderService(k *keystonev1alpha1.Keystone) *corev1.Service {
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      apiName(k),
+			Namespace: k.Namespace,
+			Labels:    commonLabels(k),
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: selectorLabels(k),
+			Ports: []corev1.ServicePort{{
+				Name:       "http",
+				Port:       apiPort,
+				TargetPort: intstr.FromString("http"),
+				Protocol:   corev1.ProtocolTCP,
+			}},
+		},
+	}
+}
+
+// jobSpec returns the spec of a Job running keystone-manage with args in
+// image, with the same configuration as the API pods.
+func jobSpec(k *keystonev1alpha1.Keystone, image string, env []corev1.EnvVar, args ...string) batchv1.JobSpec {
+	backoffLimit := int32(3)
+	return batchv1.JobSpec{
+		BackoffLimit: &backoffLimit,
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: commonLabels(k)},
+			Spec: corev1.PodSpec{
+				RestartPolicy: corev1.RestartPolicyNever,
+				Containers: []corev1.Container{{
+					Name:         "keystone-manage",
+					Image:        image,
+					Command:      append([]string{"keystone-manage"}, args...),
+					Env:          append(dbEnv(k), env...),
+					VolumeMounts: volumeMounts(),
+				}},
+				Volumes: volumes(k),
+			},
+		},
+	}
+}
+
+// dbSyncJobSpec returns the spec of the Job running db_sync with image and
+// the given flags, e.g. "--expand".
+func dbSyncJobSpec(k *keystonev1alpha1.Keystone, image string, flags ...string) batchv1.JobSpec {
+	return jobSpec(k, image, nil, append([]string{"db_sync"}, flags...)...)
+}
+
+// bootstrapJobSpec returns the spec of the Job creating the admin user,
+// project and identity endpoints at endpoint.
+func bootstrapJobSpec(k *keystonev1alpha1.Keystone, image, endpoint string) batchv1.JobSpec {
+	env := []corev1.EnvVar{{
+		Name: "OS_BOOTSTRAP_PASSWORD",
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: k.Spec.Bootstrap.AdminPasswordSecretRef.LocalObjectReference,
+			Key:                  k.Spec.Bootstrap.AdminPasswordSecretRef.Key,
+		}},
+	}}
+	return jobSpec(k, image, env, "bootstrap",
+		"--bootstrap-username", adminUser(k),
+		"--bootstrap-project-name", adminProject,
+		"--bootstrap-role-name", "admin",
+		"--bootstrap-service-name", "keystone",
+		"--bootstrap-region-id", region(k),
+		"--bootstrap-admin-url", endpoint,
+		"--bootstrap-internal-url", endpoint,
+		"--bootstrap-public-url", endpoint,
+	)
+}
+
+// renderDatabase renders the mariadb-operator Database, User and Grant of k
+// on the MariaDB spec.database.clusterRef names, for the SQL user username.
+func renderDatabase(k *keystonev1alpha1.Keystone, username string) []*unstructured.Unstructured {
+	mariaDBRef := map[string]interface{}{"name": k.Spec.Database.ClusterRef.Name}
+	database := newUnstructured(databaseGVK, k, map[string]interface{}{
+		"mariaDbRef":   mariaDBRef,
+		"name":         databaseName(k),
+		"characterSet": "utf8",
+		"collate":      "utf8_general_ci",
+	})
+	user := newUnstructured(userGVK, k, map[string]interface{}{
+		"mariaDbRef": mariaDBRef,
+		"name":       username,
+		"passwordSecretKeyRef": map[string]interface{}{
+			"name": k.Spec.Database.SecretRef.Name,
+			"key":  dbPasswordKey,
+		},
+		"host": "%",
+	})
+	grant := newUnstructured(grantGVK, k, map[string]interface{}{
+		"mariaDbRef": mariaDBRef,
+		"privileges": []interface{}{"ALL PRIVILEGES"},
+		"database":   databaseName(k),
+		"table":      "*",
+		"username":   username,
+		"host":       "%",
+	})
+	return []*unstructured.Unstructured{database, user, grant}
+}
+
+// databaseRefs returns references to the mariadb-operator Database, User and
+// Grant of k, which only carry their kind, namespace and name.
+func databaseRefs(k *keystonev1alpha1.Keystone) []client.Object {
+	if k.Spec.Database.ClusterRef == nil {
+		return nil
+	}
+	return []client.Object{
+		newUnstructured(databaseGVK, k, nil),
+		newUnstructured(userGVK, k, nil),
+		newUnstructured(grantGVK, k, nil),
+	}
+}
+
+// newUnstructured returns an object of kind gvk named after k with spec.
+func newUnstructured(gvk schema.GroupVersionKind, k *keystonev1alpha1.Keystone, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	if spec != nil {
+		obj.Object["spec"] = spec
+		obj.SetLabels(commonLabels(k))
+	}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(k.Name)
+	obj.SetNamespace(k.Namespace)
+	return obj
+}
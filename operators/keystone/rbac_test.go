@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/c5c3/forge/internal/common/operatorconfig"
+	"github.com/c5c3/forge/internal/common/scope"
+)
+
+func TestRBACModesGrantSameRules(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cluster := scope.Scope{}.OperatorRBAC(rbacName, serviceAccount, rbacRules)
+	namespaced := scope.Scope{Namespaces: []string{"openstack"}}.OperatorRBAC(rbacName, serviceAccount, rbacRules)
+
+	clusterRole, ok := cluster[0].(*rbacv1.ClusterRole)
+	g.Expect(ok).To(BeTrue())
+	role, ok := namespaced[0].(*rbacv1.Role)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(role.Namespace).To(Equal("openstack"))
+	g.Expect(role.Rules).To(Equal(clusterRole.Rules))
+
+	// The leader election objects do not depend on the watch scope.
+	g.Expect(namespaced[2:]).To(Equal(cluster[2:]))
+}
+
+func TestRenderedRBACIsUpToDate(t *testing.T) {
+	tests := []struct {
+		file       string
+		namespaces []string
+	}{
+		{file: "cluster.yaml"},
+		{file: "namespaced.yaml", namespaces: []string{"openstack"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			cfg := operatorconfig.New()
+			cfg.WatchNamespaces = tt.namespaces
+			var buf bytes.Buffer
+			g.Expect(writeRBAC(context.Background(), &buf, nil, cfg)).To(Succeed())
+
+			committed, err := os.ReadFile(filepath.Join("..", "..", "config", "rbac", "keystone", tt.file))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(buf.String()).To(Equal(string(committed)), "run make manifests")
+		})
+	}
+}
+
+func TestRenderRBACResolvesSelector(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	reader := fake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Labels: map[string]string{"tenant": "true"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+	).Build()
+	cfg := operatorconfig.New()
+	cfg.WatchNamespaceSelector = "tenant=true"
+
+	var buf bytes.Buffer
+	g.Expect(writeRBAC(context.Background(), &buf, reader, cfg)).To(Succeed())
+	out := buf.String()
+	g.Expect(out).To(ContainSubstring("kind: Role\n"))
+	g.Expect(out).To(ContainSubstring("namespace: tenant-a"))
+	g.Expect(out).NotTo(ContainSubstring("namespace: other"))
+	g.Expect(out).To(ContainSubstring("name: " + rbacName + "-namespace-reader"))
+	g.Expect(out).To(ContainSubstring("- namespaces"))
+}
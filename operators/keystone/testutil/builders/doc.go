@@ -0,0 +1,6 @@
+// Package builders provides a fluent builder for Keystone CRs in tests.
+//
+// It complements the builders of the dependency CRs in
+// internal/common/testutil/builders: a Keystone from NewKeystoneBuilder
+// refers to the MariaDB and Memcached their default builders create.
+package builders
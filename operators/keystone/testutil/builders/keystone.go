@@ -0,0 +1,204 @@
+package builders
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/c5c3/forge/internal/common/finalizer"
+	commonbuilders "github.com/c5c3/forge/internal/common/testutil/builders"
+	keystonev1alpha1 "github.com/c5c3/forge/operators/keystone/api/v1alpha1"
+)
+
+// DefaultOpenStackRelease is the release the Keystone builder, and the
+// ControlPlane builder of the c5c3 operator, use unless WithOpenStackRelease
+// is called.
+const DefaultOpenStackRelease = "2025.2"
+
+// KeystoneBuilder provides a fluent builder pattern for constructing Keystone
+// CRs, primarily intended for use in tests.
+type KeystoneBuilder struct {
+	keystone keystonev1alpha1.Keystone
+}
+
+// NewKeystoneBuilder creates a KeystoneBuilder for a Keystone named
+// "keystone" in the shared builders' DefaultNamespace. It uses the MariaDB
+// and Memcached their default MariaDBBuilder and MemcachedBuilder create, the database
+// credentials in the Secret "keystone-db" and the admin password in the key
+// "password" of the Secret "keystone-admin".
+func NewKeystoneBuilder() *KeystoneBuilder {
+	b := &KeystoneBuilder{}
+	b.keystone.SetGroupVersionKind(keystonev1alpha1.GroupVersion.WithKind("Keystone"))
+	b.keystone.Name = "keystone"
+	b.keystone.Namespace = commonbuilders.DefaultNamespace
+	b.keystone.Spec = keystonev1alpha1.KeystoneSpec{
+		OpenStackRelease: DefaultOpenStackRelease,
+		Database: keystonev1alpha1.DatabaseSpec{
+			ClusterRef: &corev1.LocalObjectReference{Name: "mariadb"},
+			SecretRef:  corev1.LocalObjectReference{Name: "keystone-db"},
+		},
+		Cache: keystonev1alpha1.CacheSpec{
+			ClusterRef: &corev1.LocalObjectReference{Name: "memcached"},
+		},
+		Bootstrap: keystonev1alpha1.BootstrapSpec{
+			AdminPasswordSecretRef: corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "keystone-admin"},
+				Key:                  "password",
+			},
+		},
+	}
+	return b
+}
+
+// WithName sets the metadata name of the Keystone.
+func (b *KeystoneBuilder) WithName(name string) *KeystoneBuilder {
+	b.keystone.Name = name
+	return b
+}
+
+// WithNamespace sets the metadata namespace of the Keystone.
+func (b *KeystoneBuilder) WithNamespace(namespace string) *KeystoneBuilder {
+	b.keystone.Namespace = namespace
+	return b
+}
+
+// WithLabels merges the provided labels into the Keystone's existing labels.
+func (b *KeystoneBuilder) WithLabels(labels map[string]string) *KeystoneBuilder {
+	b.keystone.Labels = mergeStrings(b.keystone.Labels, labels)
+	return b
+}
+
+// WithAnnotations merges the provided annotations into the Keystone's
+// existing annotations.
+func (b *KeystoneBuilder) WithAnnotations(annotations map[string]string) *KeystoneBuilder {
+	b.keystone.Annotations = mergeStrings(b.keystone.Annotations, annotations)
+	return b
+}
+
+// WithOwnerRef appends an OwnerReference to the Keystone's metadata.
+func (b *KeystoneBuilder) WithOwnerRef(ref metav1.OwnerReference) *KeystoneBuilder {
+	b.keystone.OwnerReferences = append(b.keystone.OwnerReferences, ref)
+	return b
+}
+
+// WithReplicas sets spec.replicas.
+func (b *KeystoneBuilder) WithReplicas(replicas int32) *KeystoneBuilder {
+	b.keystone.Spec.Replicas = &replicas
+	return b
+}
+
+// WithOpenStackRelease sets spec.openStackRelease.
+func (b *KeystoneBuilder) WithOpenStackRelease(release string) *KeystoneBuilder {
+	b.keystone.Spec.OpenStackRelease = release
+	return b
+}
+
+// WithImage sets spec.image.
+func (b *KeystoneBuilder) WithImage(image string) *KeystoneBuilder {
+	b.keystone.Spec.Image = image
+	return b
+}
+
+// WithDatabaseClusterRef makes Keystone use the MariaDB CR name and clears
+// spec.database.host.
+func (b *KeystoneBuilder) WithDatabaseClusterRef(name string) *KeystoneBuilder {
+	b.keystone.Spec.Database.ClusterRef = &corev1.LocalObjectReference{Name: name}
+	b.keystone.Spec.Database.Host = ""
+	return b
+}
+
+// WithDatabaseHost makes Keystone use the external database server host and
+// clears spec.database.clusterRef.
+func (b *KeystoneBuilder) WithDatabaseHost(host string) *KeystoneBuilder {
+	b.keystone.Spec.Database.Host = host
+	b.keystone.Spec.Database.ClusterRef = nil
+	return b
+}
+
+// WithDatabaseSecret sets spec.database.secretRef.
+func (b *KeystoneBuilder) WithDatabaseSecret(name string) *KeystoneBuilder {
+	b.keystone.Spec.Database.SecretRef = corev1.LocalObjectReference{Name: name}
+	return b
+}
+
+// WithCacheClusterRef makes Keystone use the Memcached CR name and clears
+// spec.cache.servers.
+func (b *KeystoneBuilder) WithCacheClusterRef(name string) *KeystoneBuilder {
+	b.keystone.Spec.Cache = keystonev1alpha1.CacheSpec{ClusterRef: &corev1.LocalObjectReference{Name: name}}
+	return b
+}
+
+// WithCacheServers makes Keystone use the external memcached servers and
+// clears spec.cache.clusterRef.
+func (b *KeystoneBuilder) WithCacheServers(servers ...string) *KeystoneBuilder {
+	b.keystone.Spec.Cache = keystonev1alpha1.CacheSpec{Servers: servers}
+	return b
+}
+
+// WithFernet sets the fernet rotation schedule and the number of active
+// keys.
+func (b *KeystoneBuilder) WithFernet(schedule string, maxActiveKeys int32) *KeystoneBuilder {
+	b.keystone.Spec.Fernet = keystonev1alpha1.FernetSpec{RotationSchedule: schedule, MaxActiveKeys: maxActiveKeys}
+	return b
+}
+
+// WithAdminPasswordSecretKeyRef sets spec.bootstrap.adminPasswordSecretRef.
+func (b *KeystoneBuilder) WithAdminPasswordSecretKeyRef(name, key string) *KeystoneBuilder {
+	b.keystone.Spec.Bootstrap.AdminPasswordSecretRef = corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: name},
+		Key:                  key,
+	}
+	return b
+}
+
+// WithRegion sets spec.bootstrap.region.
+func (b *KeystoneBuilder) WithRegion(region string) *KeystoneBuilder {
+	b.keystone.Spec.Bootstrap.Region = region
+	return b
+}
+
+// WithDeletionPolicy sets spec.deletionPolicy.
+func (b *KeystoneBuilder) WithDeletionPolicy(policy finalizer.DeletionPolicy) *KeystoneBuilder {
+	b.keystone.Spec.DeletionPolicy = policy
+	return b
+}
+
+// WithConditions replaces status.conditions. Create does not persist the
+// status; tests update the status subresource after creating the Keystone.
+func (b *KeystoneBuilder) WithConditions(conditions ...metav1.Condition) *KeystoneBuilder {
+	b.keystone.Status.Conditions = conditions
+	return b
+}
+
+// Build returns a deep copy of the constructed Keystone. Calling Build
+// multiple times returns independent objects that can be modified without
+// affecting each other or the builder's internal state.
+func (b *KeystoneBuilder) Build() *keystonev1alpha1.Keystone {
+	return b.keystone.DeepCopy()
+}
+
+// Create builds the Keystone and creates it in the cluster using the
+// provided controller-runtime client, whose scheme must include the Keystone
+// API.
+func (b *KeystoneBuilder) Create(ctx context.Context, c client.Client) (*keystonev1alpha1.Keystone, error) {
+	keystone := b.Build()
+	if err := c.Create(ctx, keystone); err != nil {
+		return nil, fmt.Errorf("creating Keystone %s/%s: %w", keystone.Namespace, keystone.Name, err)
+	}
+	return keystone, nil
+}
+
+// mergeStrings returns dst with all entries of src added, allocating dst if
+// needed.
+func mergeStrings(dst, src map[string]string) map[string]string {
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
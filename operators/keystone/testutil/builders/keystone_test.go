@@ -0,0 +1,99 @@
+package builders
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/c5c3/forge/internal/common/finalizer"
+	commonbuilders "github.com/c5c3/forge/internal/common/testutil/builders"
+	keystonev1alpha1 "github.com/c5c3/forge/operators/keystone/api/v1alpha1"
+)
+
+func TestKeystoneBuilder_Defaults(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	keystone := NewKeystoneBuilder().Build()
+
+	g.Expect(keystone.GroupVersionKind()).To(Equal(keystonev1alpha1.GroupVersion.WithKind("Keystone")))
+	g.Expect(keystone.Name).To(Equal("keystone"))
+	g.Expect(keystone.Namespace).To(Equal(commonbuilders.DefaultNamespace))
+	g.Expect(keystone.Spec.OpenStackRelease).To(Equal(DefaultOpenStackRelease))
+	g.Expect(keystone.Spec.Database.ClusterRef.Name).To(Equal(commonbuilders.NewMariaDBBuilder().Build().GetName()))
+	g.Expect(keystone.Spec.Cache.ClusterRef.Name).To(Equal(commonbuilders.NewMemcachedBuilder().Build().GetName()))
+	g.Expect(keystone.Spec.Bootstrap.AdminPasswordSecretRef.Name).To(Equal("keystone-admin"))
+}
+
+func TestKeystoneBuilder_FullChain(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	keystone := NewKeystoneBuilder().
+		WithName("identity").
+		WithNamespace("openstack").
+		WithLabels(map[string]string{"app": "keystone"}).
+		WithReplicas(2).
+		WithOpenStackRelease("2026.1").
+		WithImage("registry.example/keystone:2026.1").
+		WithDatabaseHost("db.example").
+		WithDatabaseSecret("db-creds").
+		WithCacheServers("memcached-0:11211", "memcached-1:11211").
+		WithFernet("0 * * * *", 5).
+		WithAdminPasswordSecretKeyRef("admin", "pw").
+		WithRegion("RegionTwo").
+		WithDeletionPolicy(finalizer.DeletionPolicyDelete).
+		Build()
+
+	g.Expect(keystone.Name).To(Equal("identity"))
+	g.Expect(keystone.Namespace).To(Equal("openstack"))
+	g.Expect(keystone.Labels).To(HaveKeyWithValue("app", "keystone"))
+	g.Expect(*keystone.Spec.Replicas).To(Equal(int32(2)))
+	g.Expect(keystone.Spec.OpenStackRelease).To(Equal("2026.1"))
+	g.Expect(keystone.Spec.Image).To(Equal("registry.example/keystone:2026.1"))
+	g.Expect(keystone.Spec.Database.Host).To(Equal("db.example"))
+	g.Expect(keystone.Spec.Database.ClusterRef).To(BeNil())
+	g.Expect(keystone.Spec.Database.SecretRef.Name).To(Equal("db-creds"))
+	g.Expect(keystone.Spec.Cache.ClusterRef).To(BeNil())
+	g.Expect(keystone.Spec.Cache.Servers).To(Equal([]string{"memcached-0:11211", "memcached-1:11211"}))
+	g.Expect(keystone.Spec.Fernet).To(Equal(keystonev1alpha1.FernetSpec{RotationSchedule: "0 * * * *", MaxActiveKeys: 5}))
+	g.Expect(keystone.Spec.Bootstrap.AdminPasswordSecretRef.Name).To(Equal("admin"))
+	g.Expect(keystone.Spec.Bootstrap.AdminPasswordSecretRef.Key).To(Equal("pw"))
+	g.Expect(keystone.Spec.Bootstrap.Region).To(Equal("RegionTwo"))
+	g.Expect(keystone.Spec.DeletionPolicy).To(Equal(finalizer.DeletionPolicyDelete))
+}
+
+func TestKeystoneBuilder_Build_IndependentCopies(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	builder := NewKeystoneBuilder().WithReplicas(3).WithCacheServers("a:11211")
+	first := builder.Build()
+	first.Spec.Cache.Servers[0] = "changed"
+	*first.Spec.Replicas = 1
+	first.Spec.Database.ClusterRef.Name = "changed"
+
+	second := builder.Build()
+	g.Expect(second.Spec.Cache.Servers).To(Equal([]string{"a:11211"}))
+	g.Expect(*second.Spec.Replicas).To(Equal(int32(3)))
+	g.Expect(second.Spec.Database.ClusterRef.Name).To(Equal("mariadb"))
+}
+
+func TestKeystoneBuilder_Create(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(keystonev1alpha1.AddToScheme(scheme)).To(Succeed())
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	created, err := NewKeystoneBuilder().WithNamespace("openstack").Create(context.Background(), c)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	fetched := &keystonev1alpha1.Keystone{}
+	g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(created), fetched)).To(Succeed())
+	g.Expect(fetched.Spec.Database.ClusterRef.Name).To(Equal("mariadb"))
+
+	_, err = NewKeystoneBuilder().WithNamespace("openstack").Create(context.Background(), c)
+	g.Expect(err).To(MatchError(ContainSubstring("creating Keystone openstack/keystone")))
+}